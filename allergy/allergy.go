@@ -0,0 +1,189 @@
+// Package allergy checks logged meals against a user's recorded allergies
+// and reports the foods that may set them off.
+package allergy
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/Universal-Selfcare/utils/data"
+	"github.com/Universal-Selfcare/utils/search"
+)
+
+// Match tells how a food was found to contain an allergy.
+type Match string
+
+const (
+	MatchName     Match = "name"
+	MatchSynonym  Match = "synonym"
+	MatchAllergen Match = "allergen"
+)
+
+// Warning is a food logged in a meal that matches one of the user's
+// allergies. Exactly one of MealFoodID and CustomFoodID is set. Allergens
+// lists the allergen tags shared by the food and the allergy when Match is
+// MatchAllergen.
+type Warning struct {
+	MealEntryID  int64    `json:"meal_entry_id"`
+	MealFoodID   int64    `json:"meal_food_id,omitempty"`
+	CustomFoodID int64    `json:"custom_food_id,omitempty"`
+	FoodName     string   `json:"food_name"`
+	AllergyID    int64    `json:"allergy_id"`
+	AllergyName  string   `json:"allergy_name"`
+	Reaction     string   `json:"reaction"`
+	Match        Match    `json:"match"`
+	Allergens    []string `json:"allergens,omitempty"`
+}
+
+// Notifier tells a user's caregivers about allergy warnings raised for a
+// meal the user logged.
+type Notifier interface {
+	NotifyCaregivers(
+		ctx context.Context,
+		entry *data.MealEntry,
+		caregivers []*data.Caregiver,
+		warnings []Warning,
+	) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface.
+type NotifierFunc func(
+	ctx context.Context,
+	entry *data.MealEntry,
+	caregivers []*data.Caregiver,
+	warnings []Warning,
+) error
+
+func (fn NotifierFunc) NotifyCaregivers(
+	ctx context.Context,
+	entry *data.MealEntry,
+	caregivers []*data.Caregiver,
+	warnings []Warning,
+) error {
+	return fn(ctx, entry, caregivers, warnings)
+}
+
+// Checker matches the foods of meal entries against the allergies of the
+// user who logged them.
+type Checker struct {
+	Stores *data.Stores
+
+	// Notifier, when set, receives the warnings of every meal checked with
+	// CheckAndNotify for users who have caregivers.
+	Notifier Notifier
+}
+
+func NewChecker(stores *data.Stores) *Checker {
+	return &Checker{Stores: stores}
+}
+
+// CheckMealEntry matches every food of a meal entry against the user's
+// allergies. A food is matched by its name and synonyms, which must contain
+// the allergy's name as whole words ignoring case and plurals, and by its
+// allergen tags when the allergy names an allergen, e.g. "Dairy". Custom
+// foods named exactly like a catalog item are checked as that item;
+// otherwise only their name is matched.
+func (checker *Checker) CheckMealEntry(ctx context.Context, entry *data.MealEntry) ([]Warning, error) {
+	allergies, err := checker.Stores.AllergyStore.ListUserAllergies(ctx, entry.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(allergies) == 0 {
+		return nil, nil
+	}
+
+	var warnings []Warning
+
+	mealFoods, err := checker.Stores.MealFoodStore.GetMealFoodsForMeal(ctx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, mealFood := range mealFoods {
+		item, err := checker.Stores.FoodItemStore.GetFoodItem(ctx, mealFood.FoodItemID)
+		if errors.Is(err, data.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, warning := range matchFoodItem(allergies, item) {
+			warning.MealEntryID, warning.MealFoodID = entry.ID, mealFood.ID
+			warnings = append(warnings, warning)
+		}
+	}
+
+	customFoods, err := checker.Stores.CustomFoodStore.GetCustomFoodsForMeal(ctx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, customFood := range customFoods {
+		var found []Warning
+		item, err := checker.Stores.FoodItemStore.GetFoodItemByName(ctx, customFood.Name)
+		switch {
+		case err == nil:
+			found = matchFoodItem(allergies, item)
+		case errors.Is(err, data.ErrRecordNotFound):
+			found = matchFoodItem(allergies, &data.FoodItem{Name: customFood.Name})
+		default:
+			return nil, err
+		}
+		for _, warning := range found {
+			warning.MealEntryID, warning.CustomFoodID = entry.ID, customFood.ID
+			warning.FoodName = customFood.Name
+			warnings = append(warnings, warning)
+		}
+	}
+
+	return warnings, nil
+}
+
+// CheckAndNotify checks a meal entry like CheckMealEntry and, if any
+// warnings are raised, reports them to the user's caregivers through
+// Notifier. The warnings are returned even if notifying fails.
+func (checker *Checker) CheckAndNotify(ctx context.Context, entry *data.MealEntry) ([]Warning, error) {
+	warnings, err := checker.CheckMealEntry(ctx, entry)
+	if err != nil || len(warnings) == 0 || checker.Notifier == nil {
+		return warnings, err
+	}
+
+	caregivers, err := checker.Stores.CaregiverStore.ListUserCaregivers(ctx, entry.UserID)
+	if err != nil {
+		return warnings, err
+	}
+	if len(caregivers) == 0 {
+		return warnings, nil
+	}
+	return warnings, checker.Notifier.NotifyCaregivers(ctx, entry, caregivers, warnings)
+}
+
+// matchFoodItem returns a warning for every allergy item matches, by the
+// strongest kind of match found.
+func matchFoodItem(allergies []*data.Allergy, item *data.FoodItem) []Warning {
+	var warnings []Warning
+	for _, allergy := range allergies {
+		warning := Warning{
+			FoodName:    item.Name,
+			AllergyID:   allergy.ID,
+			AllergyName: allergy.AllergyName,
+			Reaction:    allergy.Reaction,
+		}
+
+		allergen, _ := data.ParseAllergen(allergy.AllergyName)
+		switch {
+		case search.ContainsPhrase(item.Name, allergy.AllergyName):
+			warning.Match = MatchName
+		case slices.ContainsFunc(item.SynonymList(), func(synonym string) bool {
+			return search.ContainsPhrase(synonym, allergy.AllergyName)
+		}):
+			warning.Match = MatchSynonym
+		case item.Allergens.Has(allergen):
+			warning.Match = MatchAllergen
+			warning.Allergens = (item.Allergens & allergen).Names()
+		default:
+			continue
+		}
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
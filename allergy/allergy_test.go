@@ -0,0 +1,182 @@
+package allergy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func TestMatchFoodItem(t *testing.T) {
+	peanutButter := &data.FoodItem{
+		Name:      "Peanut butter",
+		Synonyms:  "groundnut paste",
+		Allergens: data.AllergenPeanuts,
+	}
+	cheddar := &data.FoodItem{Name: "Cheddar", Synonyms: "hard cheese", Allergens: data.AllergenMilk}
+	pineapple := &data.FoodItem{Name: "Pineapple"}
+	tiramisu := &data.FoodItem{
+		Name:      "Tiramisu",
+		Allergens: data.AllergenMilk | data.AllergenEgg | data.AllergenWheat,
+	}
+
+	tests := []struct {
+		name      string
+		allergy   string
+		item      *data.FoodItem
+		want      Match
+		allergens []string
+	}{
+		{name: "name ignoring case and plurals", allergy: "PEANUTS", item: peanutButter, want: MatchName},
+		{name: "name preferred to allergen tag", allergy: "peanut", item: peanutButter, want: MatchName},
+		{name: "synonym", allergy: "Groundnut", item: peanutButter, want: MatchSynonym},
+		{name: "synonym preferred to allergen tag", allergy: "cheese", item: cheddar, want: MatchSynonym},
+		{name: "allergen tag", allergy: "Dairy", item: cheddar, want: MatchAllergen, allergens: []string{"milk"}},
+		{name: "allergen alias", allergy: "gluten", item: tiramisu, want: MatchAllergen, allergens: []string{"wheat"}},
+		{name: "part of a word", allergy: "apple", item: pineapple},
+		{name: "tag not on the item", allergy: "milk", item: peanutButter},
+		{name: "not an allergen", allergy: "strawberries", item: tiramisu},
+		{name: "empty allergy", allergy: "", item: cheddar},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			allergy := &data.Allergy{ID: 7, AllergyName: test.allergy, Reaction: "hives"}
+			warnings := matchFoodItem([]*data.Allergy{allergy}, test.item)
+			if test.want == "" {
+				if len(warnings) != 0 {
+					t.Fatalf("got warnings %+v, want none", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 {
+				t.Fatalf("got %d warnings, want 1", len(warnings))
+			}
+			warning := warnings[0]
+			if warning.Match != test.want || fmt.Sprint(warning.Allergens) != fmt.Sprint(test.allergens) {
+				t.Fatalf("got a %q match on %v, want a %q match on %v",
+					warning.Match, warning.Allergens, test.want, test.allergens)
+			}
+			if warning.AllergyID != 7 || warning.AllergyName != test.allergy ||
+				warning.Reaction != "hives" || warning.FoodName != test.item.Name {
+				t.Fatalf("warning %+v does not describe the allergy and food", warning)
+			}
+		})
+	}
+}
+
+func TestMatchFoodItemAllergies(t *testing.T) {
+	tiramisu := &data.FoodItem{Name: "Tiramisu", Allergens: data.AllergenMilk | data.AllergenEgg}
+	allergies := []*data.Allergy{
+		{ID: 1, AllergyName: "Eggs"},
+		{ID: 2, AllergyName: "Shellfish"},
+		{ID: 3, AllergyName: "lactose"},
+	}
+
+	warnings := matchFoodItem(allergies, tiramisu)
+	if len(warnings) != 2 || warnings[0].AllergyID != 1 || warnings[1].AllergyID != 3 {
+		t.Fatalf("got warnings %+v, want one for the egg and one for the milk allergy", warnings)
+	}
+	if matchFoodItem(nil, tiramisu) != nil {
+		t.Fatal("got warnings without allergies")
+	}
+}
+
+func TestCheckAndNotify(t *testing.T) {
+	ctx := context.Background()
+	stores := data.NewMemoryStores()
+	must := func(what string, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", what, err)
+		}
+	}
+
+	user, err := stores.UserStore.CreateUser(ctx, &data.User{
+		UserName:    "user",
+		Email:       "user@example.com",
+		PhoneNumber: "15550100",
+	})
+	must("CreateUser", err)
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	period, err := stores.TrackingPeriodStore.CreateTrackingPeriod(ctx, data.NewTrackingPeriod(user.ID, start, 3))
+	must("CreateTrackingPeriod", err)
+	_, err = stores.AllergyStore.CreateAllergy(ctx, &data.Allergy{UserID: user.ID, AllergyName: "Dairy"})
+	must("CreateAllergy", err)
+	cheddar, err := stores.FoodItemStore.CreateFoodItem(ctx, &data.FoodItem{
+		Name:      "Cheddar",
+		Category:  "Dairy",
+		Allergens: data.AllergenMilk,
+	})
+	must("CreateFoodItem", err)
+
+	meal := func(mealType data.MealType, foodItemID int64, custom ...string) *data.MealEntry {
+		t.Helper()
+
+		entry, err := stores.MealEntryStore.CreateMealEntry(ctx, &data.MealEntry{
+			UserID:           user.ID,
+			TrackingPeriodID: period.ID,
+			TrackingDay:      1,
+			MealType:         mealType,
+			PortionQuantity:  1,
+			PortionUnit:      data.PortionServing,
+		})
+		must("CreateMealEntry", err)
+		if foodItemID != 0 {
+			_, err = stores.MealFoodStore.CreateMealFood(ctx, &data.MealFood{MealEntryID: entry.ID, FoodItemID: foodItemID})
+			must("CreateMealFood", err)
+		}
+		for _, name := range custom {
+			_, err = stores.CustomFoodStore.CreateCustomFood(ctx, &data.CustomFood{MealEntryID: entry.ID, Name: name})
+			must("CreateCustomFood", err)
+		}
+		return entry
+	}
+
+	var notified [][]Warning
+	notifyErr := errors.New("notification failed")
+	checker := NewChecker(stores)
+	checker.Notifier = NotifierFunc(func(
+		ctx context.Context,
+		entry *data.MealEntry,
+		caregivers []*data.Caregiver,
+		warnings []Warning,
+	) error {
+		notified = append(notified, warnings)
+		return notifyErr
+	})
+
+	// Without caregivers the warnings are returned but nobody is told.
+	breakfast := meal(data.MealBreakfast, cheddar.ID, "Cheddar", "Dairy milk chocolate", "Toast")
+	warnings, err := checker.CheckAndNotify(ctx, breakfast)
+	must("CheckAndNotify", err)
+	if len(warnings) != 3 || len(notified) != 0 {
+		t.Fatalf("got warnings %+v and %d notifications, want 3 warnings and none", warnings, len(notified))
+	}
+	if warnings[0].MealFoodID == 0 || warnings[0].Match != MatchAllergen ||
+		warnings[1].CustomFoodID == 0 || warnings[1].Match != MatchAllergen ||
+		warnings[2].FoodName != "Dairy milk chocolate" || warnings[2].Match != MatchName {
+		t.Fatalf("got warnings %+v, want the catalog item, the custom food named like it and the chocolate", warnings)
+	}
+
+	_, err = stores.CaregiverStore.CreateCaregiver(ctx, &data.Caregiver{
+		UserID:      user.ID,
+		Email:       "carer@example.com",
+		PhoneNumber: "15550101",
+	})
+	must("CreateCaregiver", err)
+
+	lunch := meal(data.MealLunch, 0, "Toast")
+	warnings, err = checker.CheckAndNotify(ctx, lunch)
+	if err != nil || len(warnings) != 0 || len(notified) != 0 {
+		t.Fatalf("got warnings %+v, error %v and %d notifications for a safe meal", warnings, err, len(notified))
+	}
+
+	warnings, err = checker.CheckAndNotify(ctx, breakfast)
+	if !errors.Is(err, notifyErr) || len(warnings) != 3 || len(notified) != 1 || len(notified[0]) != 3 {
+		t.Fatalf("got %d warnings, error %v and notifications %v, want the warnings returned despite the failure",
+			len(warnings), err, notified)
+	}
+}
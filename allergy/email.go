@@ -0,0 +1,100 @@
+package allergy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// EmailNotifier is a Notifier that emails the warnings to every caregiver
+// with an email address, through an SMTP server. Caregivers known only by
+// phone number are not notified.
+type EmailNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// Sender is the From address, e.g. "Selfcare <no-reply@example.com>".
+	Sender string
+
+	// send delivers a message; it is smtp.SendMail unless overridden.
+	send func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewEmailNotifier(host string, port int, username, password, sender string) *EmailNotifier {
+	return &EmailNotifier{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		Sender:   sender,
+		send:     smtp.SendMail,
+	}
+}
+
+// NotifyCaregivers sends one email to all of the caregivers with an email
+// address.
+func (notifier *EmailNotifier) NotifyCaregivers(
+	ctx context.Context,
+	entry *data.MealEntry,
+	caregivers []*data.Caregiver,
+	warnings []Warning,
+) error {
+	var to []string
+	for _, caregiver := range caregivers {
+		if email := strings.TrimSpace(caregiver.Email); email != "" {
+			to = append(to, email)
+		}
+	}
+	if len(to) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if notifier.Username != "" {
+		auth = smtp.PlainAuth("", notifier.Username, notifier.Password, notifier.Host)
+	}
+	addr := net.JoinHostPort(notifier.Host, strconv.Itoa(notifier.Port))
+	err := notifier.send(addr, auth, notifier.Sender, to, notifier.message(entry, to, warnings))
+	if err != nil {
+		return fmt.Errorf("email caregivers: %w", err)
+	}
+	return nil
+}
+
+// message writes the email describing the warnings raised for entry.
+func (notifier *EmailNotifier) message(entry *data.MealEntry, to []string, warnings []Warning) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", notifier.Sender)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: Allergy warning for a logged %s\r\n", strings.ToLower(string(entry.MealType)))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+
+	fmt.Fprintf(&b, "A %s logged on tracking day %d", strings.ToLower(string(entry.MealType)), entry.TrackingDay)
+	if !entry.MealTime.IsZero() {
+		fmt.Fprintf(&b, " at %s", entry.MealTime.Format("Jan 2 15:04"))
+	}
+	b.WriteString(" contains foods matching the user's allergies:\r\n\r\n")
+	for _, warning := range warnings {
+		fmt.Fprintf(&b, "- %s: %s", warning.FoodName, warning.AllergyName)
+		if len(warning.Allergens) > 0 {
+			fmt.Fprintf(&b, " (contains %s)", strings.Join(warning.Allergens, ", "))
+		}
+		if warning.Reaction != "" {
+			fmt.Fprintf(&b, ", reaction: %s", warning.Reaction)
+		}
+		b.WriteString("\r\n")
+	}
+	return []byte(b.String())
+}
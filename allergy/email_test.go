@@ -0,0 +1,75 @@
+package allergy
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func TestEmailNotifier(t *testing.T) {
+	var (
+		sent     int
+		gotAddr  string
+		gotTo    []string
+		gotMsg   string
+		sendErr  error
+		notifier = NewEmailNotifier("smtp.example.com", 587, "user", "secret", "Selfcare <no-reply@example.com>")
+	)
+	notifier.send = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		sent++
+		gotAddr, gotTo, gotMsg = addr, to, string(msg)
+		return sendErr
+	}
+
+	entry := &data.MealEntry{
+		ID:          3,
+		TrackingDay: 2,
+		MealType:    data.MealBreakfast,
+		MealTime:    time.Date(2025, 3, 2, 8, 30, 0, 0, time.UTC),
+	}
+	warnings := []Warning{
+		{FoodName: "Cheddar", AllergyName: "Dairy", Reaction: "hives", Match: MatchAllergen, Allergens: []string{"milk"}},
+		{FoodName: "Peanut butter", AllergyName: "Peanuts", Match: MatchName},
+	}
+	caregivers := []*data.Caregiver{
+		{Email: "carer@example.com", PhoneNumber: "15550101"},
+		{Email: " ", PhoneNumber: "15550102"},
+		{Email: "nurse@example.com"},
+	}
+
+	ctx := context.Background()
+	if err := notifier.NotifyCaregivers(ctx, entry, caregivers, warnings); err != nil {
+		t.Fatalf("NotifyCaregivers: %v", err)
+	}
+	if sent != 1 || gotAddr != "smtp.example.com:587" ||
+		strings.Join(gotTo, " ") != "carer@example.com nurse@example.com" {
+		t.Fatalf("sent %d emails to %v through %s, want one to both caregivers with an email", sent, gotTo, gotAddr)
+	}
+	for _, want := range []string{
+		"Subject: Allergy warning for a logged breakfast\r\n",
+		"tracking day 2 at Mar 2 08:30",
+		"- Cheddar: Dairy (contains milk), reaction: hives\r\n",
+		"- Peanut butter: Peanuts\r\n",
+	} {
+		if !strings.Contains(gotMsg, want) {
+			t.Errorf("message does not contain %q:\n%s", want, gotMsg)
+		}
+	}
+
+	sent = 0
+	err := notifier.NotifyCaregivers(ctx, entry, []*data.Caregiver{{PhoneNumber: "15550101"}}, warnings)
+	if err != nil || sent != 0 {
+		t.Fatalf("NotifyCaregivers without email addresses sent %d emails, error %v; want none", sent, err)
+	}
+
+	sendErr = errors.New("connection refused")
+	err = notifier.NotifyCaregivers(ctx, entry, caregivers, warnings)
+	if !errors.Is(err, sendErr) {
+		t.Fatalf("NotifyCaregivers: got error %v, want %v", err, sendErr)
+	}
+}
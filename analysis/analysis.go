@@ -0,0 +1,383 @@
+// Package analysis looks for foods that trigger symptoms by correlating the
+// meals a user logged during their tracking periods with the symptoms
+// recorded against those meals.
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// CustomCategory is the category reported for custom foods, which are not
+// part of the food item catalog.
+const CustomCategory = "Custom"
+
+// Meal is a meal entry together with everything eaten and felt after it.
+// Symptoms includes the standalone symptoms attributed to the meal, and
+// MissedDoses the medications with doses skipped or missed on its tracking
+// day.
+type Meal struct {
+	Entry       *data.MealEntry
+	Foods       []Food
+	Symptoms    []*data.Symptom
+	MissedDoses []int64
+}
+
+// Food is one thing eaten during a meal, either a catalog food item or a
+// custom food. FoodItemID is zero for custom foods, which have no allergen
+// or FODMAP information.
+type Food struct {
+	FoodItemID int64          `json:"food_item_id"`
+	Name       string         `json:"name"`
+	Category   string         `json:"category"`
+	Allergens  data.Allergens `json:"allergens"`
+	FODMAPs    data.FODMAPs   `json:"fodmaps"`
+}
+
+// Key identifies the food across meals. Catalog items are keyed by ID and
+// custom foods by their case-insensitive name, so "Rice" logged as a custom
+// food on two days counts as two exposures to the same food.
+func (food Food) Key() string {
+	if food.FoodItemID != 0 {
+		return "item:" + strconv.FormatInt(food.FoodItemID, 10)
+	}
+	return "custom:" + strings.ToLower(strings.TrimSpace(food.Name))
+}
+
+type Options struct {
+	// MinExposures is the number of meals a food must appear in before it is
+	// considered as a trigger.
+	MinExposures int
+	// MinSeverity is the lowest relative severity, between 0 and 1, that
+	// counts as a symptom. See data.SymptomType.RelativeSeverity.
+	MinSeverity float64
+	// Z is the standard score used for the confidence interval, e.g. 1.96
+	// for 95%.
+	Z float64
+	// SkipMissedDoses leaves out the meals of days with missed medication
+	// doses, whose symptoms may be due to the missed dose rather than the
+	// food.
+	SkipMissedDoses bool
+}
+
+var DefaultOptions = Options{
+	MinExposures: 2,
+	MinSeverity:  0.1,
+	Z:            1.96,
+}
+
+// Stats summarizes the meals in which a food, or any food of a category,
+// was eaten.
+type Stats struct {
+	// Exposures is the number of meals it was eaten in, and
+	// SymptomaticExposures how many of those were followed by a symptom.
+	Exposures            int `json:"exposures"`
+	SymptomaticExposures int `json:"symptomatic_exposures"`
+	// Frequency is SymptomaticExposures / Exposures.
+	Frequency float64 `json:"frequency"`
+
+	// SymptomCount is the number of symptoms recorded after those meals,
+	// split by whether they appeared around the meal or overnight.
+	// MeanSeverity is their mean relative severity, between 0 and 1.
+	SymptomCount      int     `json:"symptom_count"`
+	SameMealSymptoms  int     `json:"same_meal_symptoms"`
+	OvernightSymptoms int     `json:"overnight_symptoms"`
+	MeanSeverity      float64 `json:"mean_severity"`
+
+	// SymptomTypes counts the symptoms by SymptomType.
+	SymptomTypes map[string]int `json:"symptom_types"`
+
+	severityTotal float64
+}
+
+type FoodStats struct {
+	Food
+	Stats
+}
+
+type CategoryStats struct {
+	Category string `json:"category"`
+	Stats
+}
+
+const (
+	IngredientAllergen = "allergen"
+	IngredientFODMAP   = "fodmap"
+)
+
+// IngredientStats summarizes the meals containing an allergen or FODMAP
+// class, whichever foods it came from.
+type IngredientStats struct {
+	Kind string `json:"kind"` // IngredientAllergen or IngredientFODMAP
+	Name string `json:"name"`
+	Stats
+}
+
+// ingredients lists the allergens and FODMAP classes of food.
+func (food Food) ingredients() []IngredientStats {
+	var ingredients []IngredientStats
+	for _, name := range food.Allergens.Names() {
+		ingredients = append(ingredients, IngredientStats{Kind: IngredientAllergen, Name: name})
+	}
+	for _, name := range food.FODMAPs.Names() {
+		ingredients = append(ingredients, IngredientStats{Kind: IngredientFODMAP, Name: name})
+	}
+	return ingredients
+}
+
+// Trigger is a food that is followed by symptoms more often than the meals
+// without it.
+type Trigger struct {
+	Food FoodStats `json:"food"`
+	// BaselineFrequency is the symptom frequency of meals without the food,
+	// and Lift how much more frequent symptoms are with it.
+	BaselineFrequency float64 `json:"baseline_frequency"`
+	Lift              float64 `json:"lift"`
+	// Confidence is the lower bound of the Wilson score interval of the
+	// food's symptom frequency, so it stays low until there are enough
+	// exposures to rule out chance.
+	Confidence float64 `json:"confidence"`
+}
+
+type Report struct {
+	Meals            int `json:"meals"`
+	SymptomaticMeals int `json:"symptomatic_meals"`
+	// MissedDoseMeals is the number of meals eaten on a day with missed
+	// medication doses. They are not counted in Meals if the options skip
+	// them.
+	MissedDoseMeals int             `json:"missed_dose_meals"`
+	Foods           []FoodStats     `json:"foods"`
+	Categories      []CategoryStats `json:"categories"`
+	// Ingredients is only filled in for allergens and FODMAP classes that
+	// appear in at least one meal.
+	Ingredients []IngredientStats `json:"ingredients"`
+	// Triggers is ranked from most to least likely.
+	Triggers []Trigger `json:"triggers"`
+}
+
+// defaultScale is the scale of symptoms whose type is not in the catalog,
+// that of a new catalog entry.
+var defaultScale = &data.SymptomType{SeverityMin: 0, SeverityMax: 10}
+
+// Analyze computes the per-food and per-category statistics for meals and
+// ranks the likely trigger foods. Severities are compared on the scales of
+// their symptom types, looked up by name in types.
+func Analyze(meals []Meal, types []*data.SymptomType, options Options) *Report {
+	report := &Report{}
+
+	scales := make(map[string]*data.SymptomType, len(types))
+	for _, symptomType := range types {
+		scales[strings.ToLower(symptomType.Name)] = symptomType
+	}
+
+	foods := make(map[string]*FoodStats)
+	categories := make(map[string]*CategoryStats)
+	ingredients := make(map[string]*IngredientStats)
+
+	for _, meal := range meals {
+		if len(meal.MissedDoses) > 0 {
+			report.MissedDoseMeals++
+			if options.SkipMissedDoses {
+				continue
+			}
+		}
+		report.Meals++
+
+		symptoms := countedSymptoms(meal.Symptoms, scales, options.MinSeverity)
+		if len(symptoms) > 0 {
+			report.SymptomaticMeals++
+		}
+
+		seenFoods := make(map[string]bool)
+		seenCategories := make(map[string]bool)
+		seenIngredients := make(map[string]bool)
+		for _, food := range meal.Foods {
+			key := food.Key()
+			if !seenFoods[key] {
+				seenFoods[key] = true
+				stats, ok := foods[key]
+				if !ok {
+					stats = &FoodStats{Food: food}
+					foods[key] = stats
+				}
+				stats.add(symptoms)
+			}
+
+			category := food.Category
+			if food.FoodItemID == 0 {
+				category = CustomCategory
+			}
+			if !seenCategories[category] {
+				seenCategories[category] = true
+				stats, ok := categories[category]
+				if !ok {
+					stats = &CategoryStats{Category: category}
+					categories[category] = stats
+				}
+				stats.add(symptoms)
+			}
+
+			for _, ingredient := range food.ingredients() {
+				key := ingredient.Kind + ":" + ingredient.Name
+				if seenIngredients[key] {
+					continue
+				}
+				seenIngredients[key] = true
+				stats, ok := ingredients[key]
+				if !ok {
+					stats = &ingredient
+					ingredients[key] = stats
+				}
+				stats.add(symptoms)
+			}
+		}
+	}
+
+	for _, stats := range foods {
+		stats.finish()
+		report.Foods = append(report.Foods, *stats)
+	}
+	sort.Slice(report.Foods, func(i, j int) bool {
+		return report.Foods[i].Name < report.Foods[j].Name
+	})
+
+	for _, stats := range categories {
+		stats.finish()
+		report.Categories = append(report.Categories, *stats)
+	}
+	sort.Slice(report.Categories, func(i, j int) bool {
+		return report.Categories[i].Category < report.Categories[j].Category
+	})
+
+	for _, stats := range ingredients {
+		stats.finish()
+		report.Ingredients = append(report.Ingredients, *stats)
+	}
+	sort.Slice(report.Ingredients, func(i, j int) bool {
+		a, b := report.Ingredients[i], report.Ingredients[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	report.Triggers = rankTriggers(report, options)
+	return report
+}
+
+// countedSymptom is a symptom with its severity relative to the scale of
+// its type.
+type countedSymptom struct {
+	*data.Symptom
+	severity float64
+}
+
+func countedSymptoms(
+	symptoms []*data.Symptom,
+	scales map[string]*data.SymptomType,
+	minSeverity float64,
+) []countedSymptom {
+	var counted []countedSymptom
+	for _, s := range symptoms {
+		scale, ok := scales[strings.ToLower(strings.TrimSpace(s.SymptomType))]
+		if !ok {
+			scale = defaultScale
+		}
+		if severity := scale.RelativeSeverity(s.Severity); severity >= minSeverity {
+			counted = append(counted, countedSymptom{Symptom: s, severity: severity})
+		}
+	}
+	return counted
+}
+
+func (stats *Stats) add(symptoms []countedSymptom) {
+	stats.Exposures++
+	if len(symptoms) == 0 {
+		return
+	}
+
+	stats.SymptomaticExposures++
+	if stats.SymptomTypes == nil {
+		stats.SymptomTypes = make(map[string]int)
+	}
+	for _, symptom := range symptoms {
+		stats.SymptomCount++
+		if symptom.IsOvernight {
+			stats.OvernightSymptoms++
+		} else {
+			stats.SameMealSymptoms++
+		}
+		stats.SymptomTypes[symptom.SymptomType]++
+		stats.severityTotal += symptom.severity
+	}
+}
+
+func (stats *Stats) finish() {
+	if stats.Exposures > 0 {
+		stats.Frequency = float64(stats.SymptomaticExposures) / float64(stats.Exposures)
+	}
+	if stats.SymptomCount > 0 {
+		stats.MeanSeverity = stats.severityTotal / float64(stats.SymptomCount)
+	}
+}
+
+func rankTriggers(report *Report, options Options) []Trigger {
+	var triggers []Trigger
+
+	for _, food := range report.Foods {
+		if food.Exposures < options.MinExposures || food.SymptomaticExposures == 0 {
+			continue
+		}
+
+		// A food eaten at every meal has nothing to be compared against.
+		without := report.Meals - food.Exposures
+		if without == 0 {
+			continue
+		}
+		symptomatic := report.SymptomaticMeals - food.SymptomaticExposures
+		baseline := float64(symptomatic) / float64(without)
+		if food.Frequency <= baseline {
+			continue
+		}
+
+		triggers = append(triggers, Trigger{
+			Food:              food,
+			BaselineFrequency: baseline,
+			Lift:              food.Frequency - baseline,
+			Confidence:        wilsonLowerBound(food.SymptomaticExposures, food.Exposures, options.Z),
+		})
+	}
+
+	sort.SliceStable(triggers, func(i, j int) bool {
+		a, b := triggers[i], triggers[j]
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		if a.Lift != b.Lift {
+			return a.Lift > b.Lift
+		}
+		return a.Food.MeanSeverity > b.Food.MeanSeverity
+	})
+
+	return triggers
+}
+
+// wilsonLowerBound is the lower bound of the Wilson score interval for
+// successes out of trials.
+func wilsonLowerBound(successes, trials int, z float64) float64 {
+	if trials == 0 {
+		return 0
+	}
+
+	n := float64(trials)
+	p := float64(successes) / n
+	z2 := z * z
+
+	center := p + z2/(2*n)
+	margin := z * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+	return math.Max(0, (center-margin)/(1+z2/n))
+}
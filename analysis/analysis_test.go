@@ -0,0 +1,303 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+var (
+	milk  = Food{FoodItemID: 1, Name: "Milk", Category: "Dairy", Allergens: data.AllergenMilk, FODMAPs: data.FODMAPLactose}
+	bread = Food{FoodItemID: 2, Name: "Bread", Category: "Grains", Allergens: data.AllergenWheat, FODMAPs: data.FODMAPFructans}
+	apple = Food{FoodItemID: 3, Name: "Apple", Category: "Fruits"}
+	salt  = Food{FoodItemID: 4, Name: "Salt", Category: "Condiments"}
+)
+
+func symptom(severity int, overnight bool) *data.Symptom {
+	return &data.Symptom{SymptomType: "bloating", Severity: severity, IsOvernight: overnight}
+}
+
+func meal(foods []Food, symptoms ...*data.Symptom) Meal {
+	return Meal{Entry: &data.MealEntry{}, Foods: foods, Symptoms: symptoms}
+}
+
+func TestAnalyzeStats(t *testing.T) {
+	type exposures struct{ total, symptomatic int }
+
+	tests := []struct {
+		name          string
+		meals         []Meal
+		options       Options
+		wantMeals     int
+		wantSymptoms  int
+		wantMissed    int
+		wantFoods     map[string]exposures
+		wantCategory  map[string]exposures
+		wantTriggered bool
+	}{
+		{
+			name:         "no meals",
+			options:      DefaultOptions,
+			wantFoods:    map[string]exposures{},
+			wantCategory: map[string]exposures{},
+		},
+		{
+			name: "food counted once per meal",
+			meals: []Meal{
+				meal([]Food{milk, milk, bread}, symptom(2, false)),
+				meal([]Food{bread}),
+			},
+			options:      DefaultOptions,
+			wantMeals:    2,
+			wantSymptoms: 1,
+			wantFoods:    map[string]exposures{"Milk": {1, 1}, "Bread": {2, 1}},
+			wantCategory: map[string]exposures{"Dairy": {1, 1}, "Grains": {2, 1}},
+		},
+		{
+			name: "custom foods matched by name and grouped as custom",
+			meals: []Meal{
+				meal([]Food{{Name: "Rice", Category: "Grains"}, {Name: " rice"}}, symptom(1, true)),
+				meal([]Food{{Name: "RICE"}}),
+			},
+			options:      DefaultOptions,
+			wantMeals:    2,
+			wantSymptoms: 1,
+			wantFoods:    map[string]exposures{"Rice": {2, 1}},
+			wantCategory: map[string]exposures{CustomCategory: {2, 1}},
+		},
+		{
+			name: "symptoms below the minimum severity ignored",
+			meals: []Meal{
+				meal([]Food{milk}, symptom(1, false)),
+				meal([]Food{milk}, symptom(3, false)),
+			},
+			options:      Options{MinExposures: 2, MinSeverity: 0.2, Z: 1.96},
+			wantMeals:    2,
+			wantSymptoms: 1,
+			wantFoods:    map[string]exposures{"Milk": {2, 1}},
+			wantCategory: map[string]exposures{"Dairy": {2, 1}},
+		},
+		{
+			name: "meals with missed doses counted",
+			meals: []Meal{
+				{Entry: &data.MealEntry{}, Foods: []Food{milk}, MissedDoses: []int64{7}},
+				meal([]Food{bread}),
+			},
+			options:      DefaultOptions,
+			wantMeals:    2,
+			wantMissed:   1,
+			wantFoods:    map[string]exposures{"Milk": {1, 0}, "Bread": {1, 0}},
+			wantCategory: map[string]exposures{"Dairy": {1, 0}, "Grains": {1, 0}},
+		},
+		{
+			name: "meals with missed doses skipped",
+			meals: []Meal{
+				{Entry: &data.MealEntry{}, Foods: []Food{milk}, MissedDoses: []int64{7}},
+				meal([]Food{bread}),
+			},
+			options:      Options{MinExposures: 2, MinSeverity: 0.1, Z: 1.96, SkipMissedDoses: true},
+			wantMeals:    1,
+			wantMissed:   1,
+			wantFoods:    map[string]exposures{"Bread": {1, 0}},
+			wantCategory: map[string]exposures{"Grains": {1, 0}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := Analyze(test.meals, nil, test.options)
+			if report.Meals != test.wantMeals || report.SymptomaticMeals != test.wantSymptoms ||
+				report.MissedDoseMeals != test.wantMissed {
+				t.Fatalf("got %d meals, %d symptomatic and %d with missed doses, want %d, %d and %d",
+					report.Meals, report.SymptomaticMeals, report.MissedDoseMeals,
+					test.wantMeals, test.wantSymptoms, test.wantMissed)
+			}
+
+			if len(report.Foods) != len(test.wantFoods) {
+				t.Fatalf("got %d foods, want %d", len(report.Foods), len(test.wantFoods))
+			}
+			for _, food := range report.Foods {
+				want, ok := test.wantFoods[food.Name]
+				if !ok || food.Exposures != want.total || food.SymptomaticExposures != want.symptomatic {
+					t.Errorf("%s eaten %d times, %d with symptoms, want %+v",
+						food.Name, food.Exposures, food.SymptomaticExposures, want)
+				}
+			}
+
+			if len(report.Categories) != len(test.wantCategory) {
+				t.Fatalf("got %d categories, want %d", len(report.Categories), len(test.wantCategory))
+			}
+			for _, category := range report.Categories {
+				want, ok := test.wantCategory[category.Category]
+				if !ok || category.Exposures != want.total || category.SymptomaticExposures != want.symptomatic {
+					t.Errorf("%s eaten %d times, %d with symptoms, want %+v",
+						category.Category, category.Exposures, category.SymptomaticExposures, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeSymptomDetails(t *testing.T) {
+	report := Analyze([]Meal{
+		meal([]Food{milk, bread}, symptom(2, false), symptom(4, true)),
+		meal([]Food{milk}),
+	}, nil, DefaultOptions)
+
+	milkStats := report.Foods[1]
+	if milkStats.Name != "Milk" || milkStats.Frequency != 0.5 || milkStats.SymptomCount != 2 ||
+		milkStats.SameMealSymptoms != 1 || milkStats.OvernightSymptoms != 1 ||
+		math.Abs(milkStats.MeanSeverity-0.3) > 1e-9 || milkStats.SymptomTypes["bloating"] != 2 {
+		t.Fatalf("got %+v, want milk with a 0.5 frequency and two symptoms of mean severity 0.3", milkStats)
+	}
+
+	var ingredients []string
+	for _, ingredient := range report.Ingredients {
+		ingredients = append(ingredients, ingredient.Kind+":"+ingredient.Name)
+	}
+	want := []string{"allergen:milk", "allergen:wheat", "fodmap:fructans", "fodmap:lactose"}
+	if len(ingredients) != len(want) {
+		t.Fatalf("got ingredients %v, want %v", ingredients, want)
+	}
+	for i := range want {
+		if ingredients[i] != want[i] {
+			t.Fatalf("got ingredients %v, want %v", ingredients, want)
+		}
+	}
+}
+
+func TestAnalyzeSeverityScales(t *testing.T) {
+	types := []*data.SymptomType{
+		{Name: "Bloating", SeverityMin: 0, SeverityMax: 10},
+		{Name: "Stool form", SeverityMin: 1, SeverityMax: 7},
+	}
+	stool := func(severity int) *data.Symptom {
+		return &data.Symptom{SymptomType: "stool form", Severity: severity}
+	}
+
+	// Stool form 7 is the top of its scale and bloating 5 the middle of
+	// its own, so the mean is 0.75 rather than the raw 6. Stool form 1 is
+	// the bottom of its scale and below the minimum severity.
+	report := Analyze([]Meal{
+		meal([]Food{milk}, stool(7), symptom(5, false)),
+		meal([]Food{milk}, stool(1)),
+	}, types, DefaultOptions)
+	milkStats := report.Foods[0]
+	if milkStats.SymptomCount != 2 || milkStats.SymptomaticExposures != 1 ||
+		math.Abs(milkStats.MeanSeverity-0.75) > 1e-9 {
+		t.Fatalf("got %+v, want two symptoms after one meal with mean severity 0.75", milkStats)
+	}
+}
+
+func TestAnalyzeTriggers(t *testing.T) {
+	cheese := Food{FoodItemID: 5, Name: "Cheese", Category: "Dairy"}
+	zucchini := Food{FoodItemID: 6, Name: "Zucchini", Category: "Vegetables"}
+
+	tests := []struct {
+		name  string
+		meals []Meal
+		want  []string
+	}{
+		{
+			name: "only foods more symptomatic than the baseline",
+			// Milk is always followed by symptoms, bread as often as the
+			// meals without it, and apple never. Cheese is eaten too
+			// rarely and salt at every meal.
+			meals: []Meal{
+				meal([]Food{milk, cheese, salt}, symptom(2, false)),
+				meal([]Food{milk, salt}, symptom(2, false)),
+				meal([]Food{milk, bread, salt}, symptom(2, false)),
+				meal([]Food{milk, bread, salt}, symptom(2, false)),
+				meal([]Food{bread, salt}),
+				meal([]Food{bread, apple, salt}),
+				meal([]Food{apple, salt}),
+				meal([]Food{apple, salt}),
+			},
+			want: []string{"Milk"},
+		},
+		{
+			name: "more exposures ranked first",
+			meals: []Meal{
+				meal([]Food{milk}, symptom(2, false)),
+				meal([]Food{milk}, symptom(2, false)),
+				meal([]Food{milk, bread}, symptom(2, false)),
+				meal([]Food{bread}, symptom(2, false)),
+				meal([]Food{apple}),
+				meal([]Food{apple}),
+			},
+			want: []string{"Milk", "Bread"},
+		},
+		{
+			name: "ties broken by mean severity",
+			meals: []Meal{
+				meal([]Food{apple}, symptom(1, false)),
+				meal([]Food{apple}, symptom(1, false)),
+				meal([]Food{zucchini}, symptom(5, false)),
+				meal([]Food{zucchini}, symptom(5, false)),
+				meal([]Food{salt}),
+				meal([]Food{salt}),
+			},
+			want: []string{"Zucchini", "Apple"},
+		},
+		{
+			name: "full ties keep name order",
+			meals: []Meal{
+				meal([]Food{zucchini}, symptom(3, false)),
+				meal([]Food{zucchini}, symptom(3, false)),
+				meal([]Food{apple}, symptom(3, false)),
+				meal([]Food{apple}, symptom(3, false)),
+				meal([]Food{salt}),
+				meal([]Food{salt}),
+			},
+			want: []string{"Apple", "Zucchini"},
+		},
+		{
+			name:  "no symptoms",
+			meals: []Meal{meal([]Food{milk}), meal([]Food{milk}), meal([]Food{bread})},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := Analyze(test.meals, nil, DefaultOptions)
+
+			var got []string
+			for _, trigger := range report.Triggers {
+				got = append(got, trigger.Food.Name)
+				if trigger.Lift <= 0 || trigger.Confidence <= 0 || trigger.Confidence > trigger.Food.Frequency {
+					t.Errorf("%s has lift %g and confidence %g, want both positive and the confidence below %g",
+						trigger.Food.Name, trigger.Lift, trigger.Confidence, trigger.Food.Frequency)
+				}
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got triggers %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Fatalf("got triggers %v, want %v", got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWilsonLowerBound(t *testing.T) {
+	tests := []struct {
+		successes, trials int
+		min, max          float64
+	}{
+		{0, 0, 0, 0},
+		{0, 10, 0, 0},
+		{2, 2, 0.34, 0.35},
+		{20, 20, 0.83, 0.84},
+		{5, 10, 0.23, 0.24},
+	}
+	for _, test := range tests {
+		got := wilsonLowerBound(test.successes, test.trials, 1.96)
+		if got < test.min || got > test.max {
+			t.Errorf("wilsonLowerBound(%d, %d) = %g, want between %g and %g",
+				test.successes, test.trials, got, test.min, test.max)
+		}
+	}
+}
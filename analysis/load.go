@@ -0,0 +1,149 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Universal-Selfcare/utils/data"
+	"github.com/Universal-Selfcare/utils/tracking"
+)
+
+// LoadMeals gathers the meals of a user's tracking periods from stores,
+// noting the medication doses missed on the day of each meal. With no
+// periodIDs every tracking period of the user is included.
+func LoadMeals(
+	ctx context.Context,
+	stores *data.Stores,
+	userID int64,
+	periodIDs ...int64,
+) ([]Meal, error) {
+	if len(periodIDs) == 0 {
+		periods, err := stores.TrackingPeriodStore.ListUserTrackingPeriods(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, period := range periods {
+			periodIDs = append(periodIDs, period.ID)
+		}
+	}
+
+	foodItems := make(map[int64]*data.FoodItem)
+	lifecycle := tracking.NewLifecycle(stores)
+	var meals []Meal
+
+	for _, periodID := range periodIDs {
+		period, err := stores.TrackingPeriodStore.GetTrackingPeriod(ctx, periodID)
+		if err != nil {
+			return nil, err
+		}
+		if period.UserID != userID {
+			return nil, fmt.Errorf("tracking period %d: %w", periodID, data.ErrRecordNotFound)
+		}
+
+		adherence, err := lifecycle.Adherence(ctx, periodID)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err := stores.MealEntryStore.ListUserMealEntries(ctx, userID, periodID)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			meal, err := loadMeal(ctx, stores, entry, foodItems)
+			if err != nil {
+				return nil, err
+			}
+			meal.MissedDoses = adherence.MissedOn(entry.TrackingDay)
+			meals = append(meals, meal)
+		}
+	}
+
+	return meals, nil
+}
+
+func loadMeal(
+	ctx context.Context,
+	stores *data.Stores,
+	entry *data.MealEntry,
+	foodItems map[int64]*data.FoodItem,
+) (Meal, error) {
+	meal := Meal{Entry: entry}
+
+	mealFoods, err := stores.MealFoodStore.GetMealFoodsForMeal(ctx, entry.ID)
+	if err != nil {
+		return Meal{}, err
+	}
+	for _, mealFood := range mealFoods {
+		item, ok := foodItems[mealFood.FoodItemID]
+		if !ok {
+			item, err = stores.FoodItemStore.GetFoodItem(ctx, mealFood.FoodItemID)
+			if errors.Is(err, data.ErrRecordNotFound) {
+				// The catalog entry is gone, so there is nothing to name it by.
+				continue
+			}
+			if err != nil {
+				return Meal{}, err
+			}
+			foodItems[item.ID] = item
+		}
+		meal.Foods = append(meal.Foods, Food{
+			FoodItemID: item.ID,
+			Name:       item.Name,
+			Category:   item.Category,
+			Allergens:  item.Allergens,
+			FODMAPs:    item.FODMAPs,
+		})
+	}
+
+	customFoods, err := stores.CustomFoodStore.GetCustomFoodsForMeal(ctx, entry.ID)
+	if err != nil {
+		return Meal{}, err
+	}
+	for _, customFood := range customFoods {
+		meal.Foods = append(meal.Foods, Food{Name: customFood.Name, Category: CustomCategory})
+	}
+
+	meal.Symptoms, err = stores.SymptomStore.ListSymptomsForMeal(ctx, entry.ID)
+	if err != nil {
+		return Meal{}, err
+	}
+
+	attributions, err := stores.SymptomAttributionStore.ListAttributionsForMeal(ctx, entry.ID)
+	if err != nil {
+		return Meal{}, err
+	}
+	for _, attribution := range attributions {
+		symptom, err := stores.SymptomStore.GetSymptom(ctx, attribution.SymptomID)
+		if errors.Is(err, data.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return Meal{}, err
+		}
+		meal.Symptoms = append(meal.Symptoms, symptom)
+	}
+
+	return meal, nil
+}
+
+// AnalyzeUser loads the meals of a user's tracking periods and analyzes
+// them. With no periodIDs every tracking period of the user is included.
+func AnalyzeUser(
+	ctx context.Context,
+	stores *data.Stores,
+	userID int64,
+	options Options,
+	periodIDs ...int64,
+) (*Report, error) {
+	meals, err := LoadMeals(ctx, stores, userID, periodIDs...)
+	if err != nil {
+		return nil, err
+	}
+	types, err := stores.SymptomTypeStore.ListSymptomTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Analyze(meals, types, options), nil
+}
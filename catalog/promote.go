@@ -0,0 +1,377 @@
+// Package catalog grows the food catalog from what users log: it clusters
+// the custom foods users keep entering by hand and promotes the popular
+// ones into food items.
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Universal-Selfcare/utils/data"
+	"github.com/Universal-Selfcare/utils/search"
+	"github.com/Universal-Selfcare/utils/validator"
+)
+
+const (
+	// DefaultMinUses and DefaultMinUsers are how often, and by how many
+	// users, a custom food must be logged to become a candidate.
+	DefaultMinUses  = 10
+	DefaultMinUsers = 3
+	// DefaultSimilarity is the trigram similarity above which two names are
+	// taken to be the same food, e.g. "Banana bread" and "banana-bread" or
+	// "Bananna bread".
+	DefaultSimilarity = 0.6
+)
+
+// ErrInvalidFoodItem is returned by Promote when the food item to create
+// fails data.ValidateFoodItem.
+var ErrInvalidFoodItem = errors.New("invalid food item")
+
+// Promoter finds custom foods worth adding to the catalog and promotes them.
+type Promoter struct {
+	Stores *data.Stores
+
+	MinUses  int
+	MinUsers int
+	// Similarity is the trigram similarity, between 0 and 1, from which two
+	// custom food names fall into the same cluster.
+	Similarity float64
+}
+
+func NewPromoter(stores *data.Stores) *Promoter {
+	return &Promoter{
+		Stores:     stores,
+		MinUses:    DefaultMinUses,
+		MinUsers:   DefaultMinUsers,
+		Similarity: DefaultSimilarity,
+	}
+}
+
+// Spelling is one of the names a cluster's custom foods were logged under.
+type Spelling struct {
+	Name string `json:"name"`
+	Uses int    `json:"uses"`
+}
+
+// Cluster is a group of custom food names taken to be the same food.
+type Cluster struct {
+	// Name is the most used spelling.
+	Name string `json:"name"`
+	// Spellings are ordered from most to least used.
+	Spellings []Spelling `json:"spellings"`
+	Uses      int        `json:"uses"`
+	Users     int        `json:"users"`
+	// FoodItem is the catalog item with the same name or synonym as one of
+	// the spellings, if there is one. The custom foods should be linked to
+	// it rather than to a new item.
+	FoodItem *data.FoodItem `json:"food_item,omitempty"`
+
+	key   string
+	users map[int64]bool
+}
+
+// Has reports whether name is one of the cluster's spellings, ignoring
+// case, punctuation and plurals.
+func (cluster *Cluster) Has(name string) bool {
+	key := search.Normalize(name)
+	for _, spelling := range cluster.Spellings {
+		if search.Normalize(spelling.Name) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Candidates returns the clusters used at least MinUses times by at least
+// MinUsers users, most used first.
+func (promoter *Promoter) Candidates(ctx context.Context) ([]*Cluster, error) {
+	clusters, err := promoter.Clusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*Cluster
+	for _, cluster := range clusters {
+		if cluster.Uses >= promoter.MinUses && cluster.Users >= promoter.MinUsers {
+			candidates = append(candidates, cluster)
+		}
+	}
+	return candidates, nil
+}
+
+// Clusters groups the custom foods of every user by name, most used first.
+// Names that are the same once normalized are grouped first; each group then
+// joins the first, more used, cluster whose name is at least Similarity
+// similar to it.
+func (promoter *Promoter) Clusters(ctx context.Context) ([]*Cluster, error) {
+	usage, err := promoter.Stores.CustomFoodStore.ListCustomFoodUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*Cluster
+	byKey := make(map[string]*Cluster)
+	for _, row := range usage {
+		key := search.Normalize(row.Name)
+		if key == "" {
+			continue
+		}
+		group, ok := byKey[key]
+		if !ok {
+			group = &Cluster{key: key, users: make(map[int64]bool)}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+		group.add(Spelling{Name: row.Name, Uses: row.Uses}, row.UserID)
+	}
+	sortByUses(groups)
+
+	var clusters []*Cluster
+	for _, group := range groups {
+		var into *Cluster
+		for _, cluster := range clusters {
+			if search.Similarity(group.key, cluster.key) >= promoter.Similarity {
+				into = cluster
+				break
+			}
+		}
+		if into == nil {
+			clusters = append(clusters, group)
+			continue
+		}
+		into.merge(group)
+	}
+
+	if err := promoter.matchFoodItems(ctx, clusters); err != nil {
+		return nil, err
+	}
+	for _, cluster := range clusters {
+		cluster.finish()
+	}
+	sortByUses(clusters)
+	return clusters, nil
+}
+
+func (cluster *Cluster) add(spelling Spelling, userID int64) {
+	cluster.users[userID] = true
+	cluster.Uses += spelling.Uses
+	for i := range cluster.Spellings {
+		if cluster.Spellings[i].Name == spelling.Name {
+			cluster.Spellings[i].Uses += spelling.Uses
+			return
+		}
+	}
+	cluster.Spellings = append(cluster.Spellings, spelling)
+}
+
+func (cluster *Cluster) merge(other *Cluster) {
+	cluster.Uses += other.Uses
+	cluster.Spellings = append(cluster.Spellings, other.Spellings...)
+	for userID := range other.users {
+		cluster.users[userID] = true
+	}
+}
+
+func (cluster *Cluster) finish() {
+	sort.SliceStable(cluster.Spellings, func(i, j int) bool {
+		a, b := cluster.Spellings[i], cluster.Spellings[j]
+		if a.Uses != b.Uses {
+			return a.Uses > b.Uses
+		}
+		return a.Name < b.Name
+	})
+	cluster.Name = cluster.Spellings[0].Name
+	cluster.Users = len(cluster.users)
+}
+
+func sortByUses(clusters []*Cluster) {
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].Uses != clusters[j].Uses {
+			return clusters[i].Uses > clusters[j].Uses
+		}
+		return clusters[i].key < clusters[j].key
+	})
+}
+
+// matchFoodItems sets the FoodItem of the clusters with a spelling that is
+// already the name or a synonym of a catalog item.
+func (promoter *Promoter) matchFoodItems(ctx context.Context, clusters []*Cluster) error {
+	items, err := promoter.Stores.FoodItemStore.ListFoodItems(ctx)
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]*data.FoodItem)
+	for _, item := range items {
+		for _, name := range append(item.SynonymList(), item.Name) {
+			byName[search.Normalize(name)] = item
+		}
+	}
+
+	for _, cluster := range clusters {
+		for _, spelling := range cluster.Spellings {
+			if item, ok := byName[search.Normalize(spelling.Name)]; ok {
+				cluster.FoodItem = item
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Promotion describes the outcome of Promote.
+type Promotion struct {
+	FoodItem *data.FoodItem `json:"food_item"`
+	// Created is set when the food item was added to the catalog.
+	Created bool `json:"created"`
+	// Linked counts the meal foods created for the custom foods, and Merged
+	// the custom foods whose meal already had the food item.
+	Linked int `json:"linked"`
+	Merged int `json:"merged"`
+	// Annotated counts the custom foods whose portion or preparation, which
+	// meal foods do not record, was kept in the notes of their meal entry.
+	Annotated int `json:"annotated"`
+}
+
+// Promote rewrites every custom food logged under one of the cluster's
+// spellings into a meal food linking its meal entry to item, in a single
+// transaction. item is either an existing catalog item, identified by its
+// ID, or one to create; one to create that has the name of an existing item
+// is linked to that item instead.
+func (promoter *Promoter) Promote(
+	ctx context.Context,
+	cluster *Cluster,
+	item *data.FoodItem,
+) (*Promotion, error) {
+	names := make([]string, len(cluster.Spellings))
+	for i, spelling := range cluster.Spellings {
+		names[i] = spelling.Name
+	}
+
+	var promotion *Promotion
+	err := promoter.Stores.WithTx(ctx, func(tx *data.Stores) error {
+		promotion = &Promotion{}
+
+		var err error
+		promotion.FoodItem, promotion.Created, err = resolveFoodItem(ctx, tx, item)
+		if err != nil {
+			return err
+		}
+
+		foods, err := tx.CustomFoodStore.ListCustomFoodsByName(ctx, names)
+		if err != nil {
+			return err
+		}
+		linked := make(map[int64]bool)
+		for _, food := range foods {
+			if err := promotion.rewrite(ctx, tx, food, linked); err != nil {
+				return fmt.Errorf("custom food %d: %w", food.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+func resolveFoodItem(ctx context.Context, tx *data.Stores, item *data.FoodItem) (*data.FoodItem, bool, error) {
+	if item.ID != 0 {
+		existing, err := tx.FoodItemStore.GetFoodItem(ctx, item.ID)
+		return existing, false, err
+	}
+
+	item.Name = strings.TrimSpace(item.Name)
+	existing, err := tx.FoodItemStore.GetFoodItemByName(ctx, item.Name)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	v := validator.New()
+	data.ValidateFoodItem(v, item)
+	if !v.Valid() {
+		fields := make([]string, 0, len(v.Errors))
+		for field, message := range v.Errors {
+			fields = append(fields, field+" "+message)
+		}
+		sort.Strings(fields)
+		return nil, false, fmt.Errorf("%w: %s", ErrInvalidFoodItem, strings.Join(fields, "; "))
+	}
+
+	created, err := tx.FoodItemStore.CreateFoodItem(ctx, item)
+	return created, err == nil, err
+}
+
+// rewrite replaces food with a link from its meal entry to the promoted
+// item. linked records the meal entries known to have the link.
+func (promotion *Promotion) rewrite(
+	ctx context.Context,
+	tx *data.Stores,
+	food *data.CustomFood,
+	linked map[int64]bool,
+) error {
+	if !linked[food.MealEntryID] {
+		mealFoods, err := tx.MealFoodStore.GetMealFoodsForMeal(ctx, food.MealEntryID)
+		if err != nil {
+			return err
+		}
+		for _, mealFood := range mealFoods {
+			if mealFood.FoodItemID == promotion.FoodItem.ID {
+				linked[food.MealEntryID] = true
+			}
+		}
+	}
+
+	if linked[food.MealEntryID] {
+		promotion.Merged++
+	} else {
+		_, err := tx.MealFoodStore.CreateMealFood(ctx, &data.MealFood{
+			MealEntryID: food.MealEntryID,
+			FoodItemID:  promotion.FoodItem.ID,
+		})
+		if err != nil {
+			return err
+		}
+		linked[food.MealEntryID] = true
+		promotion.Linked++
+	}
+
+	if note := customFoodNote(food); note != "" {
+		entry, err := tx.MealEntryStore.GetMealEntry(ctx, food.MealEntryID)
+		if err != nil {
+			return err
+		}
+		if entry.Notes != "" {
+			entry.Notes += "\n"
+		}
+		entry.Notes += note
+		if err := tx.MealEntryStore.UpdateMealEntry(ctx, entry); err != nil {
+			return err
+		}
+		promotion.Annotated++
+	}
+
+	return tx.CustomFoodStore.DeleteCustomFood(ctx, food.ID)
+}
+
+// customFoodNote keeps the portion and preparation of a custom food, e.g.
+// "Banana bread: 2 slices, toasted".
+func customFoodNote(food *data.CustomFood) string {
+	var details []string
+	for _, detail := range []string{food.Portion, food.Preparation} {
+		if detail = strings.TrimSpace(detail); detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return food.Name + ": " + strings.Join(details, ", ")
+}
@@ -0,0 +1,275 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// use is a custom food logged uses times by one of the fixture's users.
+type use struct {
+	user int
+	name string
+	uses int
+}
+
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	stores *data.Stores
+	// meals holds a meal entry of each user, by user number.
+	meals map[int]*data.MealEntry
+}
+
+func newFixture(t *testing.T, uses ...use) *fixture {
+	t.Helper()
+
+	f := &fixture{
+		t:      t,
+		ctx:    context.Background(),
+		stores: data.NewMemoryStores(),
+		meals:  make(map[int]*data.MealEntry),
+	}
+	for _, u := range uses {
+		for range u.uses {
+			f.log(u.user, u.name, "1 slice", "")
+		}
+	}
+	return f
+}
+
+func (f *fixture) must(what string, err error) {
+	f.t.Helper()
+
+	if err != nil {
+		f.t.Fatalf("%s: %v", what, err)
+	}
+}
+
+// meal returns the meal entry of user number n, creating the user on first
+// use.
+func (f *fixture) meal(n int) *data.MealEntry {
+	f.t.Helper()
+
+	if entry, ok := f.meals[n]; ok {
+		return entry
+	}
+	user, err := f.stores.UserStore.CreateUser(f.ctx, &data.User{
+		UserName:    fmt.Sprintf("user%d", n),
+		Email:       fmt.Sprintf("user%d@example.com", n),
+		PhoneNumber: fmt.Sprintf("155501%02d", n),
+	})
+	f.must("CreateUser", err)
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	period, err := f.stores.TrackingPeriodStore.CreateTrackingPeriod(f.ctx, data.NewTrackingPeriod(user.ID, start, 3))
+	f.must("CreateTrackingPeriod", err)
+	entry, err := f.stores.MealEntryStore.CreateMealEntry(f.ctx, &data.MealEntry{
+		UserID:           user.ID,
+		TrackingPeriodID: period.ID,
+		TrackingDay:      1,
+		MealType:         data.MealLunch,
+		MealTime:         start.Add(12 * time.Hour),
+		PortionQuantity:  1,
+		PortionUnit:      data.PortionServing,
+	})
+	f.must("CreateMealEntry", err)
+	f.meals[n] = entry
+	return entry
+}
+
+func (f *fixture) log(n int, name, portion, preparation string) *data.CustomFood {
+	f.t.Helper()
+
+	food, err := f.stores.CustomFoodStore.CreateCustomFood(f.ctx, &data.CustomFood{
+		MealEntryID: f.meal(n).ID,
+		Name:        name,
+		Portion:     portion,
+		Preparation: preparation,
+	})
+	f.must("CreateCustomFood", err)
+	return food
+}
+
+func TestClusters(t *testing.T) {
+	type cluster struct {
+		name      string
+		uses      int
+		users     int
+		spellings []string
+	}
+
+	tests := []struct {
+		name string
+		uses []use
+		want []cluster
+	}{
+		{
+			name: "no custom foods",
+		},
+		{
+			name: "blank names ignored",
+			uses: []use{{1, " - ", 3}},
+		},
+		{
+			name: "same name once normalized",
+			uses: []use{{1, "Banana bread", 3}, {2, "banana-bread", 1}, {3, "Banana Breads", 2}},
+			want: []cluster{{"Banana bread", 6, 3, []string{"Banana bread", "Banana Breads", "banana-bread"}}},
+		},
+		{
+			name: "same spelling from several users",
+			uses: []use{{1, "Oat latte", 2}, {2, "Oat latte", 3}},
+			want: []cluster{{"Oat latte", 5, 2, []string{"Oat latte"}}},
+		},
+		{
+			name: "typo joins the more used cluster",
+			uses: []use{{1, "Bananna bread", 1}, {2, "Banana bread", 3}},
+			want: []cluster{{"Banana bread", 4, 2, []string{"Banana bread", "Bananna bread"}}},
+		},
+		{
+			name: "dissimilar names kept apart, most used first",
+			uses: []use{{1, "Banana bread", 2}, {1, "Bean stew", 3}},
+			want: []cluster{
+				{"Bean stew", 3, 1, []string{"Bean stew"}},
+				{"Banana bread", 2, 1, []string{"Banana bread"}},
+			},
+		},
+		{
+			name: "ties ordered by name",
+			uses: []use{{1, "Zucchini fritters", 2}, {2, "apple-pie", 1}, {3, "Apple pie", 1}},
+			want: []cluster{
+				{"Apple pie", 2, 2, []string{"Apple pie", "apple-pie"}},
+				{"Zucchini fritters", 2, 1, []string{"Zucchini fritters"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newFixture(t, test.uses...)
+
+			clusters, err := NewPromoter(f.stores).Clusters(f.ctx)
+			f.must("Clusters", err)
+			if len(clusters) != len(test.want) {
+				t.Fatalf("got %d clusters, want %d", len(clusters), len(test.want))
+			}
+			for i, got := range clusters {
+				want := test.want[i]
+				var spellings []string
+				for _, spelling := range got.Spellings {
+					spellings = append(spellings, spelling.Name)
+				}
+				if got.Name != want.name || got.Uses != want.uses || got.Users != want.users ||
+					fmt.Sprint(spellings) != fmt.Sprint(want.spellings) {
+					t.Errorf("cluster %d is %q with %d uses by %d users spelled %q, want %+v",
+						i, got.Name, got.Uses, got.Users, spellings, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClusterHas(t *testing.T) {
+	cluster := &Cluster{Spellings: []Spelling{{Name: "Banana bread"}, {Name: "Bananna bread"}}}
+	for name, want := range map[string]bool{
+		"banana-breads": true,
+		"BANANNA BREAD": true,
+		"Banana":        false,
+		"":              false,
+	} {
+		if got := cluster.Has(name); got != want {
+			t.Errorf("Has(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	f := newFixture(t,
+		use{1, "Banana bread", 3}, use{2, "Banana bread", 1}, use{3, "banana-bread", 1},
+		use{1, "Bean stew", 9},
+		use{2, "Oat latte", 2}, use{3, "Oat latte", 2},
+	)
+	promoter := NewPromoter(f.stores)
+	promoter.MinUses, promoter.MinUsers = 4, 2
+
+	candidates, err := promoter.Candidates(f.ctx)
+	f.must("Candidates", err)
+	if len(candidates) != 2 || candidates[0].Name != "Banana bread" || candidates[1].Name != "Oat latte" {
+		t.Fatalf("got candidates %+v, want banana bread and oat latte", candidates)
+	}
+}
+
+func TestClustersMatchFoodItems(t *testing.T) {
+	f := newFixture(t, use{1, "Aubergines", 2}, use{2, "Banana bread", 1})
+	eggplant, err := f.stores.FoodItemStore.CreateFoodItem(f.ctx, &data.FoodItem{
+		Name:     "Eggplant",
+		Category: "Vegetables",
+		Synonyms: "aubergine,brinjal",
+	})
+	f.must("CreateFoodItem", err)
+
+	clusters, err := NewPromoter(f.stores).Clusters(f.ctx)
+	f.must("Clusters", err)
+	if len(clusters) != 2 || clusters[0].FoodItem == nil || clusters[0].FoodItem.ID != eggplant.ID ||
+		clusters[1].FoodItem != nil {
+		t.Fatalf("got clusters %+v, want aubergines matched to eggplant and banana bread unmatched", clusters)
+	}
+}
+
+func TestPromote(t *testing.T) {
+	f := newFixture(t)
+	f.log(1, "Banana bread", "2 slices", "toasted")
+	f.log(1, "banana-bread", "", "")
+	f.log(2, "Banana bread", "", "")
+	other := f.log(2, "Bean stew", "", "")
+
+	promoter := NewPromoter(f.stores)
+	clusters, err := promoter.Clusters(f.ctx)
+	f.must("Clusters", err)
+	if clusters[0].Name != "Banana bread" {
+		t.Fatalf("got clusters %+v, want banana bread first", clusters)
+	}
+
+	_, err = promoter.Promote(f.ctx, clusters[0], &data.FoodItem{Name: "Banana bread", Category: "Snacks"})
+	if !errors.Is(err, ErrInvalidFoodItem) {
+		t.Fatalf("Promote with an unknown category: got error %v, want %v", err, ErrInvalidFoodItem)
+	}
+
+	promotion, err := promoter.Promote(f.ctx, clusters[0], &data.FoodItem{Name: " Banana bread ", Category: "Grains"})
+	f.must("Promote", err)
+	if !promotion.Created || promotion.FoodItem.Name != "Banana bread" ||
+		promotion.Linked != 2 || promotion.Merged != 1 || promotion.Annotated != 1 {
+		t.Fatalf("got promotion %+v, want the item created, 2 linked, 1 merged and 1 annotated", promotion)
+	}
+
+	entry, err := f.stores.MealEntryStore.GetMealEntry(f.ctx, f.meals[1].ID)
+	f.must("GetMealEntry", err)
+	if entry.Notes != "Banana bread: 2 slices, toasted" {
+		t.Fatalf("meal entry notes are %q, want the portion and preparation kept", entry.Notes)
+	}
+	for n, entry := range f.meals {
+		foods, err := f.stores.CustomFoodStore.GetCustomFoodsForMeal(f.ctx, entry.ID)
+		f.must("GetCustomFoodsForMeal", err)
+		mealFoods, err := f.stores.MealFoodStore.GetMealFoodsForMeal(f.ctx, entry.ID)
+		f.must("GetMealFoodsForMeal", err)
+		if len(mealFoods) != 1 || mealFoods[0].FoodItemID != promotion.FoodItem.ID {
+			t.Fatalf("meal of user %d has meal foods %+v, want one for the promoted item", n, mealFoods)
+		}
+		if n == 2 && (len(foods) != 1 || foods[0].ID != other.ID) {
+			t.Fatalf("meal of user 2 has custom foods %+v, want only the bean stew left", foods)
+		}
+		if n == 1 && len(foods) != 0 {
+			t.Fatalf("meal of user 1 has custom foods %+v, want none left", foods)
+		}
+	}
+
+	// Promoting again links to the existing item and finds nothing left.
+	promotion, err = promoter.Promote(f.ctx, clusters[0], &data.FoodItem{Name: "Banana bread", Category: "Grains"})
+	f.must("Promote", err)
+	if promotion.Created || promotion.Linked+promotion.Merged != 0 {
+		t.Fatalf("second promotion is %+v, want the existing item and nothing to rewrite", promotion)
+	}
+}
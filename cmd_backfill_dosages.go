@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+const backfillDosagesUsage = "usage: backfill-dosages [-dry-run] [-overwrite]"
+
+// runBackfillDosages implements the backfill-dosages subcommand, which
+// parses the free-text dosage of existing medications and supplements into
+// their structured dose and lists the dosages it could not interpret.
+func runBackfillDosages(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("backfill-dosages", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "Report what would change without writing anything")
+	overwrite := flags.Bool("overwrite", false, "Parse dosages again even if a structured dose is already set")
+	if err := flags.Parse(args); err != nil {
+		return errors.New(backfillDosagesUsage)
+	}
+	if flags.NArg() != 0 {
+		return errors.New(backfillDosagesUsage)
+	}
+
+	report, err := data.NewStores(db).BackfillDosages(
+		context.Background(),
+		data.DosageBackfillOptions{DryRun: *dryRun, Overwrite: *overwrite},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range report.Unparsed {
+		fmt.Printf("unparsed: %s\n", issue)
+	}
+	prefix := ""
+	if *dryRun {
+		prefix = "(dry run) "
+	}
+	fmt.Printf("%sparsed %d, skipped %d, %d unparsed\n", prefix, report.Parsed, report.Skipped, len(report.Unparsed))
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"github.com/Universal-Selfcare/utils/allergy"
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+const checkAllergiesUsage = "usage: check-allergies [-notify] <meal entry id>"
+
+// runCheckAllergies implements the check-allergies subcommand, which lists
+// the foods of a logged meal that match the user's allergies and, with
+// -notify, emails the warnings to the user's caregivers.
+func runCheckAllergies(db *gorm.DB, cfg config, args []string) error {
+	flags := flag.NewFlagSet("check-allergies", flag.ContinueOnError)
+	notify := flags.Bool("notify", false, "Email the warnings to the user's caregivers")
+	if err := flags.Parse(args); err != nil {
+		return errors.New(checkAllergiesUsage)
+	}
+	if flags.NArg() != 1 {
+		return errors.New(checkAllergiesUsage)
+	}
+	id, err := strconv.ParseInt(flags.Arg(0), 10, 64)
+	if err != nil {
+		return errors.New(checkAllergiesUsage)
+	}
+
+	ctx := context.Background()
+	stores := data.NewStores(db)
+	entry, err := stores.MealEntryStore.GetMealEntry(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	checker := allergy.NewChecker(stores)
+	if *notify {
+		checker.Notifier = allergy.NewEmailNotifier(
+			cfg.smtp.host,
+			cfg.smtp.port,
+			cfg.smtp.username,
+			cfg.smtp.password,
+			cfg.smtp.sender,
+		)
+	}
+	warnings, err := checker.CheckAndNotify(ctx, entry)
+
+	for _, warning := range warnings {
+		fmt.Printf("%s matches allergy %q by %s\n", warning.FoodName, warning.AllergyName, warning.Match)
+	}
+	fmt.Printf("%d warnings\n", len(warnings))
+	return err
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+const importFoodsUsage = "usage: import-foods [-format csv|json] [-dry-run] [-strict] <file>"
+
+// runImportFoods implements the import-foods subcommand, which loads the
+// predefined food catalog from a CSV or JSON file.
+func runImportFoods(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("import-foods", flag.ContinueOnError)
+	format := flags.String("format", "", "Catalog format (csv|json), by default taken from the file extension")
+	dryRun := flags.Bool("dry-run", false, "Report what would change without writing anything")
+	strict := flags.Bool("strict", false, "Import nothing if any entry is a duplicate or invalid")
+	if err := flags.Parse(args); err != nil {
+		return errors.New(importFoodsUsage)
+	}
+	if flags.NArg() != 1 {
+		return errors.New(importFoodsUsage)
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	var read func(r io.Reader) ([]*data.FoodCatalogEntry, error)
+	switch *format {
+	case "csv":
+		read = data.ReadFoodCatalogCSV
+	case "json":
+		read = data.ReadFoodCatalogJSON
+	default:
+		return fmt.Errorf("unknown catalog format %q (expected csv or json)", *format)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	entries, err := read(file)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+
+	report, err := data.NewStores(db).ImportFoodCatalog(
+		context.Background(),
+		entries,
+		data.FoodCatalogOptions{DryRun: *dryRun, Strict: *strict},
+	)
+	if report != nil {
+		printFoodCatalogReport(report, *dryRun)
+	}
+	return err
+}
+
+func printFoodCatalogReport(report *data.FoodCatalogReport, dryRun bool) {
+	for _, issue := range report.Duplicates {
+		fmt.Printf("duplicate: %s\n", issue)
+	}
+	for _, issue := range report.Invalid {
+		fmt.Printf("invalid: %s\n", issue)
+	}
+
+	prefix := ""
+	if dryRun {
+		prefix = "(dry run) "
+	}
+	fmt.Printf(
+		"%screated %d, updated %d, unchanged %d, %d duplicates, %d invalid\n",
+		prefix,
+		report.Created,
+		report.Updated,
+		report.Unchanged,
+		len(report.Duplicates),
+		len(report.Invalid),
+	)
+}
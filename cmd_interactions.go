@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"github.com/Universal-Selfcare/utils/data"
+	"github.com/Universal-Selfcare/utils/interaction"
+)
+
+const interactionsUsage = "usage: interactions [-rules file] <user id> [tracking period id ...]"
+
+// runInteractions implements the interactions subcommand, which lists the
+// medication interactions found among the records a user took during the
+// given tracking periods and their meals, or among their current records
+// and the meals of the current period.
+func runInteractions(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("interactions", flag.ContinueOnError)
+	rulesPath := flags.String("rules", "", "Interaction rules file, by default the built-in rules")
+	if err := flags.Parse(args); err != nil {
+		return errors.New(interactionsUsage)
+	}
+	if flags.NArg() < 1 {
+		return errors.New(interactionsUsage)
+	}
+
+	ids := make([]int64, flags.NArg())
+	for i, arg := range flags.Args() {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return errors.New(interactionsUsage)
+		}
+		ids[i] = id
+	}
+
+	rules, err := interaction.DefaultRules()
+	if *rulesPath != "" {
+		rules, err = interaction.LoadRules(*rulesPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	engine := interaction.NewEngine(data.NewStores(db), rules)
+	findings, err := engine.Check(context.Background(), ids[0], ids[1:]...)
+	if err != nil {
+		return err
+	}
+
+	for _, finding := range findings {
+		with := finding.Supplement
+		if finding.Kind == interaction.KindDrugFood {
+			with = fmt.Sprintf("%s (meal entry %d)", finding.Food, finding.MealEntryID)
+		}
+		fmt.Printf("[%s] %s with %s: %s\n", finding.Severity, finding.Medication, with, finding.Advice)
+	}
+	fmt.Printf("%d findings\n", len(findings))
+	return nil
+}
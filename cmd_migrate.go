@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Universal-Selfcare/utils/migrate"
+)
+
+const migrateUsage = "usage: migrate up|down|status|goto <version>"
+
+// runMigrate implements the migrate subcommand.
+func runMigrate(migrator *migrate.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	ctx := context.Background()
+
+	var (
+		changed []migrate.Migration
+		err     error
+	)
+
+	switch args[0] {
+	case "up":
+		changed, err = migrator.Up(ctx)
+	case "down":
+		changed, err = migrator.Down(ctx)
+	case "goto":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, parseErr := strconv.ParseInt(args[1], 10, 64)
+		if parseErr != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		changed, err = migrator.Goto(ctx, version)
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	for _, migration := range changed {
+		fmt.Printf("%04d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		fmt.Println("No migrations to run")
+	}
+
+	return nil
+}
+
+func printMigrationStatus(ctx context.Context, migrator *migrate.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Version\t|\tApplied At\t\t\t|\tName")
+	fmt.Println("------------------------------------------------------")
+	for _, status := range statuses {
+		appliedAt := "pending\t\t\t"
+		if status.Applied {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		fmt.Printf("%04d\t|\t%s\t|\t%s\n", status.Version, appliedAt, status.Name)
+	}
+
+	return nil
+}
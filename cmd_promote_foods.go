@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/Universal-Selfcare/utils/catalog"
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+const promoteFoodsUsage = "usage: promote-foods [-min-uses n] [-min-users n] [-similarity s] " +
+	"[-promote name [-food-item id | -category category [-name name]]]"
+
+// runPromoteFoods implements the promote-foods subcommand. Without -promote
+// it lists the custom foods that are candidates for the catalog; with it,
+// it promotes the cluster containing the given custom food name.
+func runPromoteFoods(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("promote-foods", flag.ContinueOnError)
+	minUses := flags.Int("min-uses", catalog.DefaultMinUses, "Minimum number of times a candidate was logged")
+	minUsers := flags.Int("min-users", catalog.DefaultMinUsers, "Minimum number of users who logged a candidate")
+	similarity := flags.Float64("similarity", catalog.DefaultSimilarity, "Similarity from which names are clustered (0-1)")
+	promote := flags.String("promote", "", "Custom food name whose cluster to promote")
+	foodItemID := flags.Int64("food-item", 0, "Existing food item to link the custom foods to")
+	category := flags.String("category", "", "Category of the food item to create")
+	name := flags.String("name", "", "Name of the food item to create, by default the most used spelling")
+	if err := flags.Parse(args); err != nil {
+		return errors.New(promoteFoodsUsage)
+	}
+	if flags.NArg() != 0 {
+		return errors.New(promoteFoodsUsage)
+	}
+
+	ctx := context.Background()
+	promoter := catalog.NewPromoter(data.NewStores(db))
+	promoter.MinUses, promoter.MinUsers, promoter.Similarity = *minUses, *minUsers, *similarity
+
+	if *promote == "" {
+		candidates, err := promoter.Candidates(ctx)
+		if err != nil {
+			return err
+		}
+		printCandidates(candidates)
+		return nil
+	}
+
+	clusters, err := promoter.Clusters(ctx)
+	if err != nil {
+		return err
+	}
+	var cluster *catalog.Cluster
+	for _, c := range clusters {
+		if c.Has(*promote) {
+			cluster = c
+			break
+		}
+	}
+	if cluster == nil {
+		return fmt.Errorf("no custom food is named %q", *promote)
+	}
+
+	// Without a category or food item, link to the catalog item the cluster
+	// already matches.
+	item := &data.FoodItem{ID: *foodItemID, Name: *name, Category: *category}
+	if item.ID == 0 && item.Category == "" {
+		if cluster.FoodItem == nil {
+			return errors.New("promote-foods: -category or -food-item is required")
+		}
+		item = cluster.FoodItem
+	}
+	if item.Name == "" {
+		item.Name = cluster.Name
+	}
+
+	promotion, err := promoter.Promote(ctx, cluster, item)
+	if err != nil {
+		return err
+	}
+
+	verb := "linked to"
+	if promotion.Created {
+		verb = "created"
+	}
+	fmt.Printf(
+		"%s food item %d (%s): %d meal foods linked, %d already linked, %d meal notes kept\n",
+		verb,
+		promotion.FoodItem.ID,
+		promotion.FoodItem.Name,
+		promotion.Linked,
+		promotion.Merged,
+		promotion.Annotated,
+	)
+	return nil
+}
+
+func printCandidates(candidates []*catalog.Cluster) {
+	for _, cluster := range candidates {
+		spellings := make([]string, len(cluster.Spellings))
+		for i, spelling := range cluster.Spellings {
+			spellings[i] = fmt.Sprintf("%s (%d)", spelling.Name, spelling.Uses)
+		}
+		existing := ""
+		if cluster.FoodItem != nil {
+			existing = fmt.Sprintf(", matches food item %d (%s)", cluster.FoodItem.ID, cluster.FoodItem.Name)
+		}
+		fmt.Printf(
+			"%s: %d uses by %d users%s\n  %s\n",
+			cluster.Name,
+			cluster.Uses,
+			cluster.Users,
+			existing,
+			strings.Join(spellings, ", "),
+		)
+	}
+	fmt.Printf("%d candidates\n", len(candidates))
+}
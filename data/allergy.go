@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"time"
 )
 
@@ -14,9 +15,9 @@ type Allergy struct {
 }
 
 type AllergyStore interface {
-	CreateAllergy(allergy *Allergy) (*Allergy, error)
-	GetAllergy(id int64) (*Allergy, error)
-	ListUserAllergies(userID int64) ([]*Allergy, error)
-	UpdateAllergy(allergy *Allergy) error
-	DeleteAllergy(id int64) error
+	CreateAllergy(ctx context.Context, allergy *Allergy) (*Allergy, error)
+	GetAllergy(ctx context.Context, id int64) (*Allergy, error)
+	ListUserAllergies(ctx context.Context, userID int64) ([]*Allergy, error)
+	UpdateAllergy(ctx context.Context, allergy *Allergy) error
+	DeleteAllergy(ctx context.Context, id int64) error
 }
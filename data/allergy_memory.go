@@ -0,0 +1,90 @@
+package data
+
+import "context"
+
+type MemoryAllergyStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryAllergyStore(db *MemoryDB) *MemoryAllergyStore {
+	return &MemoryAllergyStore{db: db}
+}
+
+func (store *MemoryAllergyStore) CreateAllergy(
+	ctx context.Context,
+	allergy *Allergy,
+) (*Allergy, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if allergy.ID != 0 {
+		if _, exists := store.db.allergies.get(allergy.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		allergy.ID = store.db.allergies.nextID()
+	}
+	setCreateTimestamps(&allergy.CreatedAt, &allergy.UpdatedAt)
+	store.db.allergies.put(allergy.ID, *allergy)
+
+	return allergy, nil
+}
+
+func (store *MemoryAllergyStore) GetAllergy(ctx context.Context, id int64) (*Allergy, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	allergy, ok := store.db.allergies.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &allergy, nil
+}
+
+func (store *MemoryAllergyStore) ListUserAllergies(
+	ctx context.Context,
+	userID int64,
+) ([]*Allergy, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.allergies.filter(func(row *Allergy) bool {
+		return row.UserID == userID
+	}), nil
+}
+
+func (store *MemoryAllergyStore) UpdateAllergy(ctx context.Context, allergy *Allergy) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.allergies.get(allergy.ID)
+	if allergy.ID == 0 || !ok {
+		if allergy.ID == 0 {
+			allergy.ID = store.db.allergies.nextID()
+		}
+		setCreateTimestamps(&allergy.CreatedAt, &allergy.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&allergy.CreatedAt, &allergy.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.allergies.put(allergy.ID, *allergy)
+
+	return nil
+}
+
+func (store *MemoryAllergyStore) DeleteAllergy(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.allergies.delete(id)
+	return nil
+}
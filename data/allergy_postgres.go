@@ -1,33 +1,42 @@
 package data
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type PostgresAllergyStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresAllergyStore(db *gorm.DB) *PostgresAllergyStore {
-	if err := db.AutoMigrate(&Allergy{}); err != nil {
-		panic("failed to migrate allergy schema: " + err.Error())
-	}
 	return &PostgresAllergyStore{DB: db}
 }
 
-func (store *PostgresAllergyStore) CreateAllergy(allergy *Allergy) (*Allergy, error) {
-	err := store.DB.Create(allergy).Error
+func (store *PostgresAllergyStore) CreateAllergy(
+	ctx context.Context,
+	allergy *Allergy,
+) (*Allergy, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(allergy).Error
 	if err != nil {
 		return nil, err
 	}
 	return allergy, nil
 }
 
-func (store *PostgresAllergyStore) GetAllergy(id int64) (*Allergy, error) {
+func (store *PostgresAllergyStore) GetAllergy(ctx context.Context, id int64) (*Allergy, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var allergy Allergy
-	err := store.DB.First(&allergy, id).Error
+	err := db.First(&allergy, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -37,25 +46,37 @@ func (store *PostgresAllergyStore) GetAllergy(id int64) (*Allergy, error) {
 	return &allergy, nil
 }
 
-func (store *PostgresAllergyStore) ListUserAllergies(userID int64) ([]*Allergy, error) {
+func (store *PostgresAllergyStore) ListUserAllergies(
+	ctx context.Context,
+	userID int64,
+) ([]*Allergy, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var allergies []*Allergy
-	err := store.DB.Where("user_id = ?", userID).Find(&allergies).Error
+	err := db.Where("user_id = ?", userID).Find(&allergies).Error
 	if err != nil {
 		return nil, err
 	}
 	return allergies, nil
 }
 
-func (store *PostgresAllergyStore) UpdateAllergy(allergy *Allergy) error {
-	err := store.DB.Save(allergy).Error
+func (store *PostgresAllergyStore) UpdateAllergy(ctx context.Context, allergy *Allergy) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(allergy).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresAllergyStore) DeleteAllergy(id int64) error {
-	err := store.DB.Delete(&Allergy{}, id).Error
+func (store *PostgresAllergyStore) DeleteAllergy(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&Allergy{}, id).Error
 	if err != nil {
 		return err
 	}
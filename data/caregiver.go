@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"time"
 )
 
@@ -14,10 +15,10 @@ type Caregiver struct {
 }
 
 type CaregiverStore interface {
-	CreateCaregiver(caregiver *Caregiver) (*Caregiver, error)
-	GetCaregiver(id int64) (*Caregiver, error)
-	GetCaregiverByEmail(email string) (*Caregiver, error)
-	ListUserCaregivers(userID int64) ([]*Caregiver, error)
-	UpdateCaregiver(caregiver *Caregiver) error
-	DeleteCaregiver(id int64) error
+	CreateCaregiver(ctx context.Context, caregiver *Caregiver) (*Caregiver, error)
+	GetCaregiver(ctx context.Context, id int64) (*Caregiver, error)
+	GetCaregiverByEmail(ctx context.Context, email string) (*Caregiver, error)
+	ListUserCaregivers(ctx context.Context, userID int64) ([]*Caregiver, error)
+	UpdateCaregiver(ctx context.Context, caregiver *Caregiver) error
+	DeleteCaregiver(ctx context.Context, id int64) error
 }
@@ -0,0 +1,111 @@
+package data
+
+import "context"
+
+type MemoryCaregiverStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryCaregiverStore(db *MemoryDB) *MemoryCaregiverStore {
+	return &MemoryCaregiverStore{db: db}
+}
+
+func (store *MemoryCaregiverStore) CreateCaregiver(
+	ctx context.Context,
+	caregiver *Caregiver,
+) (*Caregiver, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if caregiver.ID != 0 {
+		if _, exists := store.db.caregivers.get(caregiver.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		caregiver.ID = store.db.caregivers.nextID()
+	}
+	setCreateTimestamps(&caregiver.CreatedAt, &caregiver.UpdatedAt)
+	store.db.caregivers.put(caregiver.ID, *caregiver)
+
+	return caregiver, nil
+}
+
+func (store *MemoryCaregiverStore) GetCaregiver(ctx context.Context, id int64) (*Caregiver, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	caregiver, ok := store.db.caregivers.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &caregiver, nil
+}
+
+func (store *MemoryCaregiverStore) GetCaregiverByEmail(
+	ctx context.Context,
+	email string,
+) (*Caregiver, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	caregiver, ok := store.db.caregivers.first(func(row *Caregiver) bool {
+		return row.Email == email
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return caregiver, nil
+}
+
+func (store *MemoryCaregiverStore) ListUserCaregivers(
+	ctx context.Context,
+	userID int64,
+) ([]*Caregiver, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.caregivers.filter(func(row *Caregiver) bool {
+		return row.UserID == userID
+	}), nil
+}
+
+func (store *MemoryCaregiverStore) UpdateCaregiver(
+	ctx context.Context,
+	caregiver *Caregiver,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.caregivers.get(caregiver.ID)
+	if caregiver.ID == 0 || !ok {
+		if caregiver.ID == 0 {
+			caregiver.ID = store.db.caregivers.nextID()
+		}
+		setCreateTimestamps(&caregiver.CreatedAt, &caregiver.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&caregiver.CreatedAt, &caregiver.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.caregivers.put(caregiver.ID, *caregiver)
+
+	return nil
+}
+
+func (store *MemoryCaregiverStore) DeleteCaregiver(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.caregivers.delete(id)
+	return nil
+}
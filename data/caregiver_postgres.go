@@ -1,33 +1,45 @@
 package data
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type PostgresCaregiverStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresCaregiverStore(db *gorm.DB) *PostgresCaregiverStore {
-	if err := db.AutoMigrate(&Caregiver{}); err != nil {
-		panic("failed to migrate caregiver schema: " + err.Error())
-	}
 	return &PostgresCaregiverStore{DB: db}
 }
 
-func (store *PostgresCaregiverStore) CreateCaregiver(caregiver *Caregiver) (*Caregiver, error) {
-	err := store.DB.Create(caregiver).Error
+func (store *PostgresCaregiverStore) CreateCaregiver(
+	ctx context.Context,
+	caregiver *Caregiver,
+) (*Caregiver, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(caregiver).Error
 	if err != nil {
 		return nil, err
 	}
 	return caregiver, nil
 }
 
-func (store *PostgresCaregiverStore) GetCaregiver(id int64) (*Caregiver, error) {
+func (store *PostgresCaregiverStore) GetCaregiver(
+	ctx context.Context,
+	id int64,
+) (*Caregiver, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var caregiver Caregiver
-	err := store.DB.First(&caregiver, id).Error
+	err := db.First(&caregiver, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -37,9 +49,15 @@ func (store *PostgresCaregiverStore) GetCaregiver(id int64) (*Caregiver, error)
 	return &caregiver, nil
 }
 
-func (store *PostgresCaregiverStore) GetCaregiverByEmail(email string) (*Caregiver, error) {
+func (store *PostgresCaregiverStore) GetCaregiverByEmail(
+	ctx context.Context,
+	email string,
+) (*Caregiver, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var caregiver Caregiver
-	err := store.DB.Where("email = ?", email).First(&caregiver).Error
+	err := db.Where("email = ?", email).First(&caregiver).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -49,25 +67,40 @@ func (store *PostgresCaregiverStore) GetCaregiverByEmail(email string) (*Caregiv
 	return &caregiver, nil
 }
 
-func (store *PostgresCaregiverStore) ListUserCaregivers(userID int64) ([]*Caregiver, error) {
+func (store *PostgresCaregiverStore) ListUserCaregivers(
+	ctx context.Context,
+	userID int64,
+) ([]*Caregiver, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var caregivers []*Caregiver
-	err := store.DB.Where("user_id = ?", userID).Find(&caregivers).Error
+	err := db.Where("user_id = ?", userID).Find(&caregivers).Error
 	if err != nil {
 		return nil, err
 	}
 	return caregivers, nil
 }
 
-func (store *PostgresCaregiverStore) UpdateCaregiver(caregiver *Caregiver) error {
-	err := store.DB.Save(caregiver).Error
+func (store *PostgresCaregiverStore) UpdateCaregiver(
+	ctx context.Context,
+	caregiver *Caregiver,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(caregiver).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresCaregiverStore) DeleteCaregiver(id int64) error {
-	err := store.DB.Delete(&Caregiver{}, id).Error
+func (store *PostgresCaregiverStore) DeleteCaregiver(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&Caregiver{}, id).Error
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,103 @@
+package datatest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func testBackfillDosages(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	user := newUser(t, stores)
+
+	parsed, err := stores.MedicationStore.CreateMedication(ctx, &data.Medication{
+		UserID: user.ID,
+		Name:   "Metformin",
+		Dosage: "500 mg by mouth twice daily",
+	})
+	mustNoError(t, "CreateMedication", err)
+	set := data.Dose{DoseAmount: 1, DoseUnit: data.DoseTablet}
+	kept, err := stores.MedicationStore.CreateMedication(ctx, &data.Medication{
+		UserID: user.ID,
+		Name:   "Ibuprofen",
+		Dosage: "200mg as needed",
+		Dose:   set,
+	})
+	mustNoError(t, "CreateMedication", err)
+	invalid, err := stores.MedicationStore.CreateMedication(ctx, &data.Medication{
+		UserID: user.ID,
+		Name:   "Levothyroxine",
+		Dosage: "50 mcg daily",
+		Dose:   data.Dose{DoseAmount: 50, DoseUnit: "foo", DosePeriod: -1},
+	})
+	mustNoError(t, "CreateMedication", err)
+	unparsed, err := stores.DietarySupplementStore.CreateDietarySupplement(ctx, &data.DietarySupplement{
+		UserID: user.ID,
+		Name:   "Probiotic",
+		Dosage: "10 billion CFU",
+	})
+	mustNoError(t, "CreateDietarySupplement", err)
+
+	report, err := stores.BackfillDosages(ctx, data.DosageBackfillOptions{DryRun: true})
+	mustNoError(t, "BackfillDosages dry run", err)
+	if !hasDosageIssue(report, "dietary_supplement", unparsed.ID) {
+		t.Fatalf("BackfillDosages reported %+v, want dietary supplement %d", report.Unparsed, unparsed.ID)
+	}
+	got, err := stores.MedicationStore.GetMedication(ctx, parsed.ID)
+	mustNoError(t, "GetMedication", err)
+	if !got.Dose.IsZero() {
+		t.Fatalf("dose after a dry run = %+v, want none", got.Dose)
+	}
+
+	_, err = stores.BackfillDosages(ctx, data.DosageBackfillOptions{})
+	mustNoError(t, "BackfillDosages", err)
+	got, err = stores.MedicationStore.GetMedication(ctx, parsed.ID)
+	mustNoError(t, "GetMedication", err)
+	want := data.Dose{
+		DoseAmount:     500,
+		DoseUnit:       data.DoseMilligram,
+		DoseFrequency:  2,
+		DosePeriod:     1,
+		DosePeriodUnit: data.PeriodDay,
+		DoseRoute:      data.RouteOral,
+	}
+	if got.Dose != want {
+		t.Fatalf("backfilled dose = %+v, want %+v", got.Dose, want)
+	}
+	got, err = stores.MedicationStore.GetMedication(ctx, kept.ID)
+	mustNoError(t, "GetMedication", err)
+	if got.Dose != set {
+		t.Fatalf("dose already set = %+v, want it kept as %+v", got.Dose, set)
+	}
+	// An invalid dose is parsed again even without overwriting.
+	got, err = stores.MedicationStore.GetMedication(ctx, invalid.ID)
+	mustNoError(t, "GetMedication", err)
+	want = data.Dose{
+		DoseAmount:     50,
+		DoseUnit:       data.DoseMicrogram,
+		DoseFrequency:  1,
+		DosePeriod:     1,
+		DosePeriodUnit: data.PeriodDay,
+	}
+	if got.Dose != want {
+		t.Fatalf("dose replacing an invalid one = %+v, want %+v", got.Dose, want)
+	}
+
+	_, err = stores.BackfillDosages(ctx, data.DosageBackfillOptions{Overwrite: true})
+	mustNoError(t, "BackfillDosages with overwrite", err)
+	got, err = stores.MedicationStore.GetMedication(ctx, kept.ID)
+	mustNoError(t, "GetMedication", err)
+	if got.Dose.DoseAmount != 200 || got.Dose.DoseUnit != data.DoseMilligram || !got.Dose.DoseAsNeeded {
+		t.Fatalf("overwritten dose = %+v, want 200 mg as needed", got.Dose)
+	}
+}
+
+func hasDosageIssue(report *data.DosageBackfillReport, record string, id int64) bool {
+	for _, issue := range report.Unparsed {
+		if issue.Record == record && issue.ID == id {
+			return true
+		}
+	}
+	return false
+}
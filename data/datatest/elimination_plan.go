@@ -0,0 +1,188 @@
+package datatest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func newEliminationPlan(
+	t *testing.T,
+	stores *data.Stores,
+	userID int64,
+	start time.Time,
+) *data.EliminationPlan {
+	t.Helper()
+
+	ctx := context.Background()
+	plan, err := stores.EliminationPlanStore.CreateEliminationPlan(ctx, &data.EliminationPlan{
+		UserID:             userID,
+		Name:               "Dairy and gluten",
+		StartDate:          start,
+		ReintroductionDate: start.AddDate(0, 0, 21),
+		EndDate:            start.AddDate(0, 0, 42),
+	})
+	mustNoError(t, "CreateEliminationPlan", err)
+	return plan
+}
+
+func testEliminationPlanStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.EliminationPlanStore
+	id := func(p *data.EliminationPlan) int64 { return p.ID }
+
+	user := newUser(t, stores)
+	first := newEliminationPlan(t, stores, user.ID, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	second := newEliminationPlan(t, stores, user.ID, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC))
+
+	got, err := store.GetEliminationPlan(ctx, first.ID)
+	mustNoError(t, "GetEliminationPlan", err)
+	if got.Name != first.Name || !got.EndDate.Equal(first.EndDate) {
+		t.Fatalf("GetEliminationPlan returned %+v, want %+v", got, first)
+	}
+
+	active, err := store.GetActiveEliminationPlan(ctx, user.ID, time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC))
+	mustNoError(t, "GetActiveEliminationPlan", err)
+	if active.ID != second.ID {
+		t.Fatalf("GetActiveEliminationPlan returned plan %d, want the later plan %d", active.ID, second.ID)
+	}
+	active, err = store.GetActiveEliminationPlan(ctx, user.ID, first.StartDate)
+	mustNoError(t, "GetActiveEliminationPlan on the start date", err)
+	if active.ID != first.ID {
+		t.Fatalf("GetActiveEliminationPlan returned plan %d, want %d", active.ID, first.ID)
+	}
+	_, err = store.GetActiveEliminationPlan(ctx, user.ID, second.EndDate)
+	mustNotFound(t, "GetActiveEliminationPlan on the end date", err)
+
+	plans, err := store.ListUserEliminationPlans(ctx, user.ID)
+	mustNoError(t, "ListUserEliminationPlans", err)
+	if len(plans) != 2 || plans[0].ID != second.ID {
+		t.Fatalf("ListUserEliminationPlans returned %d plans, want the newest of 2 first", len(plans))
+	}
+
+	first.Notes = "extend elimination"
+	mustNoError(t, "UpdateEliminationPlan", store.UpdateEliminationPlan(ctx, first))
+	got, err = store.GetEliminationPlan(ctx, first.ID)
+	mustNoError(t, "GetEliminationPlan", err)
+	if got.Notes != first.Notes {
+		t.Fatalf("UpdateEliminationPlan did not persist notes: %q", got.Notes)
+	}
+
+	mustNoError(t, "DeleteEliminationPlan", store.DeleteEliminationPlan(ctx, first.ID))
+	_, err = store.GetEliminationPlan(ctx, first.ID)
+	mustNotFound(t, "GetEliminationPlan after DeleteEliminationPlan", err)
+	plans, err = store.ListUserEliminationPlans(ctx, user.ID)
+	mustNoError(t, "ListUserEliminationPlans", err)
+	if containsID(plans, id, first.ID) {
+		t.Fatal("ListUserEliminationPlans still lists the deleted plan")
+	}
+}
+
+func testEliminationRestrictionStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.EliminationRestrictionStore
+	id := func(r *data.EliminationRestriction) int64 { return r.ID }
+
+	user := newUser(t, stores)
+	plan := newEliminationPlan(t, stores, user.ID, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	item, err := stores.FoodItemStore.CreateFoodItem(ctx, &data.FoodItem{
+		Name:     "Wheat " + unique(),
+		Category: "Grains",
+	})
+	mustNoError(t, "CreateFoodItem", err)
+
+	byItem, err := store.CreateEliminationRestriction(ctx, &data.EliminationRestriction{
+		PlanID:     plan.ID,
+		FoodItemID: item.ID,
+	})
+	mustNoError(t, "CreateEliminationRestriction", err)
+	byCategory, err := store.CreateEliminationRestriction(ctx, &data.EliminationRestriction{
+		PlanID:   plan.ID,
+		Category: "Dairy",
+	})
+	mustNoError(t, "CreateEliminationRestriction", err)
+
+	restrictions, err := store.ListEliminationRestrictions(ctx, plan.ID)
+	mustNoError(t, "ListEliminationRestrictions", err)
+	if len(restrictions) != 2 || restrictions[0].ID != byItem.ID || restrictions[1].Category != "Dairy" {
+		t.Fatalf("ListEliminationRestrictions returned %+v", restrictions)
+	}
+
+	mustNoError(t, "DeleteEliminationRestriction", store.DeleteEliminationRestriction(ctx, byItem.ID))
+	restrictions, err = store.ListEliminationRestrictions(ctx, plan.ID)
+	mustNoError(t, "ListEliminationRestrictions", err)
+	if containsID(restrictions, id, byItem.ID) || !containsID(restrictions, id, byCategory.ID) {
+		t.Fatalf("DeleteEliminationRestriction left %+v", restrictions)
+	}
+
+	mustNoError(
+		t,
+		"DeleteAllEliminationRestrictionsForPlan",
+		store.DeleteAllEliminationRestrictionsForPlan(ctx, plan.ID),
+	)
+	restrictions, err = store.ListEliminationRestrictions(ctx, plan.ID)
+	mustNoError(t, "ListEliminationRestrictions", err)
+	if len(restrictions) != 0 {
+		t.Fatalf("DeleteAllEliminationRestrictionsForPlan left %d restrictions", len(restrictions))
+	}
+}
+
+func testReintroductionStepStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.ReintroductionStepStore
+
+	user := newUser(t, stores)
+	plan := newEliminationPlan(t, stores, user.ID, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	later, err := store.CreateReintroductionStep(ctx, &data.ReintroductionStep{
+		PlanID:    plan.ID,
+		Category:  "Grains",
+		StartDate: plan.ReintroductionDate.AddDate(0, 0, 7),
+		EndDate:   plan.ReintroductionDate.AddDate(0, 0, 10),
+	})
+	mustNoError(t, "CreateReintroductionStep", err)
+	earlier, err := store.CreateReintroductionStep(ctx, &data.ReintroductionStep{
+		PlanID:    plan.ID,
+		Category:  "Dairy",
+		StartDate: plan.ReintroductionDate,
+		EndDate:   plan.ReintroductionDate.AddDate(0, 0, 3),
+	})
+	mustNoError(t, "CreateReintroductionStep", err)
+
+	got, err := store.GetReintroductionStep(ctx, earlier.ID)
+	mustNoError(t, "GetReintroductionStep", err)
+	if got.Outcome != data.ReintroductionPending {
+		t.Fatalf("new step has outcome %q, want %q", got.Outcome, data.ReintroductionPending)
+	}
+
+	steps, err := store.ListReintroductionSteps(ctx, plan.ID)
+	mustNoError(t, "ListReintroductionSteps", err)
+	if len(steps) != 2 || steps[0].ID != earlier.ID || steps[1].ID != later.ID {
+		t.Fatalf("ListReintroductionSteps did not order by start date: %+v", steps)
+	}
+
+	got.Outcome = data.ReintroductionReacted
+	mustNoError(t, "UpdateReintroductionStep", store.UpdateReintroductionStep(ctx, got))
+	got, err = store.GetReintroductionStep(ctx, earlier.ID)
+	mustNoError(t, "GetReintroductionStep", err)
+	if got.Outcome != data.ReintroductionReacted {
+		t.Fatalf("UpdateReintroductionStep did not persist outcome: %q", got.Outcome)
+	}
+
+	mustNoError(t, "DeleteReintroductionStep", store.DeleteReintroductionStep(ctx, earlier.ID))
+	_, err = store.GetReintroductionStep(ctx, earlier.ID)
+	mustNotFound(t, "GetReintroductionStep after DeleteReintroductionStep", err)
+
+	mustNoError(
+		t,
+		"DeleteAllReintroductionStepsForPlan",
+		store.DeleteAllReintroductionStepsForPlan(ctx, plan.ID),
+	)
+	steps, err = store.ListReintroductionSteps(ctx, plan.ID)
+	mustNoError(t, "ListReintroductionSteps", err)
+	if len(steps) != 0 {
+		t.Fatalf("DeleteAllReintroductionStepsForPlan left %d steps", len(steps))
+	}
+}
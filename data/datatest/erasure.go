@@ -0,0 +1,142 @@
+package datatest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func testEraseUser(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+
+	user := newUser(t, stores)
+	bystander := newUser(t, stores)
+
+	_, err := stores.TokenStore.CreateToken(ctx, user.ID, time.Hour, data.ScopeAuthentication)
+	mustNoError(t, "CreateToken", err)
+	_, err = stores.UserIntakeStore.CreateUserIntake(ctx, &data.UserIntake{
+		UserID:   user.ID,
+		FormData: `{}`,
+	})
+	mustNoError(t, "CreateUserIntake", err)
+	_, err = stores.AllergyStore.CreateAllergy(ctx, &data.Allergy{UserID: user.ID, AllergyName: "Dust"})
+	mustNoError(t, "CreateAllergy", err)
+	keptAllergy, err := stores.AllergyStore.CreateAllergy(ctx, &data.Allergy{
+		UserID:      bystander.ID,
+		AllergyName: "Dust",
+	})
+	mustNoError(t, "CreateAllergy", err)
+	medication, schedule := newScheduledMedication(t, stores, user.ID)
+	_, err = stores.DoseLogStore.CreateDoseLog(ctx, &data.DoseLog{
+		UserID:       user.ID,
+		MedicationID: medication.ID,
+		ScheduledAt:  schedule.StartDate.Add(8 * time.Hour),
+		TakenAt:      schedule.StartDate.Add(8 * time.Hour),
+		Status:       data.DoseTaken,
+	})
+	mustNoError(t, "CreateDoseLog", err)
+
+	plan := newEliminationPlan(t, stores, user.ID, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	_, err = stores.EliminationRestrictionStore.CreateEliminationRestriction(ctx, &data.EliminationRestriction{
+		PlanID:   plan.ID,
+		Category: "Dairy",
+	})
+	mustNoError(t, "CreateEliminationRestriction", err)
+
+	period := newTrackingPeriod(t, stores, user.ID)
+	entry, err := stores.MealEntryStore.CreateMealEntry(ctx, &data.MealEntry{
+		UserID:           user.ID,
+		TrackingPeriodID: period.ID,
+		TrackingDay:      1,
+		MealType:         data.MealLunch,
+		MealTime:         time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		MealDuration:     30 * time.Minute,
+		PortionQuantity:  1,
+		PortionUnit:      data.PortionPlate,
+	})
+	mustNoError(t, "CreateMealEntry", err)
+	_, err = stores.CustomFoodStore.CreateCustomFood(ctx, &data.CustomFood{
+		MealEntryID: entry.ID,
+		Name:        "Curry",
+		Portion:     "1 bowl",
+		Preparation: "Stewed",
+	})
+	mustNoError(t, "CreateCustomFood", err)
+	_, err = stores.SymptomStore.CreateSymptom(ctx, &data.Symptom{
+		MealEntryID: entry.ID,
+		SymptomType: "Bloating",
+		Severity:    40,
+	})
+	mustNoError(t, "CreateSymptom", err)
+	standalone, err := stores.SymptomStore.CreateSymptom(ctx, &data.Symptom{
+		UserID:           user.ID,
+		TrackingPeriodID: period.ID,
+		OccurredAt:       time.Date(2025, 3, 1, 15, 0, 0, 0, time.UTC),
+		SymptomType:      "Headache",
+		Severity:         6,
+	})
+	mustNoError(t, "CreateSymptom", err)
+	_, err = stores.SymptomAttributionStore.CreateSymptomAttribution(ctx, &data.SymptomAttribution{
+		SymptomID:   standalone.ID,
+		MealEntryID: entry.ID,
+		Lag:         3 * time.Hour,
+	})
+	mustNoError(t, "CreateSymptomAttribution", err)
+
+	report, err := stores.EraseUser(ctx, user.ID)
+	mustNoError(t, "EraseUser", err)
+
+	want := data.ErasureReport{
+		UserID:          user.ID,
+		ErasedAt:        report.ErasedAt,
+		Tokens:          1,
+		UserIntakes:     1,
+		Allergies:       1,
+		Medications:     1,
+		TrackingPeriods: 1,
+		MealEntries:     1,
+		CustomFoods:     1,
+		Symptoms:        2,
+
+		SymptomAttributions: 1,
+
+		MedicationSchedules: 1,
+		DoseLogs:            1,
+
+		EliminationPlans:        1,
+		EliminationRestrictions: 1,
+	}
+	if *report != want {
+		t.Fatalf("EraseUser reported %+v, want %+v", *report, want)
+	}
+
+	_, err = stores.UserStore.GetUser(ctx, user.ID)
+	mustNotFound(t, "GetUser after EraseUser", err)
+	_, err = stores.TrackingPeriodStore.GetTrackingPeriod(ctx, period.ID)
+	mustNotFound(t, "GetTrackingPeriod after EraseUser", err)
+	_, err = stores.MealEntryStore.GetMealEntry(ctx, entry.ID)
+	mustNotFound(t, "GetMealEntry after EraseUser", err)
+	symptoms, err := stores.SymptomStore.ListSymptomsForMeal(ctx, entry.ID)
+	mustNoError(t, "ListSymptomsForMeal", err)
+	if len(symptoms) != 0 {
+		t.Fatalf("EraseUser left %d symptoms behind", len(symptoms))
+	}
+	_, err = stores.SymptomStore.GetSymptom(ctx, standalone.ID)
+	mustNotFound(t, "GetSymptom for a standalone symptom after EraseUser", err)
+	attributions, err := stores.SymptomAttributionStore.ListAttributionsForMeal(ctx, entry.ID)
+	mustNoError(t, "ListAttributionsForMeal", err)
+	if len(attributions) != 0 {
+		t.Fatalf("EraseUser left %d symptom attributions behind", len(attributions))
+	}
+
+	_, err = stores.EliminationPlanStore.GetEliminationPlan(ctx, plan.ID)
+	mustNotFound(t, "GetEliminationPlan after EraseUser", err)
+
+	_, err = stores.AllergyStore.GetAllergy(ctx, keptAllergy.ID)
+	mustNoError(t, "GetAllergy for another user", err)
+
+	_, err = stores.EraseUser(ctx, user.ID)
+	mustNotFound(t, "EraseUser for a missing user", err)
+}
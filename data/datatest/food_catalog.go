@@ -0,0 +1,119 @@
+package datatest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func testImportFoodCatalog(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	suffix := unique()
+	catalog := fmt.Sprintf(`name,category,allergens,fodmaps,high_histamine,protein_g
+Cheddar %[1]s,Dairy,milk,lactose,true,25
+Rye Bread %[1]s,Grains,"wheat,sesame",fructans,,8.5
+cheddar %[1]s,Dairy,milk,,,
+Mystery %[1]s,Snacks,,,,
+Crab %[1]s,Shellfish,crab,,,
+`, suffix)
+
+	read := func() []*data.FoodCatalogEntry {
+		entries, err := data.ReadFoodCatalogCSV(strings.NewReader(catalog))
+		mustNoError(t, "ReadFoodCatalogCSV", err)
+		return entries
+	}
+
+	report, err := stores.ImportFoodCatalog(ctx, read(), data.FoodCatalogOptions{Strict: true})
+	if !errors.Is(err, data.ErrCatalogRejected) {
+		t.Fatalf("ImportFoodCatalog in strict mode: got error %v, want %v", err, data.ErrCatalogRejected)
+	}
+	if len(report.Duplicates) != 1 || report.Duplicates[0].Line != 4 {
+		t.Fatalf("Duplicates = %+v, want line 4", report.Duplicates)
+	}
+	if len(report.Invalid) != 2 || report.Invalid[0].Field != "category" || report.Invalid[1].Line != 6 {
+		t.Fatalf("Invalid = %+v, want the category on line 5 and the allergen on line 6", report.Invalid)
+	}
+	mustNotImported := func(op string) {
+		t.Helper()
+		for _, name := range []string{"Cheddar ", "Rye Bread "} {
+			_, err := stores.FoodItemStore.GetFoodItemByName(ctx, name+suffix)
+			mustNotFound(t, "GetFoodItemByName after "+op, err)
+		}
+	}
+	mustNotImported("a strict-mode rejection")
+
+	report, err = stores.ImportFoodCatalog(ctx, read(), data.FoodCatalogOptions{DryRun: true})
+	mustNoError(t, "ImportFoodCatalog dry run", err)
+	if report.Created != 2 {
+		t.Fatalf("dry run reported %d items created, want 2", report.Created)
+	}
+	mustNotImported("a dry run")
+
+	report, err = stores.ImportFoodCatalog(ctx, read(), data.FoodCatalogOptions{})
+	mustNoError(t, "ImportFoodCatalog", err)
+	if report.Created != 2 || report.Updated != 0 {
+		t.Fatalf("ImportFoodCatalog created %d and updated %d items, want 2 and 0",
+			report.Created, report.Updated)
+	}
+
+	bread, err := stores.FoodItemStore.GetFoodItemByName(ctx, "Rye Bread "+suffix)
+	mustNoError(t, "GetFoodItemByName", err)
+	if bread.Allergens != data.AllergenWheat|data.AllergenSesame ||
+		bread.FODMAPs != data.FODMAPFructans || bread.ProteinG != 8.5 {
+		t.Fatalf("imported %+v, want wheat and sesame, fructans and 8.5 g protein", bread)
+	}
+
+	catalog = strings.Replace(catalog, ",8.5", ",9", 1)
+	report, err = stores.ImportFoodCatalog(ctx, read(), data.FoodCatalogOptions{DryRun: true})
+	mustNoError(t, "ImportFoodCatalog dry run", err)
+	if report.Updated != 1 {
+		t.Fatalf("dry run reported %d items updated, want 1", report.Updated)
+	}
+	unchanged, err := stores.FoodItemStore.GetFoodItemByName(ctx, bread.Name)
+	mustNoError(t, "GetFoodItemByName", err)
+	if unchanged.ProteinG != 8.5 {
+		t.Fatalf("dry run stored %g g protein, want 8.5", unchanged.ProteinG)
+	}
+
+	report, err = stores.ImportFoodCatalog(ctx, read(), data.FoodCatalogOptions{})
+	mustNoError(t, "ImportFoodCatalog", err)
+	if report.Created != 0 || report.Updated != 1 || report.Unchanged != 1 {
+		t.Fatalf("re-import created %d, updated %d and left %d unchanged, want 0, 1 and 1",
+			report.Created, report.Updated, report.Unchanged)
+	}
+	updated, err := stores.FoodItemStore.GetFoodItemByName(ctx, bread.Name)
+	mustNoError(t, "GetFoodItemByName", err)
+	if updated.ID != bread.ID || updated.ProteinG != 9 {
+		t.Fatalf("re-import stored %+v, want item %d with 9 g protein", updated, bread.ID)
+	}
+
+	// Names match existing items ignoring case.
+	catalog = fmt.Sprintf("name,category,protein_g\nrye bread %s,Grains,9\n", suffix)
+	report, err = stores.ImportFoodCatalog(ctx, read(), data.FoodCatalogOptions{})
+	mustNoError(t, "ImportFoodCatalog", err)
+	if report.Created != 0 || report.Updated != 1 {
+		t.Fatalf("import in lower case created %d and updated %d items, want 0 and 1",
+			report.Created, report.Updated)
+	}
+	renamed, err := stores.FoodItemStore.GetFoodItemByName(ctx, bread.Name)
+	mustNoError(t, "GetFoodItemByName", err)
+	if renamed.ID != bread.ID || renamed.Name != "rye bread "+suffix {
+		t.Fatalf("import in lower case stored %+v, want item %d renamed", renamed, bread.ID)
+	}
+
+	entries, err := data.ReadFoodCatalogJSON(strings.NewReader(fmt.Sprintf(
+		`[{"name": "Almonds %[1]s", "category": "Nuts and Seeds", "allergens": "tree_nuts"},
+		  {"name": "Soda %[1]s", "category": "Beverages", "sugar_g": 10}]`,
+		suffix,
+	)))
+	mustNoError(t, "ReadFoodCatalogJSON", err)
+	report, err = stores.ImportFoodCatalog(ctx, entries, data.FoodCatalogOptions{})
+	mustNoError(t, "ImportFoodCatalog", err)
+	if report.Created != 1 || len(report.Invalid) != 1 || report.Invalid[0].Line != 2 {
+		t.Fatalf("JSON import returned %+v, want one created and the second entry invalid", report)
+	}
+}
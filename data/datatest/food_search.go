@@ -0,0 +1,107 @@
+package datatest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Universal-Selfcare/utils/data"
+	"github.com/Universal-Selfcare/utils/search"
+)
+
+func testSearchFoods(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	suffix := unique()
+
+	var items []*data.FoodItem
+	for _, item := range []*data.FoodItem{
+		{Name: "Sweet Potatoes " + suffix, Category: "Vegetables", Synonyms: "kumara " + suffix},
+		{Name: "Sweet Potato Fries " + suffix, Category: "Prepared Foods"},
+		{Name: "Eggplant " + suffix, Category: "Vegetables", Synonyms: "aubergine " + suffix + ",brinjal " + suffix},
+	} {
+		created, err := stores.FoodItemStore.CreateFoodItem(ctx, item)
+		mustNoError(t, "CreateFoodItem", err)
+		items = append(items, created)
+	}
+	potatoes, fries, eggplant := items[0], items[1], items[2]
+
+	// position returns the index of the food item in results, or -1.
+	position := func(results []*data.FoodSearchResult, item *data.FoodItem) int {
+		for i, result := range results {
+			if result.FoodItem != nil && result.FoodItem.ID == item.ID {
+				return i
+			}
+		}
+		return -1
+	}
+	find := func(query string, limit int) []*data.FoodSearchResult {
+		results, err := stores.FoodItemStore.SearchFoodItems(ctx, query, limit)
+		mustNoError(t, "SearchFoodItems", err)
+		return results
+	}
+
+	t.Run("PrefixAndPlural", func(t *testing.T) {
+		results := find("sweet potato "+suffix, 0)
+		first, second := position(results, potatoes), position(results, fries)
+		if first < 0 || second < 0 || first > second {
+			t.Fatalf("SearchFoodItems returned %+v, want the potatoes before the fries", results)
+		}
+		if results[first].Match != search.MatchExact || results[second].Match != search.MatchPrefix {
+			t.Fatalf("matched %q and %q, want exact and prefix", results[first].Match, results[second].Match)
+		}
+
+		results = find("sweet potato "+suffix, 1)
+		if len(results) != 1 || position(results, potatoes) != 0 {
+			t.Fatalf("SearchFoodItems with a limit of 1 returned %+v", results)
+		}
+	})
+
+	t.Run("SynonymAndFuzzy", func(t *testing.T) {
+		results := find("AUBERGINE "+suffix, 0)
+		if i := position(results, eggplant); i < 0 || results[i].Match != search.MatchSynonym {
+			t.Fatalf("SearchFoodItems by synonym returned %+v", results)
+		}
+
+		results = find("eggplnt "+suffix, 0)
+		if i := position(results, eggplant); i < 0 || results[i].Match != search.MatchFuzzy {
+			t.Fatalf("SearchFoodItems with a typo returned %+v", results)
+		}
+
+		if results := find("  ", 0); len(results) != 0 {
+			t.Fatalf("SearchFoodItems with a blank query returned %d results", len(results))
+		}
+	})
+
+	t.Run("CustomFoods", func(t *testing.T) {
+		// Custom foods are searched per user, so only the one duplicating a
+		// catalog item needs a unique name.
+		entry := newMealEntry(t, stores)
+		other := newMealEntry(t, stores)
+		for _, food := range []*data.CustomFood{
+			{MealEntryID: entry.ID, Name: "Homemade Granola"},
+			{MealEntryID: entry.ID, Name: "homemade granola"},
+			{MealEntryID: entry.ID, Name: "Sweet potatoes " + suffix},
+			{MealEntryID: other.ID, Name: "Homemade Granola Bars"},
+		} {
+			_, err := stores.CustomFoodStore.CreateCustomFood(ctx, food)
+			mustNoError(t, "CreateCustomFood", err)
+		}
+
+		results, err := stores.CustomFoodStore.SearchCustomFoods(ctx, entry.UserID, "granola", 0)
+		mustNoError(t, "SearchCustomFoods", err)
+		if len(results) != 1 || results[0].Name != "Homemade Granola" || results[0].Uses != 2 ||
+			results[0].FoodItem != nil {
+			t.Fatalf("SearchCustomFoods returned %+v, want the user's granola used twice", results)
+		}
+
+		results, err = stores.SearchFoods(ctx, entry.UserID, "sweet potato "+suffix, 0)
+		mustNoError(t, "SearchFoods", err)
+		if position(results, potatoes) != 0 {
+			t.Fatalf("SearchFoods returned %+v, want the catalog potatoes first", results)
+		}
+		for _, result := range results {
+			if result.FoodItem == nil {
+				t.Fatalf("SearchFoods returned the custom food %q, which duplicates a catalog item", result.Name)
+			}
+		}
+	})
+}
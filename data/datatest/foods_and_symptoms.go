@@ -0,0 +1,784 @@
+package datatest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func newTrackingPeriod(t *testing.T, stores *data.Stores, userID int64) *data.TrackingPeriod {
+	t.Helper()
+
+	ctx := context.Background()
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	period, err := stores.TrackingPeriodStore.CreateTrackingPeriod(ctx, &data.TrackingPeriod{
+		UserID:    userID,
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, 5),
+	})
+	mustNoError(t, "CreateTrackingPeriod", err)
+	return period
+}
+
+func newMealEntry(t *testing.T, stores *data.Stores) *data.MealEntry {
+	t.Helper()
+
+	ctx := context.Background()
+	user := newUser(t, stores)
+	period := newTrackingPeriod(t, stores, user.ID)
+	entry, err := stores.MealEntryStore.CreateMealEntry(ctx, &data.MealEntry{
+		UserID:           user.ID,
+		TrackingPeriodID: period.ID,
+		TrackingDay:      1,
+		MealType:         data.MealBreakfast,
+		MealTime:         time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC),
+		MealDuration:     15 * time.Minute,
+		PortionQuantity:  1,
+		PortionUnit:      data.PortionBowl,
+	})
+	mustNoError(t, "CreateMealEntry", err)
+	return entry
+}
+
+func testTrackingPeriodStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.TrackingPeriodStore
+	id := func(p *data.TrackingPeriod) int64 { return p.ID }
+
+	t.Run("LengthDays", func(t *testing.T) {
+		user := newUser(t, stores)
+		defaulted := newTrackingPeriod(t, stores, user.ID)
+		got, err := store.GetTrackingPeriod(ctx, defaulted.ID)
+		mustNoError(t, "GetTrackingPeriod", err)
+		if got.LengthDays != data.DefaultTrackingPeriodLength {
+			t.Fatalf("LengthDays = %d, want default %d", got.LengthDays, data.DefaultTrackingPeriodLength)
+		}
+		mustNoError(t, "CompleteTrackingPeriod", store.CompleteTrackingPeriod(ctx, defaulted.ID))
+
+		start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+		long, err := store.CreateTrackingPeriod(ctx, data.NewTrackingPeriod(user.ID, start, 14))
+		mustNoError(t, "CreateTrackingPeriod", err)
+		got, err = store.GetTrackingPeriod(ctx, long.ID)
+		mustNoError(t, "GetTrackingPeriod", err)
+		if got.LengthDays != 14 || !got.EndDate.Equal(start.AddDate(0, 0, 14)) {
+			t.Fatalf("GetTrackingPeriod returned %+v, want a 14-day period", got)
+		}
+	})
+
+	t.Run("SingleActivePeriod", func(t *testing.T) {
+		user := newUser(t, stores)
+		active := newTrackingPeriod(t, stores, user.ID)
+		if active.ID == 0 {
+			t.Fatal("CreateTrackingPeriod did not assign an ID")
+		}
+
+		again, err := store.CreateTrackingPeriod(ctx, &data.TrackingPeriod{
+			UserID:    user.ID,
+			StartDate: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2025, 4, 6, 0, 0, 0, 0, time.UTC),
+		})
+		mustNoError(t, "CreateTrackingPeriod", err)
+		if again.ID != active.ID {
+			t.Fatalf("CreateTrackingPeriod with an active period returned %d, want existing %d",
+				again.ID, active.ID)
+		}
+
+		current, err := store.GetCurrentTrackingPeriod(ctx, user.ID, active.StartDate)
+		mustNoError(t, "GetCurrentTrackingPeriod", err)
+		if current.ID != active.ID {
+			t.Fatalf("GetCurrentTrackingPeriod returned %d, want %d", current.ID, active.ID)
+		}
+		// Once past its end date the period is expired, though still open.
+		_, err = store.GetCurrentTrackingPeriod(ctx, user.ID, active.EndDate)
+		mustNotFound(t, "GetCurrentTrackingPeriod after the end date", err)
+
+		mustNoError(t, "CompleteTrackingPeriod", store.CompleteTrackingPeriod(ctx, active.ID))
+		_, err = store.GetCurrentTrackingPeriod(ctx, user.ID, active.StartDate)
+		mustNotFound(t, "GetCurrentTrackingPeriod after completion", err)
+
+		got, err := store.GetTrackingPeriod(ctx, active.ID)
+		mustNoError(t, "GetTrackingPeriod", err)
+		if !got.IsCompleted {
+			t.Fatal("CompleteTrackingPeriod did not mark the period completed")
+		}
+	})
+
+	t.Run("CompletedPeriodsOrdering", func(t *testing.T) {
+		user := newUser(t, stores)
+
+		_, err := store.GetLastCompletedTrackingPeriod(ctx, user.ID)
+		mustNotFound(t, "GetLastCompletedTrackingPeriod", err)
+
+		var periods []*data.TrackingPeriod
+		for _, month := range []time.Month{time.May, time.January, time.March} {
+			start := time.Date(2025, month, 1, 0, 0, 0, 0, time.UTC)
+			period, err := store.CreateTrackingPeriod(ctx, &data.TrackingPeriod{
+				UserID:    user.ID,
+				StartDate: start,
+				EndDate:   start.AddDate(0, 0, 5),
+			})
+			mustNoError(t, "CreateTrackingPeriod", err)
+			mustNoError(t, "CompleteTrackingPeriod", store.CompleteTrackingPeriod(ctx, period.ID))
+			periods = append(periods, period)
+		}
+
+		last, err := store.GetLastCompletedTrackingPeriod(ctx, user.ID)
+		mustNoError(t, "GetLastCompletedTrackingPeriod", err)
+		if last.ID != periods[0].ID {
+			t.Fatalf("GetLastCompletedTrackingPeriod returned %d, want %d (latest end date)",
+				last.ID, periods[0].ID)
+		}
+
+		listed, err := store.ListUserTrackingPeriods(ctx, user.ID)
+		mustNoError(t, "ListUserTrackingPeriods", err)
+		want := []int64{periods[0].ID, periods[2].ID, periods[1].ID}
+		if len(listed) != len(want) {
+			t.Fatalf("ListUserTrackingPeriods returned %d periods, want %d", len(listed), len(want))
+		}
+		for i := range want {
+			if listed[i].ID != want[i] {
+				t.Fatalf("ListUserTrackingPeriods[%d] = %d, want %d (start date descending)",
+					i, listed[i].ID, want[i])
+			}
+		}
+		if containsID(listed, id, newTrackingPeriod(t, stores, newUser(t, stores).ID).ID) {
+			t.Fatal("ListUserTrackingPeriods returned another user's period")
+		}
+	})
+
+	t.Run("LastCompletedSkipsOtherStatuses", func(t *testing.T) {
+		user := newUser(t, stores)
+		start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+		completed, err := store.CreateTrackingPeriod(ctx, data.NewTrackingPeriod(user.ID, start, 5))
+		mustNoError(t, "CreateTrackingPeriod", err)
+		mustNoError(t, "CompleteTrackingPeriod", store.CompleteTrackingPeriod(ctx, completed.ID))
+
+		// Abandoned and incomplete periods are closed but not completed.
+		for i, status := range []string{data.TrackingPeriodAbandoned, data.TrackingPeriodIncomplete} {
+			newer, err := store.CreateTrackingPeriod(
+				ctx,
+				data.NewTrackingPeriod(user.ID, start.AddDate(0, i+1, 0), 5),
+			)
+			mustNoError(t, "CreateTrackingPeriod", err)
+			_, err = store.CloseTrackingPeriod(ctx, newer.ID, status, "")
+			mustNoError(t, "CloseTrackingPeriod", err)
+
+			last, err := store.GetLastCompletedTrackingPeriod(ctx, user.ID)
+			mustNoError(t, "GetLastCompletedTrackingPeriod", err)
+			if last.ID != completed.ID {
+				t.Fatalf("GetLastCompletedTrackingPeriod returned %d, want %d rather than the newer %s period",
+					last.ID, completed.ID, status)
+			}
+		}
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		user := newUser(t, stores)
+		period := newTrackingPeriod(t, stores, user.ID)
+		period, err := store.GetTrackingPeriod(ctx, period.ID)
+		mustNoError(t, "GetTrackingPeriod", err)
+		if period.Status != data.TrackingPeriodActive {
+			t.Fatalf("Status = %q, want default %q", period.Status, data.TrackingPeriodActive)
+		}
+
+		asOf := period.EndDate
+		expired, err := store.ListExpiredTrackingPeriods(ctx, asOf)
+		mustNoError(t, "ListExpiredTrackingPeriods", err)
+		if !containsID(expired, id, period.ID) {
+			t.Fatal("ListExpiredTrackingPeriods did not return a period that ended")
+		}
+		expired, err = store.ListExpiredTrackingPeriods(ctx, asOf.Add(-time.Second))
+		mustNoError(t, "ListExpiredTrackingPeriods", err)
+		if containsID(expired, id, period.ID) {
+			t.Fatal("ListExpiredTrackingPeriods returned a period that has not ended")
+		}
+
+		period.IsCompleted = true
+		period.Status = data.TrackingPeriodAbandoned
+		period.StatusReason = "no meals logged"
+		mustNoError(t, "UpdateTrackingPeriod", store.UpdateTrackingPeriod(ctx, period))
+		got, err := store.GetTrackingPeriod(ctx, period.ID)
+		mustNoError(t, "GetTrackingPeriod", err)
+		if got.Status != period.Status || got.StatusReason != period.StatusReason {
+			t.Fatalf("UpdateTrackingPeriod stored status %q (%q), want %q (%q)",
+				got.Status, got.StatusReason, period.Status, period.StatusReason)
+		}
+		expired, err = store.ListExpiredTrackingPeriods(ctx, asOf)
+		mustNoError(t, "ListExpiredTrackingPeriods", err)
+		if containsID(expired, id, period.ID) {
+			t.Fatal("ListExpiredTrackingPeriods returned a closed period")
+		}
+
+		completed := newTrackingPeriod(t, stores, user.ID)
+		mustNoError(t, "CompleteTrackingPeriod", store.CompleteTrackingPeriod(ctx, completed.ID))
+		got, err = store.GetTrackingPeriod(ctx, completed.ID)
+		mustNoError(t, "GetTrackingPeriod", err)
+		if got.Status != data.TrackingPeriodCompleted {
+			t.Fatalf("CompleteTrackingPeriod set status %q, want %q", got.Status, data.TrackingPeriodCompleted)
+		}
+	})
+
+	t.Run("CloseTrackingPeriod", func(t *testing.T) {
+		user := newUser(t, stores)
+		period := newTrackingPeriod(t, stores, user.ID)
+
+		// An edit made after the period was read must survive the close.
+		edited := *period
+		edited.EndDate = period.StartDate.AddDate(0, 0, 7)
+		mustNoError(t, "UpdateTrackingPeriod", store.UpdateTrackingPeriod(ctx, &edited))
+
+		closed, err := store.CloseTrackingPeriod(ctx, period.ID, data.TrackingPeriodIncomplete, "day 2 missing")
+		mustNoError(t, "CloseTrackingPeriod", err)
+		if !closed {
+			t.Fatal("CloseTrackingPeriod did not close an open period")
+		}
+		closed, err = store.CloseTrackingPeriod(ctx, period.ID, data.TrackingPeriodAbandoned, "")
+		mustNoError(t, "CloseTrackingPeriod", err)
+		if closed {
+			t.Fatal("CloseTrackingPeriod closed a period twice")
+		}
+		closed, err = store.CloseTrackingPeriod(ctx, -1, data.TrackingPeriodCompleted, "")
+		mustNoError(t, "CloseTrackingPeriod", err)
+		if closed {
+			t.Fatal("CloseTrackingPeriod closed a missing period")
+		}
+
+		got, err := store.GetTrackingPeriod(ctx, period.ID)
+		mustNoError(t, "GetTrackingPeriod", err)
+		if !got.IsCompleted || got.Status != data.TrackingPeriodIncomplete || got.StatusReason != "day 2 missing" {
+			t.Fatalf("CloseTrackingPeriod stored %+v, want the first close", got)
+		}
+		if !got.EndDate.Equal(edited.EndDate) {
+			t.Fatalf("CloseTrackingPeriod overwrote the end date with %v", got.EndDate)
+		}
+	})
+
+	t.Run("UpdateAndNotFound", func(t *testing.T) {
+		user := newUser(t, stores)
+		period := newTrackingPeriod(t, stores, user.ID)
+
+		period.EndDate = period.StartDate.AddDate(0, 0, 7)
+		mustNoError(t, "UpdateTrackingPeriod", store.UpdateTrackingPeriod(ctx, period))
+		got, err := store.GetTrackingPeriod(ctx, period.ID)
+		mustNoError(t, "GetTrackingPeriod", err)
+		if !got.EndDate.Equal(period.EndDate) {
+			t.Fatalf("UpdateTrackingPeriod stored end date %v, want %v", got.EndDate, period.EndDate)
+		}
+
+		mustNoError(t, "DeleteTrackingPeriod", store.DeleteTrackingPeriod(ctx, period.ID))
+		_, err = store.GetTrackingPeriod(ctx, period.ID)
+		mustNotFound(t, "GetTrackingPeriod after DeleteTrackingPeriod", err)
+
+		_, err = store.GetTrackingPeriod(ctx, -1)
+		mustNotFound(t, "GetTrackingPeriod", err)
+		_, err = store.GetCurrentTrackingPeriod(ctx, newUser(t, stores).ID, time.Now())
+		mustNotFound(t, "GetCurrentTrackingPeriod", err)
+	})
+}
+
+func testMealEntryStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.MealEntryStore
+
+	t.Run("IdempotentCreate", func(t *testing.T) {
+		entry := newMealEntry(t, stores)
+		if entry.ID == 0 {
+			t.Fatal("CreateMealEntry did not assign an ID")
+		}
+
+		again, err := store.CreateMealEntry(ctx, &data.MealEntry{
+			UserID:           entry.UserID,
+			TrackingPeriodID: entry.TrackingPeriodID,
+			TrackingDay:      entry.TrackingDay,
+			MealType:         entry.MealType,
+			MealTime:         time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+			MealDuration:     10 * time.Minute,
+			PortionQuantity:  2,
+			PortionUnit:      data.PortionBowl,
+		})
+		mustNoError(t, "CreateMealEntry", err)
+		if again.ID != entry.ID || !again.MealTime.Equal(entry.MealTime) {
+			t.Fatalf("CreateMealEntry for an existing meal returned %+v, want existing %+v",
+				again, entry)
+		}
+
+		got, err := store.GetMealEntryByDetails(
+			ctx, entry.UserID, entry.TrackingPeriodID, entry.TrackingDay, entry.MealType,
+		)
+		mustNoError(t, "GetMealEntryByDetails", err)
+		if got.ID != entry.ID {
+			t.Fatalf("GetMealEntryByDetails returned %d, want %d", got.ID, entry.ID)
+		}
+		if !got.MealTime.Equal(entry.MealTime) ||
+			got.MealDuration != entry.MealDuration ||
+			got.PortionQuantity != entry.PortionQuantity ||
+			got.PortionUnit != entry.PortionUnit {
+			t.Fatalf("GetMealEntryByDetails returned %+v, want %+v", got, entry)
+		}
+
+		_, err = store.GetMealEntryByDetails(
+			ctx, entry.UserID, entry.TrackingPeriodID, entry.TrackingDay, data.MealDinner,
+		)
+		mustNotFound(t, "GetMealEntryByDetails", err)
+	})
+
+	t.Run("ListOrdering", func(t *testing.T) {
+		first := newMealEntry(t, stores)
+
+		create := func(day int, mealType data.MealType) *data.MealEntry {
+			entry, err := store.CreateMealEntry(ctx, &data.MealEntry{
+				UserID:           first.UserID,
+				TrackingPeriodID: first.TrackingPeriodID,
+				TrackingDay:      day,
+				MealType:         mealType,
+				MealTime:         time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+				MealDuration:     20 * time.Minute,
+				PortionQuantity:  1,
+				PortionUnit:      data.PortionPlate,
+			})
+			mustNoError(t, "CreateMealEntry", err)
+			return entry
+		}
+		dinnerTwo := create(2, data.MealDinner)
+		lunchOne := create(1, data.MealLunch)
+		breakfastTwo := create(2, data.MealBreakfast)
+
+		entries, err := store.ListUserMealEntries(ctx, first.UserID, first.TrackingPeriodID)
+		mustNoError(t, "ListUserMealEntries", err)
+		want := []int64{first.ID, lunchOne.ID, breakfastTwo.ID, dinnerTwo.ID}
+		if len(entries) != len(want) {
+			t.Fatalf("ListUserMealEntries returned %d entries, want %d", len(entries), len(want))
+		}
+		for i := range want {
+			if entries[i].ID != want[i] {
+				t.Fatalf("ListUserMealEntries[%d] = %d, want %d (tracking day, meal type order)",
+					i, entries[i].ID, want[i])
+			}
+		}
+	})
+
+	t.Run("UpdateCompleteDelete", func(t *testing.T) {
+		entry := newMealEntry(t, stores)
+
+		entry.Notes = "Felt full"
+		mustNoError(t, "UpdateMealEntry", store.UpdateMealEntry(ctx, entry))
+		mustNoError(t, "CompleteMealEntry", store.CompleteMealEntry(ctx, entry.ID))
+
+		got, err := store.GetMealEntry(ctx, entry.ID)
+		mustNoError(t, "GetMealEntry", err)
+		if got.Notes != "Felt full" || !got.IsCompleted {
+			t.Fatalf("meal entry not updated and completed: %+v", got)
+		}
+
+		mustNoError(t, "DeleteMealEntry", store.DeleteMealEntry(ctx, entry.ID))
+		_, err = store.GetMealEntry(ctx, entry.ID)
+		mustNotFound(t, "GetMealEntry after DeleteMealEntry", err)
+	})
+}
+
+func testFoodItemStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.FoodItemStore
+	id := func(item *data.FoodItem) int64 { return item.ID }
+
+	category := "Category " + unique()
+	create := func(name string) *data.FoodItem {
+		item, err := store.CreateFoodItem(ctx, &data.FoodItem{Name: name, Category: category})
+		mustNoError(t, "CreateFoodItem", err)
+		return item
+	}
+	suffix := unique()
+	zucchini := create("Zucchini " + suffix)
+	apple := create("Apple " + suffix)
+
+	got, err := store.GetFoodItem(ctx, apple.ID)
+	mustNoError(t, "GetFoodItem", err)
+	if got.Name != apple.Name || got.Category != category {
+		t.Fatalf("GetFoodItem returned %+v, want %+v", got, apple)
+	}
+
+	got, err = store.GetFoodItemByName(ctx, zucchini.Name)
+	mustNoError(t, "GetFoodItemByName", err)
+	if got.ID != zucchini.ID {
+		t.Fatalf("GetFoodItemByName returned %d, want %d", got.ID, zucchini.ID)
+	}
+	_, err = store.GetFoodItemByName(ctx, "Missing "+unique())
+	mustNotFound(t, "GetFoodItemByName", err)
+	got, err = store.GetFoodItemByName(ctx, strings.ToUpper(zucchini.Name))
+	mustNoError(t, "GetFoodItemByName", err)
+	if got.ID != zucchini.ID {
+		t.Fatalf("GetFoodItemByName in upper case returned %d, want %d", got.ID, zucchini.ID)
+	}
+	_, err = store.CreateFoodItem(ctx, &data.FoodItem{Name: strings.ToLower(zucchini.Name), Category: category})
+	if !errors.Is(err, data.ErrRecordConflict) {
+		t.Fatalf("CreateFoodItem with a name differing in case: got error %v, want %v", err, data.ErrRecordConflict)
+	}
+
+	items, err := store.ListFoodItemsByCategory(ctx, category)
+	mustNoError(t, "ListFoodItemsByCategory", err)
+	if len(items) != 2 || items[0].ID != apple.ID || items[1].ID != zucchini.ID {
+		t.Fatalf("ListFoodItemsByCategory returned %d items, want apple then zucchini", len(items))
+	}
+
+	all, err := store.ListFoodItems(ctx)
+	mustNoError(t, "ListFoodItems", err)
+	if !containsID(all, id, apple.ID) || !containsID(all, id, zucchini.ID) {
+		t.Fatal("ListFoodItems does not contain the created items")
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Name > all[i].Name {
+			t.Fatalf("ListFoodItems is not ordered by name: %q before %q", all[i-1].Name, all[i].Name)
+		}
+	}
+
+	apple.Category = "Fruit " + suffix
+	mustNoError(t, "UpdateFoodItem", store.UpdateFoodItem(ctx, apple))
+	got, err = store.GetFoodItem(ctx, apple.ID)
+	mustNoError(t, "GetFoodItem", err)
+	if got.Category != apple.Category {
+		t.Fatalf("UpdateFoodItem stored category %q, want %q", got.Category, apple.Category)
+	}
+
+	cheese, err := store.CreateFoodItem(ctx, &data.FoodItem{
+		Name:          "Cheese " + suffix,
+		Category:      category,
+		Allergens:     data.AllergenMilk,
+		FODMAPs:       data.FODMAPLactose,
+		HighHistamine: true,
+		CaloriesKcal:  402,
+		ProteinG:      25,
+		CarbohydrateG: 1.3,
+		FatG:          33,
+	})
+	mustNoError(t, "CreateFoodItem", err)
+	bread, err := store.CreateFoodItem(ctx, &data.FoodItem{
+		Name:      "Bread " + suffix,
+		Category:  category,
+		Allergens: data.AllergenWheat | data.AllergenSesame,
+		FODMAPs:   data.FODMAPFructans,
+	})
+	mustNoError(t, "CreateFoodItem", err)
+
+	got, err = store.GetFoodItem(ctx, cheese.ID)
+	mustNoError(t, "GetFoodItem", err)
+	if got.Allergens != cheese.Allergens || got.FODMAPs != cheese.FODMAPs ||
+		!got.HighHistamine || got.FatG != cheese.FatG {
+		t.Fatalf("GetFoodItem returned %+v, want %+v", got, cheese)
+	}
+
+	withMilk, err := store.ListFoodItemsWithAllergens(ctx, data.AllergenMilk)
+	mustNoError(t, "ListFoodItemsWithAllergens", err)
+	if !containsID(withMilk, id, cheese.ID) || containsID(withMilk, id, bread.ID) ||
+		containsID(withMilk, id, apple.ID) {
+		t.Fatal("ListFoodItemsWithAllergens(milk) did not return just the items with milk")
+	}
+	withEither, err := store.ListFoodItemsWithAllergens(ctx, data.AllergenMilk|data.AllergenSesame)
+	mustNoError(t, "ListFoodItemsWithAllergens", err)
+	if !containsID(withEither, id, cheese.ID) || !containsID(withEither, id, bread.ID) {
+		t.Fatal("ListFoodItemsWithAllergens(milk, sesame) did not return items with either")
+	}
+
+	fructans, err := store.ListFoodItemsWithFODMAPs(ctx, data.FODMAPFructans)
+	mustNoError(t, "ListFoodItemsWithFODMAPs", err)
+	if !containsID(fructans, id, bread.ID) || containsID(fructans, id, cheese.ID) {
+		t.Fatal("ListFoodItemsWithFODMAPs(fructans) did not return just the items with fructans")
+	}
+
+	mustNoError(t, "DeleteFoodItem", store.DeleteFoodItem(ctx, zucchini.ID))
+	_, err = store.GetFoodItem(ctx, zucchini.ID)
+	mustNotFound(t, "GetFoodItem after DeleteFoodItem", err)
+}
+
+func testMealFoodStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.MealFoodStore
+	id := func(mealFood *data.MealFood) int64 { return mealFood.ID }
+
+	entry := newMealEntry(t, stores)
+	other := newMealEntry(t, stores)
+	item, err := stores.FoodItemStore.CreateFoodItem(ctx, &data.FoodItem{
+		Name:     "Rice " + unique(),
+		Category: "Grains",
+	})
+	mustNoError(t, "CreateFoodItem", err)
+
+	var created []*data.MealFood
+	for _, mealEntryID := range []int64{entry.ID, entry.ID, other.ID} {
+		mealFood, err := store.CreateMealFood(ctx, &data.MealFood{
+			MealEntryID: mealEntryID,
+			FoodItemID:  item.ID,
+		})
+		mustNoError(t, "CreateMealFood", err)
+		created = append(created, mealFood)
+	}
+
+	mealFoods, err := store.GetMealFoodsForMeal(ctx, entry.ID)
+	mustNoError(t, "GetMealFoodsForMeal", err)
+	if len(mealFoods) != 2 || containsID(mealFoods, id, created[2].ID) {
+		t.Fatalf("GetMealFoodsForMeal returned %d meal foods, want the meal's 2", len(mealFoods))
+	}
+
+	mustNoError(t, "DeleteMealFood", store.DeleteMealFood(ctx, created[0].ID))
+	mealFoods, err = store.GetMealFoodsForMeal(ctx, entry.ID)
+	mustNoError(t, "GetMealFoodsForMeal", err)
+	if len(mealFoods) != 1 || mealFoods[0].ID != created[1].ID {
+		t.Fatalf("DeleteMealFood left %d meal foods, want only %d", len(mealFoods), created[1].ID)
+	}
+
+	mustNoError(t, "DeleteAllMealFoodsForMeal", store.DeleteAllMealFoodsForMeal(ctx, entry.ID))
+	mealFoods, err = store.GetMealFoodsForMeal(ctx, entry.ID)
+	mustNoError(t, "GetMealFoodsForMeal", err)
+	if len(mealFoods) != 0 {
+		t.Fatalf("DeleteAllMealFoodsForMeal left %d meal foods", len(mealFoods))
+	}
+
+	mealFoods, err = store.GetMealFoodsForMeal(ctx, other.ID)
+	mustNoError(t, "GetMealFoodsForMeal", err)
+	if len(mealFoods) != 1 {
+		t.Fatalf("DeleteAllMealFoodsForMeal removed another meal's foods")
+	}
+}
+
+func testCustomFoodStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.CustomFoodStore
+	id := func(food *data.CustomFood) int64 { return food.ID }
+
+	entry := newMealEntry(t, stores)
+	other := newMealEntry(t, stores)
+
+	var created []*data.CustomFood
+	for _, mealEntryID := range []int64{entry.ID, entry.ID, other.ID} {
+		food, err := store.CreateCustomFood(ctx, &data.CustomFood{
+			MealEntryID: mealEntryID,
+			Name:        "Grandma's soup",
+			Portion:     "1 cup",
+			Preparation: "Boiled",
+		})
+		mustNoError(t, "CreateCustomFood", err)
+		created = append(created, food)
+	}
+
+	foods, err := store.GetCustomFoodsForMeal(ctx, entry.ID)
+	mustNoError(t, "GetCustomFoodsForMeal", err)
+	if len(foods) != 2 || containsID(foods, id, created[2].ID) {
+		t.Fatalf("GetCustomFoodsForMeal returned %d custom foods, want the meal's 2", len(foods))
+	}
+
+	created[0].Portion = "2 cups"
+	mustNoError(t, "UpdateCustomFood", store.UpdateCustomFood(ctx, created[0]))
+	mustNoError(t, "DeleteCustomFood", store.DeleteCustomFood(ctx, created[1].ID))
+	foods, err = store.GetCustomFoodsForMeal(ctx, entry.ID)
+	mustNoError(t, "GetCustomFoodsForMeal", err)
+	if len(foods) != 1 || foods[0].ID != created[0].ID || foods[0].Portion != "2 cups" {
+		t.Fatalf("GetCustomFoodsForMeal after update and delete returned %+v", foods)
+	}
+
+	mustNoError(t, "DeleteAllCustomFoodsForMeal", store.DeleteAllCustomFoodsForMeal(ctx, entry.ID))
+	foods, err = store.GetCustomFoodsForMeal(ctx, entry.ID)
+	mustNoError(t, "GetCustomFoodsForMeal", err)
+	if len(foods) != 0 {
+		t.Fatalf("DeleteAllCustomFoodsForMeal left %d custom foods", len(foods))
+	}
+
+	foods, err = store.GetCustomFoodsForMeal(ctx, other.ID)
+	mustNoError(t, "GetCustomFoodsForMeal", err)
+	if len(foods) != 1 {
+		t.Fatalf("DeleteAllCustomFoodsForMeal removed another meal's custom foods")
+	}
+
+	t.Run("UsageAndByName", func(t *testing.T) {
+		name := "Banana bread " + unique()
+		var created []*data.CustomFood
+		for _, mealEntryID := range []int64{entry.ID, entry.ID, other.ID} {
+			food, err := store.CreateCustomFood(ctx, &data.CustomFood{MealEntryID: mealEntryID, Name: name})
+			mustNoError(t, "CreateCustomFood", err)
+			created = append(created, food)
+		}
+
+		usage, err := store.ListCustomFoodUsage(ctx)
+		mustNoError(t, "ListCustomFoodUsage", err)
+		uses := make(map[int64]int)
+		for _, row := range usage {
+			if row.Name == name {
+				uses[row.UserID] = row.Uses
+			}
+		}
+		if len(uses) != 2 || uses[entry.UserID] != 2 || uses[other.UserID] != 1 {
+			t.Fatalf("ListCustomFoodUsage counted %v uses of %q, want 2 and 1", uses, name)
+		}
+
+		foods, err := store.ListCustomFoodsByName(ctx, []string{name, "Not logged " + unique()})
+		mustNoError(t, "ListCustomFoodsByName", err)
+		if len(foods) != 3 || foods[0].ID != created[0].ID || foods[2].ID != created[2].ID {
+			t.Fatalf("ListCustomFoodsByName returned %+v, want the 3 created in order", foods)
+		}
+		foods, err = store.ListCustomFoodsByName(ctx, nil)
+		mustNoError(t, "ListCustomFoodsByName", err)
+		if len(foods) != 0 {
+			t.Fatalf("ListCustomFoodsByName without names returned %d custom foods", len(foods))
+		}
+	})
+}
+
+func testSymptomStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.SymptomStore
+
+	t.Run("UpsertByMealTypeAndOvernight", func(t *testing.T) {
+		entry := newMealEntry(t, stores)
+
+		first, err := store.CreateSymptom(ctx, &data.Symptom{
+			MealEntryID: entry.ID,
+			SymptomType: "Bloating",
+			Severity:    20,
+		})
+		mustNoError(t, "CreateSymptom", err)
+		if first.ID == 0 {
+			t.Fatal("CreateSymptom did not assign an ID")
+		}
+
+		again, err := store.CreateSymptom(ctx, &data.Symptom{
+			MealEntryID: entry.ID,
+			SymptomType: "Bloating",
+			Severity:    90,
+		})
+		mustNoError(t, "CreateSymptom", err)
+		if again.ID != first.ID || again.Severity != 90 {
+			t.Fatalf("CreateSymptom for an existing symptom returned %+v, want %d with severity 90",
+				again, first.ID)
+		}
+
+		got, err := store.GetSymptom(ctx, first.ID)
+		mustNoError(t, "GetSymptom", err)
+		if got.Severity != 90 {
+			t.Fatalf("CreateSymptom did not update stored severity: got %d, want 90", got.Severity)
+		}
+
+		overnight, err := store.CreateSymptom(ctx, &data.Symptom{
+			MealEntryID: entry.ID,
+			SymptomType: "Bloating",
+			Severity:    40,
+			IsOvernight: true,
+		})
+		mustNoError(t, "CreateSymptom", err)
+		if overnight.ID == first.ID {
+			t.Fatal("CreateSymptom merged an overnight symptom into the same-meal symptom")
+		}
+
+		symptoms, err := store.ListSymptomsForMeal(ctx, entry.ID)
+		mustNoError(t, "ListSymptomsForMeal", err)
+		if len(symptoms) != 2 {
+			t.Fatalf("ListSymptomsForMeal returned %d symptoms, want 2", len(symptoms))
+		}
+	})
+
+	t.Run("LookupUpdateDelete", func(t *testing.T) {
+		entry := newMealEntry(t, stores)
+		other := newMealEntry(t, stores)
+
+		symptom, err := store.CreateSymptom(ctx, &data.Symptom{
+			MealEntryID: entry.ID,
+			SymptomType: "Headache",
+			Severity:    30,
+		})
+		mustNoError(t, "CreateSymptom", err)
+		_, err = store.CreateSymptom(ctx, &data.Symptom{
+			MealEntryID: other.ID,
+			SymptomType: "Headache",
+			Severity:    10,
+		})
+		mustNoError(t, "CreateSymptom", err)
+
+		got, err := store.GetSymptomByTypeForMeal(ctx, entry.ID, "Headache")
+		mustNoError(t, "GetSymptomByTypeForMeal", err)
+		if got.ID != symptom.ID {
+			t.Fatalf("GetSymptomByTypeForMeal returned %d, want %d", got.ID, symptom.ID)
+		}
+		_, err = store.GetSymptomByTypeForMeal(ctx, entry.ID, "Nausea")
+		mustNotFound(t, "GetSymptomByTypeForMeal", err)
+		_, err = store.GetSymptom(ctx, -1)
+		mustNotFound(t, "GetSymptom", err)
+
+		symptom.Severity = 60
+		mustNoError(t, "UpdateSymptom", store.UpdateSymptom(ctx, symptom))
+		got, err = store.GetSymptom(ctx, symptom.ID)
+		mustNoError(t, "GetSymptom", err)
+		if got.Severity != 60 {
+			t.Fatalf("UpdateSymptom stored severity %d, want 60", got.Severity)
+		}
+
+		mustNoError(t, "DeleteSymptom", store.DeleteSymptom(ctx, symptom.ID))
+		_, err = store.GetSymptom(ctx, symptom.ID)
+		mustNotFound(t, "GetSymptom after DeleteSymptom", err)
+
+		_, err = store.CreateSymptom(ctx, &data.Symptom{
+			MealEntryID: entry.ID,
+			SymptomType: "Nausea",
+			Severity:    15,
+		})
+		mustNoError(t, "CreateSymptom", err)
+		mustNoError(t, "DeleteAllSymptomsForMeal", store.DeleteAllSymptomsForMeal(ctx, entry.ID))
+		symptoms, err := store.ListSymptomsForMeal(ctx, entry.ID)
+		mustNoError(t, "ListSymptomsForMeal", err)
+		if len(symptoms) != 0 {
+			t.Fatalf("DeleteAllSymptomsForMeal left %d symptoms", len(symptoms))
+		}
+		symptoms, err = store.ListSymptomsForMeal(ctx, other.ID)
+		mustNoError(t, "ListSymptomsForMeal", err)
+		if len(symptoms) != 1 {
+			t.Fatal("DeleteAllSymptomsForMeal removed another meal's symptoms")
+		}
+	})
+
+	t.Run("Standalone", func(t *testing.T) {
+		entry := newMealEntry(t, stores)
+		night := time.Date(2025, 3, 2, 3, 0, 0, 0, time.UTC)
+
+		late, err := store.CreateSymptom(ctx, &data.Symptom{
+			UserID:           entry.UserID,
+			TrackingPeriodID: entry.TrackingPeriodID,
+			OccurredAt:       night.Add(time.Hour),
+			SymptomType:      "Headache",
+			Severity:         5,
+		})
+		mustNoError(t, "CreateSymptom", err)
+		early, err := store.CreateSymptom(ctx, &data.Symptom{
+			UserID:           entry.UserID,
+			TrackingPeriodID: entry.TrackingPeriodID,
+			OccurredAt:       night,
+			SymptomType:      "Headache",
+			Severity:         7,
+		})
+		mustNoError(t, "CreateSymptom", err)
+		if early.ID == late.ID {
+			t.Fatal("CreateSymptom merged two standalone symptoms of the same type")
+		}
+		_, err = store.CreateSymptom(ctx, &data.Symptom{
+			MealEntryID: entry.ID,
+			SymptomType: "Headache",
+			Severity:    2,
+		})
+		mustNoError(t, "CreateSymptom", err)
+
+		symptoms, err := store.ListStandaloneSymptoms(ctx, entry.UserID, entry.TrackingPeriodID)
+		mustNoError(t, "ListStandaloneSymptoms", err)
+		if len(symptoms) != 2 || symptoms[0].ID != early.ID || symptoms[1].ID != late.ID {
+			t.Fatalf("ListStandaloneSymptoms returned %d symptoms, want %d then %d",
+				len(symptoms), early.ID, late.ID)
+		}
+		if !symptoms[0].OccurredAt.Equal(night) || !symptoms[0].IsStandalone() {
+			t.Fatalf("ListStandaloneSymptoms returned %+v", symptoms[0])
+		}
+
+		symptoms, err = store.ListStandaloneSymptoms(ctx, entry.UserID, -1)
+		mustNoError(t, "ListStandaloneSymptoms", err)
+		if len(symptoms) != 0 {
+			t.Fatalf("ListStandaloneSymptoms for another period returned %d symptoms", len(symptoms))
+		}
+	})
+}
@@ -0,0 +1,366 @@
+package datatest
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func testExportHealthRecord(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+
+	user := newUser(t, stores)
+	user.Hash = "secret-password-hash"
+	mustNoError(t, "UpdateUser", stores.UserStore.UpdateUser(ctx, user))
+
+	token, err := stores.TokenStore.CreateToken(ctx, user.ID, time.Hour, data.ScopeAuthentication)
+	mustNoError(t, "CreateToken", err)
+	_, err = stores.MedicalInformationStore.CreateMedicalInformation(ctx, &data.MedicalInformation{
+		UserID:    user.ID,
+		Height:    170,
+		Diagnosis: "IBS",
+	})
+	mustNoError(t, "CreateMedicalInformation", err)
+	_, err = stores.AllergyStore.CreateAllergy(ctx, &data.Allergy{
+		UserID:      user.ID,
+		AllergyName: "Peanuts",
+		Reaction:    "Hives, itching",
+	})
+	mustNoError(t, "CreateAllergy", err)
+
+	item, err := stores.FoodItemStore.CreateFoodItem(ctx, &data.FoodItem{
+		Name:     "Oats " + unique(),
+		Category: "Grains",
+	})
+	mustNoError(t, "CreateFoodItem", err)
+
+	period := newTrackingPeriod(t, stores, user.ID)
+	entry, err := stores.MealEntryStore.CreateMealEntry(ctx, &data.MealEntry{
+		UserID:           user.ID,
+		TrackingPeriodID: period.ID,
+		TrackingDay:      1,
+		MealType:         data.MealBreakfast,
+		MealTime:         time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC),
+		MealDuration:     15 * time.Minute,
+		PortionQuantity:  1,
+		PortionUnit:      data.PortionBowl,
+	})
+	mustNoError(t, "CreateMealEntry", err)
+	_, err = stores.MealFoodStore.CreateMealFood(ctx, &data.MealFood{
+		MealEntryID: entry.ID,
+		FoodItemID:  item.ID,
+	})
+	mustNoError(t, "CreateMealFood", err)
+	_, err = stores.SymptomStore.CreateSymptom(ctx, &data.Symptom{
+		MealEntryID: entry.ID,
+		SymptomType: "Bloating",
+		Severity:    60,
+	})
+	mustNoError(t, "CreateSymptom", err)
+
+	record, err := stores.ExportHealthRecord(ctx, user.ID)
+	mustNoError(t, "ExportHealthRecord", err)
+
+	if record.SchemaVersion != data.HealthRecordSchemaVersion {
+		t.Fatalf("SchemaVersion = %d, want %d", record.SchemaVersion, data.HealthRecordSchemaVersion)
+	}
+	if record.User.ID != user.ID || record.User.Email != user.Email {
+		t.Fatalf("exported user %+v does not match %+v", record.User, user)
+	}
+	if record.UserIntake != nil {
+		t.Fatalf("exported a user intake that was never created: %+v", record.UserIntake)
+	}
+	if record.MedicalInformation == nil || record.MedicalInformation.Diagnosis != "IBS" {
+		t.Fatalf("exported medical information %+v", record.MedicalInformation)
+	}
+	if len(record.Allergies) != 1 || len(record.TrackingPeriods) != 1 {
+		t.Fatalf(
+			"exported %d allergies and %d tracking periods, want 1 and 1",
+			len(record.Allergies), len(record.TrackingPeriods),
+		)
+	}
+	meals := record.TrackingPeriods[0].MealEntries
+	if len(meals) != 1 || len(meals[0].MealFoods) != 1 || len(meals[0].Symptoms) != 1 {
+		t.Fatalf("exported meal entries %+v", meals)
+	}
+	if len(record.FoodItems) != 1 || record.FoodItems[0].ID != item.ID {
+		t.Fatalf("exported food items %+v, want only %d", record.FoodItems, item.ID)
+	}
+
+	var document bytes.Buffer
+	mustNoError(t, "WriteJSON", record.WriteJSON(&document))
+	var decoded map[string]any
+	mustNoError(t, "decode JSON", json.Unmarshal(document.Bytes(), &decoded))
+	if _, ok := decoded["user"].(map[string]any)["hash"]; ok {
+		t.Fatal("WriteJSON included the user's password hash")
+	}
+	mustNotContainSecrets(t, "WriteJSON", document.String(), user.Hash, token)
+
+	var archive bytes.Buffer
+	mustNoError(t, "WriteCSVZip", record.WriteCSVZip(&archive))
+	reader, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	mustNoError(t, "open zip", err)
+
+	tables := make(map[string][][]string)
+	for _, file := range reader.File {
+		contents, err := file.Open()
+		mustNoError(t, "open "+file.Name, err)
+		rows, err := csv.NewReader(contents).ReadAll()
+		mustNoError(t, "read "+file.Name, err)
+		contents.Close()
+		tables[file.Name] = rows
+
+		for _, row := range rows {
+			mustNotContainSecrets(t, file.Name, strings.Join(row, ","), user.Hash, token)
+		}
+	}
+	for name, want := range map[string]int{
+		"export.csv":               1,
+		"users.csv":                1,
+		"user_intakes.csv":         0,
+		"medical_informations.csv": 1,
+		"allergies.csv":            1,
+		"tracking_periods.csv":     1,
+		"meal_entries.csv":         1,
+		"meal_foods.csv":           1,
+		"symptoms.csv":             1,
+		"food_items.csv":           1,
+	} {
+		rows, ok := tables[name]
+		if !ok {
+			t.Fatalf("WriteCSVZip did not write %s", name)
+		}
+		if len(rows)-1 != want {
+			t.Fatalf("%s has %d rows, want %d", name, len(rows)-1, want)
+		}
+	}
+
+	_, err = stores.ExportHealthRecord(ctx, -1)
+	mustNotFound(t, "ExportHealthRecord for a missing user", err)
+}
+
+func mustNotContainSecrets(t *testing.T, what string, text string, hash string, token *data.Token) {
+	t.Helper()
+
+	for _, secret := range []string{hash, token.Plaintext, string(token.Hash)} {
+		if strings.Contains(text, secret) {
+			t.Fatalf("%s leaked a credential", what)
+		}
+	}
+}
+
+func testImportHealthRecord(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+
+	user := newUser(t, stores)
+	_, err := stores.UserIntakeStore.CreateUserIntake(ctx, &data.UserIntake{
+		UserID:   user.ID,
+		FormData: `{"step": 3}`,
+	})
+	mustNoError(t, "CreateUserIntake", err)
+	medication, err := stores.MedicationStore.CreateMedication(ctx, &data.Medication{
+		UserID: user.ID,
+		Name:   "Omeprazole",
+		Dosage: "20mg",
+	})
+	mustNoError(t, "CreateMedication", err)
+	schedule, err := stores.MedicationScheduleStore.CreateMedicationSchedule(ctx, &data.MedicationSchedule{
+		UserID:       user.ID,
+		MedicationID: medication.ID,
+		TimesOfDay:   "07:00",
+		EveryDays:    1,
+		StartDate:    time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+	})
+	mustNoError(t, "CreateMedicationSchedule", err)
+	_, err = stores.DoseLogStore.CreateDoseLog(ctx, &data.DoseLog{
+		UserID:       user.ID,
+		MedicationID: medication.ID,
+		ScheduledAt:  schedule.StartDate.Add(7 * time.Hour),
+		Status:       data.DoseSkipped,
+	})
+	mustNoError(t, "CreateDoseLog", err)
+	item, err := stores.FoodItemStore.CreateFoodItem(ctx, &data.FoodItem{
+		Name:     "Rice " + unique(),
+		Category: "Grains",
+	})
+	mustNoError(t, "CreateFoodItem", err)
+
+	completed := newTrackingPeriod(t, stores, user.ID)
+	mustNoError(t, "CompleteTrackingPeriod", stores.TrackingPeriodStore.CompleteTrackingPeriod(ctx, completed.ID))
+	active := newTrackingPeriod(t, stores, user.ID)
+	entry, err := stores.MealEntryStore.CreateMealEntry(ctx, &data.MealEntry{
+		UserID:           user.ID,
+		TrackingPeriodID: active.ID,
+		TrackingDay:      2,
+		MealType:         data.MealDinner,
+		MealTime:         time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC),
+		MealDuration:     30 * time.Minute,
+		PortionQuantity:  1,
+		PortionUnit:      data.PortionPlate,
+	})
+	mustNoError(t, "CreateMealEntry", err)
+	_, err = stores.MealFoodStore.CreateMealFood(ctx, &data.MealFood{
+		MealEntryID: entry.ID,
+		FoodItemID:  item.ID,
+	})
+	mustNoError(t, "CreateMealFood", err)
+	_, err = stores.CustomFoodStore.CreateCustomFood(ctx, &data.CustomFood{
+		MealEntryID: entry.ID,
+		Name:        "Miso soup",
+		Portion:     "1 cup",
+		Preparation: "Boiled",
+	})
+	mustNoError(t, "CreateCustomFood", err)
+	symptom, err := stores.SymptomStore.CreateSymptom(ctx, &data.Symptom{
+		UserID:           user.ID,
+		TrackingPeriodID: active.ID,
+		OccurredAt:       entry.MealTime.Add(8 * time.Hour),
+		SymptomType:      "Headache",
+		Severity:         5,
+	})
+	mustNoError(t, "CreateSymptom", err)
+	_, err = stores.SymptomAttributionStore.CreateSymptomAttribution(ctx, &data.SymptomAttribution{
+		SymptomID:   symptom.ID,
+		MealEntryID: entry.ID,
+		Lag:         8 * time.Hour,
+	})
+	mustNoError(t, "CreateSymptomAttribution", err)
+
+	plan := newEliminationPlan(t, stores, user.ID, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC))
+	plan.TrackingPeriodID = completed.ID
+	mustNoError(t, "UpdateEliminationPlan", stores.EliminationPlanStore.UpdateEliminationPlan(ctx, plan))
+	_, err = stores.EliminationRestrictionStore.CreateEliminationRestriction(ctx, &data.EliminationRestriction{
+		PlanID:     plan.ID,
+		FoodItemID: item.ID,
+	})
+	mustNoError(t, "CreateEliminationRestriction", err)
+
+	exported, err := stores.ExportHealthRecord(ctx, user.ID)
+	mustNoError(t, "ExportHealthRecord", err)
+	var document bytes.Buffer
+	mustNoError(t, "WriteJSON", exported.WriteJSON(&document))
+
+	record, err := data.ReadHealthRecord(bytes.NewReader(document.Bytes()))
+	mustNoError(t, "ReadHealthRecord", err)
+
+	t.Run("Conflict", func(t *testing.T) {
+		_, err := stores.ImportHealthRecord(ctx, record)
+		var conflict *data.ImportConflictError
+		if !errors.As(err, &conflict) || !errors.Is(err, data.ErrRecordConflict) {
+			t.Fatalf("ImportHealthRecord: got error %v, want an ImportConflictError", err)
+		}
+		if len(conflict.Fields) != 3 {
+			t.Fatalf("ImportConflictError fields = %v, want user_name, email and phone_number", conflict.Fields)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		id := unique()
+		invalid := *record
+		invalid.User.UserName = "imp_" + id
+		invalid.User.Email = "not an email"
+		invalid.User.PhoneNumber = "2" + id
+		invalid.TrackingPeriods = append(slices.Clone(record.TrackingPeriods), &data.HealthRecordTrackingPeriod{})
+		invalid.DietarySupplements = []*data.DietarySupplement{{Name: "Zinc", Dose: data.Dose{DoseUnit: "foo"}}}
+
+		_, err := stores.ImportHealthRecord(ctx, &invalid)
+		if !errors.Is(err, data.ErrInvalidHealthRecord) ||
+			!strings.Contains(err.Error(), "email") || !strings.Contains(err.Error(), "tracking_periods") ||
+			!strings.Contains(err.Error(), "dietary_supplements[0].dose_unit") {
+			t.Fatalf("ImportHealthRecord: got error %v, want %v for the email, tracking periods and dose",
+				err, data.ErrInvalidHealthRecord)
+		}
+		_, err = stores.UserStore.GetByUserName(ctx, invalid.User.UserName)
+		mustNotFound(t, "GetByUserName after a rejected import", err)
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		id := unique()
+		record.User.UserName = "imp_" + id
+		record.User.Email = "import" + id + "@example.com"
+		record.User.PhoneNumber = "2" + id
+
+		report, err := stores.ImportHealthRecord(ctx, record)
+		mustNoError(t, "ImportHealthRecord", err)
+		if report.UserID == user.ID || report.CreatedFoodItems != 0 || report.SkippedMealFoods != 0 {
+			t.Fatalf("ImportHealthRecord reported %+v", report)
+		}
+
+		imported, err := stores.ExportHealthRecord(ctx, report.UserID)
+		mustNoError(t, "ExportHealthRecord", err)
+		if imported.User.Email != record.User.Email || imported.UserIntake == nil {
+			t.Fatalf("imported user %+v with intake %+v", imported.User, imported.UserIntake)
+		}
+		if len(imported.Medications) != 1 || imported.Medications[0].Dosage != "20mg" {
+			t.Fatalf("imported medications %+v", imported.Medications)
+		}
+		if schedule := imported.Medications[0].Schedule; schedule == nil ||
+			schedule.MedicationID != imported.Medications[0].ID || schedule.TimesOfDay != "07:00" {
+			t.Fatalf("imported medication schedule %+v", schedule)
+		}
+		if logs := imported.Medications[0].DoseLogs; len(logs) != 1 ||
+			logs[0].MedicationID != imported.Medications[0].ID || logs[0].Status != data.DoseSkipped {
+			t.Fatalf("imported dose logs %+v", logs)
+		}
+		if len(imported.TrackingPeriods) != 2 {
+			t.Fatalf("imported %d tracking periods, want 2", len(imported.TrackingPeriods))
+		}
+
+		current, err := stores.TrackingPeriodStore.GetCurrentTrackingPeriod(ctx, report.UserID, active.StartDate)
+		mustNoError(t, "GetCurrentTrackingPeriod", err)
+		meals, err := stores.MealEntryStore.ListUserMealEntries(ctx, report.UserID, current.ID)
+		mustNoError(t, "ListUserMealEntries", err)
+		if len(meals) != 1 || meals[0].ID == entry.ID {
+			t.Fatalf("imported meal entries %+v", meals)
+		}
+		mealFoods, err := stores.MealFoodStore.GetMealFoodsForMeal(ctx, meals[0].ID)
+		mustNoError(t, "GetMealFoodsForMeal", err)
+		if len(mealFoods) != 1 || mealFoods[0].FoodItemID != item.ID {
+			t.Fatalf("imported meal foods %+v, want a link to food item %d", mealFoods, item.ID)
+		}
+		customFoods, err := stores.CustomFoodStore.GetCustomFoodsForMeal(ctx, meals[0].ID)
+		mustNoError(t, "GetCustomFoodsForMeal", err)
+		if len(customFoods) != 1 || customFoods[0].Name != "Miso soup" {
+			t.Fatalf("imported custom foods %+v", customFoods)
+		}
+		symptoms, err := stores.SymptomStore.ListStandaloneSymptoms(ctx, report.UserID, current.ID)
+		mustNoError(t, "ListStandaloneSymptoms", err)
+		if len(symptoms) != 1 || symptoms[0].ID == symptom.ID ||
+			!symptoms[0].OccurredAt.Equal(symptom.OccurredAt) {
+			t.Fatalf("imported standalone symptoms %+v", symptoms)
+		}
+		attributions, err := stores.SymptomAttributionStore.ListAttributionsForSymptom(ctx, symptoms[0].ID)
+		mustNoError(t, "ListAttributionsForSymptom", err)
+		if len(attributions) != 1 || attributions[0].MealEntryID != meals[0].ID {
+			t.Fatalf("imported attributions %+v, want a link to meal entry %d", attributions, meals[0].ID)
+		}
+
+		if len(imported.EliminationPlans) != 1 {
+			t.Fatalf("imported %d elimination plans, want 1", len(imported.EliminationPlans))
+		}
+		importedPlan := imported.EliminationPlans[0]
+		if importedPlan.ID == plan.ID || importedPlan.TrackingPeriodID == completed.ID ||
+			importedPlan.TrackingPeriodID == 0 {
+			t.Fatalf("imported elimination plan %+v was not remapped", importedPlan.EliminationPlan)
+		}
+		if len(importedPlan.Restrictions) != 1 || importedPlan.Restrictions[0].FoodItemID != item.ID {
+			t.Fatalf("imported restrictions %+v", importedPlan.Restrictions)
+		}
+	})
+
+	t.Run("SchemaVersion", func(t *testing.T) {
+		_, err := data.ReadHealthRecord(strings.NewReader(`{"schema_version": 999}`))
+		if !errors.Is(err, data.ErrUnsupportedSchemaVersion) {
+			t.Fatalf("ReadHealthRecord: got error %v, want %v", err, data.ErrUnsupportedSchemaVersion)
+		}
+	})
+}
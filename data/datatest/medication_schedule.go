@@ -0,0 +1,141 @@
+package datatest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func newScheduledMedication(t *testing.T, stores *data.Stores, userID int64) (*data.Medication, *data.MedicationSchedule) {
+	t.Helper()
+
+	ctx := context.Background()
+	medication, err := stores.MedicationStore.CreateMedication(ctx, &data.Medication{
+		UserID:  userID,
+		Name:    "Metformin",
+		Dosage:  "500 mg twice daily",
+		Current: true,
+	})
+	mustNoError(t, "CreateMedication", err)
+	medication.Dose, err = data.ParseDosage(medication.Dosage)
+	mustNoError(t, "ParseDosage", err)
+
+	schedule, err := data.NewMedicationSchedule(medication, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	mustNoError(t, "NewMedicationSchedule", err)
+	schedule, err = stores.MedicationScheduleStore.CreateMedicationSchedule(ctx, schedule)
+	mustNoError(t, "CreateMedicationSchedule", err)
+	return medication, schedule
+}
+
+func testMedicationScheduleStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.MedicationScheduleStore
+
+	user := newUser(t, stores)
+	medication, schedule := newScheduledMedication(t, stores, user.ID)
+	if schedule.ID == 0 || schedule.TimesOfDay != "08:00,20:00" || schedule.EveryDays != 1 {
+		t.Fatalf("CreateMedicationSchedule returned %+v, want doses at 08:00 and 20:00 every day", schedule)
+	}
+
+	_, err := store.CreateMedicationSchedule(ctx, &data.MedicationSchedule{
+		UserID:       user.ID,
+		MedicationID: medication.ID,
+		TimesOfDay:   "09:00",
+		EveryDays:    1,
+		StartDate:    schedule.StartDate,
+	})
+	if !errors.Is(err, data.ErrRecordConflict) {
+		t.Fatalf("CreateMedicationSchedule twice: got error %v, want %v", err, data.ErrRecordConflict)
+	}
+
+	got, err := store.GetScheduleForMedication(ctx, medication.ID)
+	mustNoError(t, "GetScheduleForMedication", err)
+	if got.ID != schedule.ID || !got.StartDate.Equal(schedule.StartDate) {
+		t.Fatalf("GetScheduleForMedication returned %+v, want %+v", got, schedule)
+	}
+
+	schedule.TimesOfDay = "07:30"
+	mustNoError(t, "UpdateMedicationSchedule", store.UpdateMedicationSchedule(ctx, schedule))
+	got, err = store.GetMedicationSchedule(ctx, schedule.ID)
+	mustNoError(t, "GetMedicationSchedule", err)
+	if got.TimesOfDay != "07:30" {
+		t.Fatalf("UpdateMedicationSchedule did not persist times of day: %q", got.TimesOfDay)
+	}
+
+	schedules, err := store.ListUserMedicationSchedules(ctx, user.ID)
+	mustNoError(t, "ListUserMedicationSchedules", err)
+	if len(schedules) != 1 || schedules[0].ID != schedule.ID {
+		t.Fatalf("ListUserMedicationSchedules returned %d schedules, want 1", len(schedules))
+	}
+
+	mustNoError(t, "DeleteMedicationSchedule", store.DeleteMedicationSchedule(ctx, schedule.ID))
+	_, err = store.GetScheduleForMedication(ctx, medication.ID)
+	mustNotFound(t, "GetScheduleForMedication after DeleteMedicationSchedule", err)
+}
+
+func testDoseLogStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.DoseLogStore
+
+	user := newUser(t, stores)
+	medication, schedule := newScheduledMedication(t, stores, user.ID)
+	due := schedule.DosesBetween(schedule.StartDate, schedule.StartDate.AddDate(0, 0, 1))
+	if len(due) != 2 || due[0].Hour() != 8 || due[1].Hour() != 20 {
+		t.Fatalf("DosesBetween returned %v, want 08:00 and 20:00", due)
+	}
+
+	logDose := func(scheduledAt, takenAt time.Time) *data.DoseLog {
+		t.Helper()
+
+		log, err := store.CreateDoseLog(ctx, &data.DoseLog{
+			UserID:       user.ID,
+			MedicationID: medication.ID,
+			ScheduledAt:  scheduledAt,
+			TakenAt:      takenAt,
+			Status:       data.ClassifyDose(scheduledAt, takenAt),
+		})
+		mustNoError(t, "CreateDoseLog", err)
+		return log
+	}
+	evening := logDose(due[1], time.Time{})
+	late := logDose(due[0], due[0].Add(2*time.Hour))
+	extra := logDose(time.Time{}, due[1].AddDate(0, 0, 1))
+	if late.Status != data.DoseLate || evening.Status != data.DoseSkipped || extra.Status != data.DoseTaken {
+		t.Fatalf("ClassifyDose gave %q, %q and %q, want late, skipped and taken",
+			late.Status, evening.Status, extra.Status)
+	}
+
+	logs, err := store.ListDoseLogsForMedication(ctx, medication.ID)
+	mustNoError(t, "ListDoseLogsForMedication", err)
+	if len(logs) != 3 || logs[0].ID != late.ID || logs[1].ID != evening.ID || logs[2].ID != extra.ID {
+		t.Fatalf("ListDoseLogsForMedication returned %d logs, want 3 in time order", len(logs))
+	}
+
+	logs, err = store.ListDoseLogsBetween(ctx, medication.ID, due[1], due[1].AddDate(0, 0, 2))
+	mustNoError(t, "ListDoseLogsBetween", err)
+	if len(logs) != 2 || logs[0].ID != evening.ID || logs[1].ID != extra.ID {
+		t.Fatalf("ListDoseLogsBetween returned %d logs, want the evening and unscheduled doses", len(logs))
+	}
+
+	evening.TakenAt, evening.Status = due[1].Add(10*time.Minute), data.DoseTaken
+	mustNoError(t, "UpdateDoseLog", store.UpdateDoseLog(ctx, evening))
+	got, err := store.GetDoseLog(ctx, evening.ID)
+	mustNoError(t, "GetDoseLog", err)
+	if got.Status != data.DoseTaken || !got.TakenAt.Equal(evening.TakenAt) {
+		t.Fatalf("UpdateDoseLog did not persist the dose: %+v", got)
+	}
+
+	mustNoError(t, "DeleteDoseLog", store.DeleteDoseLog(ctx, extra.ID))
+	_, err = store.GetDoseLog(ctx, extra.ID)
+	mustNotFound(t, "GetDoseLog after DeleteDoseLog", err)
+
+	mustNoError(t, "DeleteAllDoseLogsForMedication", store.DeleteAllDoseLogsForMedication(ctx, medication.ID))
+	logs, err = store.ListUserDoseLogs(ctx, user.ID)
+	mustNoError(t, "ListUserDoseLogs", err)
+	if len(logs) != 0 {
+		t.Fatalf("DeleteAllDoseLogsForMedication left %d logs behind", len(logs))
+	}
+}
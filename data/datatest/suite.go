@@ -0,0 +1,110 @@
+// Package datatest provides a conformance suite for implementations of the
+// store interfaces in package data. Every backend wired into data.Stores is
+// expected to pass RunStoreSuite so that services behave identically on top
+// of any of them.
+package datatest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// RunStoreSuite exercises every store in stores. The suite only relies on
+// records it creates itself, so it can run against a database that already
+// holds data, but it does not clean up after itself.
+func RunStoreSuite(t *testing.T, stores *data.Stores) {
+	t.Helper()
+
+	t.Run("UserStore", func(t *testing.T) { testUserStore(t, stores) })
+	t.Run("TokenStore", func(t *testing.T) { testTokenStore(t, stores) })
+	t.Run("AllergyStore", func(t *testing.T) { testAllergyStore(t, stores) })
+	t.Run("CaregiverStore", func(t *testing.T) { testCaregiverStore(t, stores) })
+	t.Run("DietarySupplementStore", func(t *testing.T) { testDietarySupplementStore(t, stores) })
+	t.Run("EmergencyContactStore", func(t *testing.T) { testEmergencyContactStore(t, stores) })
+	t.Run("FrequentFoodStore", func(t *testing.T) { testFrequentFoodStore(t, stores) })
+	t.Run("MedicalEventStore", func(t *testing.T) { testMedicalEventStore(t, stores) })
+	t.Run("MedicalInformationStore", func(t *testing.T) { testMedicalInformationStore(t, stores) })
+	t.Run("MedicationStore", func(t *testing.T) { testMedicationStore(t, stores) })
+	t.Run("BackfillDosages", func(t *testing.T) { testBackfillDosages(t, stores) })
+	t.Run("MedicationScheduleStore", func(t *testing.T) { testMedicationScheduleStore(t, stores) })
+	t.Run("DoseLogStore", func(t *testing.T) { testDoseLogStore(t, stores) })
+	t.Run("UserIntakeStore", func(t *testing.T) { testUserIntakeStore(t, stores) })
+	t.Run("TrackingPeriodStore", func(t *testing.T) { testTrackingPeriodStore(t, stores) })
+	t.Run("MealEntryStore", func(t *testing.T) { testMealEntryStore(t, stores) })
+	t.Run("FoodItemStore", func(t *testing.T) { testFoodItemStore(t, stores) })
+	t.Run("ImportFoodCatalog", func(t *testing.T) { testImportFoodCatalog(t, stores) })
+	t.Run("SearchFoods", func(t *testing.T) { testSearchFoods(t, stores) })
+	t.Run("MealFoodStore", func(t *testing.T) { testMealFoodStore(t, stores) })
+	t.Run("CustomFoodStore", func(t *testing.T) { testCustomFoodStore(t, stores) })
+	t.Run("SymptomStore", func(t *testing.T) { testSymptomStore(t, stores) })
+	t.Run("SymptomTypeStore", func(t *testing.T) { testSymptomTypeStore(t, stores) })
+	t.Run("SymptomAttributionStore", func(t *testing.T) { testSymptomAttributionStore(t, stores) })
+	t.Run("EliminationPlanStore", func(t *testing.T) { testEliminationPlanStore(t, stores) })
+	t.Run("EliminationRestrictionStore", func(t *testing.T) {
+		testEliminationRestrictionStore(t, stores)
+	})
+	t.Run("ReintroductionStepStore", func(t *testing.T) { testReintroductionStepStore(t, stores) })
+	t.Run("WithTx", func(t *testing.T) { testWithTx(t, stores) })
+	t.Run("EraseUser", func(t *testing.T) { testEraseUser(t, stores) })
+	t.Run("ExportHealthRecord", func(t *testing.T) { testExportHealthRecord(t, stores) })
+	t.Run("ImportHealthRecord", func(t *testing.T) { testImportHealthRecord(t, stores) })
+}
+
+var sequence atomic.Int64
+
+// unique returns a value that has not been handed out before, even across
+// runs against the same database.
+func unique() string {
+	return fmt.Sprintf("%d%04d", time.Now().UnixNano()%1e7, sequence.Add(1)%1e4)
+}
+
+// newUser creates a user with unique user name, email and phone number.
+func newUser(t *testing.T, stores *data.Stores) *data.User {
+	t.Helper()
+
+	ctx := context.Background()
+	id := unique()
+	user, err := stores.UserStore.CreateUser(ctx, &data.User{
+		UserName:    "user_" + id,
+		FirstName:   "Test",
+		LastName:    "User",
+		Email:       "user" + id + "@example.com",
+		PhoneNumber: "1" + id,
+		Hash:        "hash",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return user
+}
+
+func mustNotFound(t *testing.T, what string, err error) {
+	t.Helper()
+
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		t.Fatalf("%s: got error %v, want %v", what, err, data.ErrRecordNotFound)
+	}
+}
+
+func mustNoError(t *testing.T, what string, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("%s: %v", what, err)
+	}
+}
+
+func containsID[T any](rows []*T, id func(*T) int64, want int64) bool {
+	for _, row := range rows {
+		if id(row) == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package datatest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func testSymptomAttributionStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.SymptomAttributionStore
+
+	entry := newMealEntry(t, stores)
+	other := newMealEntry(t, stores)
+	symptom, err := stores.SymptomStore.CreateSymptom(ctx, &data.Symptom{
+		UserID:           entry.UserID,
+		TrackingPeriodID: entry.TrackingPeriodID,
+		OccurredAt:       entry.MealTime.Add(2 * time.Hour),
+		SymptomType:      "Bloating",
+		Severity:         4,
+	})
+	mustNoError(t, "CreateSymptom", err)
+
+	attribution, err := store.CreateSymptomAttribution(ctx, &data.SymptomAttribution{
+		SymptomID:   symptom.ID,
+		MealEntryID: entry.ID,
+		Lag:         2 * time.Hour,
+	})
+	mustNoError(t, "CreateSymptomAttribution", err)
+	if attribution.ID == 0 {
+		t.Fatal("CreateSymptomAttribution did not assign an ID")
+	}
+	_, err = store.CreateSymptomAttribution(ctx, &data.SymptomAttribution{
+		SymptomID:   symptom.ID,
+		MealEntryID: other.ID,
+		Lag:         time.Hour,
+	})
+	mustNoError(t, "CreateSymptomAttribution", err)
+
+	t.Run("List", func(t *testing.T) {
+		attributions, err := store.ListAttributionsForSymptom(ctx, symptom.ID)
+		mustNoError(t, "ListAttributionsForSymptom", err)
+		if len(attributions) != 2 || attributions[0].ID != attribution.ID ||
+			attributions[0].Lag != 2*time.Hour {
+			t.Fatalf("ListAttributionsForSymptom returned %d attributions, want 2 starting with %+v",
+				len(attributions), attribution)
+		}
+
+		attributions, err = store.ListAttributionsForMeal(ctx, entry.ID)
+		mustNoError(t, "ListAttributionsForMeal", err)
+		if len(attributions) != 1 || attributions[0].SymptomID != symptom.ID {
+			t.Fatalf("ListAttributionsForMeal returned %d attributions, want the symptom's", len(attributions))
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		_, err := store.CreateSymptomAttribution(ctx, &data.SymptomAttribution{
+			SymptomID:   symptom.ID,
+			MealEntryID: entry.ID,
+			Lag:         2 * time.Hour,
+		})
+		if !errors.Is(err, data.ErrRecordConflict) {
+			t.Fatalf("CreateSymptomAttribution twice: got error %v, want %v", err, data.ErrRecordConflict)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		mustNoError(t, "DeleteAllAttributionsForMeal", store.DeleteAllAttributionsForMeal(ctx, entry.ID))
+		attributions, err := store.ListAttributionsForSymptom(ctx, symptom.ID)
+		mustNoError(t, "ListAttributionsForSymptom", err)
+		if len(attributions) != 1 || attributions[0].MealEntryID != other.ID {
+			t.Fatalf("DeleteAllAttributionsForMeal left %d attributions, want the other meal's", len(attributions))
+		}
+
+		mustNoError(t, "DeleteAllAttributionsForSymptom", store.DeleteAllAttributionsForSymptom(ctx, symptom.ID))
+		attributions, err = store.ListAttributionsForMeal(ctx, other.ID)
+		mustNoError(t, "ListAttributionsForMeal", err)
+		if len(attributions) != 0 {
+			t.Fatalf("DeleteAllAttributionsForSymptom left %d attributions", len(attributions))
+		}
+	})
+}
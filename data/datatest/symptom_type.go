@@ -0,0 +1,142 @@
+package datatest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func testSymptomTypeStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.SymptomTypeStore
+	suffix := unique()
+
+	before, err := store.CountSymptomTypesByBodySystem(ctx)
+	mustNoError(t, "CountSymptomTypesByBodySystem", err)
+
+	rash, err := store.CreateSymptomType(ctx, &data.SymptomType{
+		Name:           "Rash " + suffix,
+		BodySystem:     data.BodySystemSkin,
+		SeverityMin:    0,
+		SeverityMax:    10,
+		SeverityLabels: "0=none,1=mild,7=severe",
+	})
+	mustNoError(t, "CreateSymptomType", err)
+	stool, err := store.CreateSymptomType(ctx, &data.SymptomType{
+		Name:        "Stool form " + suffix,
+		BodySystem:  data.BodySystemDigestive,
+		SeverityMin: 1,
+		SeverityMax: 7,
+	})
+	mustNoError(t, "CreateSymptomType", err)
+
+	t.Run("Lookup", func(t *testing.T) {
+		got, err := store.GetSymptomTypeByName(ctx, "RASH "+suffix)
+		mustNoError(t, "GetSymptomTypeByName", err)
+		if got.ID != rash.ID || got.SeverityLabels != rash.SeverityLabels {
+			t.Fatalf("GetSymptomTypeByName returned %+v, want %+v", got, rash)
+		}
+		_, err = store.GetSymptomTypeByName(ctx, "Unknown "+suffix)
+		mustNotFound(t, "GetSymptomTypeByName", err)
+		_, err = store.GetSymptomType(ctx, -1)
+		mustNotFound(t, "GetSymptomType", err)
+
+		_, err = store.CreateSymptomType(ctx, &data.SymptomType{
+			Name:        "rash " + suffix,
+			BodySystem:  data.BodySystemSkin,
+			SeverityMax: 10,
+		})
+		if !errors.Is(err, data.ErrRecordConflict) {
+			t.Fatalf("CreateSymptomType with a taken name: got error %v, want %v", err, data.ErrRecordConflict)
+		}
+	})
+
+	t.Run("ByBodySystem", func(t *testing.T) {
+		id := func(symptomType *data.SymptomType) int64 { return symptomType.ID }
+		skin, err := store.ListSymptomTypesByBodySystem(ctx, data.BodySystemSkin)
+		mustNoError(t, "ListSymptomTypesByBodySystem", err)
+		if !containsID(skin, id, rash.ID) || containsID(skin, id, stool.ID) {
+			t.Fatalf("ListSymptomTypesByBodySystem(skin) returned %d types, want the rash and not the stool", len(skin))
+		}
+
+		after, err := store.CountSymptomTypesByBodySystem(ctx)
+		mustNoError(t, "CountSymptomTypesByBodySystem", err)
+		if after[data.BodySystemSkin] != before[data.BodySystemSkin]+1 ||
+			after[data.BodySystemDigestive] != before[data.BodySystemDigestive]+1 {
+			t.Fatalf("CountSymptomTypesByBodySystem went from %v to %v", before, after)
+		}
+	})
+
+	t.Run("Summary", func(t *testing.T) {
+		entry := newMealEntry(t, stores)
+		for _, symptom := range []*data.Symptom{
+			{MealEntryID: entry.ID, SymptomType: rash.Name, Severity: 5},
+			{MealEntryID: entry.ID, SymptomType: rash.Name, Severity: 10, IsOvernight: true},
+			{MealEntryID: entry.ID, SymptomType: "stool FORM " + suffix, Severity: 4},
+			{MealEntryID: entry.ID, SymptomType: "Uncatalogued " + suffix, Severity: 3},
+			{
+				UserID:           entry.UserID,
+				TrackingPeriodID: entry.TrackingPeriodID,
+				OccurredAt:       entry.MealTime.Add(time.Hour),
+				SymptomType:      rash.Name,
+				Severity:         0,
+			},
+		} {
+			_, err := stores.SymptomStore.CreateSymptom(ctx, symptom)
+			mustNoError(t, "CreateSymptom", err)
+		}
+
+		summaries, err := stores.SymptomStore.SummarizeSymptomsByBodySystem(
+			ctx,
+			entry.UserID,
+			entry.TrackingPeriodID,
+		)
+		mustNoError(t, "SummarizeSymptomsByBodySystem", err)
+		if len(summaries) != 2 ||
+			summaries[0].BodySystem != data.BodySystemDigestive ||
+			summaries[1].BodySystem != data.BodySystemSkin {
+			t.Fatalf("SummarizeSymptomsByBodySystem returned %+v, want digestive and skin", summaries)
+		}
+		digestive, skin := summaries[0], summaries[1]
+		if digestive.Symptoms != 1 || !near(digestive.MeanSeverity, 0.5) {
+			t.Fatalf("digestive summary = %+v, want 1 symptom at 0.5", digestive)
+		}
+		if skin.Symptoms != 3 || skin.SymptomTypes != 1 ||
+			!near(skin.MeanSeverity, 0.5) || !near(skin.MaxSeverity, 1) {
+			t.Fatalf("skin summary = %+v, want 3 symptoms of 1 type at 0.5 and up to 1", skin)
+		}
+
+		summaries, err = stores.SymptomStore.SummarizeSymptomsByBodySystem(ctx, entry.UserID, -1)
+		mustNoError(t, "SummarizeSymptomsByBodySystem", err)
+		if len(summaries) != 0 {
+			t.Fatalf("SummarizeSymptomsByBodySystem for another period returned %+v", summaries)
+		}
+	})
+
+	t.Run("UpdateDelete", func(t *testing.T) {
+		stool.SeverityLabels = "1=hard,4=normal,7=watery"
+		mustNoError(t, "UpdateSymptomType", store.UpdateSymptomType(ctx, stool))
+		got, err := store.GetSymptomType(ctx, stool.ID)
+		mustNoError(t, "GetSymptomType", err)
+		if got.SeverityLabels != stool.SeverityLabels {
+			t.Fatalf("UpdateSymptomType stored labels %q", got.SeverityLabels)
+		}
+
+		stool.Name = rash.Name
+		if err := store.UpdateSymptomType(ctx, stool); !errors.Is(err, data.ErrRecordConflict) {
+			t.Fatalf("UpdateSymptomType to a taken name: got error %v, want %v", err, data.ErrRecordConflict)
+		}
+
+		mustNoError(t, "DeleteSymptomType", store.DeleteSymptomType(ctx, stool.ID))
+		_, err = store.GetSymptomType(ctx, stool.ID)
+		mustNotFound(t, "GetSymptomType after DeleteSymptomType", err)
+	})
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
@@ -0,0 +1,82 @@
+package datatest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func testWithTx(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+
+	// createIntake creates a user and one of their allergies through tx.
+	createIntake := func(tx *data.Stores) (*data.User, *data.Allergy) {
+		user := newUser(t, tx)
+		allergy, err := tx.AllergyStore.CreateAllergy(ctx, &data.Allergy{
+			UserID:      user.ID,
+			AllergyName: "Shellfish",
+			Reaction:    "Swelling",
+		})
+		mustNoError(t, "CreateAllergy", err)
+		return user, allergy
+	}
+
+	t.Run("Commit", func(t *testing.T) {
+		var user *data.User
+		var allergy *data.Allergy
+		err := stores.WithTx(ctx, func(tx *data.Stores) error {
+			user, allergy = createIntake(tx)
+			return nil
+		})
+		mustNoError(t, "WithTx", err)
+
+		_, err = stores.UserStore.GetUser(ctx, user.ID)
+		mustNoError(t, "GetUser after commit", err)
+		_, err = stores.AllergyStore.GetAllergy(ctx, allergy.ID)
+		mustNoError(t, "GetAllergy after commit", err)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		errAbort := errors.New("abort")
+
+		var user *data.User
+		var allergy *data.Allergy
+		err := stores.WithTx(ctx, func(tx *data.Stores) error {
+			user, allergy = createIntake(tx)
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("WithTx returned %v, want %v", err, errAbort)
+		}
+
+		_, err = stores.UserStore.GetUser(ctx, user.ID)
+		mustNotFound(t, "GetUser after rollback", err)
+		_, err = stores.AllergyStore.GetAllergy(ctx, allergy.ID)
+		mustNotFound(t, "GetAllergy after rollback", err)
+	})
+
+	t.Run("NestedRollback", func(t *testing.T) {
+		errAbort := errors.New("abort")
+
+		var outer, inner *data.User
+		err := stores.WithTx(ctx, func(tx *data.Stores) error {
+			outer = newUser(t, tx)
+			nestedErr := tx.WithTx(ctx, func(nested *data.Stores) error {
+				inner = newUser(t, nested)
+				return errAbort
+			})
+			if !errors.Is(nestedErr, errAbort) {
+				t.Fatalf("nested WithTx returned %v, want %v", nestedErr, errAbort)
+			}
+			return nil
+		})
+		mustNoError(t, "WithTx", err)
+
+		_, err = stores.UserStore.GetUser(ctx, outer.ID)
+		mustNoError(t, "GetUser for outer transaction", err)
+		_, err = stores.UserStore.GetUser(ctx, inner.ID)
+		mustNotFound(t, "GetUser for rolled back nested transaction", err)
+	})
+}
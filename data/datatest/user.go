@@ -0,0 +1,210 @@
+package datatest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func testUserStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.UserStore
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		user := newUser(t, stores)
+		if user.ID == 0 {
+			t.Fatal("CreateUser did not assign an ID")
+		}
+
+		got, err := store.GetUser(ctx, user.ID)
+		mustNoError(t, "GetUser", err)
+		if got.UserName != user.UserName || got.Email != user.Email {
+			t.Fatalf("GetUser returned %+v, want %+v", got, user)
+		}
+
+		got, err = store.GetByUserName(ctx, user.UserName)
+		mustNoError(t, "GetByUserName", err)
+		if got.ID != user.ID {
+			t.Fatalf("GetByUserName returned user %d, want %d", got.ID, user.ID)
+		}
+
+		got, err = store.GetByEmail(ctx, user.Email)
+		mustNoError(t, "GetByEmail", err)
+		if got.ID != user.ID {
+			t.Fatalf("GetByEmail returned user %d, want %d", got.ID, user.ID)
+		}
+
+		got, err = store.GetByPhoneNumber(ctx, user.PhoneNumber)
+		mustNoError(t, "GetByPhoneNumber", err)
+		if got.ID != user.ID {
+			t.Fatalf("GetByPhoneNumber returned user %d, want %d", got.ID, user.ID)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := store.GetUser(ctx, -1)
+		mustNotFound(t, "GetUser", err)
+		_, err = store.GetByUserName(ctx, "missing_"+unique())
+		mustNotFound(t, "GetByUserName", err)
+		_, err = store.GetByEmail(ctx, "missing"+unique()+"@example.com")
+		mustNotFound(t, "GetByEmail", err)
+		_, err = store.GetByPhoneNumber(ctx, "0"+unique())
+		mustNotFound(t, "GetByPhoneNumber", err)
+	})
+
+	t.Run("UniqueFieldsConflict", func(t *testing.T) {
+		existing := newUser(t, stores)
+
+		conflicts := map[string]func(user *data.User){
+			"user_name":    func(user *data.User) { user.UserName = existing.UserName },
+			"email":        func(user *data.User) { user.Email = existing.Email },
+			"phone_number": func(user *data.User) { user.PhoneNumber = existing.PhoneNumber },
+		}
+		for field, apply := range conflicts {
+			id := unique()
+			user := &data.User{
+				UserName:    "user_" + id,
+				FirstName:   "Test",
+				LastName:    "User",
+				Email:       "user" + id + "@example.com",
+				PhoneNumber: "1" + id,
+				Hash:        "hash",
+			}
+			apply(user)
+			if _, err := store.CreateUser(ctx, user); !errors.Is(err, data.ErrRecordConflict) {
+				t.Errorf("CreateUser with duplicate %s: got error %v, want %v",
+					field, err, data.ErrRecordConflict)
+			}
+		}
+
+		other := newUser(t, stores)
+		other.Email = existing.Email
+		if err := store.UpdateUser(ctx, other); !errors.Is(err, data.ErrRecordConflict) {
+			t.Errorf("UpdateUser with duplicate email: got error %v, want %v",
+				err, data.ErrRecordConflict)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		user := newUser(t, stores)
+		user.FirstName = "Updated"
+		user.UserIntakeComplete = true
+		mustNoError(t, "UpdateUser", store.UpdateUser(ctx, user))
+
+		got, err := store.GetUser(ctx, user.ID)
+		mustNoError(t, "GetUser", err)
+		if got.FirstName != "Updated" || !got.UserIntakeComplete {
+			t.Fatalf("UpdateUser did not persist changes: %+v", got)
+		}
+	})
+
+	t.Run("DeleteAndList", func(t *testing.T) {
+		user := newUser(t, stores)
+
+		users, err := store.ListUsers(ctx)
+		mustNoError(t, "ListUsers", err)
+		if !containsID(users, func(u *data.User) int64 { return u.ID }, user.ID) {
+			t.Fatalf("ListUsers does not contain user %d", user.ID)
+		}
+
+		mustNoError(t, "DeleteUser", store.DeleteUser(ctx, user.ID))
+		_, err = store.GetUser(ctx, user.ID)
+		mustNotFound(t, "GetUser after DeleteUser", err)
+	})
+
+	t.Run("GetByToken", func(t *testing.T) {
+		user := newUser(t, stores)
+
+		token, err := stores.TokenStore.CreateToken(ctx, user.ID, time.Hour, data.ScopeAuthentication)
+		mustNoError(t, "CreateToken", err)
+
+		got, err := store.GetByToken(ctx, data.ScopeAuthentication, token.Plaintext)
+		mustNoError(t, "GetByToken", err)
+		if got.ID != user.ID {
+			t.Fatalf("GetByToken returned user %d, want %d", got.ID, user.ID)
+		}
+
+		_, err = store.GetByToken(ctx, "other-scope", token.Plaintext)
+		mustNotFound(t, "GetByToken with wrong scope", err)
+
+		expired, err := stores.TokenStore.CreateToken(ctx, user.ID, -time.Hour, data.ScopeAuthentication)
+		mustNoError(t, "CreateToken", err)
+		_, err = store.GetByToken(ctx, data.ScopeAuthentication, expired.Plaintext)
+		mustNotFound(t, "GetByToken with expired token", err)
+	})
+}
+
+func testTokenStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.TokenStore
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		user := newUser(t, stores)
+
+		token, err := store.CreateToken(ctx, user.ID, time.Hour, data.ScopeAuthentication)
+		mustNoError(t, "CreateToken", err)
+		if len(token.Plaintext) != 26 {
+			t.Fatalf("CreateToken returned %d byte plaintext, want 26", len(token.Plaintext))
+		}
+
+		got, err := store.GetToken(ctx, data.ScopeAuthentication, token.Plaintext)
+		mustNoError(t, "GetToken", err)
+		if got.UserID != user.ID {
+			t.Fatalf("GetToken returned token for user %d, want %d", got.UserID, user.ID)
+		}
+
+		_, err = store.GetToken(ctx, "other-scope", token.Plaintext)
+		mustNotFound(t, "GetToken with wrong scope", err)
+	})
+
+	t.Run("ExpiredIgnored", func(t *testing.T) {
+		user := newUser(t, stores)
+
+		token, err := store.CreateToken(ctx, user.ID, -time.Minute, data.ScopeAuthentication)
+		mustNoError(t, "CreateToken", err)
+
+		_, err = store.GetToken(ctx, data.ScopeAuthentication, token.Plaintext)
+		mustNotFound(t, "GetToken with expired token", err)
+	})
+
+	t.Run("DeleteAllForUser", func(t *testing.T) {
+		user := newUser(t, stores)
+
+		auth, err := store.CreateToken(ctx, user.ID, time.Hour, data.ScopeAuthentication)
+		mustNoError(t, "CreateToken", err)
+		other, err := store.CreateToken(ctx, user.ID, time.Hour, "other-scope")
+		mustNoError(t, "CreateToken", err)
+
+		mustNoError(t, "DeleteAllForUser", store.DeleteAllForUser(ctx, data.ScopeAuthentication, user.ID))
+
+		_, err = store.GetToken(ctx, data.ScopeAuthentication, auth.Plaintext)
+		mustNotFound(t, "GetToken after DeleteAllForUser", err)
+
+		_, err = store.GetToken(ctx, "other-scope", other.Plaintext)
+		mustNoError(t, "GetToken in untouched scope", err)
+	})
+
+	t.Run("DeleteAllTokensForUser", func(t *testing.T) {
+		user := newUser(t, stores)
+		bystander := newUser(t, stores)
+
+		for _, scope := range []string{data.ScopeAuthentication, "other-scope"} {
+			_, err := store.CreateToken(ctx, user.ID, time.Hour, scope)
+			mustNoError(t, "CreateToken", err)
+		}
+		kept, err := store.CreateToken(ctx, bystander.ID, time.Hour, data.ScopeAuthentication)
+		mustNoError(t, "CreateToken", err)
+
+		deleted, err := store.DeleteAllTokensForUser(ctx, user.ID)
+		mustNoError(t, "DeleteAllTokensForUser", err)
+		if deleted != 2 {
+			t.Fatalf("DeleteAllTokensForUser deleted %d tokens, want 2", deleted)
+		}
+
+		_, err = store.GetToken(ctx, data.ScopeAuthentication, kept.Plaintext)
+		mustNoError(t, "GetToken for another user", err)
+	})
+}
@@ -0,0 +1,456 @@
+package datatest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// userRecordCase describes a store whose records belong to a single user and
+// support the usual create/get/list/update/delete operations.
+type userRecordCase[T any] struct {
+	create  func(ctx context.Context, userID int64) (*T, error)
+	get     func(ctx context.Context, id int64) (*T, error)
+	list    func(ctx context.Context, userID int64) ([]*T, error)
+	update  func(ctx context.Context, record *T) error
+	delete  func(ctx context.Context, id int64) error
+	id      func(record *T) int64
+	mutate  func(record *T)
+	mutated func(record *T) bool
+}
+
+func runUserRecordCase[T any](t *testing.T, stores *data.Stores, c userRecordCase[T]) {
+	t.Helper()
+
+	ctx := context.Background()
+	user := newUser(t, stores)
+	other := newUser(t, stores)
+
+	first, err := c.create(ctx, user.ID)
+	mustNoError(t, "create", err)
+	if c.id(first) == 0 {
+		t.Fatal("create did not assign an ID")
+	}
+	second, err := c.create(ctx, user.ID)
+	mustNoError(t, "create", err)
+	foreign, err := c.create(ctx, other.ID)
+	mustNoError(t, "create", err)
+
+	got, err := c.get(ctx, c.id(first))
+	mustNoError(t, "get", err)
+	if c.id(got) != c.id(first) {
+		t.Fatalf("get returned record %d, want %d", c.id(got), c.id(first))
+	}
+
+	_, err = c.get(ctx, -1)
+	mustNotFound(t, "get", err)
+
+	if c.list != nil {
+		records, err := c.list(ctx, user.ID)
+		mustNoError(t, "list", err)
+		if len(records) != 2 ||
+			!containsID(records, c.id, c.id(first)) ||
+			!containsID(records, c.id, c.id(second)) {
+			t.Fatalf("list returned %d records, want records %d and %d",
+				len(records), c.id(first), c.id(second))
+		}
+		if containsID(records, c.id, c.id(foreign)) {
+			t.Fatalf("list returned record %d belonging to another user", c.id(foreign))
+		}
+	}
+
+	c.mutate(first)
+	mustNoError(t, "update", c.update(ctx, first))
+	got, err = c.get(ctx, c.id(first))
+	mustNoError(t, "get after update", err)
+	if !c.mutated(got) {
+		t.Fatalf("update did not persist changes: %+v", got)
+	}
+
+	mustNoError(t, "delete", c.delete(ctx, c.id(second)))
+	_, err = c.get(ctx, c.id(second))
+	mustNotFound(t, "get after delete", err)
+}
+
+func testAllergyStore(t *testing.T, stores *data.Stores) {
+	store := stores.AllergyStore
+
+	runUserRecordCase(t, stores, userRecordCase[data.Allergy]{
+		create: func(ctx context.Context, userID int64) (*data.Allergy, error) {
+			return store.CreateAllergy(ctx, &data.Allergy{
+				UserID:      userID,
+				AllergyName: "Peanuts",
+				Reaction:    "Hives",
+			})
+		},
+		get:     store.GetAllergy,
+		list:    store.ListUserAllergies,
+		update:  store.UpdateAllergy,
+		delete:  store.DeleteAllergy,
+		id:      func(a *data.Allergy) int64 { return a.ID },
+		mutate:  func(a *data.Allergy) { a.Reaction = "Swelling" },
+		mutated: func(a *data.Allergy) bool { return a.Reaction == "Swelling" },
+	})
+}
+
+func testCaregiverStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.CaregiverStore
+
+	runUserRecordCase(t, stores, userRecordCase[data.Caregiver]{
+		create: func(ctx context.Context, userID int64) (*data.Caregiver, error) {
+			id := unique()
+			return store.CreateCaregiver(ctx, &data.Caregiver{
+				UserID:      userID,
+				Email:       "caregiver" + id + "@example.com",
+				PhoneNumber: "1" + id,
+			})
+		},
+		get:     store.GetCaregiver,
+		list:    store.ListUserCaregivers,
+		update:  store.UpdateCaregiver,
+		delete:  store.DeleteCaregiver,
+		id:      func(c *data.Caregiver) int64 { return c.ID },
+		mutate:  func(c *data.Caregiver) { c.PhoneNumber = "19999999999" },
+		mutated: func(c *data.Caregiver) bool { return c.PhoneNumber == "19999999999" },
+	})
+
+	t.Run("GetCaregiverByEmail", func(t *testing.T) {
+		user := newUser(t, stores)
+		email := "caregiver" + unique() + "@example.com"
+		caregiver, err := store.CreateCaregiver(ctx, &data.Caregiver{
+			UserID:      user.ID,
+			Email:       email,
+			PhoneNumber: "1" + unique(),
+		})
+		mustNoError(t, "CreateCaregiver", err)
+
+		got, err := store.GetCaregiverByEmail(ctx, email)
+		mustNoError(t, "GetCaregiverByEmail", err)
+		if got.ID != caregiver.ID {
+			t.Fatalf("GetCaregiverByEmail returned %d, want %d", got.ID, caregiver.ID)
+		}
+
+		_, err = store.GetCaregiverByEmail(ctx, "missing"+unique()+"@example.com")
+		mustNotFound(t, "GetCaregiverByEmail", err)
+	})
+}
+
+func testDietarySupplementStore(t *testing.T, stores *data.Stores) {
+	store := stores.DietarySupplementStore
+
+	runUserRecordCase(t, stores, userRecordCase[data.DietarySupplement]{
+		create: func(ctx context.Context, userID int64) (*data.DietarySupplement, error) {
+			return store.CreateDietarySupplement(ctx, &data.DietarySupplement{
+				UserID:    userID,
+				Name:      "Vitamin D",
+				Dosage:    "1000 IU",
+				StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				Current:   true,
+			})
+		},
+		get:     store.GetDietarySupplement,
+		list:    store.ListUserDietarySupplements,
+		update:  store.UpdateDietarySupplement,
+		delete:  store.DeleteDietarySupplement,
+		id:      func(s *data.DietarySupplement) int64 { return s.ID },
+		mutate:  func(s *data.DietarySupplement) { s.Dosage = "2000 IU" },
+		mutated: func(s *data.DietarySupplement) bool { return s.Dosage == "2000 IU" },
+	})
+
+	t.Run("ListUserDietarySupplementsAsOf", func(t *testing.T) {
+		ctx := context.Background()
+		user := newUser(t, stores)
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		stopped, err := store.CreateDietarySupplement(ctx, &data.DietarySupplement{
+			UserID:    user.ID,
+			Name:      "Iron",
+			StartDate: start,
+			EndDate:   start.AddDate(0, 3, 0),
+			Current:   true,
+		})
+		mustNoError(t, "CreateDietarySupplement", err)
+		ongoing, err := store.CreateDietarySupplement(ctx, &data.DietarySupplement{
+			UserID:    user.ID,
+			Name:      "Magnesium",
+			StartDate: start.AddDate(0, 2, 0),
+		})
+		mustNoError(t, "CreateDietarySupplement", err)
+
+		id := func(s *data.DietarySupplement) int64 { return s.ID }
+		supplements, err := store.ListUserDietarySupplementsAsOf(ctx, user.ID, start.AddDate(0, 1, 0))
+		mustNoError(t, "ListUserDietarySupplementsAsOf", err)
+		if len(supplements) != 1 || !containsID(supplements, id, stopped.ID) {
+			t.Fatalf("ListUserDietarySupplementsAsOf returned %d records, want only %d",
+				len(supplements), stopped.ID)
+		}
+		supplements, err = store.ListUserDietarySupplementsAsOf(ctx, user.ID, stopped.EndDate)
+		mustNoError(t, "ListUserDietarySupplementsAsOf", err)
+		if len(supplements) != 1 || !containsID(supplements, id, ongoing.ID) {
+			t.Fatalf("ListUserDietarySupplementsAsOf on the end date returned %d records, want only %d",
+				len(supplements), ongoing.ID)
+		}
+	})
+}
+
+func testEmergencyContactStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.EmergencyContactStore
+
+	runUserRecordCase(t, stores, userRecordCase[data.EmergencyContact]{
+		create: func(ctx context.Context, userID int64) (*data.EmergencyContact, error) {
+			id := unique()
+			return store.CreateEmergencyContact(ctx, &data.EmergencyContact{
+				UserID:      userID,
+				FirstName:   "Alex",
+				LastName:    "Doe",
+				PhoneNumber: "1" + id,
+				Email:       "contact" + id + "@example.com",
+			})
+		},
+		get:     store.GetEmergencyContact,
+		list:    store.ListUserEmergencyContacts,
+		update:  store.UpdateEmergencyContact,
+		delete:  store.DeleteEmergencyContact,
+		id:      func(c *data.EmergencyContact) int64 { return c.ID },
+		mutate:  func(c *data.EmergencyContact) { c.FirstName = "Sam" },
+		mutated: func(c *data.EmergencyContact) bool { return c.FirstName == "Sam" },
+	})
+
+	t.Run("GetEmergencyContactByEmail", func(t *testing.T) {
+		user := newUser(t, stores)
+		email := "contact" + unique() + "@example.com"
+		contact, err := store.CreateEmergencyContact(ctx, &data.EmergencyContact{
+			UserID: user.ID,
+			Email:  email,
+		})
+		mustNoError(t, "CreateEmergencyContact", err)
+
+		got, err := store.GetEmergencyContactByEmail(ctx, email)
+		mustNoError(t, "GetEmergencyContactByEmail", err)
+		if got.ID != contact.ID {
+			t.Fatalf("GetEmergencyContactByEmail returned %d, want %d", got.ID, contact.ID)
+		}
+
+		_, err = store.GetEmergencyContactByEmail(ctx, "missing"+unique()+"@example.com")
+		mustNotFound(t, "GetEmergencyContactByEmail", err)
+	})
+}
+
+func testFrequentFoodStore(t *testing.T, stores *data.Stores) {
+	store := stores.FrequentFoodStore
+
+	runUserRecordCase(t, stores, userRecordCase[data.FrequentFood]{
+		create: func(ctx context.Context, userID int64) (*data.FrequentFood, error) {
+			return store.CreateFrequentFood(ctx, &data.FrequentFood{UserID: userID, FoodName: "Rice"})
+		},
+		get:     store.GetFrequentFood,
+		list:    store.ListUserFrequentFoods,
+		update:  store.UpdateFrequentFood,
+		delete:  store.DeleteFrequentFood,
+		id:      func(f *data.FrequentFood) int64 { return f.ID },
+		mutate:  func(f *data.FrequentFood) { f.FoodName = "Oatmeal" },
+		mutated: func(f *data.FrequentFood) bool { return f.FoodName == "Oatmeal" },
+	})
+}
+
+func testMedicalEventStore(t *testing.T, stores *data.Stores) {
+	store := stores.MedicalEventStore
+
+	runUserRecordCase(t, stores, userRecordCase[data.MedicalEvent]{
+		create: func(ctx context.Context, userID int64) (*data.MedicalEvent, error) {
+			return store.CreateMedicalEvent(ctx, &data.MedicalEvent{
+				UserID:      userID,
+				Age:         12,
+				Description: "Broken arm",
+			})
+		},
+		get:     store.GetMedicalEvent,
+		list:    store.ListUserMedicalEvents,
+		update:  store.UpdateMedicalEvent,
+		delete:  store.DeleteMedicalEvent,
+		id:      func(e *data.MedicalEvent) int64 { return e.ID },
+		mutate:  func(e *data.MedicalEvent) { e.Age = 13 },
+		mutated: func(e *data.MedicalEvent) bool { return e.Age == 13 },
+	})
+}
+
+func testMedicalInformationStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.MedicalInformationStore
+
+	runUserRecordCase(t, stores, userRecordCase[data.MedicalInformation]{
+		create: func(ctx context.Context, userID int64) (*data.MedicalInformation, error) {
+			return store.CreateMedicalInformation(ctx, &data.MedicalInformation{
+				UserID:            userID,
+				Height:            170,
+				Weight:            70,
+				Diagnosis:         "None",
+				DiagnosisSeverity: "Mild",
+				CurrentPriority:   "Improve health",
+				Gender:            "Other",
+			})
+		},
+		get:     store.GetMedicalInformation,
+		update:  store.UpdateMedicalInformation,
+		delete:  store.DeleteMedicalInformation,
+		id:      func(m *data.MedicalInformation) int64 { return m.ID },
+		mutate:  func(m *data.MedicalInformation) { m.Weight = 72 },
+		mutated: func(m *data.MedicalInformation) bool { return m.Weight == 72 },
+	})
+
+	t.Run("GetMedicalInformationByUserID", func(t *testing.T) {
+		user := newUser(t, stores)
+		medInfo, err := store.CreateMedicalInformation(ctx, &data.MedicalInformation{
+			UserID:            user.ID,
+			Height:            160,
+			Weight:            60,
+			Diagnosis:         "Asthma",
+			DiagnosisSeverity: "Moderate",
+			CurrentPriority:   "Manage symptoms",
+			Gender:            "Female",
+		})
+		mustNoError(t, "CreateMedicalInformation", err)
+
+		got, err := store.GetMedicalInformationByUserID(ctx, user.ID)
+		mustNoError(t, "GetMedicalInformationByUserID", err)
+		if got.ID != medInfo.ID {
+			t.Fatalf("GetMedicalInformationByUserID returned %d, want %d", got.ID, medInfo.ID)
+		}
+
+		_, err = store.GetMedicalInformationByUserID(ctx, newUser(t, stores).ID)
+		mustNotFound(t, "GetMedicalInformationByUserID", err)
+	})
+}
+
+func testMedicationStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.MedicationStore
+
+	runUserRecordCase(t, stores, userRecordCase[data.Medication]{
+		create: func(ctx context.Context, userID int64) (*data.Medication, error) {
+			return store.CreateMedication(ctx, &data.Medication{
+				UserID:    userID,
+				Name:      "Metformin",
+				Dosage:    "500mg",
+				StartDate: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+				Current:   true,
+			})
+		},
+		get:     store.GetMedication,
+		list:    store.ListUserMedications,
+		update:  store.UpdateMedication,
+		delete:  store.DeleteMedication,
+		id:      func(m *data.Medication) int64 { return m.ID },
+		mutate:  func(m *data.Medication) { m.SideEffects = "Nausea" },
+		mutated: func(m *data.Medication) bool { return m.SideEffects == "Nausea" },
+	})
+
+	t.Run("ListUserCurrentMedications", func(t *testing.T) {
+		user := newUser(t, stores)
+		current, err := store.CreateMedication(ctx, &data.Medication{
+			UserID:  user.ID,
+			Name:    "Lisinopril",
+			Current: true,
+		})
+		mustNoError(t, "CreateMedication", err)
+		past, err := store.CreateMedication(ctx, &data.Medication{
+			UserID:  user.ID,
+			Name:    "Albuterol",
+			Current: false,
+		})
+		mustNoError(t, "CreateMedication", err)
+
+		medications, err := store.ListUserCurrentMedications(ctx, user.ID)
+		mustNoError(t, "ListUserCurrentMedications", err)
+		id := func(m *data.Medication) int64 { return m.ID }
+		if len(medications) != 1 || !containsID(medications, id, current.ID) {
+			t.Fatalf("ListUserCurrentMedications returned %d records, want only %d",
+				len(medications), current.ID)
+		}
+		if containsID(medications, id, past.ID) {
+			t.Fatalf("ListUserCurrentMedications returned past medication %d", past.ID)
+		}
+	})
+
+	t.Run("ListUserMedicationsAsOf", func(t *testing.T) {
+		user := newUser(t, stores)
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		stopped, err := store.CreateMedication(ctx, &data.Medication{
+			UserID:    user.ID,
+			Name:      "Amoxicillin",
+			StartDate: start,
+			EndDate:   start.AddDate(0, 0, 10),
+			Current:   true,
+		})
+		mustNoError(t, "CreateMedication", err)
+		ongoing, err := store.CreateMedication(ctx, &data.Medication{
+			UserID:    user.ID,
+			Name:      "Levothyroxine",
+			StartDate: start.AddDate(0, 0, 5),
+		})
+		mustNoError(t, "CreateMedication", err)
+
+		id := func(m *data.Medication) int64 { return m.ID }
+		medications, err := store.ListUserMedicationsAsOf(ctx, user.ID, start.AddDate(0, 0, 7))
+		mustNoError(t, "ListUserMedicationsAsOf", err)
+		if len(medications) != 2 {
+			t.Fatalf("ListUserMedicationsAsOf returned %d records, want 2", len(medications))
+		}
+		medications, err = store.ListUserMedicationsAsOf(ctx, user.ID, start.AddDate(0, 0, 2))
+		mustNoError(t, "ListUserMedicationsAsOf", err)
+		if len(medications) != 1 || !containsID(medications, id, stopped.ID) {
+			t.Fatalf("ListUserMedicationsAsOf before the second start returned %d records, want only %d",
+				len(medications), stopped.ID)
+		}
+
+		// The stored flag is ignored once the end date has passed.
+		medications, err = store.ListUserCurrentMedications(ctx, user.ID)
+		mustNoError(t, "ListUserCurrentMedications", err)
+		if len(medications) != 1 || !containsID(medications, id, ongoing.ID) {
+			t.Fatalf("ListUserCurrentMedications returned %d records, want only %d",
+				len(medications), ongoing.ID)
+		}
+	})
+}
+
+func testUserIntakeStore(t *testing.T, stores *data.Stores) {
+	ctx := context.Background()
+	store := stores.UserIntakeStore
+	user := newUser(t, stores)
+
+	_, err := store.GetUserIntakeByUserID(ctx, user.ID)
+	mustNotFound(t, "GetUserIntakeByUserID", err)
+
+	intake, err := store.CreateUserIntake(ctx, &data.UserIntake{
+		UserID:   user.ID,
+		FormData: `{"step":1}`,
+	})
+	mustNoError(t, "CreateUserIntake", err)
+
+	got, err := store.GetUserIntakeByUserID(ctx, user.ID)
+	mustNoError(t, "GetUserIntakeByUserID", err)
+	if got.ID != intake.ID {
+		t.Fatalf("GetUserIntakeByUserID returned %d, want %d", got.ID, intake.ID)
+	}
+
+	got.FormData = `{"step":2}`
+	mustNoError(t, "UpdateUserIntake", store.UpdateUserIntake(ctx, got))
+	got, err = store.GetUserIntakeByUserID(ctx, user.ID)
+	mustNoError(t, "GetUserIntakeByUserID", err)
+	// jsonb normalizes whitespace, so compare the decoded document.
+	var form struct {
+		Step int `json:"step"`
+	}
+	mustNoError(t, "decode form data", json.Unmarshal([]byte(got.FormData), &form))
+	if form.Step != 2 {
+		t.Fatalf("UpdateUserIntake did not persist form data: %s", got.FormData)
+	}
+
+	mustNoError(t, "DeleteUserIntake", store.DeleteUserIntake(ctx, intake.ID))
+	_, err = store.GetUserIntakeByUserID(ctx, user.ID)
+	mustNotFound(t, "GetUserIntakeByUserID after DeleteUserIntake", err)
+}
@@ -1,9 +1,15 @@
 package data
 
 import (
+	"context"
+	"strings"
 	"time"
+
+	"github.com/Universal-Selfcare/utils/validator"
 )
 
+// DietarySupplement is a supplement a user takes or took. Like a
+// Medication, whether it is current is derived from its dates.
 type DietarySupplement struct {
 	ID        int64     `gorm:"primaryKey"     json:"id"`
 	UserID    int64     `gorm:"not null;index" json:"user_id"`
@@ -14,12 +20,48 @@ type DietarySupplement struct {
 	Current   bool      `                      json:"current"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Dose is the structured form of Dosage; see ParseDosage.
+	Dose
 }
 
 type DietarySupplementStore interface {
-	CreateDietarySupplement(supplement *DietarySupplement) (*DietarySupplement, error)
-	GetDietarySupplement(id int64) (*DietarySupplement, error)
-	ListUserDietarySupplements(userID int64) ([]*DietarySupplement, error)
-	UpdateDietarySupplement(supplement *DietarySupplement) error
-	DeleteDietarySupplement(id int64) error
+	CreateDietarySupplement(
+		ctx context.Context,
+		supplement *DietarySupplement,
+	) (*DietarySupplement, error)
+	GetDietarySupplement(ctx context.Context, id int64) (*DietarySupplement, error)
+	ListUserDietarySupplements(ctx context.Context, userID int64) ([]*DietarySupplement, error)
+	ListUserDietarySupplementsAsOf(
+		ctx context.Context,
+		userID int64,
+		at time.Time,
+	) ([]*DietarySupplement, error)
+	UpdateDietarySupplement(ctx context.Context, supplement *DietarySupplement) error
+	DeleteDietarySupplement(ctx context.Context, id int64) error
+}
+
+// ValidateDietarySupplement checks a supplement, including its structured
+// dose.
+func ValidateDietarySupplement(v *validator.Validator, supplement *DietarySupplement) {
+	v.Check(supplement.UserID != 0, "user_id", "must be provided")
+	v.Check(strings.TrimSpace(supplement.Name) != "", "name", "must be provided")
+	v.Check(
+		supplement.EndDate.IsZero() || supplement.EndDate.After(supplement.StartDate),
+		"end_date",
+		"must be after the start date",
+	)
+	ValidateDose(v, supplement.Dose)
+}
+
+// CurrentAsOf reports whether the supplement was being taken at the given
+// time, like Medication.CurrentAsOf.
+func (supplement *DietarySupplement) CurrentAsOf(at time.Time) bool {
+	return takenAsOf(supplement.StartDate, supplement.EndDate, supplement.Current, at)
+}
+
+// TakenBetween reports whether the supplement was taken at any time from
+// from until before to.
+func (supplement *DietarySupplement) TakenBetween(from, to time.Time) bool {
+	return takenBetween(supplement.StartDate, supplement.EndDate, supplement.Current, from, to)
 }
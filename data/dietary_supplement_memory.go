@@ -0,0 +1,117 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+type MemoryDietarySupplementStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryDietarySupplementStore(db *MemoryDB) *MemoryDietarySupplementStore {
+	return &MemoryDietarySupplementStore{db: db}
+}
+
+func (store *MemoryDietarySupplementStore) CreateDietarySupplement(
+	ctx context.Context,
+	supplement *DietarySupplement,
+) (*DietarySupplement, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if supplement.ID != 0 {
+		if _, exists := store.db.dietarySupplements.get(supplement.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		supplement.ID = store.db.dietarySupplements.nextID()
+	}
+	setCreateTimestamps(&supplement.CreatedAt, &supplement.UpdatedAt)
+	store.db.dietarySupplements.put(supplement.ID, *supplement)
+
+	return supplement, nil
+}
+
+func (store *MemoryDietarySupplementStore) GetDietarySupplement(
+	ctx context.Context,
+	id int64,
+) (*DietarySupplement, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	supplement, ok := store.db.dietarySupplements.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &supplement, nil
+}
+
+func (store *MemoryDietarySupplementStore) ListUserDietarySupplements(
+	ctx context.Context,
+	userID int64,
+) ([]*DietarySupplement, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.dietarySupplements.filter(func(row *DietarySupplement) bool {
+		return row.UserID == userID
+	}), nil
+}
+
+func (store *MemoryDietarySupplementStore) ListUserDietarySupplementsAsOf(
+	ctx context.Context,
+	userID int64,
+	at time.Time,
+) ([]*DietarySupplement, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.dietarySupplements.filter(func(row *DietarySupplement) bool {
+		return row.UserID == userID && row.CurrentAsOf(at)
+	}), nil
+}
+
+func (store *MemoryDietarySupplementStore) UpdateDietarySupplement(
+	ctx context.Context,
+	supplement *DietarySupplement,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.dietarySupplements.get(supplement.ID)
+	if supplement.ID == 0 || !ok {
+		if supplement.ID == 0 {
+			supplement.ID = store.db.dietarySupplements.nextID()
+		}
+		setCreateTimestamps(&supplement.CreatedAt, &supplement.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&supplement.CreatedAt, &supplement.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.dietarySupplements.put(supplement.ID, *supplement)
+
+	return nil
+}
+
+func (store *MemoryDietarySupplementStore) DeleteDietarySupplement(
+	ctx context.Context,
+	id int64,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.dietarySupplements.delete(id)
+	return nil
+}
@@ -1,26 +1,31 @@
 package data
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type PostgresDietarySupplementStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresDietarySupplementStore(db *gorm.DB) *PostgresDietarySupplementStore {
-	if err := db.AutoMigrate(&DietarySupplement{}); err != nil {
-		panic("failed to migrate dietary supplement schema: " + err.Error())
-	}
 	return &PostgresDietarySupplementStore{DB: db}
 }
 
 func (store *PostgresDietarySupplementStore) CreateDietarySupplement(
+	ctx context.Context,
 	supplement *DietarySupplement,
 ) (*DietarySupplement, error) {
-	err := store.DB.Create(supplement).Error
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(supplement).Error
 	if err != nil {
 		return nil, err
 	}
@@ -28,10 +33,14 @@ func (store *PostgresDietarySupplementStore) CreateDietarySupplement(
 }
 
 func (store *PostgresDietarySupplementStore) GetDietarySupplement(
+	ctx context.Context,
 	id int64,
 ) (*DietarySupplement, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var supplement DietarySupplement
-	err := store.DB.First(&supplement, id).Error
+	err := db.First(&supplement, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -42,10 +51,33 @@ func (store *PostgresDietarySupplementStore) GetDietarySupplement(
 }
 
 func (store *PostgresDietarySupplementStore) ListUserDietarySupplements(
+	ctx context.Context,
+	userID int64,
+) ([]*DietarySupplement, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var supplements []*DietarySupplement
+	err := db.Where("user_id = ?", userID).Find(&supplements).Error
+	if err != nil {
+		return nil, err
+	}
+	return supplements, nil
+}
+
+func (store *PostgresDietarySupplementStore) ListUserDietarySupplementsAsOf(
+	ctx context.Context,
 	userID int64,
+	at time.Time,
 ) ([]*DietarySupplement, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var supplements []*DietarySupplement
-	err := store.DB.Where("user_id = ?", userID).Find(&supplements).Error
+	err := db.Where("user_id = ?", userID).
+		Where(takenAsOfCondition, sql.Named("at", at), sql.Named("zero", time.Time{})).
+		Order("id").
+		Find(&supplements).Error
 	if err != nil {
 		return nil, err
 	}
@@ -53,17 +85,27 @@ func (store *PostgresDietarySupplementStore) ListUserDietarySupplements(
 }
 
 func (store *PostgresDietarySupplementStore) UpdateDietarySupplement(
+	ctx context.Context,
 	supplement *DietarySupplement,
 ) error {
-	err := store.DB.Save(supplement).Error
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(supplement).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresDietarySupplementStore) DeleteDietarySupplement(id int64) error {
-	err := store.DB.Delete(&DietarySupplement{}, id).Error
+func (store *PostgresDietarySupplementStore) DeleteDietarySupplement(
+	ctx context.Context,
+	id int64,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&DietarySupplement{}, id).Error
 	if err != nil {
 		return err
 	}
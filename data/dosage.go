@@ -0,0 +1,759 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Universal-Selfcare/utils/validator"
+)
+
+// DoseUnit is the unit a dose is measured in, written as its UCUM code.
+// Countable forms such as tablets use UCUM annotations.
+type DoseUnit string
+
+const (
+	DoseMilligram         DoseUnit = "mg"
+	DoseGram              DoseUnit = "g"
+	DoseMicrogram         DoseUnit = "ug"
+	DoseMilliliter        DoseUnit = "mL"
+	DoseInternationalUnit DoseUnit = "[iU]"
+	DoseMilliequivalent   DoseUnit = "meq"
+	DoseTeaspoon          DoseUnit = "[tsp_us]"
+	DoseTablespoon        DoseUnit = "[tbs_us]"
+	DoseDrop              DoseUnit = "[drp]"
+	DoseTablet            DoseUnit = "{tablet}"
+	DoseCapsule           DoseUnit = "{capsule}"
+	DosePuff              DoseUnit = "{puff}"
+	DoseSpray             DoseUnit = "{spray}"
+	DosePatch             DoseUnit = "{patch}"
+	DoseSachet            DoseUnit = "{sachet}"
+	DoseScoop             DoseUnit = "{scoop}"
+	DoseGummy             DoseUnit = "{gummy}"
+)
+
+var DoseUnits = []DoseUnit{
+	DoseMilligram, DoseGram, DoseMicrogram, DoseMilliliter, DoseInternationalUnit,
+	DoseMilliequivalent, DoseTeaspoon, DoseTablespoon, DoseDrop, DoseTablet,
+	DoseCapsule, DosePuff, DoseSpray, DosePatch, DoseSachet, DoseScoop, DoseGummy,
+}
+
+// doseUnitAliases maps lower-case spellings to their unit, in addition to
+// the UCUM codes themselves.
+var doseUnitAliases = map[string]DoseUnit{
+	"milligram":     DoseMilligram,
+	"milligrams":    DoseMilligram,
+	"mgs":           DoseMilligram,
+	"gram":          DoseGram,
+	"grams":         DoseGram,
+	"gm":            DoseGram,
+	"gr":            DoseGram,
+	"mcg":           DoseMicrogram,
+	"µg":            DoseMicrogram,
+	"μg":            DoseMicrogram,
+	"microgram":     DoseMicrogram,
+	"micrograms":    DoseMicrogram,
+	"ml":            DoseMilliliter,
+	"mls":           DoseMilliliter,
+	"cc":            DoseMilliliter,
+	"milliliter":    DoseMilliliter,
+	"milliliters":   DoseMilliliter,
+	"millilitre":    DoseMilliliter,
+	"millilitres":   DoseMilliliter,
+	"iu":            DoseInternationalUnit,
+	"international": DoseInternationalUnit,
+	"ui":            DoseInternationalUnit,
+	"u":             DoseInternationalUnit,
+	"unit":          DoseInternationalUnit,
+	"units":         DoseInternationalUnit,
+	"meq":           DoseMilliequivalent,
+	"tsp":           DoseTeaspoon,
+	"teaspoon":      DoseTeaspoon,
+	"teaspoons":     DoseTeaspoon,
+	"tbsp":          DoseTablespoon,
+	"tablespoon":    DoseTablespoon,
+	"tablespoons":   DoseTablespoon,
+	"drop":          DoseDrop,
+	"drops":         DoseDrop,
+	"gtt":           DoseDrop,
+	"gtts":          DoseDrop,
+	"tab":           DoseTablet,
+	"tabs":          DoseTablet,
+	"tablet":        DoseTablet,
+	"tablets":       DoseTablet,
+	"pill":          DoseTablet,
+	"pills":         DoseTablet,
+	"cap":           DoseCapsule,
+	"caps":          DoseCapsule,
+	"capsule":       DoseCapsule,
+	"capsules":      DoseCapsule,
+	"softgel":       DoseCapsule,
+	"softgels":      DoseCapsule,
+	"puff":          DosePuff,
+	"puffs":         DosePuff,
+	"inhalation":    DosePuff,
+	"inhalations":   DosePuff,
+	"spray":         DoseSpray,
+	"sprays":        DoseSpray,
+	"patch":         DosePatch,
+	"patches":       DosePatch,
+	"sachet":        DoseSachet,
+	"sachets":       DoseSachet,
+	"packet":        DoseSachet,
+	"packets":       DoseSachet,
+	"scoop":         DoseScoop,
+	"scoops":        DoseScoop,
+	"gummy":         DoseGummy,
+	"gummies":       DoseGummy,
+}
+
+// PeriodUnit is the unit of the period over which a dose is repeated,
+// written as its UCUM code.
+type PeriodUnit string
+
+const (
+	PeriodHour  PeriodUnit = "h"
+	PeriodDay   PeriodUnit = "d"
+	PeriodWeek  PeriodUnit = "wk"
+	PeriodMonth PeriodUnit = "mo"
+)
+
+var PeriodUnits = []PeriodUnit{PeriodHour, PeriodDay, PeriodWeek, PeriodMonth}
+
+// days returns the length of one unit in days; a month is the UCUM mean
+// Julian month.
+func (unit PeriodUnit) days() float64 {
+	switch unit {
+	case PeriodHour:
+		return 1.0 / 24
+	case PeriodDay:
+		return 1
+	case PeriodWeek:
+		return 7
+	case PeriodMonth:
+		return 30.4375
+	}
+	return 0
+}
+
+// Route is the way a dose is taken.
+type Route string
+
+const (
+	RouteOral          Route = "oral"
+	RouteSublingual    Route = "sublingual"
+	RouteTopical       Route = "topical"
+	RouteTransdermal   Route = "transdermal"
+	RouteInhaled       Route = "inhaled"
+	RouteNasal         Route = "nasal"
+	RouteOphthalmic    Route = "ophthalmic"
+	RouteOtic          Route = "otic"
+	RouteRectal        Route = "rectal"
+	RouteVaginal       Route = "vaginal"
+	RouteSubcutaneous  Route = "subcutaneous"
+	RouteIntramuscular Route = "intramuscular"
+	RouteIntravenous   Route = "intravenous"
+)
+
+var Routes = []Route{
+	RouteOral, RouteSublingual, RouteTopical, RouteTransdermal, RouteInhaled,
+	RouteNasal, RouteOphthalmic, RouteOtic, RouteRectal, RouteVaginal,
+	RouteSubcutaneous, RouteIntramuscular, RouteIntravenous,
+}
+
+// routeAliases maps lower-case phrases to their route, in addition to the
+// routes themselves.
+var routeAliases = map[string]Route{
+	"po":               RouteOral,
+	"orally":           RouteOral,
+	"by mouth":         RouteOral,
+	"sl":               RouteSublingual,
+	"sublingually":     RouteSublingual,
+	"under the tongue": RouteSublingual,
+	"topically":        RouteTopical,
+	"to the skin":      RouteTopical,
+	"on the skin":      RouteTopical,
+	"td":               RouteTransdermal,
+	"inh":              RouteInhaled,
+	"inhale":           RouteInhaled,
+	"by inhalation":    RouteInhaled,
+	"intranasal":       RouteNasal,
+	"nasally":          RouteNasal,
+	"in each nostril":  RouteNasal,
+	"in the nose":      RouteNasal,
+	"in each eye":      RouteOphthalmic,
+	"in the eye":       RouteOphthalmic,
+	"in the eyes":      RouteOphthalmic,
+	"in each ear":      RouteOtic,
+	"in the ear":       RouteOtic,
+	"pr":               RouteRectal,
+	"rectally":         RouteRectal,
+	"pv":               RouteVaginal,
+	"vaginally":        RouteVaginal,
+	"sc":               RouteSubcutaneous,
+	"sq":               RouteSubcutaneous,
+	"subq":             RouteSubcutaneous,
+	"subcut":           RouteSubcutaneous,
+	"subcutaneously":   RouteSubcutaneous,
+	"im":               RouteIntramuscular,
+	"intramuscularly":  RouteIntramuscular,
+	"iv":               RouteIntravenous,
+	"intravenously":    RouteIntravenous,
+}
+
+// Dose is the structured form of a free-text dosage: DoseAmount of DoseUnit
+// taken DoseFrequency times every DosePeriod DosePeriodUnit by DoseRoute.
+// Zero fields are unknown. A dose taken as needed may still give a
+// frequency, which is then the most it may be taken.
+type Dose struct {
+	DoseAmount     float64    `gorm:"not null;default:0"            json:"dose_amount"`
+	DoseUnit       DoseUnit   `gorm:"type:text;not null;default:''" json:"dose_unit"`
+	DoseFrequency  int        `gorm:"not null;default:0"            json:"dose_frequency"`
+	DosePeriod     float64    `gorm:"not null;default:0"            json:"dose_period"`
+	DosePeriodUnit PeriodUnit `gorm:"type:text;not null;default:''" json:"dose_period_unit"`
+	DoseRoute      Route      `gorm:"type:text;not null;default:''" json:"dose_route"`
+	DoseAsNeeded   bool       `gorm:"not null;default:false"        json:"dose_as_needed"`
+}
+
+// IsZero reports whether nothing is known about the dose.
+func (dose Dose) IsZero() bool {
+	return dose == Dose{}
+}
+
+// MaxDosesPerDay is the most doses a day ValidateDose accepts.
+const MaxDosesPerDay = 24
+
+// DosesPerDay returns how many times a day the dose is taken, or 0 if its
+// frequency is unknown.
+func (dose Dose) DosesPerDay() float64 {
+	days := dose.DosePeriod * dose.DosePeriodUnit.days()
+	if dose.DoseFrequency == 0 || days == 0 {
+		return 0
+	}
+	return float64(dose.DoseFrequency) / days
+}
+
+var ErrInvalidDosage = errors.New("invalid dosage")
+
+// ParseDoseUnit returns the dose unit named by s, ignoring case and
+// accepting plurals and common abbreviations such as "mcg" and "IU".
+func ParseDoseUnit(s string) (DoseUnit, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for _, unit := range DoseUnits {
+		if name == strings.ToLower(string(unit)) {
+			return unit, nil
+		}
+	}
+	if unit, ok := doseUnitAliases[name]; ok {
+		return unit, nil
+	}
+	return "", fmt.Errorf("%w: unknown unit %q", ErrInvalidDosage, s)
+}
+
+// ParseRoute returns the route named by s, ignoring case and accepting
+// abbreviations such as "PO" and phrases such as "by mouth".
+func ParseRoute(s string) (Route, error) {
+	name := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	if validator.PermittedValue(Route(name), Routes...) {
+		return Route(name), nil
+	}
+	if route, ok := routeAliases[name]; ok {
+		return route, nil
+	}
+	return "", fmt.Errorf("%w: unknown route %q", ErrInvalidDosage, s)
+}
+
+type doseFrequency struct {
+	frequency int
+	period    float64
+	unit      PeriodUnit
+}
+
+// frequencyPhrases are the phrases that give a frequency on their own.
+var frequencyPhrases = map[string]doseFrequency{
+	"daily":               {1, 1, PeriodDay},
+	"every day":           {1, 1, PeriodDay},
+	"a day":               {1, 1, PeriodDay},
+	"per day":             {1, 1, PeriodDay},
+	"each day":            {1, 1, PeriodDay},
+	"qd":                  {1, 1, PeriodDay},
+	"od":                  {1, 1, PeriodDay},
+	"nightly":             {1, 1, PeriodDay},
+	"at night":            {1, 1, PeriodDay},
+	"at bedtime":          {1, 1, PeriodDay},
+	"before bed":          {1, 1, PeriodDay},
+	"qhs":                 {1, 1, PeriodDay},
+	"hs":                  {1, 1, PeriodDay},
+	"in the morning":      {1, 1, PeriodDay},
+	"every morning":       {1, 1, PeriodDay},
+	"in the evening":      {1, 1, PeriodDay},
+	"every evening":       {1, 1, PeriodDay},
+	"every night":         {1, 1, PeriodDay},
+	"qam":                 {1, 1, PeriodDay},
+	"qpm":                 {1, 1, PeriodDay},
+	"morning and night":   {2, 1, PeriodDay},
+	"morning and evening": {2, 1, PeriodDay},
+	"bid":                 {2, 1, PeriodDay},
+	"bd":                  {2, 1, PeriodDay},
+	"tid":                 {3, 1, PeriodDay},
+	"tds":                 {3, 1, PeriodDay},
+	"qid":                 {4, 1, PeriodDay},
+	"qds":                 {4, 1, PeriodDay},
+	"every other day":     {1, 2, PeriodDay},
+	"on alternate days":   {1, 2, PeriodDay},
+	"qod":                 {1, 2, PeriodDay},
+	"hourly":              {1, 1, PeriodHour},
+	"every hour":          {1, 1, PeriodHour},
+	"weekly":              {1, 1, PeriodWeek},
+	"every week":          {1, 1, PeriodWeek},
+	"once a week":         {1, 1, PeriodWeek},
+	"a week":              {1, 1, PeriodWeek},
+	"per week":            {1, 1, PeriodWeek},
+	"monthly":             {1, 1, PeriodMonth},
+	"every month":         {1, 1, PeriodMonth},
+}
+
+// repeatPhrases end a frequency given as a count, as in "twice a day" or
+// "3 times weekly".
+var repeatPhrases = map[string]PeriodUnit{
+	"daily":     PeriodDay,
+	"a day":     PeriodDay,
+	"per day":   PeriodDay,
+	"each day":  PeriodDay,
+	"every day": PeriodDay,
+	"hourly":    PeriodHour,
+	"an hour":   PeriodHour,
+	"per hour":  PeriodHour,
+	"weekly":    PeriodWeek,
+	"a week":    PeriodWeek,
+	"per week":  PeriodWeek,
+	"monthly":   PeriodMonth,
+	"a month":   PeriodMonth,
+	"per month": PeriodMonth,
+}
+
+// intervalUnits are the units of a frequency given as an interval, as in
+// "every 8 hours".
+var intervalUnits = map[string]PeriodUnit{
+	"h":     PeriodHour,
+	"hr":    PeriodHour,
+	"hrs":   PeriodHour,
+	"hour":  PeriodHour,
+	"hours": PeriodHour,
+	"d":     PeriodDay,
+	"day":   PeriodDay,
+	"days":  PeriodDay,
+	"wk":    PeriodWeek,
+	"week":  PeriodWeek,
+	"weeks": PeriodWeek,
+}
+
+var asNeededPhrases = map[string]bool{
+	"as needed":     true,
+	"when needed":   true,
+	"if needed":     true,
+	"as required":   true,
+	"when required": true,
+	"prn":           true,
+}
+
+// dosageFillers are words and instructions that carry nothing the
+// structured dose records.
+var dosageFillers = map[string]bool{
+	"take":                true,
+	"taken":               true,
+	"use":                 true,
+	"apply":               true,
+	"give":                true,
+	"of":                  true,
+	"with food":           true,
+	"with meals":          true,
+	"with a meal":         true,
+	"with water":          true,
+	"on an empty stomach": true,
+}
+
+var (
+	countWords  = map[string]int{"once": 1, "twice": 2, "thrice": 3}
+	numberWords = map[string]float64{
+		"half": 0.5, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+		"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+	}
+)
+
+var (
+	dosageThousandsRX = regexp.MustCompile(`(\d),(\d{3})`)
+	dosageDotsRX      = regexp.MustCompile(`([\pL])\.`)
+	dosageHyphenRX    = regexp.MustCompile(`([\pL])-([\pL])`)
+	dosageSlashRX     = regexp.MustCompile(`/([\pL])`)
+	dosageNumberRX    = regexp.MustCompile(`\b(\d+(?:\.\d+)?)([\pL\[{])`)
+	dosageIntervalRX  = regexp.MustCompile(`^q(\d+)(h|d)$`)
+	dosageRangeRX     = regexp.MustCompile(`^\d+(?:\.\d+)?-\d+(?:\.\d+)?$`)
+)
+
+// tokenizeDosage lower-cases s and splits it into words, separating
+// numbers from the units glued to them and dropping the dots of
+// abbreviations such as "b.i.d.".
+func tokenizeDosage(s string) []string {
+	s = strings.ToLower(s)
+	s = dosageThousandsRX.ReplaceAllString(s, "$1$2")
+	s = dosageDotsRX.ReplaceAllString(s, "$1")
+	s = dosageHyphenRX.ReplaceAllString(s, "$1 $2")
+	s = dosageSlashRX.ReplaceAllString(s, " per $1")
+	s = dosageNumberRX.ReplaceAllString(s, "$1 $2")
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return strings.ContainsRune(" \t\n,;:()", r)
+	})
+}
+
+// matchPhrase looks for the longest phrase of phrases, up to four words,
+// at the start of tokens and returns its value and length in words.
+func matchPhrase[T any](tokens []string, phrases map[string]T) (T, int, bool) {
+	for n := min(4, len(tokens)); n > 0; n-- {
+		if value, ok := phrases[strings.Join(tokens[:n], " ")]; ok {
+			return value, n, true
+		}
+	}
+	var zero T
+	return zero, 0, false
+}
+
+func parseDoseNumber(token string) (float64, bool) {
+	if n, ok := numberWords[token]; ok {
+		return n, true
+	}
+	if numerator, denominator, ok := strings.Cut(token, "/"); ok {
+		n, err := strconv.ParseFloat(numerator, 64)
+		d, derr := strconv.ParseFloat(denominator, 64)
+		if err != nil || derr != nil || d == 0 {
+			return 0, false
+		}
+		return n / d, true
+	}
+	if token == "" || token[0] < '0' || token[0] > '9' {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(token, 64)
+	return n, err == nil
+}
+
+// ParseDosage reads a free-text dosage such as "25mg", "2 capsules twice
+// daily" or "1 puff inhaled every 4 hours as needed" into a Dose. Units are
+// normalized to their UCUM codes. Instructions such as "with food" are
+// ignored; any other word that is not understood, or a part given twice,
+// makes the dosage invalid.
+func ParseDosage(s string) (Dose, error) {
+	var dose Dose
+	invalid := func(format string, args ...any) (Dose, error) {
+		return Dose{}, fmt.Errorf("%w: %q: %s", ErrInvalidDosage, s, fmt.Sprintf(format, args...))
+	}
+
+	tokens := tokenizeDosage(s)
+	if len(tokens) == 0 {
+		return invalid("nothing to parse")
+	}
+
+	setFrequency := func(frequency doseFrequency) bool {
+		if dose.DoseFrequency != 0 {
+			return false
+		}
+		dose.DoseFrequency, dose.DosePeriod, dose.DosePeriodUnit = frequency.frequency, frequency.period, frequency.unit
+		return true
+	}
+
+	for i := 0; i < len(tokens); {
+		rest := tokens[i:]
+		token := rest[0]
+
+		if match := dosageIntervalRX.FindStringSubmatch(token); match != nil {
+			period, _ := strconv.ParseFloat(match[1], 64)
+			if period == 0 || !setFrequency(doseFrequency{1, period, intervalUnits[match[2]]}) {
+				return invalid("unexpected %q", token)
+			}
+			i++
+			continue
+		}
+
+		if (token == "every" || token == "q") && len(rest) >= 3 {
+			period, isNumber := parseDoseNumber(rest[1])
+			unit, isUnit := intervalUnits[rest[2]]
+			if isNumber && isUnit {
+				if period == 0 || !setFrequency(doseFrequency{1, period, unit}) {
+					return invalid("unexpected %q", strings.Join(rest[:3], " "))
+				}
+				i += 3
+				continue
+			}
+		}
+
+		count, isCount := countWords[token]
+		n, isNumber := parseDoseNumber(token)
+		if isNumber && len(rest) > 1 && (rest[1] == "times" || rest[1] == "time" || rest[1] == "x") {
+			count, isCount = int(n), n == float64(int(n)) && n > 0
+			if !isCount {
+				return invalid("%q is not a whole number of times", token)
+			}
+			rest, i = rest[1:], i+1
+		}
+		if isCount {
+			unit, length, ok := matchPhrase(rest[1:], repeatPhrases)
+			if !ok {
+				if frequency, length, ok := matchPhrase(rest, frequencyPhrases); ok {
+					if !setFrequency(frequency) {
+						return invalid("more than one frequency")
+					}
+					i += length
+					continue
+				}
+				return invalid("%q is not followed by a period such as \"a day\"", token)
+			}
+			if !setFrequency(doseFrequency{count, 1, unit}) {
+				return invalid("more than one frequency")
+			}
+			i += 1 + length
+			continue
+		}
+
+		if isNumber {
+			if len(rest) < 2 {
+				return invalid("%q has no unit", token)
+			}
+			unit, err := ParseDoseUnit(rest[1])
+			if err != nil {
+				return invalid("unknown unit %q", rest[1])
+			}
+			if dose.DoseAmount != 0 {
+				return invalid("more than one amount")
+			}
+			if n == 0 {
+				return invalid("amount must be greater than zero")
+			}
+			dose.DoseAmount, dose.DoseUnit = n, unit
+			i += 2
+			if unit == DoseInternationalUnit && i < len(tokens) && (tokens[i] == "units" || tokens[i] == "unit") {
+				i++
+			}
+			continue
+		}
+
+		if frequency, length, ok := matchPhrase(rest, frequencyPhrases); ok {
+			if !setFrequency(frequency) {
+				return invalid("more than one frequency")
+			}
+			i += length
+			continue
+		}
+		if _, length, ok := matchPhrase(rest, asNeededPhrases); ok {
+			dose.DoseAsNeeded = true
+			i += length
+			continue
+		}
+		if _, length, ok := matchPhrase(rest, dosageFillers); ok {
+			i += length
+			continue
+		}
+		if route, length, ok := matchRoute(rest); ok {
+			if dose.DoseRoute != "" && dose.DoseRoute != route {
+				return invalid("more than one route")
+			}
+			dose.DoseRoute = route
+			i += length
+			continue
+		}
+
+		if dosageRangeRX.MatchString(token) {
+			return invalid("ranges such as %q are not supported", token)
+		}
+		return invalid("unexpected %q", token)
+	}
+
+	return dose, nil
+}
+
+// matchRoute matches the longest route phrase at the start of tokens.
+func matchRoute(tokens []string) (Route, int, bool) {
+	for n := min(4, len(tokens)); n > 0; n-- {
+		if route, err := ParseRoute(strings.Join(tokens[:n], " ")); err == nil {
+			return route, n, true
+		}
+	}
+	return "", 0, false
+}
+
+// ValidateDose checks a structured dose. Every part is optional, but an
+// amount needs a unit and a frequency needs a period.
+func ValidateDose(v *validator.Validator, dose Dose) {
+	v.Check(dose.DoseAmount >= 0, "dose_amount", "must not be negative")
+	if dose.DoseAmount > 0 || dose.DoseUnit != "" {
+		v.Check(dose.DoseAmount > 0, "dose_amount", "must be greater than zero")
+		v.Check(validator.PermittedValue(dose.DoseUnit, DoseUnits...), "dose_unit", "must be a supported unit")
+	}
+
+	v.Check(dose.DoseFrequency >= 0, "dose_frequency", "must not be negative")
+	if dose.DoseFrequency > 0 || dose.DosePeriod != 0 || dose.DosePeriodUnit != "" {
+		v.Check(dose.DoseFrequency > 0, "dose_frequency", "must be greater than zero")
+		v.Check(dose.DosePeriod > 0, "dose_period", "must be greater than zero")
+		v.Check(
+			validator.PermittedValue(dose.DosePeriodUnit, PeriodUnits...),
+			"dose_period_unit",
+			"must be h, d, wk or mo",
+		)
+		v.Check(
+			dose.DosesPerDay() <= MaxDosesPerDay,
+			"dose_frequency",
+			fmt.Sprintf("must not be more than %d doses a day", MaxDosesPerDay),
+		)
+	}
+
+	if dose.DoseRoute != "" {
+		v.Check(validator.PermittedValue(dose.DoseRoute, Routes...), "dose_route", "must be a supported route")
+	}
+}
+
+// validationProblems lists the errors of v as "field message" pairs, sorted
+// by field.
+func validationProblems(v *validator.Validator) string {
+	problems := make([]string, 0, len(v.Errors))
+	for field, message := range v.Errors {
+		problems = append(problems, field+" "+message)
+	}
+	sort.Strings(problems)
+	return strings.Join(problems, "; ")
+}
+
+// DosageIssue is a dosage BackfillDosages could not interpret. Record is
+// "medication" or "dietary_supplement".
+type DosageIssue struct {
+	Record  string `json:"record"`
+	ID      int64  `json:"id"`
+	UserID  int64  `json:"user_id"`
+	Name    string `json:"name"`
+	Dosage  string `json:"dosage"`
+	Message string `json:"message"`
+}
+
+func (issue DosageIssue) String() string {
+	return fmt.Sprintf("%s %d (%s): %s", issue.Record, issue.ID, issue.Name, issue.Message)
+}
+
+// DosageBackfillReport describes the outcome of BackfillDosages. Parsed
+// counts the records given a structured dose, or that would have been in a
+// dry run, and Skipped those with no dosage or a dose already set.
+type DosageBackfillReport struct {
+	Parsed   int           `json:"parsed"`
+	Skipped  int           `json:"skipped"`
+	Unparsed []DosageIssue `json:"unparsed"`
+}
+
+type DosageBackfillOptions struct {
+	// DryRun rolls the backfill back once the report is complete.
+	DryRun bool
+	// Overwrite parses dosages again even if a dose is already set.
+	Overwrite bool
+}
+
+// BackfillDosages parses the free-text dosage of every medication and
+// dietary supplement into its structured dose, in a single transaction.
+// A dose already set is only parsed again if it fails ValidateDose.
+// Dosages that cannot be parsed, or whose record would then fail
+// ValidateMedication or ValidateDietarySupplement, are left alone and
+// reported.
+func (stores *Stores) BackfillDosages(
+	ctx context.Context,
+	options DosageBackfillOptions,
+) (*DosageBackfillReport, error) {
+	report := &DosageBackfillReport{}
+
+	// backfill parses dosage into dose, reporting whether the record
+	// should be saved. validate checks the record with the parsed dose.
+	backfill := func(
+		record string,
+		id, userID int64,
+		name, dosage string,
+		dose *Dose,
+		validate func(v *validator.Validator),
+	) bool {
+		v := validator.New()
+		ValidateDose(v, *dose)
+		if strings.TrimSpace(dosage) == "" || (!dose.IsZero() && v.Valid() && !options.Overwrite) {
+			report.Skipped++
+			return false
+		}
+
+		previous := *dose
+		parsed, err := ParseDosage(dosage)
+		if err == nil {
+			*dose = parsed
+			v := validator.New()
+			validate(v)
+			if !v.Valid() {
+				*dose = previous
+				err = fmt.Errorf("%w: %s", ErrInvalidDosage, validationProblems(v))
+			}
+		}
+		if err != nil {
+			report.Unparsed = append(report.Unparsed, DosageIssue{
+				Record:  record,
+				ID:      id,
+				UserID:  userID,
+				Name:    name,
+				Dosage:  dosage,
+				Message: err.Error(),
+			})
+			return false
+		}
+		report.Parsed++
+		return true
+	}
+
+	err := stores.WithTx(ctx, func(tx *Stores) error {
+		users, err := tx.UserStore.ListUsers(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			medications, err := tx.MedicationStore.ListUserMedications(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			for _, m := range medications {
+				validate := func(v *validator.Validator) { ValidateMedication(v, m) }
+				if !backfill("medication", m.ID, m.UserID, m.Name, m.Dosage, &m.Dose, validate) {
+					continue
+				}
+				if err := tx.MedicationStore.UpdateMedication(ctx, m); err != nil {
+					return fmt.Errorf("medication %d: %w", m.ID, err)
+				}
+			}
+
+			supplements, err := tx.DietarySupplementStore.ListUserDietarySupplements(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			for _, s := range supplements {
+				validate := func(v *validator.Validator) { ValidateDietarySupplement(v, s) }
+				if !backfill("dietary_supplement", s.ID, s.UserID, s.Name, s.Dosage, &s.Dose, validate) {
+					continue
+				}
+				if err := tx.DietarySupplementStore.UpdateDietarySupplement(ctx, s); err != nil {
+					return fmt.Errorf("dietary supplement %d: %w", s.ID, err)
+				}
+			}
+		}
+
+		if options.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,201 @@
+package data_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+	"github.com/Universal-Selfcare/utils/validator"
+)
+
+func TestParseDosage(t *testing.T) {
+	daily := func(times int) data.Dose {
+		return data.Dose{DoseFrequency: times, DosePeriod: 1, DosePeriodUnit: data.PeriodDay}
+	}
+	with := func(dose data.Dose, amount float64, unit data.DoseUnit) data.Dose {
+		dose.DoseAmount, dose.DoseUnit = amount, unit
+		return dose
+	}
+
+	tests := []struct {
+		in   string
+		want data.Dose
+	}{
+		{"25mg", data.Dose{DoseAmount: 25, DoseUnit: data.DoseMilligram}},
+		{"500 MG twice daily", with(daily(2), 500, data.DoseMilligram)},
+		{"2 capsules twice daily", with(daily(2), 2, data.DoseCapsule)},
+		{"1,000 IU daily", with(daily(1), 1000, data.DoseInternationalUnit)},
+		{"1000 international units a day", with(daily(1), 1000, data.DoseInternationalUnit)},
+		{"50 mcg once a day", with(daily(1), 50, data.DoseMicrogram)},
+		{"0.5 tab b.i.d.", with(daily(2), 0.5, data.DoseTablet)},
+		{"half tablet at bedtime", with(daily(1), 0.5, data.DoseTablet)},
+		{"1/2 tablet qhs", with(daily(1), 0.5, data.DoseTablet)},
+		{"two tablets 3 times a day with food", with(daily(3), 2, data.DoseTablet)},
+		{"5 mL tid", with(daily(3), 5, data.DoseMilliliter)},
+		{"10 mg PO qd", data.Dose{
+			DoseAmount: 10, DoseUnit: data.DoseMilligram, DoseRoute: data.RouteOral,
+			DoseFrequency: 1, DosePeriod: 1, DosePeriodUnit: data.PeriodDay,
+		}},
+		{"1 puff inhaled every 4 hours as needed", data.Dose{
+			DoseAmount: 1, DoseUnit: data.DosePuff, DoseRoute: data.RouteInhaled,
+			DoseFrequency: 1, DosePeriod: 4, DosePeriodUnit: data.PeriodHour, DoseAsNeeded: true,
+		}},
+		{"2 drops in each eye q8h", data.Dose{
+			DoseAmount: 2, DoseUnit: data.DoseDrop, DoseRoute: data.RouteOphthalmic,
+			DoseFrequency: 1, DosePeriod: 8, DosePeriodUnit: data.PeriodHour,
+		}},
+		{"1 patch weekly", data.Dose{
+			DoseAmount: 1, DoseUnit: data.DosePatch,
+			DoseFrequency: 1, DosePeriod: 1, DosePeriodUnit: data.PeriodWeek,
+		}},
+		{"take 1 tablet every other day", data.Dose{
+			DoseAmount: 1, DoseUnit: data.DoseTablet,
+			DoseFrequency: 1, DosePeriod: 2, DosePeriodUnit: data.PeriodDay,
+		}},
+		{"1 scoop 3x per week", data.Dose{
+			DoseAmount: 1, DoseUnit: data.DoseScoop,
+			DoseFrequency: 3, DosePeriod: 1, DosePeriodUnit: data.PeriodWeek,
+		}},
+		{"10 mEq/day", with(daily(1), 10, data.DoseMilliequivalent)},
+		{"PRN", data.Dose{DoseAsNeeded: true}},
+		{"Once daily", daily(1)},
+	}
+	for _, test := range tests {
+		got, err := data.ParseDosage(test.in)
+		if err != nil {
+			t.Errorf("ParseDosage(%q): %v", test.in, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseDosage(%q) = %+v, want %+v", test.in, got, test.want)
+		}
+		v := validator.New()
+		data.ValidateDose(v, got)
+		if !v.Valid() {
+			t.Errorf("ParseDosage(%q) = %+v, which is not valid: %v", test.in, got, v.Errors)
+		}
+	}
+}
+
+func TestParseDosageInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"  ",
+		"as directed",
+		"25",
+		"25 furlongs",
+		"0 mg",
+		"1-2 tablets",
+		"500 mg 250 mg",
+		"daily twice daily",
+		"every 0 hours",
+		"1.5 times a day",
+		"twice",
+		"1 tablet orally IV",
+	} {
+		got, err := data.ParseDosage(in)
+		if !errors.Is(err, data.ErrInvalidDosage) {
+			t.Errorf("ParseDosage(%q) = %+v, %v, want %v", in, got, err, data.ErrInvalidDosage)
+		}
+	}
+}
+
+func TestParseDoseUnit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want data.DoseUnit
+	}{
+		{"mg", data.DoseMilligram},
+		{"MCG", data.DoseMicrogram},
+		{"µg", data.DoseMicrogram},
+		{"mL", data.DoseMilliliter},
+		{"cc", data.DoseMilliliter},
+		{"[iU]", data.DoseInternationalUnit},
+		{" Softgels ", data.DoseCapsule},
+		{"{tablet}", data.DoseTablet},
+	}
+	for _, test := range tests {
+		if got, err := data.ParseDoseUnit(test.in); err != nil || got != test.want {
+			t.Errorf("ParseDoseUnit(%q) = %q, %v, want %q", test.in, got, err, test.want)
+		}
+	}
+	for _, in := range []string{"", "furlong", "mgg"} {
+		if _, err := data.ParseDoseUnit(in); !errors.Is(err, data.ErrInvalidDosage) {
+			t.Errorf("ParseDoseUnit(%q): got error %v, want %v", in, err, data.ErrInvalidDosage)
+		}
+	}
+}
+
+func TestDosesPerDay(t *testing.T) {
+	tests := []struct {
+		dose data.Dose
+		want float64
+	}{
+		{data.Dose{}, 0},
+		{data.Dose{DoseFrequency: 2, DosePeriod: 1, DosePeriodUnit: data.PeriodDay}, 2},
+		{data.Dose{DoseFrequency: 1, DosePeriod: 8, DosePeriodUnit: data.PeriodHour}, 3},
+		{data.Dose{DoseFrequency: 1, DosePeriod: 2, DosePeriodUnit: data.PeriodDay}, 0.5},
+		{data.Dose{DoseFrequency: 7, DosePeriod: 1, DosePeriodUnit: data.PeriodWeek}, 1},
+		{data.Dose{DoseFrequency: 1, DosePeriod: 1}, 0},
+	}
+	for _, test := range tests {
+		if got := test.dose.DosesPerDay(); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%+v.DosesPerDay() = %g, want %g", test.dose, got, test.want)
+		}
+	}
+}
+
+func TestValidateMedication(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		medication data.Medication
+		want       []string
+	}{
+		{
+			name: "valid",
+			medication: data.Medication{
+				UserID: 1,
+				Name:   "Metformin",
+				Dose:   data.Dose{DoseAmount: 500, DoseUnit: data.DoseMilligram},
+			},
+		},
+		{name: "no dose", medication: data.Medication{UserID: 1, Name: "Metformin"}},
+		{name: "missing user and name", medication: data.Medication{Name: " "}, want: []string{"name", "user_id"}},
+		{
+			name:       "end before start",
+			medication: data.Medication{UserID: 1, Name: "Metformin", StartDate: start, EndDate: start},
+			want:       []string{"end_date"},
+		},
+		{
+			name: "invalid dose",
+			medication: data.Medication{UserID: 1, Name: "Metformin", Dose: data.Dose{
+				DoseAmount: 5, DoseUnit: "foo", DoseFrequency: 1, DosePeriod: -1, DosePeriodUnit: data.PeriodDay,
+			}},
+			want: []string{"dose_period", "dose_unit"},
+		},
+		{
+			name: "too frequent",
+			medication: data.Medication{UserID: 1, Name: "Metformin", Dose: data.Dose{
+				DoseFrequency: 1_000_000, DosePeriod: 1, DosePeriodUnit: data.PeriodHour,
+			}},
+			want: []string{"dose_frequency"},
+		},
+	}
+	for _, test := range tests {
+		v := validator.New()
+		data.ValidateMedication(v, &test.medication)
+		var got []string
+		for field := range v.Errors {
+			got = append(got, field)
+		}
+		sort.Strings(got)
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("%s: got errors on %v, want %v", test.name, got, test.want)
+		}
+	}
+}
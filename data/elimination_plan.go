@@ -0,0 +1,161 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/validator"
+)
+
+// EliminationPlan is a dietitian-led elimination diet, usually started after
+// a tracking period has identified suspect foods. The restricted foods are
+// avoided from StartDate, then brought back one at a time following the
+// reintroduction schedule from ReintroductionDate until EndDate.
+type EliminationPlan struct {
+	ID                 int64     `gorm:"primaryKey"     json:"id"`
+	UserID             int64     `gorm:"not null;index" json:"user_id"`
+	TrackingPeriodID   int64     `gorm:"index"          json:"tracking_period_id"` // 0 if not based on a period
+	Name               string    `gorm:"type:text"      json:"name"`
+	StartDate          time.Time `gorm:"not null"       json:"start_date"`
+	ReintroductionDate time.Time `gorm:"not null"       json:"reintroduction_date"`
+	EndDate            time.Time `gorm:"not null"       json:"end_date"`
+	Notes              string    `gorm:"type:text"      json:"notes"`
+	CreatedAt          time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// EliminationRestriction is a food item, or a whole FoodItem.Category, to
+// avoid while a plan is active. Exactly one of FoodItemID and Category is set.
+type EliminationRestriction struct {
+	ID         int64     `gorm:"primaryKey"     json:"id"`
+	PlanID     int64     `gorm:"not null;index" json:"plan_id"`
+	FoodItemID int64     `gorm:"index"          json:"food_item_id"`
+	Category   string    `gorm:"type:text"      json:"category"`
+	Reason     string    `gorm:"type:text"      json:"reason"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+const (
+	ReintroductionPending   = "pending"
+	ReintroductionTolerated = "tolerated"
+	ReintroductionReacted   = "reacted"
+)
+
+// ReintroductionStep schedules a restricted food item or category to be
+// eaten again between StartDate and EndDate. Once the challenge window has
+// passed, the food stays allowed unless Outcome is ReintroductionReacted.
+type ReintroductionStep struct {
+	ID         int64     `gorm:"primaryKey"                  json:"id"`
+	PlanID     int64     `gorm:"not null;index"              json:"plan_id"`
+	FoodItemID int64     `gorm:"index"                       json:"food_item_id"`
+	Category   string    `gorm:"type:text"                   json:"category"`
+	StartDate  time.Time `gorm:"not null"                    json:"start_date"`
+	EndDate    time.Time `gorm:"not null"                    json:"end_date"`
+	Outcome    string    `gorm:"type:text;default:'pending'" json:"outcome"`
+	Notes      string    `gorm:"type:text"                   json:"notes"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"              json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"              json:"updated_at"`
+}
+
+// IsActive reports whether at falls within the plan, from the start of the
+// elimination phase until the end of reintroduction.
+func (plan *EliminationPlan) IsActive(at time.Time) bool {
+	return !at.Before(plan.StartDate) && at.Before(plan.EndDate)
+}
+
+// Allows reports whether the step lets the food back into the diet at the
+// given time: during its challenge window, and afterwards unless the user
+// reacted to it.
+func (step *ReintroductionStep) Allows(at time.Time) bool {
+	if at.Before(step.StartDate) {
+		return false
+	}
+	return at.Before(step.EndDate) || step.Outcome != ReintroductionReacted
+}
+
+// EliminationPlanStore provides database operations for elimination plans
+type EliminationPlanStore interface {
+	CreateEliminationPlan(ctx context.Context, plan *EliminationPlan) (*EliminationPlan, error)
+	GetEliminationPlan(ctx context.Context, id int64) (*EliminationPlan, error)
+	GetActiveEliminationPlan(ctx context.Context, userID int64, at time.Time) (*EliminationPlan, error)
+	ListUserEliminationPlans(ctx context.Context, userID int64) ([]*EliminationPlan, error)
+	UpdateEliminationPlan(ctx context.Context, plan *EliminationPlan) error
+	DeleteEliminationPlan(ctx context.Context, id int64) error
+}
+
+// EliminationRestrictionStore provides database operations for elimination restrictions
+type EliminationRestrictionStore interface {
+	CreateEliminationRestriction(
+		ctx context.Context,
+		restriction *EliminationRestriction,
+	) (*EliminationRestriction, error)
+	ListEliminationRestrictions(ctx context.Context, planID int64) ([]*EliminationRestriction, error)
+	DeleteEliminationRestriction(ctx context.Context, id int64) error
+	DeleteAllEliminationRestrictionsForPlan(ctx context.Context, planID int64) error
+}
+
+// ReintroductionStepStore provides database operations for reintroduction steps
+type ReintroductionStepStore interface {
+	CreateReintroductionStep(ctx context.Context, step *ReintroductionStep) (*ReintroductionStep, error)
+	GetReintroductionStep(ctx context.Context, id int64) (*ReintroductionStep, error)
+	ListReintroductionSteps(ctx context.Context, planID int64) ([]*ReintroductionStep, error)
+	UpdateReintroductionStep(ctx context.Context, step *ReintroductionStep) error
+	DeleteReintroductionStep(ctx context.Context, id int64) error
+	DeleteAllReintroductionStepsForPlan(ctx context.Context, planID int64) error
+}
+
+func ValidateEliminationPlan(v *validator.Validator, plan *EliminationPlan) {
+	v.Check(plan.UserID != 0, "user_id", "must be provided")
+	v.Check(!plan.StartDate.IsZero(), "start_date", "must be provided")
+	v.Check(!plan.ReintroductionDate.IsZero(), "reintroduction_date", "must be provided")
+	v.Check(!plan.EndDate.IsZero(), "end_date", "must be provided")
+	v.Check(
+		plan.ReintroductionDate.After(plan.StartDate),
+		"reintroduction_date",
+		"must be after the start date",
+	)
+	v.Check(
+		!plan.EndDate.Before(plan.ReintroductionDate),
+		"end_date",
+		"must not be before the reintroduction date",
+	)
+}
+
+func ValidateEliminationRestriction(v *validator.Validator, restriction *EliminationRestriction) {
+	v.Check(
+		(restriction.FoodItemID != 0) != (restriction.Category != ""),
+		"food_item_id",
+		"exactly one of food_item_id and category must be provided",
+	)
+}
+
+func ValidateReintroductionStep(
+	v *validator.Validator,
+	step *ReintroductionStep,
+	plan *EliminationPlan,
+) {
+	v.Check(
+		(step.FoodItemID != 0) != (step.Category != ""),
+		"food_item_id",
+		"exactly one of food_item_id and category must be provided",
+	)
+	v.Check(step.EndDate.After(step.StartDate), "end_date", "must be after the start date")
+	v.Check(
+		!step.StartDate.Before(plan.ReintroductionDate),
+		"start_date",
+		"must not be before the plan's reintroduction date",
+	)
+	v.Check(!step.EndDate.After(plan.EndDate), "end_date", "must not be after the plan's end date")
+	v.Check(
+		validator.PermittedValue(
+			step.Outcome,
+			"",
+			ReintroductionPending,
+			ReintroductionTolerated,
+			ReintroductionReacted,
+		),
+		"outcome",
+		"must be pending, tolerated or reacted",
+	)
+}
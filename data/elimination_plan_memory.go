@@ -0,0 +1,323 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// MemoryEliminationPlanStore implements EliminationPlanStore interface
+type MemoryEliminationPlanStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryEliminationPlanStore(db *MemoryDB) *MemoryEliminationPlanStore {
+	return &MemoryEliminationPlanStore{db: db}
+}
+
+func (store *MemoryEliminationPlanStore) CreateEliminationPlan(
+	ctx context.Context,
+	plan *EliminationPlan,
+) (*EliminationPlan, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if plan.ID != 0 {
+		if _, exists := store.db.eliminationPlans.get(plan.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		plan.ID = store.db.eliminationPlans.nextID()
+	}
+	setCreateTimestamps(&plan.CreatedAt, &plan.UpdatedAt)
+	store.db.eliminationPlans.put(plan.ID, *plan)
+
+	return plan, nil
+}
+
+func (store *MemoryEliminationPlanStore) GetEliminationPlan(
+	ctx context.Context,
+	id int64,
+) (*EliminationPlan, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	plan, ok := store.db.eliminationPlans.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &plan, nil
+}
+
+func (store *MemoryEliminationPlanStore) GetActiveEliminationPlan(
+	ctx context.Context,
+	userID int64,
+	at time.Time,
+) (*EliminationPlan, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	plans := store.db.eliminationPlans.filter(func(row *EliminationPlan) bool {
+		return row.UserID == userID && row.IsActive(at)
+	})
+	if len(plans) == 0 {
+		return nil, ErrRecordNotFound
+	}
+	sortEliminationPlans(plans)
+	return plans[0], nil
+}
+
+func (store *MemoryEliminationPlanStore) ListUserEliminationPlans(
+	ctx context.Context,
+	userID int64,
+) ([]*EliminationPlan, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	plans := store.db.eliminationPlans.filter(func(row *EliminationPlan) bool {
+		return row.UserID == userID
+	})
+	sortEliminationPlans(plans)
+	return plans, nil
+}
+
+func (store *MemoryEliminationPlanStore) UpdateEliminationPlan(
+	ctx context.Context,
+	plan *EliminationPlan,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.eliminationPlans.get(plan.ID)
+	if plan.ID == 0 || !ok {
+		if plan.ID == 0 {
+			plan.ID = store.db.eliminationPlans.nextID()
+		}
+		setCreateTimestamps(&plan.CreatedAt, &plan.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&plan.CreatedAt, &plan.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.eliminationPlans.put(plan.ID, *plan)
+
+	return nil
+}
+
+func (store *MemoryEliminationPlanStore) DeleteEliminationPlan(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.eliminationPlans.delete(id)
+	return nil
+}
+
+// sortEliminationPlans orders plans by start date, newest first.
+func sortEliminationPlans(plans []*EliminationPlan) {
+	sort.SliceStable(plans, func(i, j int) bool {
+		return plans[i].StartDate.After(plans[j].StartDate)
+	})
+}
+
+// MemoryEliminationRestrictionStore implements EliminationRestrictionStore interface
+type MemoryEliminationRestrictionStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryEliminationRestrictionStore(db *MemoryDB) *MemoryEliminationRestrictionStore {
+	return &MemoryEliminationRestrictionStore{db: db}
+}
+
+func (store *MemoryEliminationRestrictionStore) CreateEliminationRestriction(
+	ctx context.Context,
+	restriction *EliminationRestriction,
+) (*EliminationRestriction, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if restriction.ID != 0 {
+		if _, exists := store.db.eliminationRestrictions.get(restriction.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		restriction.ID = store.db.eliminationRestrictions.nextID()
+	}
+	setCreateTimestamps(&restriction.CreatedAt, &restriction.UpdatedAt)
+	store.db.eliminationRestrictions.put(restriction.ID, *restriction)
+
+	return restriction, nil
+}
+
+func (store *MemoryEliminationRestrictionStore) ListEliminationRestrictions(
+	ctx context.Context,
+	planID int64,
+) ([]*EliminationRestriction, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.eliminationRestrictions.filter(func(row *EliminationRestriction) bool {
+		return row.PlanID == planID
+	}), nil
+}
+
+func (store *MemoryEliminationRestrictionStore) DeleteEliminationRestriction(
+	ctx context.Context,
+	id int64,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.eliminationRestrictions.delete(id)
+	return nil
+}
+
+func (store *MemoryEliminationRestrictionStore) DeleteAllEliminationRestrictionsForPlan(
+	ctx context.Context,
+	planID int64,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.eliminationRestrictions.deleteWhere(func(row *EliminationRestriction) bool {
+		return row.PlanID == planID
+	})
+	return nil
+}
+
+// MemoryReintroductionStepStore implements ReintroductionStepStore interface
+type MemoryReintroductionStepStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryReintroductionStepStore(db *MemoryDB) *MemoryReintroductionStepStore {
+	return &MemoryReintroductionStepStore{db: db}
+}
+
+func (store *MemoryReintroductionStepStore) CreateReintroductionStep(
+	ctx context.Context,
+	step *ReintroductionStep,
+) (*ReintroductionStep, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if step.ID != 0 {
+		if _, exists := store.db.reintroductionSteps.get(step.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		step.ID = store.db.reintroductionSteps.nextID()
+	}
+	// Mirror the column default.
+	if step.Outcome == "" {
+		step.Outcome = ReintroductionPending
+	}
+	setCreateTimestamps(&step.CreatedAt, &step.UpdatedAt)
+	store.db.reintroductionSteps.put(step.ID, *step)
+
+	return step, nil
+}
+
+func (store *MemoryReintroductionStepStore) GetReintroductionStep(
+	ctx context.Context,
+	id int64,
+) (*ReintroductionStep, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	step, ok := store.db.reintroductionSteps.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &step, nil
+}
+
+func (store *MemoryReintroductionStepStore) ListReintroductionSteps(
+	ctx context.Context,
+	planID int64,
+) ([]*ReintroductionStep, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	steps := store.db.reintroductionSteps.filter(func(row *ReintroductionStep) bool {
+		return row.PlanID == planID
+	})
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].StartDate.Before(steps[j].StartDate)
+	})
+	return steps, nil
+}
+
+func (store *MemoryReintroductionStepStore) UpdateReintroductionStep(
+	ctx context.Context,
+	step *ReintroductionStep,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.reintroductionSteps.get(step.ID)
+	if step.ID == 0 || !ok {
+		if step.ID == 0 {
+			step.ID = store.db.reintroductionSteps.nextID()
+		}
+		setCreateTimestamps(&step.CreatedAt, &step.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&step.CreatedAt, &step.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.reintroductionSteps.put(step.ID, *step)
+
+	return nil
+}
+
+func (store *MemoryReintroductionStepStore) DeleteReintroductionStep(
+	ctx context.Context,
+	id int64,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.reintroductionSteps.delete(id)
+	return nil
+}
+
+func (store *MemoryReintroductionStepStore) DeleteAllReintroductionStepsForPlan(
+	ctx context.Context,
+	planID int64,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.reintroductionSteps.deleteWhere(func(row *ReintroductionStep) bool {
+		return row.PlanID == planID
+	})
+	return nil
+}
@@ -0,0 +1,252 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresEliminationPlanStore implements EliminationPlanStore interface
+type PostgresEliminationPlanStore struct {
+	DB      *gorm.DB
+	Timeout time.Duration
+}
+
+func NewPostgresEliminationPlanStore(db *gorm.DB) *PostgresEliminationPlanStore {
+	return &PostgresEliminationPlanStore{DB: db}
+}
+
+func (store *PostgresEliminationPlanStore) CreateEliminationPlan(
+	ctx context.Context,
+	plan *EliminationPlan,
+) (*EliminationPlan, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(plan).Error
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (store *PostgresEliminationPlanStore) GetEliminationPlan(
+	ctx context.Context,
+	id int64,
+) (*EliminationPlan, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var plan EliminationPlan
+	err := db.First(&plan, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// GetActiveEliminationPlan returns the plan running at the given time. If
+// plans overlap, the one that started last wins.
+func (store *PostgresEliminationPlanStore) GetActiveEliminationPlan(
+	ctx context.Context,
+	userID int64,
+	at time.Time,
+) (*EliminationPlan, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var plan EliminationPlan
+	err := db.Where("user_id = ? AND start_date <= ? AND end_date > ?", userID, at, at).
+		Order("start_date DESC").
+		First(&plan).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (store *PostgresEliminationPlanStore) ListUserEliminationPlans(
+	ctx context.Context,
+	userID int64,
+) ([]*EliminationPlan, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var plans []*EliminationPlan
+	err := db.Where("user_id = ?", userID).Order("start_date DESC").Find(&plans).Error
+	if err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (store *PostgresEliminationPlanStore) UpdateEliminationPlan(
+	ctx context.Context,
+	plan *EliminationPlan,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	return db.Save(plan).Error
+}
+
+func (store *PostgresEliminationPlanStore) DeleteEliminationPlan(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	return db.Delete(&EliminationPlan{}, id).Error
+}
+
+// PostgresEliminationRestrictionStore implements EliminationRestrictionStore interface
+type PostgresEliminationRestrictionStore struct {
+	DB      *gorm.DB
+	Timeout time.Duration
+}
+
+func NewPostgresEliminationRestrictionStore(db *gorm.DB) *PostgresEliminationRestrictionStore {
+	return &PostgresEliminationRestrictionStore{DB: db}
+}
+
+func (store *PostgresEliminationRestrictionStore) CreateEliminationRestriction(
+	ctx context.Context,
+	restriction *EliminationRestriction,
+) (*EliminationRestriction, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(restriction).Error
+	if err != nil {
+		return nil, err
+	}
+	return restriction, nil
+}
+
+func (store *PostgresEliminationRestrictionStore) ListEliminationRestrictions(
+	ctx context.Context,
+	planID int64,
+) ([]*EliminationRestriction, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var restrictions []*EliminationRestriction
+	err := db.Where("plan_id = ?", planID).Order("id").Find(&restrictions).Error
+	if err != nil {
+		return nil, err
+	}
+	return restrictions, nil
+}
+
+func (store *PostgresEliminationRestrictionStore) DeleteEliminationRestriction(
+	ctx context.Context,
+	id int64,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	return db.Delete(&EliminationRestriction{}, id).Error
+}
+
+func (store *PostgresEliminationRestrictionStore) DeleteAllEliminationRestrictionsForPlan(
+	ctx context.Context,
+	planID int64,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	return db.Where("plan_id = ?", planID).Delete(&EliminationRestriction{}).Error
+}
+
+// PostgresReintroductionStepStore implements ReintroductionStepStore interface
+type PostgresReintroductionStepStore struct {
+	DB      *gorm.DB
+	Timeout time.Duration
+}
+
+func NewPostgresReintroductionStepStore(db *gorm.DB) *PostgresReintroductionStepStore {
+	return &PostgresReintroductionStepStore{DB: db}
+}
+
+func (store *PostgresReintroductionStepStore) CreateReintroductionStep(
+	ctx context.Context,
+	step *ReintroductionStep,
+) (*ReintroductionStep, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(step).Error
+	if err != nil {
+		return nil, err
+	}
+	return step, nil
+}
+
+func (store *PostgresReintroductionStepStore) GetReintroductionStep(
+	ctx context.Context,
+	id int64,
+) (*ReintroductionStep, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var step ReintroductionStep
+	err := db.First(&step, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &step, nil
+}
+
+func (store *PostgresReintroductionStepStore) ListReintroductionSteps(
+	ctx context.Context,
+	planID int64,
+) ([]*ReintroductionStep, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var steps []*ReintroductionStep
+	err := db.Where("plan_id = ?", planID).Order("start_date, id").Find(&steps).Error
+	if err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+func (store *PostgresReintroductionStepStore) UpdateReintroductionStep(
+	ctx context.Context,
+	step *ReintroductionStep,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	return db.Save(step).Error
+}
+
+func (store *PostgresReintroductionStepStore) DeleteReintroductionStep(
+	ctx context.Context,
+	id int64,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	return db.Delete(&ReintroductionStep{}, id).Error
+}
+
+func (store *PostgresReintroductionStepStore) DeleteAllReintroductionStepsForPlan(
+	ctx context.Context,
+	planID int64,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	return db.Where("plan_id = ?", planID).Delete(&ReintroductionStep{}).Error
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"time"
 )
 
@@ -16,10 +17,13 @@ type EmergencyContact struct {
 }
 
 type EmergencyContactStore interface {
-	CreateEmergencyContact(contact *EmergencyContact) (*EmergencyContact, error)
-	GetEmergencyContact(id int64) (*EmergencyContact, error)
-	GetEmergencyContactByEmail(email string) (*EmergencyContact, error)
-	ListUserEmergencyContacts(userID int64) ([]*EmergencyContact, error)
-	UpdateEmergencyContact(contact *EmergencyContact) error
-	DeleteEmergencyContact(id int64) error
+	CreateEmergencyContact(
+		ctx context.Context,
+		contact *EmergencyContact,
+	) (*EmergencyContact, error)
+	GetEmergencyContact(ctx context.Context, id int64) (*EmergencyContact, error)
+	GetEmergencyContactByEmail(ctx context.Context, email string) (*EmergencyContact, error)
+	ListUserEmergencyContacts(ctx context.Context, userID int64) ([]*EmergencyContact, error)
+	UpdateEmergencyContact(ctx context.Context, contact *EmergencyContact) error
+	DeleteEmergencyContact(ctx context.Context, id int64) error
 }
@@ -0,0 +1,117 @@
+package data
+
+import "context"
+
+type MemoryEmergencyContactStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryEmergencyContactStore(db *MemoryDB) *MemoryEmergencyContactStore {
+	return &MemoryEmergencyContactStore{db: db}
+}
+
+func (store *MemoryEmergencyContactStore) CreateEmergencyContact(
+	ctx context.Context,
+	contact *EmergencyContact,
+) (*EmergencyContact, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if contact.ID != 0 {
+		if _, exists := store.db.emergencyContacts.get(contact.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		contact.ID = store.db.emergencyContacts.nextID()
+	}
+	setCreateTimestamps(&contact.CreatedAt, &contact.UpdatedAt)
+	store.db.emergencyContacts.put(contact.ID, *contact)
+
+	return contact, nil
+}
+
+func (store *MemoryEmergencyContactStore) GetEmergencyContact(
+	ctx context.Context,
+	id int64,
+) (*EmergencyContact, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	contact, ok := store.db.emergencyContacts.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &contact, nil
+}
+
+func (store *MemoryEmergencyContactStore) GetEmergencyContactByEmail(
+	ctx context.Context,
+	email string,
+) (*EmergencyContact, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	contact, ok := store.db.emergencyContacts.first(func(row *EmergencyContact) bool {
+		return row.Email == email
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return contact, nil
+}
+
+func (store *MemoryEmergencyContactStore) ListUserEmergencyContacts(
+	ctx context.Context,
+	userID int64,
+) ([]*EmergencyContact, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.emergencyContacts.filter(func(row *EmergencyContact) bool {
+		return row.UserID == userID
+	}), nil
+}
+
+func (store *MemoryEmergencyContactStore) UpdateEmergencyContact(
+	ctx context.Context,
+	contact *EmergencyContact,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.emergencyContacts.get(contact.ID)
+	if contact.ID == 0 || !ok {
+		if contact.ID == 0 {
+			contact.ID = store.db.emergencyContacts.nextID()
+		}
+		setCreateTimestamps(&contact.CreatedAt, &contact.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&contact.CreatedAt, &contact.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.emergencyContacts.put(contact.ID, *contact)
+
+	return nil
+}
+
+func (store *MemoryEmergencyContactStore) DeleteEmergencyContact(
+	ctx context.Context,
+	id int64,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.emergencyContacts.delete(id)
+	return nil
+}
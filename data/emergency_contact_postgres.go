@@ -1,26 +1,30 @@
 package data
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type PostgresEmergencyContactStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresEmergencyContactStore(db *gorm.DB) *PostgresEmergencyContactStore {
-	if err := db.AutoMigrate(&EmergencyContact{}); err != nil {
-		panic("failed to migrate emergency contact schema: " + err.Error())
-	}
 	return &PostgresEmergencyContactStore{DB: db}
 }
 
 func (store *PostgresEmergencyContactStore) CreateEmergencyContact(
+	ctx context.Context,
 	contact *EmergencyContact,
 ) (*EmergencyContact, error) {
-	err := store.DB.Create(contact).Error
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(contact).Error
 	if err != nil {
 		return nil, err
 	}
@@ -28,10 +32,14 @@ func (store *PostgresEmergencyContactStore) CreateEmergencyContact(
 }
 
 func (store *PostgresEmergencyContactStore) GetEmergencyContact(
+	ctx context.Context,
 	id int64,
 ) (*EmergencyContact, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var contact EmergencyContact
-	err := store.DB.First(&contact, id).Error
+	err := db.First(&contact, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -42,10 +50,14 @@ func (store *PostgresEmergencyContactStore) GetEmergencyContact(
 }
 
 func (store *PostgresEmergencyContactStore) GetEmergencyContactByEmail(
+	ctx context.Context,
 	email string,
 ) (*EmergencyContact, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var contact EmergencyContact
-	err := store.DB.Where("email = ?", email).First(&contact).Error
+	err := db.Where("email = ?", email).First(&contact).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -56,10 +68,14 @@ func (store *PostgresEmergencyContactStore) GetEmergencyContactByEmail(
 }
 
 func (store *PostgresEmergencyContactStore) ListUserEmergencyContacts(
+	ctx context.Context,
 	userID int64,
 ) ([]*EmergencyContact, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var contacts []*EmergencyContact
-	err := store.DB.Where("user_id = ?", userID).Find(&contacts).Error
+	err := db.Where("user_id = ?", userID).Find(&contacts).Error
 	if err != nil {
 		return nil, err
 	}
@@ -67,17 +83,27 @@ func (store *PostgresEmergencyContactStore) ListUserEmergencyContacts(
 }
 
 func (store *PostgresEmergencyContactStore) UpdateEmergencyContact(
+	ctx context.Context,
 	contact *EmergencyContact,
 ) error {
-	err := store.DB.Save(contact).Error
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(contact).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresEmergencyContactStore) DeleteEmergencyContact(id int64) error {
-	err := store.DB.Delete(&EmergencyContact{}, id).Error
+func (store *PostgresEmergencyContactStore) DeleteEmergencyContact(
+	ctx context.Context,
+	id int64,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&EmergencyContact{}, id).Error
 	if err != nil {
 		return err
 	}
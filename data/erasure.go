@@ -0,0 +1,365 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErasureReport lists how many records of each kind were removed when a
+// user was erased.
+type ErasureReport struct {
+	UserID   int64     `json:"user_id"`
+	ErasedAt time.Time `json:"erased_at"`
+
+	Tokens             int64 `json:"tokens"`
+	UserIntakes        int   `json:"user_intakes"`
+	MedicalInformation int   `json:"medical_information"`
+	Caregivers         int   `json:"caregivers"`
+	EmergencyContacts  int   `json:"emergency_contacts"`
+	MedicalEvents      int   `json:"medical_events"`
+	FrequentFoods      int   `json:"frequent_foods"`
+	Allergies          int   `json:"allergies"`
+	Medications        int   `json:"medications"`
+	DietarySupplements int   `json:"dietary_supplements"`
+	TrackingPeriods    int   `json:"tracking_periods"`
+	MealEntries        int   `json:"meal_entries"`
+	MealFoods          int   `json:"meal_foods"`
+	CustomFoods        int   `json:"custom_foods"`
+	Symptoms           int   `json:"symptoms"`
+
+	SymptomAttributions int `json:"symptom_attributions"`
+
+	MedicationSchedules int `json:"medication_schedules"`
+	DoseLogs            int `json:"dose_logs"`
+
+	EliminationPlans        int `json:"elimination_plans"`
+	EliminationRestrictions int `json:"elimination_restrictions"`
+	ReintroductionSteps     int `json:"reintroduction_steps"`
+}
+
+// EraseUser permanently removes a user and every record tied to them across
+// all stores in a single transaction, for honoring account deletion
+// requests. Unlike UserStore.DeleteUser it leaves nothing orphaned. It
+// returns ErrRecordNotFound if the user does not exist.
+func (stores *Stores) EraseUser(ctx context.Context, userID int64) (*ErasureReport, error) {
+	var report *ErasureReport
+
+	err := stores.WithTx(ctx, func(tx *Stores) error {
+		if _, err := tx.UserStore.GetUser(ctx, userID); err != nil {
+			return err
+		}
+
+		report = &ErasureReport{UserID: userID}
+		if err := eraseEliminationPlans(ctx, tx, report); err != nil {
+			return err
+		}
+		if err := eraseTracking(ctx, tx, report); err != nil {
+			return err
+		}
+		if err := eraseUserRecords(ctx, tx, report); err != nil {
+			return err
+		}
+		if err := eraseIntake(ctx, tx, report); err != nil {
+			return err
+		}
+
+		tokens, err := tx.TokenStore.DeleteAllTokensForUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		report.Tokens = tokens
+
+		if err := tx.UserStore.DeleteUser(ctx, userID); err != nil {
+			return err
+		}
+
+		report.ErasedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// eraseTracking removes the user's tracking periods along with every meal
+// entry and the foods and symptoms logged against them, and the standalone
+// symptoms logged during them.
+func eraseTracking(ctx context.Context, tx *Stores, report *ErasureReport) error {
+	periods, err := tx.TrackingPeriodStore.ListUserTrackingPeriods(ctx, report.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, period := range periods {
+		symptoms, err := tx.SymptomStore.ListStandaloneSymptoms(ctx, report.UserID, period.ID)
+		if err != nil {
+			return err
+		}
+		for _, symptom := range symptoms {
+			if err := eraseStandaloneSymptom(ctx, tx, symptom.ID, report); err != nil {
+				return err
+			}
+		}
+
+		entries, err := tx.MealEntryStore.ListUserMealEntries(ctx, report.UserID, period.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := eraseMealEntry(ctx, tx, entry.ID, report); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.TrackingPeriodStore.DeleteTrackingPeriod(ctx, period.ID); err != nil {
+			return err
+		}
+		report.TrackingPeriods++
+	}
+
+	return nil
+}
+
+func eraseMealEntry(ctx context.Context, tx *Stores, mealEntryID int64, report *ErasureReport) error {
+	mealFoods, err := tx.MealFoodStore.GetMealFoodsForMeal(ctx, mealEntryID)
+	if err != nil {
+		return err
+	}
+	if err := tx.MealFoodStore.DeleteAllMealFoodsForMeal(ctx, mealEntryID); err != nil {
+		return err
+	}
+	report.MealFoods += len(mealFoods)
+
+	customFoods, err := tx.CustomFoodStore.GetCustomFoodsForMeal(ctx, mealEntryID)
+	if err != nil {
+		return err
+	}
+	if err := tx.CustomFoodStore.DeleteAllCustomFoodsForMeal(ctx, mealEntryID); err != nil {
+		return err
+	}
+	report.CustomFoods += len(customFoods)
+
+	symptoms, err := tx.SymptomStore.ListSymptomsForMeal(ctx, mealEntryID)
+	if err != nil {
+		return err
+	}
+	if err := tx.SymptomStore.DeleteAllSymptomsForMeal(ctx, mealEntryID); err != nil {
+		return err
+	}
+	report.Symptoms += len(symptoms)
+
+	attributions, err := tx.SymptomAttributionStore.ListAttributionsForMeal(ctx, mealEntryID)
+	if err != nil {
+		return err
+	}
+	if err := tx.SymptomAttributionStore.DeleteAllAttributionsForMeal(ctx, mealEntryID); err != nil {
+		return err
+	}
+	report.SymptomAttributions += len(attributions)
+
+	if err := tx.MealEntryStore.DeleteMealEntry(ctx, mealEntryID); err != nil {
+		return err
+	}
+	report.MealEntries++
+
+	return nil
+}
+
+func eraseStandaloneSymptom(ctx context.Context, tx *Stores, symptomID int64, report *ErasureReport) error {
+	attributions, err := tx.SymptomAttributionStore.ListAttributionsForSymptom(ctx, symptomID)
+	if err != nil {
+		return err
+	}
+	if err := tx.SymptomAttributionStore.DeleteAllAttributionsForSymptom(ctx, symptomID); err != nil {
+		return err
+	}
+	report.SymptomAttributions += len(attributions)
+
+	if err := tx.SymptomStore.DeleteSymptom(ctx, symptomID); err != nil {
+		return err
+	}
+	report.Symptoms++
+
+	return nil
+}
+
+// eraseEliminationPlans removes the user's elimination plans along with
+// their restrictions and reintroduction schedules.
+func eraseEliminationPlans(ctx context.Context, tx *Stores, report *ErasureReport) error {
+	plans, err := tx.EliminationPlanStore.ListUserEliminationPlans(ctx, report.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, plan := range plans {
+		restrictions, err := tx.EliminationRestrictionStore.ListEliminationRestrictions(ctx, plan.ID)
+		if err != nil {
+			return err
+		}
+		err = tx.EliminationRestrictionStore.DeleteAllEliminationRestrictionsForPlan(ctx, plan.ID)
+		if err != nil {
+			return err
+		}
+		report.EliminationRestrictions += len(restrictions)
+
+		steps, err := tx.ReintroductionStepStore.ListReintroductionSteps(ctx, plan.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.ReintroductionStepStore.DeleteAllReintroductionStepsForPlan(ctx, plan.ID); err != nil {
+			return err
+		}
+		report.ReintroductionSteps += len(steps)
+
+		if err := tx.EliminationPlanStore.DeleteEliminationPlan(ctx, plan.ID); err != nil {
+			return err
+		}
+		report.EliminationPlans++
+	}
+
+	return nil
+}
+
+// eraseUserRecords removes the list entities that hang directly off a user.
+func eraseUserRecords(ctx context.Context, tx *Stores, report *ErasureReport) error {
+	userID := report.UserID
+
+	caregivers, err := tx.CaregiverStore.ListUserCaregivers(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, caregiver := range caregivers {
+		if err := tx.CaregiverStore.DeleteCaregiver(ctx, caregiver.ID); err != nil {
+			return err
+		}
+	}
+	report.Caregivers = len(caregivers)
+
+	contacts, err := tx.EmergencyContactStore.ListUserEmergencyContacts(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, contact := range contacts {
+		if err := tx.EmergencyContactStore.DeleteEmergencyContact(ctx, contact.ID); err != nil {
+			return err
+		}
+	}
+	report.EmergencyContacts = len(contacts)
+
+	events, err := tx.MedicalEventStore.ListUserMedicalEvents(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := tx.MedicalEventStore.DeleteMedicalEvent(ctx, event.ID); err != nil {
+			return err
+		}
+	}
+	report.MedicalEvents = len(events)
+
+	foods, err := tx.FrequentFoodStore.ListUserFrequentFoods(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, food := range foods {
+		if err := tx.FrequentFoodStore.DeleteFrequentFood(ctx, food.ID); err != nil {
+			return err
+		}
+	}
+	report.FrequentFoods = len(foods)
+
+	allergies, err := tx.AllergyStore.ListUserAllergies(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, allergy := range allergies {
+		if err := tx.AllergyStore.DeleteAllergy(ctx, allergy.ID); err != nil {
+			return err
+		}
+	}
+	report.Allergies = len(allergies)
+
+	schedules, err := tx.MedicationScheduleStore.ListUserMedicationSchedules(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		if err := tx.MedicationScheduleStore.DeleteMedicationSchedule(ctx, schedule.ID); err != nil {
+			return err
+		}
+	}
+	report.MedicationSchedules = len(schedules)
+
+	doseLogs, err := tx.DoseLogStore.ListUserDoseLogs(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, log := range doseLogs {
+		if err := tx.DoseLogStore.DeleteDoseLog(ctx, log.ID); err != nil {
+			return err
+		}
+	}
+	report.DoseLogs = len(doseLogs)
+
+	medications, err := tx.MedicationStore.ListUserMedications(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, medication := range medications {
+		if err := tx.MedicationStore.DeleteMedication(ctx, medication.ID); err != nil {
+			return err
+		}
+	}
+	report.Medications = len(medications)
+
+	supplements, err := tx.DietarySupplementStore.ListUserDietarySupplements(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, supplement := range supplements {
+		if err := tx.DietarySupplementStore.DeleteDietarySupplement(ctx, supplement.ID); err != nil {
+			return err
+		}
+	}
+	report.DietarySupplements = len(supplements)
+
+	return nil
+}
+
+// eraseIntake removes the one-per-user intake records. Both are looked up by
+// user until none remain, in case earlier bugs left duplicates behind.
+func eraseIntake(ctx context.Context, tx *Stores, report *ErasureReport) error {
+	for {
+		medInfo, err := tx.MedicalInformationStore.GetMedicalInformationByUserID(ctx, report.UserID)
+		if errors.Is(err, ErrRecordNotFound) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.MedicalInformationStore.DeleteMedicalInformation(ctx, medInfo.ID); err != nil {
+			return err
+		}
+		report.MedicalInformation++
+	}
+
+	for {
+		intake, err := tx.UserIntakeStore.GetUserIntakeByUserID(ctx, report.UserID)
+		if errors.Is(err, ErrRecordNotFound) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.UserIntakeStore.DeleteUserIntake(ctx, intake.ID); err != nil {
+			return err
+		}
+		report.UserIntakes++
+	}
+
+	return nil
+}
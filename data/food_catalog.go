@@ -0,0 +1,344 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/validator"
+)
+
+// FoodCategories are the categories of the predefined food catalog.
+var FoodCategories = []string{
+	"Beverages",
+	"Condiments",
+	"Dairy",
+	"Eggs",
+	"Fats and Oils",
+	"Fish",
+	"Fruits",
+	"Grains",
+	"Herbs and Spices",
+	"Legumes",
+	"Meat",
+	"Nuts and Seeds",
+	"Poultry",
+	"Prepared Foods",
+	"Shellfish",
+	"Sweets",
+	"Vegetables",
+}
+
+// ErrCatalogRejected is returned by ImportFoodCatalog in strict mode when
+// the catalog has duplicate or invalid entries.
+var ErrCatalogRejected = errors.New("food catalog has duplicate or invalid entries")
+
+// FoodCatalogEntry is a food item read from a catalog file. Line is the
+// line of a CSV file or the index of a JSON array element, counting from 1.
+type FoodCatalogEntry struct {
+	Line int
+	Item *FoodItem
+	// Err is set when the entry could not be parsed.
+	Err error
+}
+
+// FoodCatalogIssue is a catalog entry that was not imported.
+type FoodCatalogIssue struct {
+	Line    int    `json:"line"`
+	Name    string `json:"name"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (issue FoodCatalogIssue) String() string {
+	field := ""
+	if issue.Field != "" {
+		field = issue.Field + " "
+	}
+	return fmt.Sprintf("line %d (%s): %s%s", issue.Line, issue.Name, field, issue.Message)
+}
+
+// FoodCatalogReport describes the outcome of ImportFoodCatalog. Created,
+// Updated and Unchanged count the food items written, or that would have
+// been written in a dry run.
+type FoodCatalogReport struct {
+	Created    int                `json:"created"`
+	Updated    int                `json:"updated"`
+	Unchanged  int                `json:"unchanged"`
+	Duplicates []FoodCatalogIssue `json:"duplicates"`
+	Invalid    []FoodCatalogIssue `json:"invalid"`
+}
+
+type FoodCatalogOptions struct {
+	// DryRun rolls the import back once the report is complete.
+	DryRun bool
+	// Strict imports nothing if any entry is a duplicate or invalid.
+	Strict bool
+}
+
+var errDryRun = errors.New("dry run")
+
+// ImportFoodCatalog upserts the catalog entries by name in a single
+// transaction. Entries whose name appeared earlier in the catalog, ignoring
+// case, are reported as duplicates and skipped, as are entries that fail
+// ValidateFoodItem, including those with a category not in FoodCategories.
+func (stores *Stores) ImportFoodCatalog(
+	ctx context.Context,
+	entries []*FoodCatalogEntry,
+	options FoodCatalogOptions,
+) (*FoodCatalogReport, error) {
+	report := &FoodCatalogReport{}
+	valid := checkFoodCatalog(entries, report)
+
+	if options.Strict && (len(report.Duplicates) > 0 || len(report.Invalid) > 0) {
+		return report, ErrCatalogRejected
+	}
+
+	err := stores.WithTx(ctx, func(tx *Stores) error {
+		for _, entry := range valid {
+			if err := upsertFoodItem(ctx, tx, entry.Item, report); err != nil {
+				return fmt.Errorf("line %d (%s): %w", entry.Line, entry.Item.Name, err)
+			}
+		}
+		if options.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// checkFoodCatalog reports the duplicate and invalid entries and returns
+// the rest.
+func checkFoodCatalog(entries []*FoodCatalogEntry, report *FoodCatalogReport) []*FoodCatalogEntry {
+	var valid []*FoodCatalogEntry
+	seen := make(map[string]int)
+
+	for _, entry := range entries {
+		if entry.Err != nil {
+			report.Invalid = append(report.Invalid, FoodCatalogIssue{
+				Line:    entry.Line,
+				Name:    entryName(entry),
+				Message: entry.Err.Error(),
+			})
+			continue
+		}
+
+		item := entry.Item
+		item.Name = strings.TrimSpace(item.Name)
+		item.Category = strings.TrimSpace(item.Category)
+
+		v := validator.New()
+		ValidateFoodItem(v, item)
+		if !v.Valid() {
+			fields := make([]string, 0, len(v.Errors))
+			for field := range v.Errors {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				report.Invalid = append(report.Invalid, FoodCatalogIssue{
+					Line:    entry.Line,
+					Name:    item.Name,
+					Field:   field,
+					Message: v.Errors[field],
+				})
+			}
+			continue
+		}
+
+		key := strings.ToLower(item.Name)
+		if first, ok := seen[key]; ok {
+			report.Duplicates = append(report.Duplicates, FoodCatalogIssue{
+				Line:    entry.Line,
+				Name:    item.Name,
+				Message: fmt.Sprintf("duplicates line %d", first),
+			})
+			continue
+		}
+		seen[key] = entry.Line
+
+		valid = append(valid, entry)
+	}
+
+	return valid
+}
+
+func entryName(entry *FoodCatalogEntry) string {
+	if entry.Item == nil {
+		return ""
+	}
+	return entry.Item.Name
+}
+
+func upsertFoodItem(ctx context.Context, tx *Stores, item *FoodItem, report *FoodCatalogReport) error {
+	existing, err := tx.FoodItemStore.GetFoodItemByName(ctx, item.Name)
+	if errors.Is(err, ErrRecordNotFound) {
+		report.Created++
+		_, err = tx.FoodItemStore.CreateFoodItem(ctx, item)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	item.ID, item.CreatedAt, item.UpdatedAt = existing.ID, existing.CreatedAt, existing.UpdatedAt
+	if *item == *existing {
+		report.Unchanged++
+		return nil
+	}
+	report.Updated++
+	return tx.FoodItemStore.UpdateFoodItem(ctx, item)
+}
+
+// ReadFoodCatalogJSON reads a catalog written as a JSON array of food items
+// in their export format, e.g. {"name": "Cheddar", "category": "Dairy",
+// "allergens": "milk"}.
+func ReadFoodCatalogJSON(r io.Reader) ([]*FoodCatalogEntry, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	entries := make([]*FoodCatalogEntry, len(raw))
+	for i, element := range raw {
+		entry := &FoodCatalogEntry{Line: i + 1, Item: &FoodItem{}}
+
+		decoder := json.NewDecoder(bytes.NewReader(element))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(entry.Item); err != nil {
+			// Keep the name, if there is one, for the report.
+			var named struct {
+				Name string `json:"name"`
+			}
+			_ = json.Unmarshal(element, &named)
+			entry.Item = &FoodItem{Name: named.Name}
+			entry.Err = err
+		}
+		entry.Item.ID, entry.Item.CreatedAt, entry.Item.UpdatedAt = 0, time.Time{}, time.Time{}
+
+		entries[i] = entry
+	}
+
+	return entries, nil
+}
+
+// ReadFoodCatalogCSV reads a catalog written as CSV with a header row. The
+// columns are the JSON names of the FoodItem fields, in any order; name and
+// category are required. Synonyms, allergens and FODMAP classes are
+// comma-separated lists within their cell, e.g. "milk,egg".
+func ReadFoodCatalogCSV(r io.Reader) ([]*FoodCatalogEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	columns := make([]string, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := foodCatalogColumns[name]; !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[i] = name
+	}
+	for _, required := range []string{"name", "category"} {
+		if !slices.Contains(columns, required) {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	var entries []*FoodCatalogEntry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		entry := &FoodCatalogEntry{Line: line, Item: &FoodItem{}}
+		for i, name := range columns {
+			err := foodCatalogColumns[name](entry.Item, strings.TrimSpace(record[i]))
+			if err != nil && entry.Err == nil {
+				entry.Err = fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// foodCatalogColumns sets a FoodItem field from a CSV cell. The id and
+// timestamp columns of an exported food_items.csv are accepted and ignored.
+var foodCatalogColumns = map[string]func(item *FoodItem, value string) error{
+	"id":         ignoreCatalogColumn,
+	"created_at": ignoreCatalogColumn,
+	"updated_at": ignoreCatalogColumn,
+	"name":       func(item *FoodItem, value string) error { item.Name = value; return nil },
+	"category":   func(item *FoodItem, value string) error { item.Category = value; return nil },
+	"synonyms":   func(item *FoodItem, value string) error { item.Synonyms = value; return nil },
+	"allergens": func(item *FoodItem, value string) (err error) {
+		item.Allergens, err = ParseAllergens(value)
+		return err
+	},
+	"fodmaps": func(item *FoodItem, value string) (err error) {
+		item.FODMAPs, err = ParseFODMAPs(value)
+		return err
+	},
+	"high_histamine": catalogBool(func(item *FoodItem) *bool { return &item.HighHistamine }),
+	"high_lectin":    catalogBool(func(item *FoodItem) *bool { return &item.HighLectin }),
+	"high_oxalate":   catalogBool(func(item *FoodItem) *bool { return &item.HighOxalate }),
+	"calories_kcal":  catalogFloat(func(item *FoodItem) *float64 { return &item.CaloriesKcal }),
+	"protein_g":      catalogFloat(func(item *FoodItem) *float64 { return &item.ProteinG }),
+	"carbohydrate_g": catalogFloat(func(item *FoodItem) *float64 { return &item.CarbohydrateG }),
+	"fat_g":          catalogFloat(func(item *FoodItem) *float64 { return &item.FatG }),
+	"fiber_g":        catalogFloat(func(item *FoodItem) *float64 { return &item.FiberG }),
+}
+
+func ignoreCatalogColumn(item *FoodItem, value string) error { return nil }
+
+func catalogBool(field func(item *FoodItem) *bool) func(item *FoodItem, value string) error {
+	return func(item *FoodItem, value string) error {
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		*field(item) = parsed
+		return nil
+	}
+}
+
+func catalogFloat(field func(item *FoodItem) *float64) func(item *FoodItem, value string) error {
+	return func(item *FoodItem, value string) error {
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		*field(item) = parsed
+		return nil
+	}
+}
@@ -0,0 +1,257 @@
+package data_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func TestReadFoodCatalogCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []data.FoodItem
+		wantErr string
+		// entryErr lists the lines whose entry is expected to have Err set.
+		entryErr []int
+	}{
+		{
+			name: "columns in any order",
+			csv: "category, Name ,synonyms,allergens,fodmaps,high_lectin,fiber_g\n" +
+				`Grains,Rye Bread,"rye,pumpernickel","wheat,gluten",fructans,true,5.8` + "\n",
+			want: []data.FoodItem{{
+				Name:       "Rye Bread",
+				Category:   "Grains",
+				Synonyms:   "rye,pumpernickel",
+				Allergens:  data.AllergenWheat,
+				FODMAPs:    data.FODMAPFructans,
+				HighLectin: true,
+				FiberG:     5.8,
+			}},
+		},
+		{
+			name: "exported columns ignored",
+			csv: "id,name,category,created_at,updated_at\n" +
+				"42,Cheddar,Dairy,2025-01-01T00:00:00Z,2025-01-02T00:00:00Z\n",
+			want: []data.FoodItem{{Name: "Cheddar", Category: "Dairy"}},
+		},
+		{
+			name: "empty cells keep the zero value",
+			csv:  "name,category,high_histamine,protein_g\nApple,Fruits,,\n",
+			want: []data.FoodItem{{Name: "Apple", Category: "Fruits"}},
+		},
+		{
+			name: "cell errors are kept on the entry",
+			csv: "name,category,allergens,high_oxalate,calories_kcal\n" +
+				"Crab,Shellfish,crab,,\n" +
+				"Spinach,Vegetables,,sometimes,\n" +
+				"Honey,Sweets,,,lots\n" +
+				"Rice,Grains,,,130\n",
+			want: []data.FoodItem{
+				{Name: "Crab", Category: "Shellfish"},
+				{Name: "Spinach", Category: "Vegetables"},
+				{Name: "Honey", Category: "Sweets"},
+				{Name: "Rice", Category: "Grains", CaloriesKcal: 130},
+			},
+			entryErr: []int{2, 3, 4},
+		},
+		{
+			name:    "unknown column",
+			csv:     "name,category,sugar_g\nSoda,Beverages,10\n",
+			wantErr: `unknown column "sugar_g"`,
+		},
+		{
+			name:    "missing category column",
+			csv:     "name,allergens\nCheddar,milk\n",
+			wantErr: `missing column "category"`,
+		},
+		{
+			name:    "empty file",
+			csv:     "",
+			wantErr: "read header",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := data.ReadFoodCatalogCSV(strings.NewReader(test.csv))
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadFoodCatalogCSV: %v", err)
+			}
+			checkCatalogEntries(t, entries, test.want, test.entryErr)
+		})
+	}
+}
+
+func TestReadFoodCatalogJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		want     []data.FoodItem
+		wantErr  bool
+		entryErr []int
+	}{
+		{
+			name: "export format",
+			json: `[{"name": "Cheddar", "category": "Dairy", "allergens": "milk",
+				"fodmaps": "lactose", "high_histamine": true, "protein_g": 25}]`,
+			want: []data.FoodItem{{
+				Name:          "Cheddar",
+				Category:      "Dairy",
+				Allergens:     data.AllergenMilk,
+				FODMAPs:       data.FODMAPLactose,
+				HighHistamine: true,
+				ProteinG:      25,
+			}},
+		},
+		{
+			name: "id and timestamps cleared",
+			json: `[{"id": 42, "name": "Apple", "category": "Fruits",
+				"created_at": "2025-01-01T00:00:00Z", "updated_at": "2025-01-02T00:00:00Z"}]`,
+			want: []data.FoodItem{{Name: "Apple", Category: "Fruits"}},
+		},
+		{
+			name: "unknown fields rejected per entry",
+			json: `[{"name": "Almonds", "category": "Nuts and Seeds"},
+				{"name": "Soda", "category": "Beverages", "sugar_g": 10},
+				{"name": "Crab", "category": "Shellfish", "allergens": "crab"}]`,
+			want: []data.FoodItem{
+				{Name: "Almonds", Category: "Nuts and Seeds"},
+				{Name: "Soda"},
+				{Name: "Crab"},
+			},
+			entryErr: []int{2, 3},
+		},
+		{
+			name: "empty array",
+			json: `[]`,
+			want: []data.FoodItem{},
+		},
+		{
+			name:    "not an array",
+			json:    `{"name": "Cheddar", "category": "Dairy"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := data.ReadFoodCatalogJSON(strings.NewReader(test.json))
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("ReadFoodCatalogJSON returned %d entries, want an error", len(entries))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadFoodCatalogJSON: %v", err)
+			}
+			checkCatalogEntries(t, entries, test.want, test.entryErr)
+		})
+	}
+}
+
+func checkCatalogEntries(t *testing.T, entries []*data.FoodCatalogEntry, want []data.FoodItem, entryErr []int) {
+	t.Helper()
+
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	failed := make(map[int]bool)
+	for _, line := range entryErr {
+		failed[line] = true
+	}
+	for i, entry := range entries {
+		if failed[entry.Line] {
+			if entry.Err == nil {
+				t.Errorf("entry on line %d has no error, want one", entry.Line)
+			}
+			if entry.Item.Name != want[i].Name {
+				t.Errorf("entry on line %d is named %q, want %q", entry.Line, entry.Item.Name, want[i].Name)
+			}
+			continue
+		}
+		if entry.Err != nil {
+			t.Errorf("entry on line %d: %v", entry.Line, entry.Err)
+			continue
+		}
+		if *entry.Item != want[i] {
+			t.Errorf("entry on line %d is %+v, want %+v", entry.Line, *entry.Item, want[i])
+		}
+	}
+}
+
+func TestImportFoodCatalogOptions(t *testing.T) {
+	const catalog = "name,category,allergens\n" +
+		"Cheddar,Dairy,milk\n" +
+		"Apple,Fruits,\n" +
+		"apple,Fruits,\n" +
+		"Mystery,Snacks,\n"
+
+	tests := []struct {
+		name    string
+		options data.FoodCatalogOptions
+		wantErr error
+		want    data.FoodCatalogReport
+		stored  bool
+	}{
+		{
+			name:   "import",
+			want:   data.FoodCatalogReport{Created: 2},
+			stored: true,
+		},
+		{
+			name:    "dry run",
+			options: data.FoodCatalogOptions{DryRun: true},
+			want:    data.FoodCatalogReport{Created: 2},
+		},
+		{
+			name:    "strict",
+			options: data.FoodCatalogOptions{Strict: true},
+			wantErr: data.ErrCatalogRejected,
+		},
+		{
+			name:    "strict dry run",
+			options: data.FoodCatalogOptions{Strict: true, DryRun: true},
+			wantErr: data.ErrCatalogRejected,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			stores := data.NewMemoryStores()
+
+			entries, err := data.ReadFoodCatalogCSV(strings.NewReader(catalog))
+			if err != nil {
+				t.Fatalf("ReadFoodCatalogCSV: %v", err)
+			}
+			report, err := stores.ImportFoodCatalog(ctx, entries, test.options)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("got error %v, want %v", err, test.wantErr)
+			}
+			if report.Created != test.want.Created || report.Updated != test.want.Updated ||
+				len(report.Duplicates) != 1 || len(report.Invalid) != 1 {
+				t.Fatalf("got report %+v, want %d created, one duplicate and one invalid entry",
+					report, test.want.Created)
+			}
+
+			items, err := stores.FoodItemStore.ListFoodItems(ctx)
+			if err != nil {
+				t.Fatalf("ListFoodItems: %v", err)
+			}
+			if stored := len(items) > 0; stored != test.stored {
+				t.Fatalf("%d food items stored, want stored = %v", len(items), test.stored)
+			}
+		})
+	}
+}
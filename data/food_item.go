@@ -0,0 +1,249 @@
+package data
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Universal-Selfcare/utils/validator"
+)
+
+// Allergens is a set of the top 9 food allergens, stored as a bit mask.
+// It is written out as a comma-separated list of names, e.g. "milk,egg".
+type Allergens uint32
+
+const (
+	AllergenMilk Allergens = 1 << iota
+	AllergenEgg
+	AllergenFish
+	AllergenShellfish
+	AllergenTreeNuts
+	AllergenPeanuts
+	AllergenWheat
+	AllergenSoy
+	AllergenSesame
+
+	AllAllergens = AllergenSesame<<1 - 1
+)
+
+var allergenNames = []struct {
+	allergen Allergens
+	name     string
+}{
+	{AllergenMilk, "milk"},
+	{AllergenEgg, "egg"},
+	{AllergenFish, "fish"},
+	{AllergenShellfish, "shellfish"},
+	{AllergenTreeNuts, "tree_nuts"},
+	{AllergenPeanuts, "peanuts"},
+	{AllergenWheat, "wheat"},
+	{AllergenSoy, "soy"},
+	{AllergenSesame, "sesame"},
+}
+
+// allergenAliases maps other lower-case spellings to their allergen.
+var allergenAliases = map[string]Allergens{
+	"dairy":       AllergenMilk,
+	"lactose":     AllergenMilk,
+	"eggs":        AllergenEgg,
+	"crustacean":  AllergenShellfish,
+	"crustaceans": AllergenShellfish,
+	"tree nut":    AllergenTreeNuts,
+	"tree nuts":   AllergenTreeNuts,
+	"tree_nut":    AllergenTreeNuts,
+	"nuts":        AllergenTreeNuts,
+	"peanut":      AllergenPeanuts,
+	"gluten":      AllergenWheat,
+	"soya":        AllergenSoy,
+	"soybean":     AllergenSoy,
+	"soybeans":    AllergenSoy,
+}
+
+// FODMAPs is a set of FODMAP classes, stored as a bit mask. It is written
+// out as a comma-separated list of names, e.g. "fructans,gos".
+type FODMAPs uint32
+
+const (
+	FODMAPFructans FODMAPs = 1 << iota
+	FODMAPGOS
+	FODMAPLactose
+	FODMAPFructose
+	FODMAPSorbitol
+	FODMAPMannitol
+
+	AllFODMAPs = FODMAPMannitol<<1 - 1
+)
+
+var fodmapNames = []struct {
+	fodmap FODMAPs
+	name   string
+}{
+	{FODMAPFructans, "fructans"},
+	{FODMAPGOS, "gos"},
+	{FODMAPLactose, "lactose"},
+	{FODMAPFructose, "fructose"},
+	{FODMAPSorbitol, "sorbitol"},
+	{FODMAPMannitol, "mannitol"},
+}
+
+// ParseAllergen returns the allergen named by s, ignoring case and
+// accepting common names such as "dairy" and "gluten".
+func ParseAllergen(s string) (Allergens, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for _, entry := range allergenNames {
+		if name == entry.name {
+			return entry.allergen, nil
+		}
+	}
+	if allergen, ok := allergenAliases[name]; ok {
+		return allergen, nil
+	}
+	return 0, fmt.Errorf("unknown allergen %q", s)
+}
+
+// ParseAllergens parses a comma-separated list of allergens.
+func ParseAllergens(s string) (Allergens, error) {
+	var allergens Allergens
+	for _, name := range splitList(s) {
+		allergen, err := ParseAllergen(name)
+		if err != nil {
+			return 0, err
+		}
+		allergens |= allergen
+	}
+	return allergens, nil
+}
+
+// Has reports whether allergens contains any of other.
+func (allergens Allergens) Has(other Allergens) bool {
+	return allergens&other != 0
+}
+
+// Names lists the allergens in the set.
+func (allergens Allergens) Names() []string {
+	var names []string
+	for _, entry := range allergenNames {
+		if allergens.Has(entry.allergen) {
+			names = append(names, entry.name)
+		}
+	}
+	return names
+}
+
+func (allergens Allergens) String() string {
+	return strings.Join(allergens.Names(), ",")
+}
+
+func (allergens Allergens) MarshalText() ([]byte, error) {
+	return []byte(allergens.String()), nil
+}
+
+func (allergens *Allergens) UnmarshalText(text []byte) error {
+	parsed, err := ParseAllergens(string(text))
+	if err != nil {
+		return err
+	}
+	*allergens = parsed
+	return nil
+}
+
+// ParseFODMAP returns the FODMAP class named by s, ignoring case.
+func ParseFODMAP(s string) (FODMAPs, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for _, entry := range fodmapNames {
+		if name == entry.name {
+			return entry.fodmap, nil
+		}
+	}
+	if name == "galacto-oligosaccharides" {
+		return FODMAPGOS, nil
+	}
+	return 0, fmt.Errorf("unknown FODMAP class %q", s)
+}
+
+// ParseFODMAPs parses a comma-separated list of FODMAP classes.
+func ParseFODMAPs(s string) (FODMAPs, error) {
+	var fodmaps FODMAPs
+	for _, name := range splitList(s) {
+		fodmap, err := ParseFODMAP(name)
+		if err != nil {
+			return 0, err
+		}
+		fodmaps |= fodmap
+	}
+	return fodmaps, nil
+}
+
+// Has reports whether fodmaps contains any of other.
+func (fodmaps FODMAPs) Has(other FODMAPs) bool {
+	return fodmaps&other != 0
+}
+
+// Names lists the FODMAP classes in the set.
+func (fodmaps FODMAPs) Names() []string {
+	var names []string
+	for _, entry := range fodmapNames {
+		if fodmaps.Has(entry.fodmap) {
+			names = append(names, entry.name)
+		}
+	}
+	return names
+}
+
+func (fodmaps FODMAPs) String() string {
+	return strings.Join(fodmaps.Names(), ",")
+}
+
+func (fodmaps FODMAPs) MarshalText() ([]byte, error) {
+	return []byte(fodmaps.String()), nil
+}
+
+func (fodmaps *FODMAPs) UnmarshalText(text []byte) error {
+	parsed, err := ParseFODMAPs(string(text))
+	if err != nil {
+		return err
+	}
+	*fodmaps = parsed
+	return nil
+}
+
+func ValidateFoodItem(v *validator.Validator, item *FoodItem) {
+	v.Check(item.Name != "", "name", "must be provided")
+	v.Check(item.Category != "", "category", "must be provided")
+	v.Check(
+		item.Category == "" || validator.PermittedValue(item.Category, FoodCategories...),
+		"category",
+		"must be one of the food catalog categories",
+	)
+	v.Check(
+		!slices.ContainsFunc(item.SynonymList(), func(synonym string) bool {
+			return strings.EqualFold(synonym, item.Name)
+		}),
+		"synonyms",
+		"must not repeat the name",
+	)
+	v.Check(item.Allergens&^AllAllergens == 0, "allergens", "must only contain known allergens")
+	v.Check(item.FODMAPs&^AllFODMAPs == 0, "fodmaps", "must only contain known FODMAP classes")
+	v.Check(
+		item.CaloriesKcal >= 0 && item.ProteinG >= 0 && item.CarbohydrateG >= 0 &&
+			item.FatG >= 0 && item.FiberG >= 0,
+		"nutrients",
+		"must not be negative",
+	)
+	v.Check(
+		item.ProteinG+item.CarbohydrateG+item.FatG <= 100,
+		"nutrients",
+		"must not add up to more than 100 g per 100 g",
+	)
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
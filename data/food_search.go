@@ -0,0 +1,150 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Universal-Selfcare/utils/search"
+)
+
+// DefaultFoodSearchLimit is the number of results returned by the food
+// searches when no limit is given.
+const DefaultFoodSearchLimit = 20
+
+// FoodSearchResult is a food matching a search query. FoodItem is nil for a
+// custom food, which is returned once per distinct name with Uses counting
+// the times it was logged.
+type FoodSearchResult struct {
+	FoodItem *FoodItem    `json:"food_item,omitempty"`
+	Name     string       `json:"name"`
+	Match    search.Match `json:"match"`
+	Score    float64      `json:"score"`
+	Uses     int          `json:"uses,omitempty"`
+}
+
+// SynonymList returns the synonyms of the food item.
+func (item *FoodItem) SynonymList() []string {
+	return splitList(item.Synonyms)
+}
+
+// SearchFoods searches the food catalog and the custom foods the user has
+// logged, ranking both together. A custom food with the same name as a
+// matching catalog item is left out.
+func (stores *Stores) SearchFoods(
+	ctx context.Context,
+	userID int64,
+	query string,
+	limit int,
+) ([]*FoodSearchResult, error) {
+	limit = foodSearchLimit(limit)
+
+	items, err := stores.FoodItemStore.SearchFoodItems(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	custom, err := stores.CustomFoodStore.SearchCustomFoods(ctx, userID, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := make(map[string]bool, len(items))
+	for _, result := range items {
+		catalog[search.Normalize(result.Name)] = true
+	}
+	results := items
+	for _, result := range custom {
+		if !catalog[search.Normalize(result.Name)] {
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func foodSearchLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultFoodSearchLimit
+	}
+	return limit
+}
+
+// rankFoodItems ranks food item candidates against query with the pure-Go
+// ranker. Both stores use it, the Postgres store on the candidates found
+// with pg_trgm, so results are ranked the same way whatever the backend.
+// Candidates are taken in ID order so ties rank the same way too.
+func rankFoodItems(query string, items []*FoodItem, limit int) []*FoodSearchResult {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+
+	ranked := search.Rank(
+		search.DefaultRanker,
+		query,
+		items,
+		func(item *FoodItem) (string, []string) { return item.Name, item.SynonymList() },
+		foodSearchLimit(limit),
+	)
+
+	results := make([]*FoodSearchResult, len(ranked))
+	for i, result := range ranked {
+		results[i] = &FoodSearchResult{
+			FoodItem: result.Item,
+			Name:     result.Item.Name,
+			Match:    result.Match,
+			Score:    result.Score,
+		}
+	}
+	return results
+}
+
+// rankCustomFoods groups custom food candidates by their normalized name,
+// keeping the first spelling logged, and ranks the names against query.
+func rankCustomFoods(query string, foods []*CustomFood, limit int) []*FoodSearchResult {
+	sort.SliceStable(foods, func(i, j int) bool {
+		return foods[i].ID < foods[j].ID
+	})
+
+	var names []*FoodSearchResult
+	byName := make(map[string]*FoodSearchResult)
+	for _, food := range foods {
+		name := strings.TrimSpace(food.Name)
+		key := search.Normalize(name)
+		if key == "" {
+			continue
+		}
+		if result, ok := byName[key]; ok {
+			result.Uses++
+			continue
+		}
+		result := &FoodSearchResult{Name: name, Uses: 1}
+		byName[key] = result
+		names = append(names, result)
+	}
+
+	ranked := search.Rank(
+		search.DefaultRanker,
+		query,
+		names,
+		func(result *FoodSearchResult) (string, []string) { return result.Name, nil },
+		foodSearchLimit(limit),
+	)
+
+	results := make([]*FoodSearchResult, len(ranked))
+	for i, result := range ranked {
+		result.Item.Match, result.Item.Score = result.Match, result.Score
+		results[i] = result.Item
+	}
+	return results
+}
+
+// likeEscape escapes the LIKE wildcards in s.
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
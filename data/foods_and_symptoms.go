@@ -1,43 +1,85 @@
 package data
 
 import (
+	"context"
+	"errors"
 	"time"
+
+	"github.com/Universal-Selfcare/utils/validator"
 )
 
-// TrackingPeriod represents a 5-day tracking period for a user
+// DefaultTrackingPeriodLength is the length in days of a tracking period
+// that does not set LengthDays.
+const DefaultTrackingPeriodLength = 5
+
+// TrackingPeriodLengths are the supported tracking period lengths in days.
+var TrackingPeriodLengths = []int{3, 5, 7, 14}
+
+var ErrOutsideTrackingPeriod = errors.New("time falls outside the tracking period")
+
+// Tracking period statuses. Every status but TrackingPeriodActive marks a
+// closed period, which also has IsCompleted set.
+const (
+	TrackingPeriodActive     = "active"
+	TrackingPeriodCompleted  = "completed"
+	TrackingPeriodIncomplete = "incomplete"
+	TrackingPeriodAbandoned  = "abandoned"
+)
+
+// TrackingPeriod represents a tracking period of LengthDays days for a user.
+// Status tells how a closed period ended and StatusReason explains an
+// incomplete or abandoned one.
 type TrackingPeriod struct {
-	ID          int64     `gorm:"primaryKey"     json:"id"`
-	UserID      int64     `gorm:"not null;index" json:"user_id"`
-	StartDate   time.Time `gorm:"not null"       json:"start_date"`
-	EndDate     time.Time `gorm:"not null"       json:"end_date"`
-	IsCompleted bool      `gorm:"default:false"  json:"is_completed"`
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID           int64     `gorm:"primaryKey"                          json:"id"`
+	UserID       int64     `gorm:"not null;index"                      json:"user_id"`
+	StartDate    time.Time `gorm:"not null"                            json:"start_date"`
+	EndDate      time.Time `gorm:"not null"                            json:"end_date"`
+	LengthDays   int       `gorm:"not null;default:5"                  json:"length_days"` // 3, 5, 7 or 14
+	IsCompleted  bool      `gorm:"default:false"                       json:"is_completed"`
+	Status       string    `gorm:"type:text;not null;default:'active'" json:"status"`
+	StatusReason string    `gorm:"type:text"                           json:"status_reason"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"                      json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"                      json:"updated_at"`
 }
 
-// MealEntry represents a single meal entry during a tracking day
+// MealEntry represents a single meal entry during a tracking day. MealTime
+// is when the meal started and PortionQuantity is in PortionUnit.
 type MealEntry struct {
-	ID               int64     `gorm:"primaryKey"     json:"id"`
-	UserID           int64     `gorm:"not null;index" json:"user_id"`
-	TrackingPeriodID int64     `gorm:"not null;index" json:"tracking_period_id"`
-	TrackingDay      int       `gorm:"not null"       json:"tracking_day"` // 1-5
-	MealType         string    `gorm:"not null"       json:"meal_type"`    // Breakfast, Lunch, Snack, Dinner
-	MealTime         string    `gorm:"not null"       json:"meal_time"`    // Time the meal was eaten
-	MealDuration     string    `gorm:"not null"       json:"meal_duration"`
-	Notes            string    `gorm:"type:text"      json:"notes"`
-	PortionSize      string    `gorm:"not null"       json:"portion_size"`
-	IsCompleted      bool      `gorm:"default:false"  json:"is_completed"`
-	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"updated_at"`
-}
-
-// FoodItem represents a food item from the predefined list
+	ID               int64         `gorm:"primaryKey"     json:"id"`
+	UserID           int64         `gorm:"not null;index" json:"user_id"`
+	TrackingPeriodID int64         `gorm:"not null;index" json:"tracking_period_id"`
+	TrackingDay      int           `gorm:"not null"       json:"tracking_day"` // 1 to the period's LengthDays
+	MealType         MealType      `gorm:"not null"       json:"meal_type"`
+	MealTime         time.Time     `gorm:"not null"       json:"meal_time"`
+	MealDuration     time.Duration `gorm:"not null"       json:"meal_duration"`
+	Notes            string        `gorm:"type:text"      json:"notes"`
+	PortionQuantity  float64       `gorm:"not null"       json:"portion_quantity"`
+	PortionUnit      PortionUnit   `gorm:"not null"       json:"portion_unit"`
+	IsCompleted      bool          `gorm:"default:false"  json:"is_completed"`
+	CreatedAt        time.Time     `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time     `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// FoodItem represents a food item from the predefined list. Synonyms is a
+// comma-separated list of other names the food is searched by, e.g.
+// "aubergine,brinjal" for eggplant. The nutrient amounts are per 100 g.
 type FoodItem struct {
-	ID        int64     `gorm:"primaryKey"     json:"id"`
-	Name      string    `gorm:"not null;index" json:"name"`
-	Category  string    `gorm:"not null"       json:"category"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID            int64     `gorm:"primaryKey"             json:"id"`
+	Name          string    `gorm:"not null;uniqueIndex"   json:"name"`
+	Category      string    `gorm:"not null"               json:"category"`
+	Synonyms      string    `gorm:"not null;default:''"    json:"synonyms"`
+	Allergens     Allergens `gorm:"not null;default:0"     json:"allergens"`
+	FODMAPs       FODMAPs   `gorm:"not null;default:0"     json:"fodmaps"`
+	HighHistamine bool      `gorm:"not null;default:false" json:"high_histamine"`
+	HighLectin    bool      `gorm:"not null;default:false" json:"high_lectin"`
+	HighOxalate   bool      `gorm:"not null;default:false" json:"high_oxalate"`
+	CaloriesKcal  float64   `gorm:"not null;default:0"     json:"calories_kcal"`
+	ProteinG      float64   `gorm:"not null;default:0"     json:"protein_g"`
+	CarbohydrateG float64   `gorm:"not null;default:0"     json:"carbohydrate_g"`
+	FatG          float64   `gorm:"not null;default:0"     json:"fat_g"`
+	FiberG        float64   `gorm:"not null;default:0"     json:"fiber_g"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"         json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"         json:"updated_at"`
 }
 
 // MealFood links a meal entry to a food item
@@ -60,74 +102,222 @@ type CustomFood struct {
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
-// Symptom represents a tracked symptom related to a meal
+// CustomFoodUsage counts how often a user logged a custom food by the exact
+// name.
+type CustomFoodUsage struct {
+	Name   string `json:"name"`
+	UserID int64  `json:"user_id"`
+	Uses   int    `json:"uses"`
+}
+
+// Symptom represents a tracked symptom. SymptomType names an entry of the
+// symptom catalog, on whose scale Severity is recorded. A symptom is either
+// logged against a meal, or standalone: MealEntryID is then 0 and the
+// symptom records its user, tracking period and when it occurred instead,
+// to be linked to the meals before it by SymptomAttribution.
 type Symptom struct {
-	ID          int64     `gorm:"primaryKey"     json:"id"`
-	MealEntryID int64     `gorm:"not null;index" json:"meal_entry_id"`
-	SymptomType string    `gorm:"not null"       json:"symptom_type"`
-	Severity    int       `gorm:"not null"       json:"severity"` // 0-180
-	IsOvernight bool      `gorm:"default:false"  json:"is_overnight"`
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID               int64     `gorm:"primaryKey"         json:"id"`
+	MealEntryID      int64     `gorm:"not null;index"     json:"meal_entry_id"`
+	UserID           int64     `gorm:"not null;default:0" json:"user_id"`            // standalone only
+	TrackingPeriodID int64     `gorm:"not null;default:0" json:"tracking_period_id"` // standalone only
+	OccurredAt       time.Time `gorm:"type:timestamptz"   json:"occurred_at"`        // standalone only
+	SymptomType      string    `gorm:"not null"           json:"symptom_type"`
+	Severity         int       `gorm:"not null"           json:"severity"`
+	IsOvernight      bool      `gorm:"default:false"      json:"is_overnight"`
+	CreatedAt        time.Time `gorm:"autoCreateTime"     json:"created_at"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"     json:"updated_at"`
 }
 
 // TrackingPeriodStore provides database operations for tracking periods
 type TrackingPeriodStore interface {
-	CreateTrackingPeriod(period *TrackingPeriod) (*TrackingPeriod, error)
-	GetTrackingPeriod(id int64) (*TrackingPeriod, error)
-	GetCurrentTrackingPeriod(userID int64) (*TrackingPeriod, error)
-	GetLastCompletedTrackingPeriod(userID int64) (*TrackingPeriod, error)
-	ListUserTrackingPeriods(userID int64) ([]*TrackingPeriod, error)
-	UpdateTrackingPeriod(period *TrackingPeriod) error
-	CompleteTrackingPeriod(id int64) error
+	CreateTrackingPeriod(ctx context.Context, period *TrackingPeriod) (*TrackingPeriod, error)
+	GetTrackingPeriod(ctx context.Context, id int64) (*TrackingPeriod, error)
+	// GetCurrentTrackingPeriod returns the user's open period that has not
+	// yet ended as of asOf. An open period past its end date is expired and
+	// not returned, even before it is closed.
+	GetCurrentTrackingPeriod(ctx context.Context, userID int64, asOf time.Time) (*TrackingPeriod, error)
+	// GetLastCompletedTrackingPeriod returns the period with the latest end
+	// date among those closed as TrackingPeriodCompleted.
+	GetLastCompletedTrackingPeriod(ctx context.Context, userID int64) (*TrackingPeriod, error)
+	ListUserTrackingPeriods(ctx context.Context, userID int64) ([]*TrackingPeriod, error)
+	UpdateTrackingPeriod(ctx context.Context, period *TrackingPeriod) error
+	CompleteTrackingPeriod(ctx context.Context, id int64) error
+	// CloseTrackingPeriod closes an open period with the given status,
+	// leaving its other fields alone. It reports false if the period was
+	// already closed or does not exist.
+	CloseTrackingPeriod(ctx context.Context, id int64, status, reason string) (bool, error)
+	ListExpiredTrackingPeriods(ctx context.Context, asOf time.Time) ([]*TrackingPeriod, error)
+	DeleteTrackingPeriod(ctx context.Context, id int64) error
 }
 
 // MealEntryStore provides database operations for meal entries
 type MealEntryStore interface {
-	CreateMealEntry(entry *MealEntry) (*MealEntry, error)
-	GetMealEntry(id int64) (*MealEntry, error)
-	GetMealEntryByDetails(userID int64, trackingPeriodID int64, day int, mealType string) (*MealEntry, error)
-	ListUserMealEntries(userID int64, trackingPeriodID int64) ([]*MealEntry, error)
-	UpdateMealEntry(entry *MealEntry) error
-	CompleteMealEntry(id int64) error
-	DeleteMealEntry(id int64) error
+	CreateMealEntry(ctx context.Context, entry *MealEntry) (*MealEntry, error)
+	GetMealEntry(ctx context.Context, id int64) (*MealEntry, error)
+	GetMealEntryByDetails(ctx context.Context, userID int64, trackingPeriodID int64, day int, mealType MealType) (*MealEntry, error)
+	ListUserMealEntries(ctx context.Context, userID int64, trackingPeriodID int64) ([]*MealEntry, error)
+	UpdateMealEntry(ctx context.Context, entry *MealEntry) error
+	CompleteMealEntry(ctx context.Context, id int64) error
+	DeleteMealEntry(ctx context.Context, id int64) error
 }
 
-// FoodItemStore provides database operations for food items
+// FoodItemStore provides database operations for food items. Names are
+// unique and looked up ignoring case. The
+// WithAllergens and WithFODMAPs queries match items containing any of the
+// given allergens or FODMAP classes.
 type FoodItemStore interface {
-	CreateFoodItem(item *FoodItem) (*FoodItem, error)
-	GetFoodItem(id int64) (*FoodItem, error)
-	GetFoodItemByName(name string) (*FoodItem, error)
-	ListFoodItems() ([]*FoodItem, error)
-	ListFoodItemsByCategory(category string) ([]*FoodItem, error)
-	UpdateFoodItem(item *FoodItem) error
-	DeleteFoodItem(id int64) error
+	CreateFoodItem(ctx context.Context, item *FoodItem) (*FoodItem, error)
+	GetFoodItem(ctx context.Context, id int64) (*FoodItem, error)
+	GetFoodItemByName(ctx context.Context, name string) (*FoodItem, error)
+	ListFoodItems(ctx context.Context) ([]*FoodItem, error)
+	ListFoodItemsByCategory(ctx context.Context, category string) ([]*FoodItem, error)
+	ListFoodItemsWithAllergens(ctx context.Context, allergens Allergens) ([]*FoodItem, error)
+	ListFoodItemsWithFODMAPs(ctx context.Context, fodmaps FODMAPs) ([]*FoodItem, error)
+	// SearchFoodItems matches query against the names and synonyms of the
+	// food items, case-insensitively by prefix or trigram similarity, and
+	// returns at most limit results from best to worst.
+	SearchFoodItems(ctx context.Context, query string, limit int) ([]*FoodSearchResult, error)
+	UpdateFoodItem(ctx context.Context, item *FoodItem) error
+	DeleteFoodItem(ctx context.Context, id int64) error
 }
 
 // MealFoodStore provides database operations for meal foods
 type MealFoodStore interface {
-	CreateMealFood(mealFood *MealFood) (*MealFood, error)
-	GetMealFoodsForMeal(mealEntryID int64) ([]*MealFood, error)
-	DeleteMealFood(id int64) error
-	DeleteAllMealFoodsForMeal(mealEntryID int64) error
+	CreateMealFood(ctx context.Context, mealFood *MealFood) (*MealFood, error)
+	GetMealFoodsForMeal(ctx context.Context, mealEntryID int64) ([]*MealFood, error)
+	DeleteMealFood(ctx context.Context, id int64) error
+	DeleteAllMealFoodsForMeal(ctx context.Context, mealEntryID int64) error
 }
 
 // CustomFoodStore provides database operations for custom foods
 type CustomFoodStore interface {
-	CreateCustomFood(food *CustomFood) (*CustomFood, error)
-	GetCustomFoodsForMeal(mealEntryID int64) ([]*CustomFood, error)
-	UpdateCustomFood(food *CustomFood) error
-	DeleteCustomFood(id int64) error
-	DeleteAllCustomFoodsForMeal(mealEntryID int64) error
+	CreateCustomFood(ctx context.Context, food *CustomFood) (*CustomFood, error)
+	GetCustomFoodsForMeal(ctx context.Context, mealEntryID int64) ([]*CustomFood, error)
+	UpdateCustomFood(ctx context.Context, food *CustomFood) error
+	DeleteCustomFood(ctx context.Context, id int64) error
+	DeleteAllCustomFoodsForMeal(ctx context.Context, mealEntryID int64) error
+	// SearchCustomFoods matches query against the names of the custom foods
+	// the user has logged, like SearchFoodItems.
+	SearchCustomFoods(ctx context.Context, userID int64, query string, limit int) ([]*FoodSearchResult, error)
+	// ListCustomFoodUsage counts the custom foods of every user by name,
+	// ordered by name and user.
+	ListCustomFoodUsage(ctx context.Context) ([]*CustomFoodUsage, error)
+	// ListCustomFoodsByName returns the custom foods with any of the exact
+	// names, across users, ordered by ID.
+	ListCustomFoodsByName(ctx context.Context, names []string) ([]*CustomFood, error)
 }
 
 // SymptomStore provides database operations for symptoms
 type SymptomStore interface {
-	CreateSymptom(symptom *Symptom) (*Symptom, error)
-	GetSymptom(id int64) (*Symptom, error)
-	GetSymptomByTypeForMeal(mealEntryID int64, symptomType string) (*Symptom, error)
-	ListSymptomsForMeal(mealEntryID int64) ([]*Symptom, error)
-	UpdateSymptom(symptom *Symptom) error
-	DeleteSymptom(id int64) error
-	DeleteAllSymptomsForMeal(mealEntryID int64) error
+	CreateSymptom(ctx context.Context, symptom *Symptom) (*Symptom, error)
+	GetSymptom(ctx context.Context, id int64) (*Symptom, error)
+	GetSymptomByTypeForMeal(ctx context.Context, mealEntryID int64, symptomType string) (*Symptom, error)
+	ListSymptomsForMeal(ctx context.Context, mealEntryID int64) ([]*Symptom, error)
+	UpdateSymptom(ctx context.Context, symptom *Symptom) error
+	DeleteSymptom(ctx context.Context, id int64) error
+	DeleteAllSymptomsForMeal(ctx context.Context, mealEntryID int64) error
+	// ListStandaloneSymptoms returns the user's standalone symptoms during a
+	// tracking period in the order they occurred.
+	ListStandaloneSymptoms(ctx context.Context, userID int64, trackingPeriodID int64) ([]*Symptom, error)
+	// SummarizeSymptomsByBodySystem aggregates the user's symptoms during a
+	// tracking period, or all of them if trackingPeriodID is 0, by the body
+	// system of their type, standalone symptoms included. Symptoms of types
+	// not in the catalog are left out.
+	SummarizeSymptomsByBodySystem(
+		ctx context.Context,
+		userID int64,
+		trackingPeriodID int64,
+	) ([]*BodySystemSummary, error)
+}
+
+// NewTrackingPeriod returns a period of lengthDays days starting at start.
+func NewTrackingPeriod(userID int64, start time.Time, lengthDays int) *TrackingPeriod {
+	return &TrackingPeriod{
+		UserID:     userID,
+		StartDate:  start,
+		EndDate:    start.AddDate(0, 0, lengthDays),
+		LengthDays: lengthDays,
+	}
+}
+
+// Length returns the length of the period in days, falling back to
+// DefaultTrackingPeriodLength for periods created before it was stored.
+func (period *TrackingPeriod) Length() int {
+	if period.LengthDays == 0 {
+		return DefaultTrackingPeriodLength
+	}
+	return period.LengthDays
+}
+
+// Date returns the start of the given tracking day, day 1 being the start
+// date of the period.
+func (period *TrackingPeriod) Date(day int) time.Time {
+	return period.StartDate.AddDate(0, 0, day-1)
+}
+
+// TrackingDayFor derives the tracking day a moment falls on. Days are
+// calendar days in the location of StartDate, so a meal eaten late on the
+// first evening is still on day 1 even if the period started that morning.
+// It returns ErrOutsideTrackingPeriod if t is before the period's first day
+// or after its last.
+func (period *TrackingPeriod) TrackingDayFor(t time.Time) (int, error) {
+	location := period.StartDate.Location()
+	startYear, startMonth, startDay := period.StartDate.Date()
+	year, month, day := t.In(location).Date()
+
+	// Compare the calendar dates in UTC so daylight saving changes in
+	// location cannot make a day 23 or 25 hours long.
+	start := time.Date(startYear, startMonth, startDay, 0, 0, 0, 0, time.UTC)
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	trackingDay := int(date.Sub(start).Hours()/24) + 1
+
+	if trackingDay < 1 || trackingDay > period.Length() {
+		return 0, ErrOutsideTrackingPeriod
+	}
+	return trackingDay, nil
+}
+
+func ValidateTrackingPeriod(v *validator.Validator, period *TrackingPeriod) {
+	v.Check(period.UserID != 0, "user_id", "must be provided")
+	v.Check(!period.StartDate.IsZero(), "start_date", "must be provided")
+	v.Check(
+		validator.PermittedValue(period.LengthDays, TrackingPeriodLengths...),
+		"length_days",
+		"must be 3, 5, 7 or 14",
+	)
+	v.Check(
+		period.EndDate.Equal(period.StartDate.AddDate(0, 0, period.LengthDays)),
+		"end_date",
+		"must be length_days after the start date",
+	)
+}
+
+// ValidateMealEntry checks entry against the tracking period it is logged in.
+func ValidateMealEntry(v *validator.Validator, entry *MealEntry, period *TrackingPeriod) {
+	v.Check(entry.TrackingPeriodID == period.ID, "tracking_period_id", "must match the tracking period")
+	v.Check(entry.UserID == period.UserID, "user_id", "must match the tracking period's user")
+	v.Check(
+		entry.TrackingDay >= 1 && entry.TrackingDay <= period.Length(),
+		"tracking_day",
+		"must fall within the tracking period",
+	)
+	v.Check(validator.PermittedValue(entry.MealType, MealTypes...), "meal_type", "must be Breakfast, Lunch, Snack or Dinner")
+
+	v.Check(!entry.MealTime.IsZero(), "meal_time", "must be provided")
+	if !entry.MealTime.IsZero() {
+		day, err := period.TrackingDayFor(entry.MealTime)
+		v.Check(err == nil && day == entry.TrackingDay, "meal_time", "must fall on the tracking day")
+	}
+	v.Check(
+		entry.MealDuration >= time.Minute && entry.MealDuration <= MaxMealDuration,
+		"meal_duration",
+		"must be between 1 minute and 4 hours",
+	)
+
+	ValidatePortion(v, entry.PortionQuantity, entry.PortionUnit)
+}
+
+func ValidatePortion(v *validator.Validator, quantity float64, unit PortionUnit) {
+	v.Check(quantity > 0, "portion_quantity", "must be greater than zero")
+	v.Check(validator.PermittedValue(unit, PortionUnits...), "portion_unit", "must be a supported unit")
 }
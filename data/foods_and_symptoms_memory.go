@@ -0,0 +1,895 @@
+package data
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MemoryTrackingPeriodStore implements TrackingPeriodStore interface
+type MemoryTrackingPeriodStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryTrackingPeriodStore(db *MemoryDB) *MemoryTrackingPeriodStore {
+	return &MemoryTrackingPeriodStore{db: db}
+}
+
+func (store *MemoryTrackingPeriodStore) CreateTrackingPeriod(ctx context.Context, period *TrackingPeriod) (*TrackingPeriod, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	// Check if there's an active tracking period for this user
+	existingPeriod, ok := store.db.trackingPeriods.first(func(row *TrackingPeriod) bool {
+		return row.UserID == period.UserID && !row.IsCompleted
+	})
+	if ok {
+		// An active period already exists
+		return existingPeriod, nil
+	}
+
+	if period.ID != 0 {
+		if _, exists := store.db.trackingPeriods.get(period.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		period.ID = store.db.trackingPeriods.nextID()
+	}
+	// Mirror the column defaults.
+	if period.LengthDays == 0 {
+		period.LengthDays = DefaultTrackingPeriodLength
+	}
+	if period.Status == "" {
+		period.Status = TrackingPeriodActive
+	}
+	setCreateTimestamps(&period.CreatedAt, &period.UpdatedAt)
+	store.db.trackingPeriods.put(period.ID, *period)
+
+	return period, nil
+}
+
+func (store *MemoryTrackingPeriodStore) GetTrackingPeriod(ctx context.Context, id int64) (*TrackingPeriod, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	period, ok := store.db.trackingPeriods.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &period, nil
+}
+
+func (store *MemoryTrackingPeriodStore) GetCurrentTrackingPeriod(ctx context.Context, userID int64, asOf time.Time) (*TrackingPeriod, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	period, ok := store.db.trackingPeriods.first(func(row *TrackingPeriod) bool {
+		return row.UserID == userID && !row.IsCompleted && row.EndDate.After(asOf)
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return period, nil
+}
+
+func (store *MemoryTrackingPeriodStore) GetLastCompletedTrackingPeriod(ctx context.Context, userID int64) (*TrackingPeriod, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	periods := store.db.trackingPeriods.filter(func(row *TrackingPeriod) bool {
+		return row.UserID == userID && row.Status == TrackingPeriodCompleted
+	})
+	if len(periods) == 0 {
+		return nil, ErrRecordNotFound
+	}
+	sort.SliceStable(periods, func(i, j int) bool {
+		return periods[i].EndDate.After(periods[j].EndDate)
+	})
+	return periods[0], nil
+}
+
+func (store *MemoryTrackingPeriodStore) ListUserTrackingPeriods(ctx context.Context, userID int64) ([]*TrackingPeriod, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	periods := store.db.trackingPeriods.filter(func(row *TrackingPeriod) bool {
+		return row.UserID == userID
+	})
+	sort.SliceStable(periods, func(i, j int) bool {
+		return periods[i].StartDate.After(periods[j].StartDate)
+	})
+	return periods, nil
+}
+
+func (store *MemoryTrackingPeriodStore) UpdateTrackingPeriod(ctx context.Context, period *TrackingPeriod) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.trackingPeriods.get(period.ID)
+	if period.ID == 0 || !ok {
+		if period.ID == 0 {
+			period.ID = store.db.trackingPeriods.nextID()
+		}
+		setCreateTimestamps(&period.CreatedAt, &period.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&period.CreatedAt, &period.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.trackingPeriods.put(period.ID, *period)
+
+	return nil
+}
+
+func (store *MemoryTrackingPeriodStore) CompleteTrackingPeriod(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	period, ok := store.db.trackingPeriods.get(id)
+	if !ok {
+		return nil
+	}
+	period.IsCompleted = true
+	period.Status = TrackingPeriodCompleted
+	period.StatusReason = ""
+	period.UpdatedAt = time.Now()
+	store.db.trackingPeriods.put(id, period)
+	return nil
+}
+
+func (store *MemoryTrackingPeriodStore) CloseTrackingPeriod(
+	ctx context.Context,
+	id int64,
+	status string,
+	reason string,
+) (bool, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return false, err
+	}
+	defer store.db.mu.Unlock()
+
+	period, ok := store.db.trackingPeriods.get(id)
+	if !ok || period.IsCompleted {
+		return false, nil
+	}
+	period.IsCompleted = true
+	period.Status = status
+	period.StatusReason = reason
+	period.UpdatedAt = time.Now()
+	store.db.trackingPeriods.put(id, period)
+	return true, nil
+}
+
+func (store *MemoryTrackingPeriodStore) ListExpiredTrackingPeriods(ctx context.Context, asOf time.Time) ([]*TrackingPeriod, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	periods := store.db.trackingPeriods.filter(func(row *TrackingPeriod) bool {
+		return !row.IsCompleted && !row.EndDate.After(asOf)
+	})
+	sort.SliceStable(periods, func(i, j int) bool {
+		return periods[i].EndDate.Before(periods[j].EndDate)
+	})
+	return periods, nil
+}
+
+func (store *MemoryTrackingPeriodStore) DeleteTrackingPeriod(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.trackingPeriods.delete(id)
+	return nil
+}
+
+// MemoryMealEntryStore implements MealEntryStore interface
+type MemoryMealEntryStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryMealEntryStore(db *MemoryDB) *MemoryMealEntryStore {
+	return &MemoryMealEntryStore{db: db}
+}
+
+func (store *MemoryMealEntryStore) CreateMealEntry(ctx context.Context, entry *MealEntry) (*MealEntry, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	// Check if an entry already exists for this meal
+	existingEntry, ok := store.db.mealEntries.first(func(row *MealEntry) bool {
+		return row.UserID == entry.UserID &&
+			row.TrackingPeriodID == entry.TrackingPeriodID &&
+			row.TrackingDay == entry.TrackingDay &&
+			row.MealType == entry.MealType
+	})
+	if ok {
+		// Entry already exists, return it
+		return existingEntry, nil
+	}
+
+	if entry.ID != 0 {
+		if _, exists := store.db.mealEntries.get(entry.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		entry.ID = store.db.mealEntries.nextID()
+	}
+	setCreateTimestamps(&entry.CreatedAt, &entry.UpdatedAt)
+	store.db.mealEntries.put(entry.ID, *entry)
+
+	return entry, nil
+}
+
+func (store *MemoryMealEntryStore) GetMealEntry(ctx context.Context, id int64) (*MealEntry, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	entry, ok := store.db.mealEntries.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &entry, nil
+}
+
+func (store *MemoryMealEntryStore) GetMealEntryByDetails(
+	ctx context.Context,
+	userID int64,
+	trackingPeriodID int64,
+	day int,
+	mealType MealType,
+) (*MealEntry, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	entry, ok := store.db.mealEntries.first(func(row *MealEntry) bool {
+		return row.UserID == userID &&
+			row.TrackingPeriodID == trackingPeriodID &&
+			row.TrackingDay == day &&
+			row.MealType == mealType
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return entry, nil
+}
+
+func (store *MemoryMealEntryStore) ListUserMealEntries(ctx context.Context, userID int64, trackingPeriodID int64) ([]*MealEntry, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	entries := store.db.mealEntries.filter(func(row *MealEntry) bool {
+		return row.UserID == userID && row.TrackingPeriodID == trackingPeriodID
+	})
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].TrackingDay != entries[j].TrackingDay {
+			return entries[i].TrackingDay < entries[j].TrackingDay
+		}
+		return entries[i].MealType < entries[j].MealType
+	})
+	return entries, nil
+}
+
+func (store *MemoryMealEntryStore) UpdateMealEntry(ctx context.Context, entry *MealEntry) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.mealEntries.get(entry.ID)
+	if entry.ID == 0 || !ok {
+		if entry.ID == 0 {
+			entry.ID = store.db.mealEntries.nextID()
+		}
+		setCreateTimestamps(&entry.CreatedAt, &entry.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&entry.CreatedAt, &entry.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.mealEntries.put(entry.ID, *entry)
+
+	return nil
+}
+
+func (store *MemoryMealEntryStore) CompleteMealEntry(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	entry, ok := store.db.mealEntries.get(id)
+	if !ok {
+		return nil
+	}
+	entry.IsCompleted = true
+	entry.UpdatedAt = time.Now()
+	store.db.mealEntries.put(id, entry)
+	return nil
+}
+
+func (store *MemoryMealEntryStore) DeleteMealEntry(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.mealEntries.delete(id)
+	return nil
+}
+
+// MemoryFoodItemStore implements FoodItemStore interface
+type MemoryFoodItemStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryFoodItemStore(db *MemoryDB) *MemoryFoodItemStore {
+	return &MemoryFoodItemStore{db: db}
+}
+
+// conflicts reports whether another food item has the same name, ignoring
+// case. The caller must hold the lock.
+func (store *MemoryFoodItemStore) conflicts(item *FoodItem) bool {
+	_, found := store.db.foodItems.first(func(row *FoodItem) bool {
+		return row.ID != item.ID && strings.EqualFold(row.Name, item.Name)
+	})
+	return found
+}
+
+func (store *MemoryFoodItemStore) CreateFoodItem(ctx context.Context, item *FoodItem) (*FoodItem, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if item.ID != 0 {
+		if _, exists := store.db.foodItems.get(item.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	}
+	if store.conflicts(item) {
+		return nil, ErrRecordConflict
+	}
+	if item.ID == 0 {
+		item.ID = store.db.foodItems.nextID()
+	}
+	setCreateTimestamps(&item.CreatedAt, &item.UpdatedAt)
+	store.db.foodItems.put(item.ID, *item)
+
+	return item, nil
+}
+
+func (store *MemoryFoodItemStore) GetFoodItem(ctx context.Context, id int64) (*FoodItem, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	item, ok := store.db.foodItems.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &item, nil
+}
+
+func (store *MemoryFoodItemStore) GetFoodItemByName(ctx context.Context, name string) (*FoodItem, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	item, ok := store.db.foodItems.first(func(row *FoodItem) bool {
+		return strings.EqualFold(row.Name, name)
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return item, nil
+}
+
+func (store *MemoryFoodItemStore) ListFoodItems(ctx context.Context) ([]*FoodItem, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	items := store.db.foodItems.filter(nil)
+	sortFoodItemsByName(items)
+	return items, nil
+}
+
+func (store *MemoryFoodItemStore) ListFoodItemsByCategory(ctx context.Context, category string) ([]*FoodItem, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	items := store.db.foodItems.filter(func(row *FoodItem) bool {
+		return row.Category == category
+	})
+	sortFoodItemsByName(items)
+	return items, nil
+}
+
+func (store *MemoryFoodItemStore) ListFoodItemsWithAllergens(
+	ctx context.Context,
+	allergens Allergens,
+) ([]*FoodItem, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	items := store.db.foodItems.filter(func(row *FoodItem) bool {
+		return row.Allergens.Has(allergens)
+	})
+	sortFoodItemsByName(items)
+	return items, nil
+}
+
+func (store *MemoryFoodItemStore) ListFoodItemsWithFODMAPs(
+	ctx context.Context,
+	fodmaps FODMAPs,
+) ([]*FoodItem, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	items := store.db.foodItems.filter(func(row *FoodItem) bool {
+		return row.FODMAPs.Has(fodmaps)
+	})
+	sortFoodItemsByName(items)
+	return items, nil
+}
+
+func (store *MemoryFoodItemStore) SearchFoodItems(
+	ctx context.Context,
+	query string,
+	limit int,
+) ([]*FoodSearchResult, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	items := store.db.foodItems.filter(nil)
+	return rankFoodItems(query, items, limit), nil
+}
+
+func (store *MemoryFoodItemStore) UpdateFoodItem(ctx context.Context, item *FoodItem) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	if store.conflicts(item) {
+		return ErrRecordConflict
+	}
+	existing, ok := store.db.foodItems.get(item.ID)
+	if item.ID == 0 || !ok {
+		if item.ID == 0 {
+			item.ID = store.db.foodItems.nextID()
+		}
+		setCreateTimestamps(&item.CreatedAt, &item.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&item.CreatedAt, &item.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.foodItems.put(item.ID, *item)
+
+	return nil
+}
+
+func (store *MemoryFoodItemStore) DeleteFoodItem(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.foodItems.delete(id)
+	return nil
+}
+
+func sortFoodItemsByName(items []*FoodItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+}
+
+// MemoryMealFoodStore implements MealFoodStore interface
+type MemoryMealFoodStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryMealFoodStore(db *MemoryDB) *MemoryMealFoodStore {
+	return &MemoryMealFoodStore{db: db}
+}
+
+func (store *MemoryMealFoodStore) CreateMealFood(ctx context.Context, mealFood *MealFood) (*MealFood, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if mealFood.ID != 0 {
+		if _, exists := store.db.mealFoods.get(mealFood.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		mealFood.ID = store.db.mealFoods.nextID()
+	}
+	setCreateTimestamps(&mealFood.CreatedAt, &mealFood.UpdatedAt)
+	store.db.mealFoods.put(mealFood.ID, *mealFood)
+
+	return mealFood, nil
+}
+
+func (store *MemoryMealFoodStore) GetMealFoodsForMeal(ctx context.Context, mealEntryID int64) ([]*MealFood, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.mealFoods.filter(func(row *MealFood) bool {
+		return row.MealEntryID == mealEntryID
+	}), nil
+}
+
+func (store *MemoryMealFoodStore) DeleteMealFood(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.mealFoods.delete(id)
+	return nil
+}
+
+func (store *MemoryMealFoodStore) DeleteAllMealFoodsForMeal(ctx context.Context, mealEntryID int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.mealFoods.deleteWhere(func(row *MealFood) bool {
+		return row.MealEntryID == mealEntryID
+	})
+	return nil
+}
+
+// MemoryCustomFoodStore implements CustomFoodStore interface
+type MemoryCustomFoodStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryCustomFoodStore(db *MemoryDB) *MemoryCustomFoodStore {
+	return &MemoryCustomFoodStore{db: db}
+}
+
+func (store *MemoryCustomFoodStore) CreateCustomFood(ctx context.Context, food *CustomFood) (*CustomFood, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if food.ID != 0 {
+		if _, exists := store.db.customFoods.get(food.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		food.ID = store.db.customFoods.nextID()
+	}
+	setCreateTimestamps(&food.CreatedAt, &food.UpdatedAt)
+	store.db.customFoods.put(food.ID, *food)
+
+	return food, nil
+}
+
+func (store *MemoryCustomFoodStore) GetCustomFoodsForMeal(ctx context.Context, mealEntryID int64) ([]*CustomFood, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.customFoods.filter(func(row *CustomFood) bool {
+		return row.MealEntryID == mealEntryID
+	}), nil
+}
+
+func (store *MemoryCustomFoodStore) UpdateCustomFood(ctx context.Context, food *CustomFood) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.customFoods.get(food.ID)
+	if food.ID == 0 || !ok {
+		if food.ID == 0 {
+			food.ID = store.db.customFoods.nextID()
+		}
+		setCreateTimestamps(&food.CreatedAt, &food.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&food.CreatedAt, &food.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.customFoods.put(food.ID, *food)
+
+	return nil
+}
+
+func (store *MemoryCustomFoodStore) DeleteCustomFood(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.customFoods.delete(id)
+	return nil
+}
+
+func (store *MemoryCustomFoodStore) DeleteAllCustomFoodsForMeal(ctx context.Context, mealEntryID int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.customFoods.deleteWhere(func(row *CustomFood) bool {
+		return row.MealEntryID == mealEntryID
+	})
+	return nil
+}
+
+func (store *MemoryCustomFoodStore) SearchCustomFoods(
+	ctx context.Context,
+	userID int64,
+	query string,
+	limit int,
+) ([]*FoodSearchResult, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	foods := store.db.customFoods.filter(func(row *CustomFood) bool {
+		entry, ok := store.db.mealEntries.get(row.MealEntryID)
+		return ok && entry.UserID == userID
+	})
+	return rankCustomFoods(query, foods, limit), nil
+}
+
+func (store *MemoryCustomFoodStore) ListCustomFoodUsage(ctx context.Context) ([]*CustomFoodUsage, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	type key struct {
+		name   string
+		userID int64
+	}
+	counts := make(map[key]*CustomFoodUsage)
+	var usage []*CustomFoodUsage
+	for _, food := range store.db.customFoods.filter(nil) {
+		entry, ok := store.db.mealEntries.get(food.MealEntryID)
+		if !ok {
+			continue
+		}
+		k := key{food.Name, entry.UserID}
+		if counts[k] == nil {
+			counts[k] = &CustomFoodUsage{Name: food.Name, UserID: entry.UserID}
+			usage = append(usage, counts[k])
+		}
+		counts[k].Uses++
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Name != usage[j].Name {
+			return usage[i].Name < usage[j].Name
+		}
+		return usage[i].UserID < usage[j].UserID
+	})
+	return usage, nil
+}
+
+func (store *MemoryCustomFoodStore) ListCustomFoodsByName(
+	ctx context.Context,
+	names []string,
+) ([]*CustomFood, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.customFoods.filter(func(row *CustomFood) bool {
+		return slices.Contains(names, row.Name)
+	}), nil
+}
+
+// MemorySymptomStore implements SymptomStore interface
+type MemorySymptomStore struct {
+	db *MemoryDB
+}
+
+func NewMemorySymptomStore(db *MemoryDB) *MemorySymptomStore {
+	return &MemorySymptomStore{db: db}
+}
+
+func (store *MemorySymptomStore) CreateSymptom(ctx context.Context, symptom *Symptom) (*Symptom, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	// Check if a symptom of this type already exists for this meal.
+	// Standalone symptoms are always new.
+	existingSymptom, ok := store.db.symptoms.first(func(row *Symptom) bool {
+		return symptom.MealEntryID != 0 &&
+			row.MealEntryID == symptom.MealEntryID &&
+			row.SymptomType == symptom.SymptomType &&
+			row.IsOvernight == symptom.IsOvernight
+	})
+	if ok {
+		// Update existing symptom severity
+		existingSymptom.Severity = symptom.Severity
+		existingSymptom.UpdatedAt = time.Now()
+		store.db.symptoms.put(existingSymptom.ID, *existingSymptom)
+		return existingSymptom, nil
+	}
+
+	if symptom.ID != 0 {
+		if _, exists := store.db.symptoms.get(symptom.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		symptom.ID = store.db.symptoms.nextID()
+	}
+	setCreateTimestamps(&symptom.CreatedAt, &symptom.UpdatedAt)
+	store.db.symptoms.put(symptom.ID, *symptom)
+
+	return symptom, nil
+}
+
+func (store *MemorySymptomStore) GetSymptom(ctx context.Context, id int64) (*Symptom, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	symptom, ok := store.db.symptoms.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &symptom, nil
+}
+
+func (store *MemorySymptomStore) GetSymptomByTypeForMeal(ctx context.Context, mealEntryID int64, symptomType string) (*Symptom, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	symptom, ok := store.db.symptoms.first(func(row *Symptom) bool {
+		return row.MealEntryID == mealEntryID && row.SymptomType == symptomType
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return symptom, nil
+}
+
+func (store *MemorySymptomStore) ListSymptomsForMeal(ctx context.Context, mealEntryID int64) ([]*Symptom, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.symptoms.filter(func(row *Symptom) bool {
+		return row.MealEntryID == mealEntryID
+	}), nil
+}
+
+func (store *MemorySymptomStore) ListStandaloneSymptoms(
+	ctx context.Context,
+	userID int64,
+	trackingPeriodID int64,
+) ([]*Symptom, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	symptoms := store.db.symptoms.filter(func(row *Symptom) bool {
+		return row.MealEntryID == 0 && row.UserID == userID && row.TrackingPeriodID == trackingPeriodID
+	})
+	sort.SliceStable(symptoms, func(i, j int) bool {
+		return symptoms[i].OccurredAt.Before(symptoms[j].OccurredAt)
+	})
+	return symptoms, nil
+}
+
+func (store *MemorySymptomStore) UpdateSymptom(ctx context.Context, symptom *Symptom) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.symptoms.get(symptom.ID)
+	if symptom.ID == 0 || !ok {
+		if symptom.ID == 0 {
+			symptom.ID = store.db.symptoms.nextID()
+		}
+		setCreateTimestamps(&symptom.CreatedAt, &symptom.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&symptom.CreatedAt, &symptom.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.symptoms.put(symptom.ID, *symptom)
+
+	return nil
+}
+
+func (store *MemorySymptomStore) DeleteSymptom(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.symptoms.delete(id)
+	return nil
+}
+
+func (store *MemorySymptomStore) DeleteAllSymptomsForMeal(ctx context.Context, mealEntryID int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.symptoms.deleteWhere(func(row *Symptom) bool {
+		return row.MealEntryID == mealEntryID
+	})
+	return nil
+}
+
+func (store *MemorySymptomStore) SummarizeSymptomsByBodySystem(
+	ctx context.Context,
+	userID int64,
+	trackingPeriodID int64,
+) ([]*BodySystemSummary, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	symptoms := store.db.symptoms.filter(func(row *Symptom) bool {
+		owner, period := row.UserID, row.TrackingPeriodID
+		if row.MealEntryID != 0 {
+			entry, ok := store.db.mealEntries.get(row.MealEntryID)
+			if !ok {
+				return false
+			}
+			owner, period = entry.UserID, entry.TrackingPeriodID
+		}
+		return owner == userID && (trackingPeriodID == 0 || period == trackingPeriodID)
+	})
+	return summarizeByBodySystem(symptoms, store.db.symptomTypes.filter(nil)), nil
+}
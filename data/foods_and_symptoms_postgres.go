@@ -1,27 +1,36 @@
 package data
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Universal-Selfcare/utils/search"
 )
 
 // PostgresTrackingPeriodStore implements TrackingPeriodStore interface
 type PostgresTrackingPeriodStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresTrackingPeriodStore(db *gorm.DB) *PostgresTrackingPeriodStore {
-	if err := db.AutoMigrate(&TrackingPeriod{}); err != nil {
-		panic("failed to migrate tracking period schema: " + err.Error())
-	}
 	return &PostgresTrackingPeriodStore{DB: db}
 }
 
-func (store *PostgresTrackingPeriodStore) CreateTrackingPeriod(period *TrackingPeriod) (*TrackingPeriod, error) {
+func (store *PostgresTrackingPeriodStore) CreateTrackingPeriod(ctx context.Context, period *TrackingPeriod) (*TrackingPeriod, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	// Check if there's an active tracking period for this user
 	var existingPeriod TrackingPeriod
-	err := store.DB.Where("user_id = ? AND is_completed = ?", period.UserID, false).First(&existingPeriod).Error
+	err := db.Where("user_id = ? AND is_completed = ?", period.UserID, false).First(&existingPeriod).Error
 	if err == nil {
 		// An active period already exists
 		return &existingPeriod, nil
@@ -31,16 +40,19 @@ func (store *PostgresTrackingPeriodStore) CreateTrackingPeriod(period *TrackingP
 	}
 
 	// Create a new tracking period
-	err = store.DB.Create(period).Error
+	err = db.Create(period).Error
 	if err != nil {
 		return nil, err
 	}
 	return period, nil
 }
 
-func (store *PostgresTrackingPeriodStore) GetTrackingPeriod(id int64) (*TrackingPeriod, error) {
+func (store *PostgresTrackingPeriodStore) GetTrackingPeriod(ctx context.Context, id int64) (*TrackingPeriod, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var period TrackingPeriod
-	err := store.DB.First(&period, id).Error
+	err := db.First(&period, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -50,9 +62,12 @@ func (store *PostgresTrackingPeriodStore) GetTrackingPeriod(id int64) (*Tracking
 	return &period, nil
 }
 
-func (store *PostgresTrackingPeriodStore) GetCurrentTrackingPeriod(userID int64) (*TrackingPeriod, error) {
+func (store *PostgresTrackingPeriodStore) GetCurrentTrackingPeriod(ctx context.Context, userID int64, asOf time.Time) (*TrackingPeriod, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var period TrackingPeriod
-	err := store.DB.Where("user_id = ? AND is_completed = ?", userID, false).First(&period).Error
+	err := db.Where("user_id = ? AND is_completed = ? AND end_date > ?", userID, false, asOf).First(&period).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -62,9 +77,12 @@ func (store *PostgresTrackingPeriodStore) GetCurrentTrackingPeriod(userID int64)
 	return &period, nil
 }
 
-func (store *PostgresTrackingPeriodStore) GetLastCompletedTrackingPeriod(userID int64) (*TrackingPeriod, error) {
+func (store *PostgresTrackingPeriodStore) GetLastCompletedTrackingPeriod(ctx context.Context, userID int64) (*TrackingPeriod, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var period TrackingPeriod
-	err := store.DB.Where("user_id = ? AND is_completed = ?", userID, true).
+	err := db.Where("user_id = ? AND status = ?", userID, TrackingPeriodCompleted).
 		Order("end_date DESC").
 		First(&period).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -76,43 +94,108 @@ func (store *PostgresTrackingPeriodStore) GetLastCompletedTrackingPeriod(userID
 	return &period, nil
 }
 
-func (store *PostgresTrackingPeriodStore) ListUserTrackingPeriods(userID int64) ([]*TrackingPeriod, error) {
+func (store *PostgresTrackingPeriodStore) ListUserTrackingPeriods(ctx context.Context, userID int64) ([]*TrackingPeriod, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var periods []*TrackingPeriod
-	err := store.DB.Where("user_id = ?", userID).Order("start_date DESC").Find(&periods).Error
+	err := db.Where("user_id = ?", userID).Order("start_date DESC").Find(&periods).Error
 	if err != nil {
 		return nil, err
 	}
 	return periods, nil
 }
 
-func (store *PostgresTrackingPeriodStore) UpdateTrackingPeriod(period *TrackingPeriod) error {
-	err := store.DB.Save(period).Error
+func (store *PostgresTrackingPeriodStore) UpdateTrackingPeriod(ctx context.Context, period *TrackingPeriod) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(period).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresTrackingPeriodStore) CompleteTrackingPeriod(id int64) error {
-	return store.DB.Model(&TrackingPeriod{}).Where("id = ?", id).Update("is_completed", true).Error
+func (store *PostgresTrackingPeriodStore) CompleteTrackingPeriod(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	return db.Model(&TrackingPeriod{}).Where("id = ?", id).Updates(map[string]any{
+		"is_completed":  true,
+		"status":        TrackingPeriodCompleted,
+		"status_reason": "",
+	}).Error
+}
+
+func (store *PostgresTrackingPeriodStore) CloseTrackingPeriod(
+	ctx context.Context,
+	id int64,
+	status string,
+	reason string,
+) (bool, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	// The condition on is_completed makes a concurrent close wait for this
+	// one and then match nothing.
+	result := db.Model(&TrackingPeriod{}).
+		Where("id = ? AND is_completed = ?", id, false).
+		Updates(map[string]any{
+			"is_completed":  true,
+			"status":        status,
+			"status_reason": reason,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListExpiredTrackingPeriods returns the open periods of every user whose
+// end date is at or before asOf, oldest first.
+func (store *PostgresTrackingPeriodStore) ListExpiredTrackingPeriods(ctx context.Context, asOf time.Time) ([]*TrackingPeriod, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var periods []*TrackingPeriod
+	err := db.Where("is_completed = ? AND end_date <= ?", false, asOf).
+		Order("end_date, id").
+		Find(&periods).Error
+	if err != nil {
+		return nil, err
+	}
+	return periods, nil
+}
+
+func (store *PostgresTrackingPeriodStore) DeleteTrackingPeriod(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&TrackingPeriod{}, id).Error
+	if err != nil {
+		return err
+	}
+	return nil
 }
 
 // PostgresMealEntryStore implements MealEntryStore interface
 type PostgresMealEntryStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresMealEntryStore(db *gorm.DB) *PostgresMealEntryStore {
-	if err := db.AutoMigrate(&MealEntry{}); err != nil {
-		panic("failed to migrate meal entry schema: " + err.Error())
-	}
 	return &PostgresMealEntryStore{DB: db}
 }
 
-func (store *PostgresMealEntryStore) CreateMealEntry(entry *MealEntry) (*MealEntry, error) {
+func (store *PostgresMealEntryStore) CreateMealEntry(ctx context.Context, entry *MealEntry) (*MealEntry, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	// Check if an entry already exists for this meal
 	var existingEntry MealEntry
-	err := store.DB.Where(
+	err := db.Where(
 		"user_id = ? AND tracking_period_id = ? AND tracking_day = ? AND meal_type = ?",
 		entry.UserID, entry.TrackingPeriodID, entry.TrackingDay, entry.MealType,
 	).First(&existingEntry).Error
@@ -126,16 +209,19 @@ func (store *PostgresMealEntryStore) CreateMealEntry(entry *MealEntry) (*MealEnt
 	}
 
 	// Create new entry
-	err = store.DB.Create(entry).Error
+	err = db.Create(entry).Error
 	if err != nil {
 		return nil, err
 	}
 	return entry, nil
 }
 
-func (store *PostgresMealEntryStore) GetMealEntry(id int64) (*MealEntry, error) {
+func (store *PostgresMealEntryStore) GetMealEntry(ctx context.Context, id int64) (*MealEntry, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var entry MealEntry
-	err := store.DB.First(&entry, id).Error
+	err := db.First(&entry, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -146,13 +232,17 @@ func (store *PostgresMealEntryStore) GetMealEntry(id int64) (*MealEntry, error)
 }
 
 func (store *PostgresMealEntryStore) GetMealEntryByDetails(
+	ctx context.Context,
 	userID int64,
 	trackingPeriodID int64,
 	day int,
-	mealType string,
+	mealType MealType,
 ) (*MealEntry, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var entry MealEntry
-	err := store.DB.Where(
+	err := db.Where(
 		"user_id = ? AND tracking_period_id = ? AND tracking_day = ? AND meal_type = ?",
 		userID, trackingPeriodID, day, mealType,
 	).First(&entry).Error
@@ -165,9 +255,12 @@ func (store *PostgresMealEntryStore) GetMealEntryByDetails(
 	return &entry, nil
 }
 
-func (store *PostgresMealEntryStore) ListUserMealEntries(userID int64, trackingPeriodID int64) ([]*MealEntry, error) {
+func (store *PostgresMealEntryStore) ListUserMealEntries(ctx context.Context, userID int64, trackingPeriodID int64) ([]*MealEntry, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var entries []*MealEntry
-	err := store.DB.Where(
+	err := db.Where(
 		"user_id = ? AND tracking_period_id = ?",
 		userID, trackingPeriodID,
 	).Order("tracking_day, meal_type").Find(&entries).Error
@@ -177,20 +270,29 @@ func (store *PostgresMealEntryStore) ListUserMealEntries(userID int64, trackingP
 	return entries, nil
 }
 
-func (store *PostgresMealEntryStore) UpdateMealEntry(entry *MealEntry) error {
-	err := store.DB.Save(entry).Error
+func (store *PostgresMealEntryStore) UpdateMealEntry(ctx context.Context, entry *MealEntry) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(entry).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresMealEntryStore) CompleteMealEntry(id int64) error {
-	return store.DB.Model(&MealEntry{}).Where("id = ?", id).Update("is_completed", true).Error
+func (store *PostgresMealEntryStore) CompleteMealEntry(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	return db.Model(&MealEntry{}).Where("id = ?", id).Update("is_completed", true).Error
 }
 
-func (store *PostgresMealEntryStore) DeleteMealEntry(id int64) error {
-	err := store.DB.Delete(&MealEntry{}, id).Error
+func (store *PostgresMealEntryStore) DeleteMealEntry(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&MealEntry{}, id).Error
 	if err != nil {
 		return err
 	}
@@ -199,27 +301,34 @@ func (store *PostgresMealEntryStore) DeleteMealEntry(id int64) error {
 
 // PostgresFoodItemStore implements FoodItemStore interface
 type PostgresFoodItemStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresFoodItemStore(db *gorm.DB) *PostgresFoodItemStore {
-	if err := db.AutoMigrate(&FoodItem{}); err != nil {
-		panic("failed to migrate food item schema: " + err.Error())
-	}
 	return &PostgresFoodItemStore{DB: db}
 }
 
-func (store *PostgresFoodItemStore) CreateFoodItem(item *FoodItem) (*FoodItem, error) {
-	err := store.DB.Create(item).Error
+func (store *PostgresFoodItemStore) CreateFoodItem(ctx context.Context, item *FoodItem) (*FoodItem, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(item).Error
 	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, ErrRecordConflict
+		}
 		return nil, err
 	}
 	return item, nil
 }
 
-func (store *PostgresFoodItemStore) GetFoodItem(id int64) (*FoodItem, error) {
+func (store *PostgresFoodItemStore) GetFoodItem(ctx context.Context, id int64) (*FoodItem, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var item FoodItem
-	err := store.DB.First(&item, id).Error
+	err := db.First(&item, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -229,9 +338,12 @@ func (store *PostgresFoodItemStore) GetFoodItem(id int64) (*FoodItem, error) {
 	return &item, nil
 }
 
-func (store *PostgresFoodItemStore) GetFoodItemByName(name string) (*FoodItem, error) {
+func (store *PostgresFoodItemStore) GetFoodItemByName(ctx context.Context, name string) (*FoodItem, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var item FoodItem
-	err := store.DB.Where("name = ?", name).First(&item).Error
+	err := db.Where("lower(name) = lower(?)", name).First(&item).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -241,34 +353,165 @@ func (store *PostgresFoodItemStore) GetFoodItemByName(name string) (*FoodItem, e
 	return &item, nil
 }
 
-func (store *PostgresFoodItemStore) ListFoodItems() ([]*FoodItem, error) {
+func (store *PostgresFoodItemStore) ListFoodItems(ctx context.Context) ([]*FoodItem, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var items []*FoodItem
+	err := db.Order("name").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (store *PostgresFoodItemStore) ListFoodItemsByCategory(ctx context.Context, category string) ([]*FoodItem, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var items []*FoodItem
+	err := db.Where("category = ?", category).Order("name").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (store *PostgresFoodItemStore) ListFoodItemsWithAllergens(
+	ctx context.Context,
+	allergens Allergens,
+) ([]*FoodItem, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var items []*FoodItem
-	err := store.DB.Order("name").Find(&items).Error
+	err := db.Where("allergens & ? <> 0", int64(allergens)).Order("name").Find(&items).Error
 	if err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
-func (store *PostgresFoodItemStore) ListFoodItemsByCategory(category string) ([]*FoodItem, error) {
+func (store *PostgresFoodItemStore) ListFoodItemsWithFODMAPs(
+	ctx context.Context,
+	fodmaps FODMAPs,
+) ([]*FoodItem, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var items []*FoodItem
-	err := store.DB.Where("category = ?", category).Order("name").Find(&items).Error
+	err := db.Where("fodmaps & ? <> 0", int64(fodmaps)).Order("name").Find(&items).Error
 	if err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
-func (store *PostgresFoodItemStore) UpdateFoodItem(item *FoodItem) error {
-	err := store.DB.Save(item).Error
+// SearchFoodItems finds the best candidates with pg_trgm, at most
+// searchCandidateFactor per result, and ranks them with the same ranker as
+// the in-memory store.
+func (store *PostgresFoodItemStore) SearchFoodItems(
+	ctx context.Context,
+	query string,
+	limit int,
+) ([]*FoodSearchResult, error) {
+	q := search.Normalize(query)
+	if q == "" {
+		return nil, nil
+	}
+
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var items []*FoodItem
+	err := withSimilarityThreshold(db, func(tx *gorm.DB) error {
+		return tx.Where(
+			"lower(name) LIKE @prefix OR lower(synonyms) LIKE @word "+
+				"OR @q <% lower(name) OR @q <% lower(synonyms)",
+			sql.Named("q", q),
+			sql.Named("prefix", likeEscape(q)+"%"),
+			sql.Named("word", "%"+likeEscape(q)+"%"),
+		).Order(clause.OrderBy{Expression: clause.Expr{
+			SQL: "lower(name) LIKE ? DESC, " +
+				"greatest(word_similarity(?, lower(name)), word_similarity(?, lower(synonyms))) DESC, id",
+			Vars:               []any{likeEscape(q) + "%", q, q},
+			WithoutParentheses: true,
+		}}).Limit(foodSearchLimit(limit) * searchCandidateFactor).Find(&items).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rankFoodItems(query, items, limit), nil
+}
+
+// searchCandidateFactor is how many candidates per requested result
+// SearchFoodItems fetches for the pure-Go ranker to choose from. Prefix
+// matches are fetched first and fuzzy matches by descending similarity, so
+// the ones left out would rank last anyway.
+const searchCandidateFactor = 5
+
+// withSimilarityThreshold runs fn in a transaction in which the <% operator
+// matches at search.DefaultThreshold. The operator reads its threshold from
+// pg_trgm.word_similarity_threshold. The setting is made local to the
+// transaction so it does not leak to other users of the connection, and the
+// previous value is restored afterwards because inside a caller's
+// transaction db.Transaction only opens a savepoint, and releasing it keeps
+// the setting for the rest of the caller's transaction. When fn fails the
+// transaction or savepoint is rolled back, which undoes the setting too.
+func withSimilarityThreshold(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var previous string
+		err := tx.Raw(
+			"SELECT coalesce(current_setting(?, true), ?)",
+			wordSimilarityThresholdSetting, defaultWordSimilarityThreshold,
+		).Scan(&previous).Error
+		if err != nil {
+			return err
+		}
+
+		threshold := strconv.FormatFloat(search.DefaultThreshold, 'f', -1, 64)
+		if err := setLocal(tx, wordSimilarityThresholdSetting, threshold); err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return setLocal(tx, wordSimilarityThresholdSetting, previous)
+	})
+}
+
+const (
+	wordSimilarityThresholdSetting = "pg_trgm.word_similarity_threshold"
+	// defaultWordSimilarityThreshold is pg_trgm's own default, used when
+	// the extension has not been loaded into the session yet.
+	defaultWordSimilarityThreshold = "0.6"
+)
+
+// setLocal sets a configuration parameter until the end of the current
+// transaction.
+func setLocal(tx *gorm.DB, name, value string) error {
+	return tx.Exec("SELECT set_config(?, ?, true)", name, value).Error
+}
+
+func (store *PostgresFoodItemStore) UpdateFoodItem(ctx context.Context, item *FoodItem) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(item).Error
 	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return ErrRecordConflict
+		}
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresFoodItemStore) DeleteFoodItem(id int64) error {
-	err := store.DB.Delete(&FoodItem{}, id).Error
+func (store *PostgresFoodItemStore) DeleteFoodItem(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&FoodItem{}, id).Error
 	if err != nil {
 		return err
 	}
@@ -277,43 +520,53 @@ func (store *PostgresFoodItemStore) DeleteFoodItem(id int64) error {
 
 // PostgresMealFoodStore implements MealFoodStore interface
 type PostgresMealFoodStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresMealFoodStore(db *gorm.DB) *PostgresMealFoodStore {
-	if err := db.AutoMigrate(&MealFood{}); err != nil {
-		panic("failed to migrate meal food schema: " + err.Error())
-	}
 	return &PostgresMealFoodStore{DB: db}
 }
 
-func (store *PostgresMealFoodStore) CreateMealFood(mealFood *MealFood) (*MealFood, error) {
-	err := store.DB.Create(mealFood).Error
+func (store *PostgresMealFoodStore) CreateMealFood(ctx context.Context, mealFood *MealFood) (*MealFood, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(mealFood).Error
 	if err != nil {
 		return nil, err
 	}
 	return mealFood, nil
 }
 
-func (store *PostgresMealFoodStore) GetMealFoodsForMeal(mealEntryID int64) ([]*MealFood, error) {
+func (store *PostgresMealFoodStore) GetMealFoodsForMeal(ctx context.Context, mealEntryID int64) ([]*MealFood, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var mealFoods []*MealFood
-	err := store.DB.Where("meal_entry_id = ?", mealEntryID).Find(&mealFoods).Error
+	err := db.Where("meal_entry_id = ?", mealEntryID).Find(&mealFoods).Error
 	if err != nil {
 		return nil, err
 	}
 	return mealFoods, nil
 }
 
-func (store *PostgresMealFoodStore) DeleteMealFood(id int64) error {
-	err := store.DB.Delete(&MealFood{}, id).Error
+func (store *PostgresMealFoodStore) DeleteMealFood(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&MealFood{}, id).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresMealFoodStore) DeleteAllMealFoodsForMeal(mealEntryID int64) error {
-	err := store.DB.Where("meal_entry_id = ?", mealEntryID).Delete(&MealFood{}).Error
+func (store *PostgresMealFoodStore) DeleteAllMealFoodsForMeal(ctx context.Context, mealEntryID int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Where("meal_entry_id = ?", mealEntryID).Delete(&MealFood{}).Error
 	if err != nil {
 		return err
 	}
@@ -322,101 +575,193 @@ func (store *PostgresMealFoodStore) DeleteAllMealFoodsForMeal(mealEntryID int64)
 
 // PostgresCustomFoodStore implements CustomFoodStore interface
 type PostgresCustomFoodStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresCustomFoodStore(db *gorm.DB) *PostgresCustomFoodStore {
-	if err := db.AutoMigrate(&CustomFood{}); err != nil {
-		panic("failed to migrate custom food schema: " + err.Error())
-	}
 	return &PostgresCustomFoodStore{DB: db}
 }
 
-func (store *PostgresCustomFoodStore) CreateCustomFood(food *CustomFood) (*CustomFood, error) {
-	err := store.DB.Create(food).Error
+func (store *PostgresCustomFoodStore) CreateCustomFood(ctx context.Context, food *CustomFood) (*CustomFood, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(food).Error
 	if err != nil {
 		return nil, err
 	}
 	return food, nil
 }
 
-func (store *PostgresCustomFoodStore) GetCustomFoodsForMeal(mealEntryID int64) ([]*CustomFood, error) {
+func (store *PostgresCustomFoodStore) GetCustomFoodsForMeal(ctx context.Context, mealEntryID int64) ([]*CustomFood, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var customFoods []*CustomFood
-	err := store.DB.Where("meal_entry_id = ?", mealEntryID).Find(&customFoods).Error
+	err := db.Where("meal_entry_id = ?", mealEntryID).Find(&customFoods).Error
 	if err != nil {
 		return nil, err
 	}
 	return customFoods, nil
 }
 
-func (store *PostgresCustomFoodStore) UpdateCustomFood(food *CustomFood) error {
-	err := store.DB.Save(food).Error
+func (store *PostgresCustomFoodStore) UpdateCustomFood(ctx context.Context, food *CustomFood) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(food).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresCustomFoodStore) DeleteCustomFood(id int64) error {
-	err := store.DB.Delete(&CustomFood{}, id).Error
+func (store *PostgresCustomFoodStore) DeleteCustomFood(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&CustomFood{}, id).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresCustomFoodStore) DeleteAllCustomFoodsForMeal(mealEntryID int64) error {
-	err := store.DB.Where("meal_entry_id = ?", mealEntryID).Delete(&CustomFood{}).Error
+func (store *PostgresCustomFoodStore) DeleteAllCustomFoodsForMeal(ctx context.Context, mealEntryID int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Where("meal_entry_id = ?", mealEntryID).Delete(&CustomFood{}).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+func (store *PostgresCustomFoodStore) SearchCustomFoods(
+	ctx context.Context,
+	userID int64,
+	query string,
+	limit int,
+) ([]*FoodSearchResult, error) {
+	q := search.Normalize(query)
+	if q == "" {
+		return nil, nil
+	}
+
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var foods []*CustomFood
+	err := withSimilarityThreshold(db, func(tx *gorm.DB) error {
+		return tx.Joins("JOIN meal_entries ON meal_entries.id = custom_foods.meal_entry_id").
+			Where("meal_entries.user_id = @user", sql.Named("user", userID)).
+			Where(
+				"lower(custom_foods.name) LIKE @prefix OR @q <% lower(custom_foods.name)",
+				sql.Named("q", q),
+				sql.Named("prefix", likeEscape(q)+"%"),
+			).
+			Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "word_similarity(?, lower(custom_foods.name)) DESC, custom_foods.id",
+				Vars:               []any{q},
+				WithoutParentheses: true,
+			}}).
+			Find(&foods).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rankCustomFoods(query, foods, limit), nil
+}
+
+func (store *PostgresCustomFoodStore) ListCustomFoodUsage(ctx context.Context) ([]*CustomFoodUsage, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var usage []*CustomFoodUsage
+	err := db.Model(&CustomFood{}).
+		Select("custom_foods.name, meal_entries.user_id, count(*) AS uses").
+		Joins("JOIN meal_entries ON meal_entries.id = custom_foods.meal_entry_id").
+		Group("custom_foods.name, meal_entries.user_id").
+		Order("custom_foods.name, meal_entries.user_id").
+		Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (store *PostgresCustomFoodStore) ListCustomFoodsByName(
+	ctx context.Context,
+	names []string,
+) ([]*CustomFood, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var customFoods []*CustomFood
+	err := db.Where("name IN ?", names).Order("id").Find(&customFoods).Error
+	if err != nil {
+		return nil, err
+	}
+	return customFoods, nil
+}
+
 // PostgresSymptomStore implements SymptomStore interface
 type PostgresSymptomStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresSymptomStore(db *gorm.DB) *PostgresSymptomStore {
-	if err := db.AutoMigrate(&Symptom{}); err != nil {
-		panic("failed to migrate symptom schema: " + err.Error())
-	}
 	return &PostgresSymptomStore{DB: db}
 }
 
-func (store *PostgresSymptomStore) CreateSymptom(symptom *Symptom) (*Symptom, error) {
-	// Check if a symptom of this type already exists for this meal
-	var existingSymptom Symptom
-	err := store.DB.Where(
-		"meal_entry_id = ? AND symptom_type = ? AND is_overnight = ?",
-		symptom.MealEntryID, symptom.SymptomType, symptom.IsOvernight,
-	).First(&existingSymptom).Error
-
-	if err == nil {
-		// Update existing symptom severity
-		existingSymptom.Severity = symptom.Severity
-		err = store.DB.Save(&existingSymptom).Error
-		if err != nil {
+func (store *PostgresSymptomStore) CreateSymptom(ctx context.Context, symptom *Symptom) (*Symptom, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	// Check if a symptom of this type already exists for this meal.
+	// Standalone symptoms are always new.
+	if symptom.MealEntryID != 0 {
+		var existingSymptom Symptom
+		err := db.Where(
+			"meal_entry_id = ? AND symptom_type = ? AND is_overnight = ?",
+			symptom.MealEntryID, symptom.SymptomType, symptom.IsOvernight,
+		).First(&existingSymptom).Error
+
+		if err == nil {
+			// Update existing symptom severity
+			existingSymptom.Severity = symptom.Severity
+			err = db.Save(&existingSymptom).Error
+			if err != nil {
+				return nil, err
+			}
+			return &existingSymptom, nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			// Some other error occurred
 			return nil, err
 		}
-		return &existingSymptom, nil
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		// Some other error occurred
-		return nil, err
 	}
 
 	// Create new symptom
-	err = store.DB.Create(symptom).Error
+	err := db.Create(symptom).Error
 	if err != nil {
 		return nil, err
 	}
 	return symptom, nil
 }
 
-func (store *PostgresSymptomStore) GetSymptom(id int64) (*Symptom, error) {
+func (store *PostgresSymptomStore) GetSymptom(ctx context.Context, id int64) (*Symptom, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var symptom Symptom
-	err := store.DB.First(&symptom, id).Error
+	err := db.First(&symptom, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -426,9 +771,12 @@ func (store *PostgresSymptomStore) GetSymptom(id int64) (*Symptom, error) {
 	return &symptom, nil
 }
 
-func (store *PostgresSymptomStore) GetSymptomByTypeForMeal(mealEntryID int64, symptomType string) (*Symptom, error) {
+func (store *PostgresSymptomStore) GetSymptomByTypeForMeal(ctx context.Context, mealEntryID int64, symptomType string) (*Symptom, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var symptom Symptom
-	err := store.DB.Where(
+	err := db.Where(
 		"meal_entry_id = ? AND symptom_type = ?",
 		mealEntryID, symptomType,
 	).First(&symptom).Error
@@ -441,35 +789,110 @@ func (store *PostgresSymptomStore) GetSymptomByTypeForMeal(mealEntryID int64, sy
 	return &symptom, nil
 }
 
-func (store *PostgresSymptomStore) ListSymptomsForMeal(mealEntryID int64) ([]*Symptom, error) {
+func (store *PostgresSymptomStore) ListSymptomsForMeal(ctx context.Context, mealEntryID int64) ([]*Symptom, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var symptoms []*Symptom
+	err := db.Where("meal_entry_id = ?", mealEntryID).Find(&symptoms).Error
+	if err != nil {
+		return nil, err
+	}
+	return symptoms, nil
+}
+
+func (store *PostgresSymptomStore) ListStandaloneSymptoms(
+	ctx context.Context,
+	userID int64,
+	trackingPeriodID int64,
+) ([]*Symptom, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var symptoms []*Symptom
-	err := store.DB.Where("meal_entry_id = ?", mealEntryID).Find(&symptoms).Error
+	err := db.Where(
+		"meal_entry_id = 0 AND user_id = ? AND tracking_period_id = ?",
+		userID, trackingPeriodID,
+	).Order("occurred_at, id").Find(&symptoms).Error
 	if err != nil {
 		return nil, err
 	}
 	return symptoms, nil
 }
 
-func (store *PostgresSymptomStore) UpdateSymptom(symptom *Symptom) error {
-	err := store.DB.Save(symptom).Error
+func (store *PostgresSymptomStore) UpdateSymptom(ctx context.Context, symptom *Symptom) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(symptom).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresSymptomStore) DeleteSymptom(id int64) error {
-	err := store.DB.Delete(&Symptom{}, id).Error
+func (store *PostgresSymptomStore) DeleteSymptom(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&Symptom{}, id).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresSymptomStore) DeleteAllSymptomsForMeal(mealEntryID int64) error {
-	err := store.DB.Where("meal_entry_id = ?", mealEntryID).Delete(&Symptom{}).Error
+func (store *PostgresSymptomStore) DeleteAllSymptomsForMeal(ctx context.Context, mealEntryID int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Where("meal_entry_id = ?", mealEntryID).Delete(&Symptom{}).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// SummarizeSymptomsByBodySystem aggregates in SQL; the relative severity
+// expression mirrors SymptomType.RelativeSeverity. Standalone symptoms have
+// no meal entry and carry the user and tracking period themselves.
+func (store *PostgresSymptomStore) SummarizeSymptomsByBodySystem(
+	ctx context.Context,
+	userID int64,
+	trackingPeriodID int64,
+) ([]*BodySystemSummary, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	const relative = "coalesce((symptoms.severity - symptom_types.severity_min)::float8 / " +
+		"nullif(symptom_types.severity_max - symptom_types.severity_min, 0), 0)"
+
+	query := db.Model(&Symptom{}).
+		Select("symptom_types.body_system, "+
+			"count(DISTINCT symptom_types.id) AS symptom_types, "+
+			"count(*) AS symptoms, "+
+			"avg("+relative+") AS mean_severity, "+
+			"max("+relative+") AS max_severity").
+		Joins("LEFT JOIN meal_entries ON meal_entries.id = symptoms.meal_entry_id").
+		Joins("JOIN symptom_types ON lower(symptom_types.name) = lower(symptoms.symptom_type)").
+		Where(
+			"((symptoms.meal_entry_id = 0 AND symptoms.user_id = ?) OR meal_entries.user_id = ?)",
+			userID, userID,
+		)
+	if trackingPeriodID != 0 {
+		query = query.Where(
+			"((symptoms.meal_entry_id = 0 AND symptoms.tracking_period_id = ?) OR "+
+				"meal_entries.tracking_period_id = ?)",
+			trackingPeriodID, trackingPeriodID,
+		)
+	}
+
+	var summaries []*BodySystemSummary
+	err := query.Group("symptom_types.body_system").
+		Order("symptom_types.body_system").
+		Scan(&summaries).Error
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
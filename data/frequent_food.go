@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"time"
 )
 
@@ -13,9 +14,9 @@ type FrequentFood struct {
 }
 
 type FrequentFoodStore interface {
-	CreateFrequentFood(food *FrequentFood) (*FrequentFood, error)
-	GetFrequentFood(id int64) (*FrequentFood, error)
-	ListUserFrequentFoods(userID int64) ([]*FrequentFood, error)
-	UpdateFrequentFood(food *FrequentFood) error
-	DeleteFrequentFood(id int64) error
+	CreateFrequentFood(ctx context.Context, food *FrequentFood) (*FrequentFood, error)
+	GetFrequentFood(ctx context.Context, id int64) (*FrequentFood, error)
+	ListUserFrequentFoods(ctx context.Context, userID int64) ([]*FrequentFood, error)
+	UpdateFrequentFood(ctx context.Context, food *FrequentFood) error
+	DeleteFrequentFood(ctx context.Context, id int64) error
 }
@@ -0,0 +1,96 @@
+package data
+
+import "context"
+
+type MemoryFrequentFoodStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryFrequentFoodStore(db *MemoryDB) *MemoryFrequentFoodStore {
+	return &MemoryFrequentFoodStore{db: db}
+}
+
+func (store *MemoryFrequentFoodStore) CreateFrequentFood(
+	ctx context.Context,
+	food *FrequentFood,
+) (*FrequentFood, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if food.ID != 0 {
+		if _, exists := store.db.frequentFoods.get(food.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		food.ID = store.db.frequentFoods.nextID()
+	}
+	setCreateTimestamps(&food.CreatedAt, &food.UpdatedAt)
+	store.db.frequentFoods.put(food.ID, *food)
+
+	return food, nil
+}
+
+func (store *MemoryFrequentFoodStore) GetFrequentFood(
+	ctx context.Context,
+	id int64,
+) (*FrequentFood, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	food, ok := store.db.frequentFoods.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &food, nil
+}
+
+func (store *MemoryFrequentFoodStore) ListUserFrequentFoods(
+	ctx context.Context,
+	userID int64,
+) ([]*FrequentFood, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.frequentFoods.filter(func(row *FrequentFood) bool {
+		return row.UserID == userID
+	}), nil
+}
+
+func (store *MemoryFrequentFoodStore) UpdateFrequentFood(
+	ctx context.Context,
+	food *FrequentFood,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.frequentFoods.get(food.ID)
+	if food.ID == 0 || !ok {
+		if food.ID == 0 {
+			food.ID = store.db.frequentFoods.nextID()
+		}
+		setCreateTimestamps(&food.CreatedAt, &food.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&food.CreatedAt, &food.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.frequentFoods.put(food.ID, *food)
+
+	return nil
+}
+
+func (store *MemoryFrequentFoodStore) DeleteFrequentFood(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.frequentFoods.delete(id)
+	return nil
+}
@@ -1,33 +1,45 @@
 package data
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type PostgresFrequentFoodStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresFrequentFoodStore(db *gorm.DB) *PostgresFrequentFoodStore {
-	if err := db.AutoMigrate(&FrequentFood{}); err != nil {
-		panic("failed to migrate frequent food schema: " + err.Error())
-	}
 	return &PostgresFrequentFoodStore{DB: db}
 }
 
-func (store *PostgresFrequentFoodStore) CreateFrequentFood(food *FrequentFood) (*FrequentFood, error) {
-	err := store.DB.Create(food).Error
+func (store *PostgresFrequentFoodStore) CreateFrequentFood(
+	ctx context.Context,
+	food *FrequentFood,
+) (*FrequentFood, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(food).Error
 	if err != nil {
 		return nil, err
 	}
 	return food, nil
 }
 
-func (store *PostgresFrequentFoodStore) GetFrequentFood(id int64) (*FrequentFood, error) {
+func (store *PostgresFrequentFoodStore) GetFrequentFood(
+	ctx context.Context,
+	id int64,
+) (*FrequentFood, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var food FrequentFood
-	err := store.DB.First(&food, id).Error
+	err := db.First(&food, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -37,25 +49,40 @@ func (store *PostgresFrequentFoodStore) GetFrequentFood(id int64) (*FrequentFood
 	return &food, nil
 }
 
-func (store *PostgresFrequentFoodStore) ListUserFrequentFoods(userID int64) ([]*FrequentFood, error) {
+func (store *PostgresFrequentFoodStore) ListUserFrequentFoods(
+	ctx context.Context,
+	userID int64,
+) ([]*FrequentFood, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var foods []*FrequentFood
-	err := store.DB.Where("user_id = ?", userID).Find(&foods).Error
+	err := db.Where("user_id = ?", userID).Find(&foods).Error
 	if err != nil {
 		return nil, err
 	}
 	return foods, nil
 }
 
-func (store *PostgresFrequentFoodStore) UpdateFrequentFood(food *FrequentFood) error {
-	err := store.DB.Save(food).Error
+func (store *PostgresFrequentFoodStore) UpdateFrequentFood(
+	ctx context.Context,
+	food *FrequentFood,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(food).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresFrequentFoodStore) DeleteFrequentFood(id int64) error {
-	err := store.DB.Delete(&FrequentFood{}, id).Error
+func (store *PostgresFrequentFoodStore) DeleteFrequentFood(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&FrequentFood{}, id).Error
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,392 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"time"
+)
+
+// HealthRecordSchemaVersion is the version of the HealthRecord document
+// format. Bump it whenever a field is renamed or removed.
+const HealthRecordSchemaVersion = 2
+
+// HealthRecord is everything stored about a single user, assembled so it can
+// be handed to them as one document. Credentials are never part of it: the
+// password hash is left out of HealthRecordUser and tokens are not exported.
+type HealthRecord struct {
+	SchemaVersion int       `json:"schema_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+
+	User               HealthRecordUser    `json:"user"`
+	UserIntake         *UserIntake         `json:"user_intake"`
+	MedicalInformation *MedicalInformation `json:"medical_information"`
+
+	Caregivers         []*Caregiver              `json:"caregivers"`
+	EmergencyContacts  []*EmergencyContact       `json:"emergency_contacts"`
+	MedicalEvents      []*MedicalEvent           `json:"medical_events"`
+	FrequentFoods      []*FrequentFood           `json:"frequent_foods"`
+	Allergies          []*Allergy                `json:"allergies"`
+	Medications        []*HealthRecordMedication `json:"medications"`
+	DietarySupplements []*DietarySupplement      `json:"dietary_supplements"`
+
+	TrackingPeriods  []*HealthRecordTrackingPeriod  `json:"tracking_periods"`
+	EliminationPlans []*HealthRecordEliminationPlan `json:"elimination_plans"`
+
+	// FoodItems holds the catalog entries referenced by the exported meal
+	// foods and elimination plans, so the document can be read without the catalog at hand.
+	FoodItems []*FoodItem `json:"food_items"`
+}
+
+// HealthRecordUser is a User without its password hash and associations.
+type HealthRecordUser struct {
+	ID                 int64     `json:"id"`
+	UserName           string    `json:"user_name"`
+	FirstName          string    `json:"first_name"`
+	LastName           string    `json:"last_name"`
+	Email              string    `json:"email"`
+	PhoneNumber        string    `json:"phone_number"`
+	UserIntakeComplete bool      `json:"user_intake_complete"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// HealthRecordMedication is a medication with its schedule, nil if it has
+// none, and the doses logged for it.
+type HealthRecordMedication struct {
+	Medication
+	Schedule *MedicationSchedule `json:"schedule"`
+	DoseLogs []*DoseLog          `json:"dose_logs"`
+}
+
+// HealthRecordTrackingPeriod holds a tracking period with its meals and the
+// standalone symptoms logged during it.
+type HealthRecordTrackingPeriod struct {
+	TrackingPeriod
+	MealEntries []*HealthRecordMealEntry `json:"meal_entries"`
+	Symptoms    []*HealthRecordSymptom   `json:"symptoms"`
+}
+
+type HealthRecordMealEntry struct {
+	MealEntry
+	MealFoods   []*MealFood   `json:"meal_foods"`
+	CustomFoods []*CustomFood `json:"custom_foods"`
+	Symptoms    []*Symptom    `json:"symptoms"`
+}
+
+// HealthRecordSymptom is a standalone symptom with the meals it is
+// attributed to.
+type HealthRecordSymptom struct {
+	Symptom
+	Attributions []*SymptomAttribution `json:"attributions"`
+}
+
+type HealthRecordEliminationPlan struct {
+	EliminationPlan
+	Restrictions        []*EliminationRestriction `json:"restrictions"`
+	ReintroductionSteps []*ReintroductionStep     `json:"reintroduction_steps"`
+}
+
+func newHealthRecordUser(user *User) HealthRecordUser {
+	return HealthRecordUser{
+		ID:                 user.ID,
+		UserName:           user.UserName,
+		FirstName:          user.FirstName,
+		LastName:           user.LastName,
+		Email:              user.Email,
+		PhoneNumber:        user.PhoneNumber,
+		UserIntakeComplete: user.UserIntakeComplete,
+		CreatedAt:          user.CreatedAt,
+		UpdatedAt:          user.UpdatedAt,
+	}
+}
+
+// ExportHealthRecord assembles the complete health record of a user. The
+// reads run in a single transaction so the document is internally
+// consistent. It returns ErrRecordNotFound if the user does not exist.
+func (stores *Stores) ExportHealthRecord(ctx context.Context, userID int64) (*HealthRecord, error) {
+	var record *HealthRecord
+
+	err := stores.WithTx(ctx, func(tx *Stores) error {
+		user, err := tx.UserStore.GetUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		record = &HealthRecord{
+			SchemaVersion: HealthRecordSchemaVersion,
+			User:          newHealthRecordUser(user),
+		}
+
+		if err := exportIntake(ctx, tx, record); err != nil {
+			return err
+		}
+		if err := exportUserRecords(ctx, tx, record); err != nil {
+			return err
+		}
+		foodItems := make(map[int64]*FoodItem)
+		if err := exportTracking(ctx, tx, record, foodItems); err != nil {
+			return err
+		}
+		if err := exportEliminationPlans(ctx, tx, record, foodItems); err != nil {
+			return err
+		}
+
+		record.FoodItems = make([]*FoodItem, 0, len(foodItems))
+		for _, item := range foodItems {
+			record.FoodItems = append(record.FoodItems, item)
+		}
+		sort.Slice(record.FoodItems, func(i, j int) bool {
+			return record.FoodItems[i].ID < record.FoodItems[j].ID
+		})
+
+		record.ExportedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// exportIntake adds the one-per-user intake records, leaving them nil when
+// the user has not filled them in yet.
+func exportIntake(ctx context.Context, tx *Stores, record *HealthRecord) error {
+	userID := record.User.ID
+
+	intake, err := tx.UserIntakeStore.GetUserIntakeByUserID(ctx, userID)
+	switch {
+	case err == nil:
+		record.UserIntake = intake
+	case !errors.Is(err, ErrRecordNotFound):
+		return err
+	}
+
+	medInfo, err := tx.MedicalInformationStore.GetMedicalInformationByUserID(ctx, userID)
+	switch {
+	case err == nil:
+		record.MedicalInformation = medInfo
+	case !errors.Is(err, ErrRecordNotFound):
+		return err
+	}
+
+	return nil
+}
+
+func exportUserRecords(ctx context.Context, tx *Stores, record *HealthRecord) error {
+	userID := record.User.ID
+	var err error
+
+	if record.Caregivers, err = tx.CaregiverStore.ListUserCaregivers(ctx, userID); err != nil {
+		return err
+	}
+	record.EmergencyContacts, err = tx.EmergencyContactStore.ListUserEmergencyContacts(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if record.MedicalEvents, err = tx.MedicalEventStore.ListUserMedicalEvents(ctx, userID); err != nil {
+		return err
+	}
+	if record.FrequentFoods, err = tx.FrequentFoodStore.ListUserFrequentFoods(ctx, userID); err != nil {
+		return err
+	}
+	if record.Allergies, err = tx.AllergyStore.ListUserAllergies(ctx, userID); err != nil {
+		return err
+	}
+	if err := exportMedications(ctx, tx, record); err != nil {
+		return err
+	}
+	record.DietarySupplements, err = tx.DietarySupplementStore.ListUserDietarySupplements(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func exportMedications(ctx context.Context, tx *Stores, record *HealthRecord) error {
+	medications, err := tx.MedicationStore.ListUserMedications(ctx, record.User.ID)
+	if err != nil {
+		return err
+	}
+
+	record.Medications = make([]*HealthRecordMedication, len(medications))
+	for i, medication := range medications {
+		exported := &HealthRecordMedication{Medication: *medication}
+
+		schedule, err := tx.MedicationScheduleStore.GetScheduleForMedication(ctx, medication.ID)
+		switch {
+		case err == nil:
+			exported.Schedule = schedule
+		case !errors.Is(err, ErrRecordNotFound):
+			return err
+		}
+
+		if exported.DoseLogs, err = tx.DoseLogStore.ListDoseLogsForMedication(ctx, medication.ID); err != nil {
+			return err
+		}
+		record.Medications[i] = exported
+	}
+
+	return nil
+}
+
+func exportTracking(
+	ctx context.Context,
+	tx *Stores,
+	record *HealthRecord,
+	foodItems map[int64]*FoodItem,
+) error {
+	userID := record.User.ID
+
+	periods, err := tx.TrackingPeriodStore.ListUserTrackingPeriods(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	record.TrackingPeriods = make([]*HealthRecordTrackingPeriod, 0, len(periods))
+
+	for _, period := range periods {
+		entries, err := tx.MealEntryStore.ListUserMealEntries(ctx, userID, period.ID)
+		if err != nil {
+			return err
+		}
+
+		exported := &HealthRecordTrackingPeriod{
+			TrackingPeriod: *period,
+			MealEntries:    make([]*HealthRecordMealEntry, 0, len(entries)),
+		}
+		for _, entry := range entries {
+			meal, err := exportMealEntry(ctx, tx, entry, foodItems)
+			if err != nil {
+				return err
+			}
+			exported.MealEntries = append(exported.MealEntries, meal)
+		}
+
+		symptoms, err := tx.SymptomStore.ListStandaloneSymptoms(ctx, userID, period.ID)
+		if err != nil {
+			return err
+		}
+		exported.Symptoms = make([]*HealthRecordSymptom, 0, len(symptoms))
+		for _, symptom := range symptoms {
+			attributions, err := tx.SymptomAttributionStore.ListAttributionsForSymptom(ctx, symptom.ID)
+			if err != nil {
+				return err
+			}
+			exported.Symptoms = append(exported.Symptoms, &HealthRecordSymptom{
+				Symptom:      *symptom,
+				Attributions: attributions,
+			})
+		}
+
+		record.TrackingPeriods = append(record.TrackingPeriods, exported)
+	}
+
+	return nil
+}
+
+// exportMealEntry collects the foods and symptoms of a meal entry, adding
+// any food item it references to foodItems.
+func exportMealEntry(
+	ctx context.Context,
+	tx *Stores,
+	entry *MealEntry,
+	foodItems map[int64]*FoodItem,
+) (*HealthRecordMealEntry, error) {
+	meal := &HealthRecordMealEntry{MealEntry: *entry}
+	var err error
+
+	if meal.MealFoods, err = tx.MealFoodStore.GetMealFoodsForMeal(ctx, entry.ID); err != nil {
+		return nil, err
+	}
+	if meal.CustomFoods, err = tx.CustomFoodStore.GetCustomFoodsForMeal(ctx, entry.ID); err != nil {
+		return nil, err
+	}
+	if meal.Symptoms, err = tx.SymptomStore.ListSymptomsForMeal(ctx, entry.ID); err != nil {
+		return nil, err
+	}
+
+	for _, mealFood := range meal.MealFoods {
+		if err := exportFoodItem(ctx, tx, mealFood.FoodItemID, foodItems); err != nil {
+			return nil, err
+		}
+	}
+
+	return meal, nil
+}
+
+func exportEliminationPlans(
+	ctx context.Context,
+	tx *Stores,
+	record *HealthRecord,
+	foodItems map[int64]*FoodItem,
+) error {
+	plans, err := tx.EliminationPlanStore.ListUserEliminationPlans(ctx, record.User.ID)
+	if err != nil {
+		return err
+	}
+
+	record.EliminationPlans = make([]*HealthRecordEliminationPlan, 0, len(plans))
+	for _, plan := range plans {
+		exported := &HealthRecordEliminationPlan{EliminationPlan: *plan}
+
+		exported.Restrictions, err = tx.EliminationRestrictionStore.ListEliminationRestrictions(ctx, plan.ID)
+		if err != nil {
+			return err
+		}
+		exported.ReintroductionSteps, err = tx.ReintroductionStepStore.ListReintroductionSteps(ctx, plan.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, restriction := range exported.Restrictions {
+			if err := exportFoodItem(ctx, tx, restriction.FoodItemID, foodItems); err != nil {
+				return err
+			}
+		}
+		for _, step := range exported.ReintroductionSteps {
+			if err := exportFoodItem(ctx, tx, step.FoodItemID, foodItems); err != nil {
+				return err
+			}
+		}
+
+		record.EliminationPlans = append(record.EliminationPlans, exported)
+	}
+
+	return nil
+}
+
+// exportFoodItem adds the catalog entry with the given ID to foodItems. An
+// ID of zero, or one whose entry was removed from the catalog, is skipped;
+// the referencing row still records the ID.
+func exportFoodItem(
+	ctx context.Context,
+	tx *Stores,
+	id int64,
+	foodItems map[int64]*FoodItem,
+) error {
+	if _, ok := foodItems[id]; ok || id == 0 {
+		return nil
+	}
+
+	item, err := tx.FoodItemStore.GetFoodItem(ctx, id)
+	if errors.Is(err, ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	foodItems[item.ID] = item
+	return nil
+}
+
+// WriteJSON writes the record as an indented JSON document.
+func (record *HealthRecord) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(record)
+}
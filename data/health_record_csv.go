@@ -0,0 +1,232 @@
+package data
+
+import (
+	"archive/zip"
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteCSVZip writes the record as a zip archive holding one CSV file per
+// table, named after the Postgres tables. Nested rows carry the IDs of their
+// parents (e.g. meal_entries.tracking_period_id) so the tables can be joined
+// back together. export.csv records the schema version and export time.
+func (record *HealthRecord) WriteCSVZip(w io.Writer) error {
+	var (
+		periods     []*TrackingPeriod
+		mealEntries []*MealEntry
+		mealFoods   []*MealFood
+		customFoods []*CustomFood
+		symptoms    []*Symptom
+
+		attributions []*SymptomAttribution
+	)
+	for _, period := range record.TrackingPeriods {
+		periods = append(periods, &period.TrackingPeriod)
+		for _, entry := range period.MealEntries {
+			mealEntries = append(mealEntries, &entry.MealEntry)
+			mealFoods = append(mealFoods, entry.MealFoods...)
+			customFoods = append(customFoods, entry.CustomFoods...)
+			symptoms = append(symptoms, entry.Symptoms...)
+		}
+		for _, symptom := range period.Symptoms {
+			symptoms = append(symptoms, &symptom.Symptom)
+			attributions = append(attributions, symptom.Attributions...)
+		}
+	}
+
+	var (
+		plans        []*EliminationPlan
+		restrictions []*EliminationRestriction
+		steps        []*ReintroductionStep
+	)
+	for _, plan := range record.EliminationPlans {
+		plans = append(plans, &plan.EliminationPlan)
+		restrictions = append(restrictions, plan.Restrictions...)
+		steps = append(steps, plan.ReintroductionSteps...)
+	}
+
+	var (
+		medications []*Medication
+		schedules   []*MedicationSchedule
+		doseLogs    []*DoseLog
+	)
+	for _, medication := range record.Medications {
+		medications = append(medications, &medication.Medication)
+		if medication.Schedule != nil {
+			schedules = append(schedules, medication.Schedule)
+		}
+		doseLogs = append(doseLogs, medication.DoseLogs...)
+	}
+
+	var userIntakes []*UserIntake
+	if record.UserIntake != nil {
+		userIntakes = append(userIntakes, record.UserIntake)
+	}
+	var medicalInformation []*MedicalInformation
+	if record.MedicalInformation != nil {
+		medicalInformation = append(medicalInformation, record.MedicalInformation)
+	}
+
+	type export struct {
+		SchemaVersion int       `json:"schema_version"`
+		ExportedAt    time.Time `json:"exported_at"`
+		UserID        int64     `json:"user_id"`
+	}
+
+	archive := zip.NewWriter(w)
+	tables := []struct {
+		name string
+		rows any
+	}{
+		{"export", []*export{{record.SchemaVersion, record.ExportedAt, record.User.ID}}},
+		{"users", []*HealthRecordUser{&record.User}},
+		{"user_intakes", userIntakes},
+		{"medical_informations", medicalInformation},
+		{"caregivers", record.Caregivers},
+		{"emergency_contacts", record.EmergencyContacts},
+		{"medical_events", record.MedicalEvents},
+		{"frequent_foods", record.FrequentFoods},
+		{"allergies", record.Allergies},
+		{"medications", medications},
+		{"medication_schedules", schedules},
+		{"dose_logs", doseLogs},
+		{"dietary_supplements", record.DietarySupplements},
+		{"tracking_periods", periods},
+		{"meal_entries", mealEntries},
+		{"meal_foods", mealFoods},
+		{"custom_foods", customFoods},
+		{"symptoms", symptoms},
+		{"symptom_attributions", attributions},
+		{"elimination_plans", plans},
+		{"elimination_restrictions", restrictions},
+		{"reintroduction_steps", steps},
+		{"food_items", record.FoodItems},
+	}
+	for _, table := range tables {
+		if err := writeCSVTable(archive, table.name+".csv", table.rows); err != nil {
+			return fmt.Errorf("write %s: %w", table.name, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+// writeCSVTable writes rows, a slice of struct pointers, as a CSV file whose
+// columns are the struct's JSON field names. The header is written even
+// when there are no rows.
+func writeCSVTable(archive *zip.Writer, name string, rows any) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	slice := reflect.ValueOf(rows)
+	columns := csvColumns(slice.Type().Elem().Elem())
+
+	out := csv.NewWriter(file)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	for i := 0; i < slice.Len(); i++ {
+		row := slice.Index(i).Elem()
+		record := make([]string, len(columns))
+		for j, column := range columns {
+			record[j] = csvValue(row.FieldByIndex(column.index))
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// csvColumns lists the exported scalar fields of t in declaration order,
+// flattening embedded structs the way encoding/json does. Fields tagged
+// json:"-" and nested slices are left out.
+func csvColumns(t reflect.Type) []csvColumn {
+	var columns []csvColumn
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for _, column := range csvColumns(field.Type) {
+				column.index = append([]int{i}, column.index...)
+				columns = append(columns, column)
+			}
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Pointer:
+			continue
+		case reflect.Struct:
+			if field.Type != reflect.TypeOf(time.Time{}) {
+				continue
+			}
+		}
+
+		columns = append(columns, csvColumn{name: name, index: []int{i}})
+	}
+
+	return columns
+}
+
+func csvValue(value reflect.Value) string {
+	if t, ok := value.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339Nano)
+	}
+
+	if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
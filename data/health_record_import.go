@@ -0,0 +1,562 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/validator"
+)
+
+var ErrUnsupportedSchemaVersion = errors.New("unsupported health record schema version")
+
+// ErrInvalidHealthRecord is returned by ImportHealthRecord when the record
+// fails ValidateHealthRecord. The error lists the problems found.
+var ErrInvalidHealthRecord = errors.New("invalid health record")
+
+// ImportConflictError reports which unique user fields of an imported
+// record are already held by another user. It matches ErrRecordConflict
+// with errors.Is.
+type ImportConflictError struct {
+	Fields []string
+}
+
+func (err *ImportConflictError) Error() string {
+	return fmt.Sprintf("%s: %s already in use", ErrRecordConflict, strings.Join(err.Fields, ", "))
+}
+
+func (err *ImportConflictError) Unwrap() error {
+	return ErrRecordConflict
+}
+
+// ImportReport describes the outcome of ImportHealthRecord. UserID is the
+// ID the user was created with; every other ID in the document is remapped
+// in the same way.
+type ImportReport struct {
+	UserID int64 `json:"user_id"`
+
+	// CreatedFoodItems counts catalog entries that did not exist under the
+	// same name and were added so imported meals could refer to them.
+	CreatedFoodItems int `json:"created_food_items"`
+	// SkippedMealFoods counts meal foods, and SkippedEliminationRules
+	// elimination restrictions and reintroduction steps, whose food item is
+	// missing from the document and so could not be linked.
+	SkippedMealFoods        int `json:"skipped_meal_foods"`
+	SkippedEliminationRules int `json:"skipped_elimination_rules"`
+}
+
+// ReadHealthRecord decodes a document written by HealthRecord.WriteJSON. It
+// returns ErrUnsupportedSchemaVersion before decoding the rest of the
+// document if the schema version is not one this binary understands.
+func ReadHealthRecord(r io.Reader) (*HealthRecord, error) {
+	document, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(document, &header); err != nil {
+		return nil, err
+	}
+	if err := checkSchemaVersion(header.SchemaVersion); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.DisallowUnknownFields()
+
+	var record HealthRecord
+	if err := decoder.Decode(&record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func checkSchemaVersion(version int) error {
+	if version != HealthRecordSchemaVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, version)
+	}
+	return nil
+}
+
+func ValidateHealthRecord(v *validator.Validator, record *HealthRecord) {
+	v.Check(
+		record.SchemaVersion == HealthRecordSchemaVersion,
+		"schema_version",
+		fmt.Sprintf("must be %d", HealthRecordSchemaVersion),
+	)
+
+	ValidateUser(v, &User{
+		UserName:    record.User.UserName,
+		FirstName:   record.User.FirstName,
+		LastName:    record.User.LastName,
+		Email:       record.User.Email,
+		PhoneNumber: record.User.PhoneNumber,
+	})
+
+	if record.MedicalInformation != nil {
+		ValidateMedicalInformation(v, record.MedicalInformation, false)
+	}
+
+	active := 0
+	for _, period := range record.TrackingPeriods {
+		if !period.IsCompleted {
+			active++
+		}
+	}
+	v.Check(active <= 1, "tracking_periods", "must not contain more than one active period")
+
+	// The records are recreated for the imported user, so only their doses
+	// are checked.
+	for i, medication := range record.Medications {
+		validateNested(v, fmt.Sprintf("medications[%d]", i), func(v *validator.Validator) {
+			ValidateDose(v, medication.Dose)
+		})
+	}
+	for i, supplement := range record.DietarySupplements {
+		validateNested(v, fmt.Sprintf("dietary_supplements[%d]", i), func(v *validator.Validator) {
+			ValidateDose(v, supplement.Dose)
+		})
+	}
+}
+
+// validateNested runs validate and adds the errors it finds to v, with
+// their keys prefixed by prefix.
+func validateNested(v *validator.Validator, prefix string, validate func(v *validator.Validator)) {
+	nested := validator.New()
+	validate(nested)
+	for field, message := range nested.Errors {
+		v.AddError(prefix+"."+field, message)
+	}
+}
+
+// ImportHealthRecord recreates the user described by record, together with
+// all of their records, in a single transaction. Every ID in the document
+// is replaced by a newly assigned one. Food items are matched to the
+// catalog by name and created when missing. A record that fails
+// ValidateHealthRecord is rejected with an error wrapping
+// ErrInvalidHealthRecord before anything is written.
+//
+// The document carries no password hash, so the imported user must set a
+// new password before they can log in. If the user name, email or phone
+// number is already taken, nothing is imported and an *ImportConflictError
+// is returned.
+func (stores *Stores) ImportHealthRecord(
+	ctx context.Context,
+	record *HealthRecord,
+) (*ImportReport, error) {
+	if err := checkSchemaVersion(record.SchemaVersion); err != nil {
+		return nil, err
+	}
+	v := validator.New()
+	ValidateHealthRecord(v, record)
+	if !v.Valid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidHealthRecord, validationProblems(v))
+	}
+
+	var report *ImportReport
+
+	err := stores.WithTx(ctx, func(tx *Stores) error {
+		if err := checkImportConflicts(ctx, tx, &record.User); err != nil {
+			return err
+		}
+
+		user, err := tx.UserStore.CreateUser(ctx, &User{
+			UserName:           record.User.UserName,
+			FirstName:          record.User.FirstName,
+			LastName:           record.User.LastName,
+			Email:              record.User.Email,
+			PhoneNumber:        record.User.PhoneNumber,
+			UserIntakeComplete: record.User.UserIntakeComplete,
+			CreatedAt:          record.User.CreatedAt,
+			UpdatedAt:          record.User.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		report = &ImportReport{UserID: user.ID}
+		if err := importIntake(ctx, tx, record, user.ID); err != nil {
+			return err
+		}
+		if err := importUserRecords(ctx, tx, record, user.ID); err != nil {
+			return err
+		}
+
+		foodItemIDs, err := importFoodItems(ctx, tx, record, report)
+		if err != nil {
+			return err
+		}
+		periodIDs, err := importTracking(ctx, tx, record, foodItemIDs, report)
+		if err != nil {
+			return err
+		}
+		return importEliminationPlans(ctx, tx, record, foodItemIDs, periodIDs, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func checkImportConflicts(ctx context.Context, tx *Stores, user *HealthRecordUser) error {
+	lookups := []struct {
+		field string
+		get   func() (*User, error)
+	}{
+		{"user_name", func() (*User, error) { return tx.UserStore.GetByUserName(ctx, user.UserName) }},
+		{"email", func() (*User, error) { return tx.UserStore.GetByEmail(ctx, user.Email) }},
+		{"phone_number", func() (*User, error) {
+			return tx.UserStore.GetByPhoneNumber(ctx, user.PhoneNumber)
+		}},
+	}
+
+	var conflict ImportConflictError
+	for _, lookup := range lookups {
+		_, err := lookup.get()
+		switch {
+		case err == nil:
+			conflict.Fields = append(conflict.Fields, lookup.field)
+		case !errors.Is(err, ErrRecordNotFound):
+			return err
+		}
+	}
+
+	if len(conflict.Fields) > 0 {
+		return &conflict
+	}
+	return nil
+}
+
+func importIntake(ctx context.Context, tx *Stores, record *HealthRecord, userID int64) error {
+	if record.UserIntake != nil {
+		intake := *record.UserIntake
+		intake.ID, intake.UserID = 0, userID
+		if _, err := tx.UserIntakeStore.CreateUserIntake(ctx, &intake); err != nil {
+			return err
+		}
+	}
+
+	if record.MedicalInformation != nil {
+		medInfo := *record.MedicalInformation
+		medInfo.ID, medInfo.UserID = 0, userID
+		if _, err := tx.MedicalInformationStore.CreateMedicalInformation(ctx, &medInfo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func importUserRecords(ctx context.Context, tx *Stores, record *HealthRecord, userID int64) error {
+	for _, exported := range record.Caregivers {
+		caregiver := *exported
+		caregiver.ID, caregiver.UserID = 0, userID
+		if _, err := tx.CaregiverStore.CreateCaregiver(ctx, &caregiver); err != nil {
+			return err
+		}
+	}
+
+	for _, exported := range record.EmergencyContacts {
+		contact := *exported
+		contact.ID, contact.UserID = 0, userID
+		if _, err := tx.EmergencyContactStore.CreateEmergencyContact(ctx, &contact); err != nil {
+			return err
+		}
+	}
+
+	for _, exported := range record.MedicalEvents {
+		event := *exported
+		event.ID, event.UserID = 0, userID
+		if _, err := tx.MedicalEventStore.CreateMedicalEvent(ctx, &event); err != nil {
+			return err
+		}
+	}
+
+	for _, exported := range record.FrequentFoods {
+		food := *exported
+		food.ID, food.UserID = 0, userID
+		if _, err := tx.FrequentFoodStore.CreateFrequentFood(ctx, &food); err != nil {
+			return err
+		}
+	}
+
+	for _, exported := range record.Allergies {
+		allergy := *exported
+		allergy.ID, allergy.UserID = 0, userID
+		if _, err := tx.AllergyStore.CreateAllergy(ctx, &allergy); err != nil {
+			return err
+		}
+	}
+
+	for _, exported := range record.Medications {
+		if err := importMedication(ctx, tx, userID, exported); err != nil {
+			return err
+		}
+	}
+
+	for _, exported := range record.DietarySupplements {
+		supplement := *exported
+		supplement.ID, supplement.UserID = 0, userID
+		if _, err := tx.DietarySupplementStore.CreateDietarySupplement(ctx, &supplement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importMedication recreates a medication with its schedule and dose logs.
+func importMedication(ctx context.Context, tx *Stores, userID int64, exported *HealthRecordMedication) error {
+	medication := exported.Medication
+	medication.ID, medication.UserID = 0, userID
+	if _, err := tx.MedicationStore.CreateMedication(ctx, &medication); err != nil {
+		return err
+	}
+
+	if exported.Schedule != nil {
+		schedule := *exported.Schedule
+		schedule.ID, schedule.UserID, schedule.MedicationID = 0, userID, medication.ID
+		if _, err := tx.MedicationScheduleStore.CreateMedicationSchedule(ctx, &schedule); err != nil {
+			return err
+		}
+	}
+
+	for _, exportedLog := range exported.DoseLogs {
+		log := *exportedLog
+		log.ID, log.UserID, log.MedicationID = 0, userID, medication.ID
+		if _, err := tx.DoseLogStore.CreateDoseLog(ctx, &log); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importTracking recreates the tracking periods with their meals and
+// standalone symptoms, returning the new ID of each exported period.
+func importTracking(
+	ctx context.Context,
+	tx *Stores,
+	record *HealthRecord,
+	foodItemIDs map[int64]int64,
+	report *ImportReport,
+) (map[int64]int64, error) {
+	periodIDs := make(map[int64]int64, len(record.TrackingPeriods))
+
+	// CreateTrackingPeriod hands back the user's active period instead of
+	// creating a second one, so completed periods must go in first.
+	periods := make([]*HealthRecordTrackingPeriod, len(record.TrackingPeriods))
+	copy(periods, record.TrackingPeriods)
+	sort.SliceStable(periods, func(i, j int) bool {
+		return periods[i].IsCompleted && !periods[j].IsCompleted
+	})
+
+	for _, exported := range periods {
+		period := exported.TrackingPeriod
+		period.ID, period.UserID = 0, report.UserID
+		created, err := tx.TrackingPeriodStore.CreateTrackingPeriod(ctx, &period)
+		if err != nil {
+			return nil, err
+		}
+		if created.ID != period.ID {
+			return nil, fmt.Errorf("%w: more than one active tracking period", ErrRecordConflict)
+		}
+		periodIDs[exported.ID] = period.ID
+
+		mealEntryIDs := make(map[int64]int64, len(exported.MealEntries))
+		for _, meal := range exported.MealEntries {
+			mealEntryID, err := importMealEntry(ctx, tx, meal, period.ID, foodItemIDs, report)
+			if err != nil {
+				return nil, err
+			}
+			mealEntryIDs[meal.ID] = mealEntryID
+		}
+
+		for _, symptom := range exported.Symptoms {
+			err := importStandaloneSymptom(ctx, tx, symptom, period.ID, mealEntryIDs, report)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return periodIDs, nil
+}
+
+// importFoodItems maps the IDs of the exported food items to the IDs of the
+// catalog entries with the same name, creating any that are missing.
+func importFoodItems(
+	ctx context.Context,
+	tx *Stores,
+	record *HealthRecord,
+	report *ImportReport,
+) (map[int64]int64, error) {
+	ids := make(map[int64]int64, len(record.FoodItems))
+
+	for _, exported := range record.FoodItems {
+		item, err := tx.FoodItemStore.GetFoodItemByName(ctx, exported.Name)
+		if errors.Is(err, ErrRecordNotFound) {
+			created := *exported
+			created.ID, created.CreatedAt, created.UpdatedAt = 0, time.Time{}, time.Time{}
+			item, err = tx.FoodItemStore.CreateFoodItem(ctx, &created)
+			report.CreatedFoodItems++
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids[exported.ID] = item.ID
+	}
+
+	return ids, nil
+}
+
+// importMealEntry recreates a meal with its foods and symptoms, returning
+// the ID of the entry they were added to.
+func importMealEntry(
+	ctx context.Context,
+	tx *Stores,
+	meal *HealthRecordMealEntry,
+	trackingPeriodID int64,
+	foodItemIDs map[int64]int64,
+	report *ImportReport,
+) (int64, error) {
+	entry := meal.MealEntry
+	entry.ID, entry.UserID, entry.TrackingPeriodID = 0, report.UserID, trackingPeriodID
+	// A duplicate day and meal type comes back as the entry created first,
+	// which then receives the foods and symptoms of both.
+	created, err := tx.MealEntryStore.CreateMealEntry(ctx, &entry)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, exported := range meal.MealFoods {
+		foodItemID, ok := foodItemIDs[exported.FoodItemID]
+		if !ok {
+			report.SkippedMealFoods++
+			continue
+		}
+		mealFood := *exported
+		mealFood.ID, mealFood.MealEntryID, mealFood.FoodItemID = 0, created.ID, foodItemID
+		if _, err := tx.MealFoodStore.CreateMealFood(ctx, &mealFood); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, exported := range meal.CustomFoods {
+		food := *exported
+		food.ID, food.MealEntryID = 0, created.ID
+		if _, err := tx.CustomFoodStore.CreateCustomFood(ctx, &food); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, exported := range meal.Symptoms {
+		symptom := *exported
+		symptom.ID, symptom.MealEntryID = 0, created.ID
+		if _, err := tx.SymptomStore.CreateSymptom(ctx, &symptom); err != nil {
+			return 0, err
+		}
+	}
+
+	return created.ID, nil
+}
+
+// importStandaloneSymptom recreates a standalone symptom with its
+// attributions. Attributions to meals missing from the document are
+// skipped.
+func importStandaloneSymptom(
+	ctx context.Context,
+	tx *Stores,
+	exported *HealthRecordSymptom,
+	trackingPeriodID int64,
+	mealEntryIDs map[int64]int64,
+	report *ImportReport,
+) error {
+	symptom := exported.Symptom
+	symptom.ID, symptom.MealEntryID = 0, 0
+	symptom.UserID, symptom.TrackingPeriodID = report.UserID, trackingPeriodID
+	created, err := tx.SymptomStore.CreateSymptom(ctx, &symptom)
+	if err != nil {
+		return err
+	}
+
+	// Two exported meals merged on import would otherwise be linked twice.
+	linked := make(map[int64]bool, len(exported.Attributions))
+	for _, exportedAttribution := range exported.Attributions {
+		mealEntryID, ok := mealEntryIDs[exportedAttribution.MealEntryID]
+		if !ok || linked[mealEntryID] {
+			continue
+		}
+		linked[mealEntryID] = true
+		attribution := *exportedAttribution
+		attribution.ID, attribution.SymptomID, attribution.MealEntryID = 0, created.ID, mealEntryID
+		if _, err := tx.SymptomAttributionStore.CreateSymptomAttribution(ctx, &attribution); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func importEliminationPlans(
+	ctx context.Context,
+	tx *Stores,
+	record *HealthRecord,
+	foodItemIDs map[int64]int64,
+	periodIDs map[int64]int64,
+	report *ImportReport,
+) error {
+	for _, exported := range record.EliminationPlans {
+		plan := exported.EliminationPlan
+		plan.ID, plan.UserID = 0, report.UserID
+		plan.TrackingPeriodID = periodIDs[plan.TrackingPeriodID]
+		if _, err := tx.EliminationPlanStore.CreateEliminationPlan(ctx, &plan); err != nil {
+			return err
+		}
+
+		for _, exportedRestriction := range exported.Restrictions {
+			restriction := *exportedRestriction
+			restriction.ID, restriction.PlanID = 0, plan.ID
+			if restriction.FoodItemID != 0 {
+				foodItemID, ok := foodItemIDs[restriction.FoodItemID]
+				if !ok {
+					report.SkippedEliminationRules++
+					continue
+				}
+				restriction.FoodItemID = foodItemID
+			}
+			_, err := tx.EliminationRestrictionStore.CreateEliminationRestriction(ctx, &restriction)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, exportedStep := range exported.ReintroductionSteps {
+			step := *exportedStep
+			step.ID, step.PlanID = 0, plan.ID
+			if step.FoodItemID != 0 {
+				foodItemID, ok := foodItemIDs[step.FoodItemID]
+				if !ok {
+					report.SkippedEliminationRules++
+					continue
+				}
+				step.FoodItemID = foodItemID
+			}
+			if _, err := tx.ReintroductionStepStore.CreateReintroductionStep(ctx, &step); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
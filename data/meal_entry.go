@@ -0,0 +1,210 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MealType is the kind of meal a MealEntry records. A user logs at most one
+// entry of each type per tracking day.
+type MealType string
+
+const (
+	MealBreakfast MealType = "Breakfast"
+	MealLunch     MealType = "Lunch"
+	MealSnack     MealType = "Snack"
+	MealDinner    MealType = "Dinner"
+)
+
+// MealTypes are the meal types in the order they are eaten during a day.
+var MealTypes = []MealType{MealBreakfast, MealLunch, MealSnack, MealDinner}
+
+// mealTypeAliases maps the accepted lower-case spellings to their
+// meal type. Keep it in step with migration 0005_meal_entry_fields.
+var mealTypeAliases = map[string]MealType{
+	"breakfast": MealBreakfast,
+	"bf":        MealBreakfast,
+	"brekkie":   MealBreakfast,
+	"lunch":     MealLunch,
+	"snack":     MealSnack,
+	"snacks":    MealSnack,
+	"dinner":    MealDinner,
+	"supper":    MealDinner,
+}
+
+// PortionUnit is the unit a meal's portion is measured in.
+type PortionUnit string
+
+const (
+	PortionGram       PortionUnit = "g"
+	PortionKilogram   PortionUnit = "kg"
+	PortionMilliliter PortionUnit = "ml"
+	PortionLiter      PortionUnit = "l"
+	PortionOunce      PortionUnit = "oz"
+	PortionCup        PortionUnit = "cup"
+	PortionTablespoon PortionUnit = "tbsp"
+	PortionTeaspoon   PortionUnit = "tsp"
+	PortionPiece      PortionUnit = "piece"
+	PortionSlice      PortionUnit = "slice"
+	PortionBowl       PortionUnit = "bowl"
+	PortionPlate      PortionUnit = "plate"
+	PortionHandful    PortionUnit = "handful"
+	PortionServing    PortionUnit = "serving"
+)
+
+var PortionUnits = []PortionUnit{
+	PortionGram, PortionKilogram, PortionMilliliter, PortionLiter, PortionOunce,
+	PortionCup, PortionTablespoon, PortionTeaspoon, PortionPiece, PortionSlice,
+	PortionBowl, PortionPlate, PortionHandful, PortionServing,
+}
+
+// portionUnitAliases maps lower-case spellings to their unit, in addition to
+// the units themselves and their plurals. Keep it in step with migration
+// 0005_meal_entry_fields.
+var portionUnitAliases = map[string]PortionUnit{
+	"gram":        PortionGram,
+	"grams":       PortionGram,
+	"gr":          PortionGram,
+	"kilogram":    PortionKilogram,
+	"kilograms":   PortionKilogram,
+	"milliliter":  PortionMilliliter,
+	"milliliters": PortionMilliliter,
+	"millilitre":  PortionMilliliter,
+	"millilitres": PortionMilliliter,
+	"liter":       PortionLiter,
+	"liters":      PortionLiter,
+	"litre":       PortionLiter,
+	"litres":      PortionLiter,
+	"ounce":       PortionOunce,
+	"ounces":      PortionOunce,
+	"cups":        PortionCup,
+	"tablespoon":  PortionTablespoon,
+	"tablespoons": PortionTablespoon,
+	"teaspoon":    PortionTeaspoon,
+	"teaspoons":   PortionTeaspoon,
+	"pieces":      PortionPiece,
+	"pc":          PortionPiece,
+	"pcs":         PortionPiece,
+	"slices":      PortionSlice,
+	"bowls":       PortionBowl,
+	"plates":      PortionPlate,
+	"handfuls":    PortionHandful,
+	"servings":    PortionServing,
+	"portion":     PortionServing,
+	"portions":    PortionServing,
+}
+
+// MaxMealDuration is the longest a single meal may last.
+const MaxMealDuration = 4 * time.Hour
+
+var (
+	ErrInvalidMealType     = errors.New("invalid meal type")
+	ErrInvalidPortion      = errors.New("invalid portion size")
+	ErrInvalidMealTime     = errors.New("invalid meal time")
+	ErrInvalidMealDuration = errors.New("invalid meal duration")
+)
+
+// ParseMealType returns the meal type named by s, ignoring case and
+// surrounding space and accepting common aliases such as "BF" and "supper".
+func ParseMealType(s string) (MealType, error) {
+	mealType, ok := mealTypeAliases[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidMealType, s)
+	}
+	return mealType, nil
+}
+
+// ParsePortionUnit returns the portion unit named by s, ignoring case and
+// accepting plurals and spelled out units such as "grams".
+func ParsePortionUnit(s string) (PortionUnit, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for _, unit := range PortionUnits {
+		if name == string(unit) {
+			return unit, nil
+		}
+	}
+	if unit, ok := portionUnitAliases[name]; ok {
+		return unit, nil
+	}
+	return "", fmt.Errorf("%w: unknown unit %q", ErrInvalidPortion, s)
+}
+
+var portionRX = regexp.MustCompile(`^\s*(\d+/\d+|\d+(?:\.\d+)?)\s*(.*?)\s*$`)
+
+// ParsePortion splits a portion size such as "1 bowl", "250g" or "1/2 cup"
+// into its quantity and unit. A bare number is a number of servings.
+func ParsePortion(s string) (float64, PortionUnit, error) {
+	match := portionRX.FindStringSubmatch(s)
+	if match == nil {
+		return 0, "", fmt.Errorf("%w: %q", ErrInvalidPortion, s)
+	}
+
+	var quantity float64
+	if numerator, denominator, ok := strings.Cut(match[1], "/"); ok {
+		n, _ := strconv.ParseFloat(numerator, 64)
+		d, _ := strconv.ParseFloat(denominator, 64)
+		if d == 0 {
+			return 0, "", fmt.Errorf("%w: %q", ErrInvalidPortion, s)
+		}
+		quantity = n / d
+	} else {
+		quantity, _ = strconv.ParseFloat(match[1], 64)
+	}
+
+	if match[2] == "" {
+		return quantity, PortionServing, nil
+	}
+	unit, err := ParsePortionUnit(match[2])
+	if err != nil {
+		return 0, "", err
+	}
+	return quantity, unit, nil
+}
+
+var mealClockLayouts = []string{"15:04", "15:04:05", "3:04pm", "3:04 pm", "3pm", "3 pm"}
+
+// ParseMealTime returns the time of day given by clock, e.g. "08:30" or
+// "7:15 pm", on the calendar day of date and in its location.
+func ParseMealTime(date time.Time, clock string) (time.Time, error) {
+	clock = strings.ToLower(strings.TrimSpace(clock))
+	for _, layout := range mealClockLayouts {
+		parsed, err := time.Parse(layout, clock)
+		if err != nil {
+			continue
+		}
+		year, month, day := date.Date()
+		return time.Date(
+			year, month, day,
+			parsed.Hour(), parsed.Minute(), parsed.Second(), 0,
+			date.Location(),
+		), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidMealTime, clock)
+}
+
+var mealDurationRX = regexp.MustCompile(
+	`^(?:(\d+(?:\.\d+)?)\s*h(?:ours?|rs?)?)?\s*(?:(\d+)\s*m(?:in(?:ute)?s?)?)?$`,
+)
+
+// ParseMealDuration parses how long a meal took. Besides Go durations such
+// as "1h15m" it accepts "20 min", "1.5 hours" and a bare number of minutes.
+func ParseMealDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if minutes, err := strconv.Atoi(s); err == nil {
+		return time.Duration(minutes) * time.Minute, nil
+	}
+	if match := mealDurationRX.FindStringSubmatch(s); s != "" && match != nil {
+		hours, _ := strconv.ParseFloat(match[1], 64)
+		minutes, _ := strconv.Atoi(match[2])
+		return time.Duration(hours*float64(time.Hour)) + time.Duration(minutes)*time.Minute, nil
+	}
+	if duration, err := time.ParseDuration(s); err == nil {
+		return duration, nil
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidMealDuration, s)
+}
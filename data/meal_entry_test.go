@@ -0,0 +1,104 @@
+package data_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func TestParseMealType(t *testing.T) {
+	tests := []struct {
+		in   string
+		want data.MealType
+		err  bool
+	}{
+		{in: "Breakfast", want: data.MealBreakfast},
+		{in: "  LUNCH ", want: data.MealLunch},
+		{in: "bf", want: data.MealBreakfast},
+		{in: "Brekkie", want: data.MealBreakfast},
+		{in: "snacks", want: data.MealSnack},
+		{in: "Supper", want: data.MealDinner},
+		{in: "", err: true},
+		{in: "brunch", err: true},
+	}
+	for _, test := range tests {
+		got, err := data.ParseMealType(test.in)
+		if test.err {
+			if !errors.Is(err, data.ErrInvalidMealType) {
+				t.Errorf("ParseMealType(%q) = %q, %v, want %v", test.in, got, err, data.ErrInvalidMealType)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("ParseMealType(%q) = %q, %v, want %q", test.in, got, err, test.want)
+		}
+	}
+}
+
+func TestParsePortion(t *testing.T) {
+	tests := []struct {
+		in       string
+		quantity float64
+		unit     data.PortionUnit
+		err      bool
+	}{
+		{in: "1 bowl", quantity: 1, unit: data.PortionBowl},
+		{in: "250g", quantity: 250, unit: data.PortionGram},
+		{in: "1/2 cup", quantity: 0.5, unit: data.PortionCup},
+		{in: " 1.5 Cups ", quantity: 1.5, unit: data.PortionCup},
+		{in: "2", quantity: 2, unit: data.PortionServing},
+		{in: "3 pcs", quantity: 3, unit: data.PortionPiece},
+		{in: "330 millilitres", quantity: 330, unit: data.PortionMilliliter},
+		{in: "2 TBSP", quantity: 2, unit: data.PortionTablespoon},
+		{in: "", err: true},
+		{in: "a cup", err: true},
+		{in: "1/0 cup", err: true},
+		{in: "2 buckets", err: true},
+	}
+	for _, test := range tests {
+		quantity, unit, err := data.ParsePortion(test.in)
+		if test.err {
+			if !errors.Is(err, data.ErrInvalidPortion) {
+				t.Errorf("ParsePortion(%q) = %g %q, %v, want %v", test.in, quantity, unit, err, data.ErrInvalidPortion)
+			}
+			continue
+		}
+		if err != nil || quantity != test.quantity || unit != test.unit {
+			t.Errorf("ParsePortion(%q) = %g %q, %v, want %g %q",
+				test.in, quantity, unit, err, test.quantity, test.unit)
+		}
+	}
+}
+
+func TestParseMealDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{in: "45", want: 45 * time.Minute},
+		{in: "20 min", want: 20 * time.Minute},
+		{in: " 30 MINS ", want: 30 * time.Minute},
+		{in: "1.5 hours", want: 90 * time.Minute},
+		{in: "2 hrs 10 minutes", want: 130 * time.Minute},
+		{in: "1h15m", want: 75 * time.Minute},
+		{in: "90s", want: 90 * time.Second},
+		{in: "", err: true},
+		{in: "soon", err: true},
+		{in: "10 days", err: true},
+	}
+	for _, test := range tests {
+		got, err := data.ParseMealDuration(test.in)
+		if test.err {
+			if !errors.Is(err, data.ErrInvalidMealDuration) {
+				t.Errorf("ParseMealDuration(%q) = %v, %v, want %v", test.in, got, err, data.ErrInvalidMealDuration)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("ParseMealDuration(%q) = %v, %v, want %v", test.in, got, err, test.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"time"
 )
 
@@ -14,9 +15,9 @@ type MedicalEvent struct {
 }
 
 type MedicalEventStore interface {
-	CreateMedicalEvent(event *MedicalEvent) (*MedicalEvent, error)
-	GetMedicalEvent(id int64) (*MedicalEvent, error)
-	ListUserMedicalEvents(userID int64) ([]*MedicalEvent, error)
-	UpdateMedicalEvent(event *MedicalEvent) error
-	DeleteMedicalEvent(id int64) error
+	CreateMedicalEvent(ctx context.Context, event *MedicalEvent) (*MedicalEvent, error)
+	GetMedicalEvent(ctx context.Context, id int64) (*MedicalEvent, error)
+	ListUserMedicalEvents(ctx context.Context, userID int64) ([]*MedicalEvent, error)
+	UpdateMedicalEvent(ctx context.Context, event *MedicalEvent) error
+	DeleteMedicalEvent(ctx context.Context, id int64) error
 }
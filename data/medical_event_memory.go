@@ -0,0 +1,96 @@
+package data
+
+import "context"
+
+type MemoryMedicalEventStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryMedicalEventStore(db *MemoryDB) *MemoryMedicalEventStore {
+	return &MemoryMedicalEventStore{db: db}
+}
+
+func (store *MemoryMedicalEventStore) CreateMedicalEvent(
+	ctx context.Context,
+	event *MedicalEvent,
+) (*MedicalEvent, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if event.ID != 0 {
+		if _, exists := store.db.medicalEvents.get(event.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		event.ID = store.db.medicalEvents.nextID()
+	}
+	setCreateTimestamps(&event.CreatedAt, &event.UpdatedAt)
+	store.db.medicalEvents.put(event.ID, *event)
+
+	return event, nil
+}
+
+func (store *MemoryMedicalEventStore) GetMedicalEvent(
+	ctx context.Context,
+	id int64,
+) (*MedicalEvent, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	event, ok := store.db.medicalEvents.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &event, nil
+}
+
+func (store *MemoryMedicalEventStore) ListUserMedicalEvents(
+	ctx context.Context,
+	userID int64,
+) ([]*MedicalEvent, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.medicalEvents.filter(func(row *MedicalEvent) bool {
+		return row.UserID == userID
+	}), nil
+}
+
+func (store *MemoryMedicalEventStore) UpdateMedicalEvent(
+	ctx context.Context,
+	event *MedicalEvent,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.medicalEvents.get(event.ID)
+	if event.ID == 0 || !ok {
+		if event.ID == 0 {
+			event.ID = store.db.medicalEvents.nextID()
+		}
+		setCreateTimestamps(&event.CreatedAt, &event.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&event.CreatedAt, &event.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.medicalEvents.put(event.ID, *event)
+
+	return nil
+}
+
+func (store *MemoryMedicalEventStore) DeleteMedicalEvent(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.medicalEvents.delete(id)
+	return nil
+}
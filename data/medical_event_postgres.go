@@ -1,35 +1,45 @@
 package data
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type PostgresMedicalEventStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresMedicalEventStore(db *gorm.DB) *PostgresMedicalEventStore {
-	if err := db.AutoMigrate(&MedicalEvent{}); err != nil {
-		panic("failed to migrate medical event schema: " + err.Error())
-	}
 	return &PostgresMedicalEventStore{DB: db}
 }
 
 func (store *PostgresMedicalEventStore) CreateMedicalEvent(
+	ctx context.Context,
 	event *MedicalEvent,
 ) (*MedicalEvent, error) {
-	err := store.DB.Create(event).Error
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(event).Error
 	if err != nil {
 		return nil, err
 	}
 	return event, nil
 }
 
-func (store *PostgresMedicalEventStore) GetMedicalEvent(id int64) (*MedicalEvent, error) {
+func (store *PostgresMedicalEventStore) GetMedicalEvent(
+	ctx context.Context,
+	id int64,
+) (*MedicalEvent, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var event MedicalEvent
-	err := store.DB.First(&event, id).Error
+	err := db.First(&event, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -40,26 +50,39 @@ func (store *PostgresMedicalEventStore) GetMedicalEvent(id int64) (*MedicalEvent
 }
 
 func (store *PostgresMedicalEventStore) ListUserMedicalEvents(
+	ctx context.Context,
 	userID int64,
 ) ([]*MedicalEvent, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var events []*MedicalEvent
-	err := store.DB.Where("user_id = ?", userID).Find(&events).Error
+	err := db.Where("user_id = ?", userID).Find(&events).Error
 	if err != nil {
 		return nil, err
 	}
 	return events, nil
 }
 
-func (store *PostgresMedicalEventStore) UpdateMedicalEvent(event *MedicalEvent) error {
-	err := store.DB.Save(event).Error
+func (store *PostgresMedicalEventStore) UpdateMedicalEvent(
+	ctx context.Context,
+	event *MedicalEvent,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(event).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresMedicalEventStore) DeleteMedicalEvent(id int64) error {
-	err := store.DB.Delete(&MedicalEvent{}, id).Error
+func (store *PostgresMedicalEventStore) DeleteMedicalEvent(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&MedicalEvent{}, id).Error
 	if err != nil {
 		return err
 	}
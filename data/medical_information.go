@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"time"
 
 	"github.com/Universal-Selfcare/utils/validator"
@@ -166,11 +167,11 @@ type MedicalInformation struct {
 }
 
 type MedicalInformationStore interface {
-	CreateMedicalInformation(userIntake *MedicalInformation) (*MedicalInformation, error)
-	GetMedicalInformation(id int64) (*MedicalInformation, error)
-	GetMedicalInformationByUserID(userID int64) (*MedicalInformation, error)
-	UpdateMedicalInformation(userIntake *MedicalInformation) error
-	DeleteMedicalInformation(id int64) error
+	CreateMedicalInformation(ctx context.Context, userIntake *MedicalInformation) (*MedicalInformation, error)
+	GetMedicalInformation(ctx context.Context, id int64) (*MedicalInformation, error)
+	GetMedicalInformationByUserID(ctx context.Context, userID int64) (*MedicalInformation, error)
+	UpdateMedicalInformation(ctx context.Context, userIntake *MedicalInformation) error
+	DeleteMedicalInformation(ctx context.Context, id int64) error
 }
 
 func ValidateMedicalInformation(
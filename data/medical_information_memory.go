@@ -0,0 +1,103 @@
+package data
+
+import "context"
+
+type MemoryMedicalInformationStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryMedicalInformationStore(db *MemoryDB) *MemoryMedicalInformationStore {
+	return &MemoryMedicalInformationStore{db: db}
+}
+
+func (store *MemoryMedicalInformationStore) CreateMedicalInformation(
+	ctx context.Context,
+	medInfo *MedicalInformation,
+) (*MedicalInformation, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if medInfo.ID != 0 {
+		if _, exists := store.db.medicalInformation.get(medInfo.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		medInfo.ID = store.db.medicalInformation.nextID()
+	}
+	setCreateTimestamps(&medInfo.CreatedAt, &medInfo.UpdatedAt)
+	store.db.medicalInformation.put(medInfo.ID, *medInfo)
+
+	return medInfo, nil
+}
+
+func (store *MemoryMedicalInformationStore) GetMedicalInformation(
+	ctx context.Context,
+	id int64,
+) (*MedicalInformation, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	medInfo, ok := store.db.medicalInformation.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &medInfo, nil
+}
+
+func (store *MemoryMedicalInformationStore) GetMedicalInformationByUserID(
+	ctx context.Context,
+	userID int64,
+) (*MedicalInformation, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	medInfo, ok := store.db.medicalInformation.first(func(row *MedicalInformation) bool {
+		return row.UserID == userID
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return medInfo, nil
+}
+
+func (store *MemoryMedicalInformationStore) UpdateMedicalInformation(
+	ctx context.Context,
+	medInfo *MedicalInformation,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.medicalInformation.get(medInfo.ID)
+	if medInfo.ID == 0 || !ok {
+		if medInfo.ID == 0 {
+			medInfo.ID = store.db.medicalInformation.nextID()
+		}
+		setCreateTimestamps(&medInfo.CreatedAt, &medInfo.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&medInfo.CreatedAt, &medInfo.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.medicalInformation.put(medInfo.ID, *medInfo)
+
+	return nil
+}
+
+func (store *MemoryMedicalInformationStore) DeleteMedicalInformation(
+	ctx context.Context,
+	id int64,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.medicalInformation.delete(id)
+	return nil
+}
@@ -1,26 +1,30 @@
 package data
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type PostgresMedicalInformationStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresMedicalInformationStore(db *gorm.DB) *PostgresMedicalInformationStore {
-	if err := db.AutoMigrate(&MedicalInformation{}); err != nil {
-		panic("failed to migrate medical information schema: " + err.Error())
-	}
 	return &PostgresMedicalInformationStore{DB: db}
 }
 
 func (store *PostgresMedicalInformationStore) CreateMedicalInformation(
+	ctx context.Context,
 	medInfo *MedicalInformation,
 ) (*MedicalInformation, error) {
-	err := store.DB.Create(medInfo).Error
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(medInfo).Error
 	if err != nil {
 		return nil, err
 	}
@@ -28,10 +32,14 @@ func (store *PostgresMedicalInformationStore) CreateMedicalInformation(
 }
 
 func (store *PostgresMedicalInformationStore) GetMedicalInformation(
+	ctx context.Context,
 	id int64,
 ) (*MedicalInformation, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var medInfo MedicalInformation
-	err := store.DB.First(&medInfo, id).Error
+	err := db.First(&medInfo, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -42,10 +50,14 @@ func (store *PostgresMedicalInformationStore) GetMedicalInformation(
 }
 
 func (store *PostgresMedicalInformationStore) GetMedicalInformationByUserID(
+	ctx context.Context,
 	userID int64,
 ) (*MedicalInformation, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var medInfo MedicalInformation
-	err := store.DB.Where("user_id = ?", userID).First(&medInfo).Error
+	err := db.Where("user_id = ?", userID).First(&medInfo).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -56,17 +68,27 @@ func (store *PostgresMedicalInformationStore) GetMedicalInformationByUserID(
 }
 
 func (store *PostgresMedicalInformationStore) UpdateMedicalInformation(
+	ctx context.Context,
 	medInfo *MedicalInformation,
 ) error {
-	err := store.DB.Save(medInfo).Error
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(medInfo).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresMedicalInformationStore) DeleteMedicalInformation(id int64) error {
-	err := store.DB.Delete(&MedicalInformation{}, id).Error
+func (store *PostgresMedicalInformationStore) DeleteMedicalInformation(
+	ctx context.Context,
+	id int64,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&MedicalInformation{}, id).Error
 	if err != nil {
 		return err
 	}
@@ -1,9 +1,16 @@
 package data
 
 import (
+	"context"
+	"strings"
 	"time"
+
+	"github.com/Universal-Selfcare/utils/validator"
 )
 
+// Medication is a medication a user takes or took. Whether it is current is
+// derived from StartDate and EndDate, see CurrentAsOf; the stored Current
+// flag only counts for records with neither date.
 type Medication struct {
 	ID          int64     `gorm:"primaryKey"     json:"id"`
 	UserID      int64     `gorm:"not null;index" json:"user_id"`
@@ -15,13 +22,60 @@ type Medication struct {
 	SideEffects string    `gorm:"type:text"      json:"side_effects"`
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Dose is the structured form of Dosage; see ParseDosage.
+	Dose
 }
 
 type MedicationStore interface {
-	CreateMedication(medication *Medication) (*Medication, error)
-	GetMedication(id int64) (*Medication, error)
-	ListUserMedications(userID int64) ([]*Medication, error)
-	ListUserCurrentMedications(userID int64) ([]*Medication, error)
-	UpdateMedication(medication *Medication) error
-	DeleteMedication(id int64) error
+	CreateMedication(ctx context.Context, medication *Medication) (*Medication, error)
+	GetMedication(ctx context.Context, id int64) (*Medication, error)
+	ListUserMedications(ctx context.Context, userID int64) ([]*Medication, error)
+	// ListUserCurrentMedications lists the medications current as of now.
+	ListUserCurrentMedications(ctx context.Context, userID int64) ([]*Medication, error)
+	ListUserMedicationsAsOf(ctx context.Context, userID int64, at time.Time) ([]*Medication, error)
+	UpdateMedication(ctx context.Context, medication *Medication) error
+	DeleteMedication(ctx context.Context, id int64) error
+}
+
+// ValidateMedication checks a medication, including its structured dose.
+func ValidateMedication(v *validator.Validator, medication *Medication) {
+	v.Check(medication.UserID != 0, "user_id", "must be provided")
+	v.Check(strings.TrimSpace(medication.Name) != "", "name", "must be provided")
+	v.Check(
+		medication.EndDate.IsZero() || medication.EndDate.After(medication.StartDate),
+		"end_date",
+		"must be after the start date",
+	)
+	ValidateDose(v, medication.Dose)
+}
+
+// CurrentAsOf reports whether the medication was being taken at the given
+// time: from StartDate until before EndDate, either of which may be unset.
+func (medication *Medication) CurrentAsOf(at time.Time) bool {
+	return takenAsOf(medication.StartDate, medication.EndDate, medication.Current, at)
+}
+
+// TakenBetween reports whether the medication was taken at any time from
+// from until before to.
+func (medication *Medication) TakenBetween(from, to time.Time) bool {
+	return takenBetween(medication.StartDate, medication.EndDate, medication.Current, from, to)
+}
+
+// takenAsOf reports whether something taken from start until before end
+// was being taken at the given time. A zero start or end leaves that side
+// open; with neither set, the current flag decides.
+func takenAsOf(start, end time.Time, current bool, at time.Time) bool {
+	if start.IsZero() && end.IsZero() {
+		return current
+	}
+	return !start.After(at) && (end.IsZero() || end.After(at))
+}
+
+// takenBetween is like takenAsOf for any time from from until before to.
+func takenBetween(start, end time.Time, current bool, from, to time.Time) bool {
+	if start.IsZero() && end.IsZero() {
+		return current
+	}
+	return start.Before(to) && (end.IsZero() || end.After(from))
 }
@@ -0,0 +1,121 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+type MemoryMedicationStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryMedicationStore(db *MemoryDB) *MemoryMedicationStore {
+	return &MemoryMedicationStore{db: db}
+}
+
+func (store *MemoryMedicationStore) CreateMedication(
+	ctx context.Context,
+	medication *Medication,
+) (*Medication, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if medication.ID != 0 {
+		if _, exists := store.db.medications.get(medication.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		medication.ID = store.db.medications.nextID()
+	}
+	setCreateTimestamps(&medication.CreatedAt, &medication.UpdatedAt)
+	store.db.medications.put(medication.ID, *medication)
+
+	return medication, nil
+}
+
+func (store *MemoryMedicationStore) GetMedication(
+	ctx context.Context,
+	id int64,
+) (*Medication, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	medication, ok := store.db.medications.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &medication, nil
+}
+
+func (store *MemoryMedicationStore) ListUserMedications(
+	ctx context.Context,
+	userID int64,
+) ([]*Medication, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.medications.filter(func(row *Medication) bool {
+		return row.UserID == userID
+	}), nil
+}
+
+func (store *MemoryMedicationStore) ListUserCurrentMedications(
+	ctx context.Context,
+	userID int64,
+) ([]*Medication, error) {
+	return store.ListUserMedicationsAsOf(ctx, userID, time.Now())
+}
+
+func (store *MemoryMedicationStore) ListUserMedicationsAsOf(
+	ctx context.Context,
+	userID int64,
+	at time.Time,
+) ([]*Medication, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.medications.filter(func(row *Medication) bool {
+		return row.UserID == userID && row.CurrentAsOf(at)
+	}), nil
+}
+
+func (store *MemoryMedicationStore) UpdateMedication(
+	ctx context.Context,
+	medication *Medication,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.medications.get(medication.ID)
+	if medication.ID == 0 || !ok {
+		if medication.ID == 0 {
+			medication.ID = store.db.medications.nextID()
+		}
+		setCreateTimestamps(&medication.CreatedAt, &medication.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&medication.CreatedAt, &medication.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.medications.put(medication.ID, *medication)
+
+	return nil
+}
+
+func (store *MemoryMedicationStore) DeleteMedication(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.medications.delete(id)
+	return nil
+}
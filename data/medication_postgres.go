@@ -1,33 +1,46 @@
 package data
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type PostgresMedicationStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresMedicationStore(db *gorm.DB) *PostgresMedicationStore {
-	if err := db.AutoMigrate(&Medication{}); err != nil {
-		panic("failed to migrate medication schema: " + err.Error())
-	}
 	return &PostgresMedicationStore{DB: db}
 }
 
-func (store *PostgresMedicationStore) CreateMedication(medication *Medication) (*Medication, error) {
-	err := store.DB.Create(medication).Error
+func (store *PostgresMedicationStore) CreateMedication(
+	ctx context.Context,
+	medication *Medication,
+) (*Medication, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(medication).Error
 	if err != nil {
 		return nil, err
 	}
 	return medication, nil
 }
 
-func (store *PostgresMedicationStore) GetMedication(id int64) (*Medication, error) {
+func (store *PostgresMedicationStore) GetMedication(
+	ctx context.Context,
+	id int64,
+) (*Medication, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var medication Medication
-	err := store.DB.First(&medication, id).Error
+	err := db.First(&medication, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -37,34 +50,74 @@ func (store *PostgresMedicationStore) GetMedication(id int64) (*Medication, erro
 	return &medication, nil
 }
 
-func (store *PostgresMedicationStore) ListUserMedications(userID int64) ([]*Medication, error) {
+func (store *PostgresMedicationStore) ListUserMedications(
+	ctx context.Context,
+	userID int64,
+) ([]*Medication, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var medications []*Medication
-	err := store.DB.Where("user_id = ?", userID).Find(&medications).Error
+	err := db.Where("user_id = ?", userID).Find(&medications).Error
 	if err != nil {
 		return nil, err
 	}
 	return medications, nil
 }
 
-func (store *PostgresMedicationStore) ListUserCurrentMedications(userID int64) ([]*Medication, error) {
+func (store *PostgresMedicationStore) ListUserCurrentMedications(
+	ctx context.Context,
+	userID int64,
+) ([]*Medication, error) {
+	return store.ListUserMedicationsAsOf(ctx, userID, time.Now())
+}
+
+func (store *PostgresMedicationStore) ListUserMedicationsAsOf(
+	ctx context.Context,
+	userID int64,
+	at time.Time,
+) ([]*Medication, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var medications []*Medication
-	err := store.DB.Where("user_id = ? AND current = ?", userID, true).Find(&medications).Error
+	err := db.Where("user_id = ?", userID).
+		Where(takenAsOfCondition, sql.Named("at", at), sql.Named("zero", time.Time{})).
+		Order("id").
+		Find(&medications).Error
 	if err != nil {
 		return nil, err
 	}
 	return medications, nil
 }
 
-func (store *PostgresMedicationStore) UpdateMedication(medication *Medication) error {
-	err := store.DB.Save(medication).Error
+// takenAsOfCondition matches the medication or supplement rows taken at
+// @at, like takenAsOf. Unset dates may be NULL or the zero time @zero.
+const takenAsOfCondition = `CASE
+	WHEN COALESCE(start_date, @zero) = @zero AND COALESCE(end_date, @zero) = @zero
+		THEN COALESCE(current, false)
+	ELSE COALESCE(start_date, @zero) <= @at AND (COALESCE(end_date, @zero) = @zero OR end_date > @at)
+END`
+
+func (store *PostgresMedicationStore) UpdateMedication(
+	ctx context.Context,
+	medication *Medication,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(medication).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresMedicationStore) DeleteMedication(id int64) error {
-	err := store.DB.Delete(&Medication{}, id).Error
+func (store *PostgresMedicationStore) DeleteMedication(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&Medication{}, id).Error
 	if err != nil {
 		return err
 	}
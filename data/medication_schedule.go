@@ -0,0 +1,305 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/validator"
+)
+
+// MedicationSchedule says when the doses of a medication are due: at each of
+// TimesOfDay, a comma-separated list such as "08:00,20:00", on every
+// EveryDays-th day counting from the day of StartDate. Times are in the
+// location of StartDate. EndDate, when set, is the moment from which no
+// more doses are due. A medication has at most one schedule.
+type MedicationSchedule struct {
+	ID           int64     `gorm:"primaryKey"                json:"id"`
+	UserID       int64     `gorm:"not null;index"            json:"user_id"`
+	MedicationID int64     `gorm:"not null;uniqueIndex"      json:"medication_id"`
+	TimesOfDay   string    `gorm:"type:text;not null"        json:"times_of_day"`
+	EveryDays    int       `gorm:"not null;default:1"        json:"every_days"`
+	StartDate    time.Time `gorm:"type:timestamptz;not null" json:"start_date"`
+	EndDate      time.Time `gorm:"type:timestamptz"          json:"end_date"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"            json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"            json:"updated_at"`
+}
+
+// DoseStatus records what happened to a dose.
+type DoseStatus string
+
+const (
+	DoseTaken   DoseStatus = "taken"
+	DoseLate    DoseStatus = "late"
+	DoseSkipped DoseStatus = "skipped"
+)
+
+var DoseStatuses = []DoseStatus{DoseTaken, DoseLate, DoseSkipped}
+
+// DoseLateAfter is how long after it was due a dose counts as late.
+const DoseLateAfter = time.Hour
+
+// DoseLog records a dose of a medication being taken or skipped.
+// ScheduledAt is the due time from the medication's schedule, or zero for
+// an unscheduled dose such as one taken as needed. TakenAt is zero for a
+// skipped dose.
+type DoseLog struct {
+	ID           int64      `gorm:"primaryKey"         json:"id"`
+	UserID       int64      `gorm:"not null;index"     json:"user_id"`
+	MedicationID int64      `gorm:"not null;index"     json:"medication_id"`
+	ScheduledAt  time.Time  `gorm:"type:timestamptz"   json:"scheduled_at"`
+	TakenAt      time.Time  `gorm:"type:timestamptz"   json:"taken_at"`
+	Status       DoseStatus `gorm:"type:text;not null" json:"status"`
+	Notes        string     `gorm:"type:text"          json:"notes"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"     json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime"     json:"updated_at"`
+}
+
+// MedicationScheduleStore provides database operations for medication
+// schedules. Creating a second schedule for a medication returns
+// ErrRecordConflict.
+type MedicationScheduleStore interface {
+	CreateMedicationSchedule(ctx context.Context, schedule *MedicationSchedule) (*MedicationSchedule, error)
+	GetMedicationSchedule(ctx context.Context, id int64) (*MedicationSchedule, error)
+	GetScheduleForMedication(ctx context.Context, medicationID int64) (*MedicationSchedule, error)
+	ListUserMedicationSchedules(ctx context.Context, userID int64) ([]*MedicationSchedule, error)
+	UpdateMedicationSchedule(ctx context.Context, schedule *MedicationSchedule) error
+	DeleteMedicationSchedule(ctx context.Context, id int64) error
+}
+
+// DoseLogStore provides database operations for dose logs. Logs are listed
+// in the order the doses were due or, for unscheduled doses, taken.
+type DoseLogStore interface {
+	CreateDoseLog(ctx context.Context, log *DoseLog) (*DoseLog, error)
+	GetDoseLog(ctx context.Context, id int64) (*DoseLog, error)
+	ListDoseLogsForMedication(ctx context.Context, medicationID int64) ([]*DoseLog, error)
+	ListUserDoseLogs(ctx context.Context, userID int64) ([]*DoseLog, error)
+	// ListDoseLogsBetween lists the logs of doses due, or taken, from from
+	// until before to.
+	ListDoseLogsBetween(ctx context.Context, medicationID int64, from, to time.Time) ([]*DoseLog, error)
+	UpdateDoseLog(ctx context.Context, log *DoseLog) error
+	DeleteDoseLog(ctx context.Context, id int64) error
+	DeleteAllDoseLogsForMedication(ctx context.Context, medicationID int64) error
+}
+
+// At returns when the dose was due or, if it was not scheduled, taken.
+func (log *DoseLog) At() time.Time {
+	if log.ScheduledAt.IsZero() {
+		return log.TakenAt
+	}
+	return log.ScheduledAt
+}
+
+// sortDoseLogs orders logs by At, then by ID.
+func sortDoseLogs(logs []*DoseLog) {
+	sort.SliceStable(logs, func(i, j int) bool {
+		if !logs[i].At().Equal(logs[j].At()) {
+			return logs[i].At().Before(logs[j].At())
+		}
+		return logs[i].ID < logs[j].ID
+	})
+}
+
+// ClassifyDose returns the status of a dose due at scheduledAt and taken at
+// takenAt: skipped if takenAt is zero, late if it was taken more than
+// DoseLateAfter after it was due, and taken otherwise.
+func ClassifyDose(scheduledAt, takenAt time.Time) DoseStatus {
+	switch {
+	case takenAt.IsZero():
+		return DoseSkipped
+	case !scheduledAt.IsZero() && takenAt.Sub(scheduledAt) > DoseLateAfter:
+		return DoseLate
+	default:
+		return DoseTaken
+	}
+}
+
+// Default times of the first and last dose of the day used by
+// NewMedicationSchedule.
+const (
+	DefaultFirstDoseTime = 8 * time.Hour
+	DefaultLastDoseTime  = 20 * time.Hour
+)
+
+// ErrNotSchedulable is returned by NewMedicationSchedule for a medication
+// whose dose has no regular frequency.
+var ErrNotSchedulable = errors.New("medication has no regular frequency")
+
+// NewMedicationSchedule derives a schedule from the structured dose of a
+// medication, with doses due from the day of start. Doses repeated every
+// few hours start at DefaultFirstDoseTime, and the hours must divide evenly
+// into a day so the times repeat daily; several doses a day are spread
+// evenly from DefaultFirstDoseTime to DefaultLastDoseTime. Frequencies that
+// do not divide into whole days, such as twice a week, are rounded to the
+// nearest number of days between doses. Medications taken as needed, with
+// no frequency, or with more than MaxDosesPerDay doses a day return
+// ErrNotSchedulable.
+func NewMedicationSchedule(medication *Medication, start time.Time) (*MedicationSchedule, error) {
+	dose := medication.Dose
+	days := dose.DosePeriod * dose.DosePeriodUnit.days()
+	if dose.DoseAsNeeded || dose.DoseFrequency <= 0 || days <= 0 {
+		return nil, fmt.Errorf("medication %d: %w", medication.ID, ErrNotSchedulable)
+	}
+	if dose.DosesPerDay() > MaxDosesPerDay {
+		return nil, fmt.Errorf(
+			"medication %d: %w: more than %d doses a day",
+			medication.ID, ErrNotSchedulable, MaxDosesPerDay,
+		)
+	}
+
+	schedule := &MedicationSchedule{
+		UserID:       medication.UserID,
+		MedicationID: medication.ID,
+		EveryDays:    1,
+		StartDate:    time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location()),
+		EndDate:      medication.EndDate,
+	}
+	frequency := float64(dose.DoseFrequency)
+
+	var times []time.Duration
+	interval := time.Duration(days / frequency * float64(24*time.Hour))
+	switch {
+	case dose.DosePeriodUnit == PeriodHour && interval < 24*time.Hour:
+		if interval <= 0 || (24*time.Hour)%interval != 0 {
+			return nil, fmt.Errorf(
+				"medication %d: %w: a dose every %s does not repeat daily",
+				medication.ID, ErrNotSchedulable, interval,
+			)
+		}
+		for at := time.Duration(0); at < 24*time.Hour; at += interval {
+			times = append(times, (DefaultFirstDoseTime+at)%(24*time.Hour))
+		}
+	case dose.DosePeriodUnit == PeriodDay && days == math.Trunc(days):
+		schedule.EveryDays = int(days)
+		times = spreadDoseTimes(dose.DoseFrequency)
+	case frequency >= days:
+		times = spreadDoseTimes(int(math.Round(frequency / days)))
+	default:
+		schedule.EveryDays = int(math.Round(days / frequency))
+		times = spreadDoseTimes(1)
+	}
+
+	schedule.TimesOfDay = FormatTimesOfDay(times)
+	return schedule, nil
+}
+
+// spreadDoseTimes spreads n doses evenly over the default dosing hours.
+func spreadDoseTimes(n int) []time.Duration {
+	if n <= 1 {
+		return []time.Duration{DefaultFirstDoseTime}
+	}
+	step := (DefaultLastDoseTime - DefaultFirstDoseTime) / time.Duration(n-1)
+	times := make([]time.Duration, n)
+	for i := range times {
+		times[i] = (DefaultFirstDoseTime + time.Duration(i)*step).Truncate(time.Minute)
+	}
+	return times
+}
+
+// FormatTimesOfDay writes times since midnight in the TimesOfDay format,
+// sorted and without duplicates.
+func FormatTimesOfDay(times []time.Duration) string {
+	sorted := make([]time.Duration, len(times))
+	copy(sorted, times)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var clocks []string
+	for _, at := range sorted {
+		clock := fmt.Sprintf("%02d:%02d", int(at.Hours()), int(at.Minutes())%60)
+		if len(clocks) == 0 || clocks[len(clocks)-1] != clock {
+			clocks = append(clocks, clock)
+		}
+	}
+	return strings.Join(clocks, ",")
+}
+
+// ParseTimesOfDay reads a TimesOfDay list into times since midnight.
+func ParseTimesOfDay(s string) ([]time.Duration, error) {
+	var times []time.Duration
+	for _, clock := range splitList(s) {
+		parsed, err := time.Parse("15:04", clock)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time of day %q", clock)
+		}
+		times = append(times, time.Duration(parsed.Hour())*time.Hour+time.Duration(parsed.Minute())*time.Minute)
+	}
+	return times, nil
+}
+
+// DosesBetween returns the times doses are due from from until before to,
+// in order.
+func (schedule *MedicationSchedule) DosesBetween(from, to time.Time) []time.Time {
+	times, err := ParseTimesOfDay(schedule.TimesOfDay)
+	if err != nil || len(times) == 0 || schedule.EveryDays < 1 {
+		return nil
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	if !schedule.EndDate.IsZero() && schedule.EndDate.Before(to) {
+		to = schedule.EndDate
+	}
+	if from.Before(schedule.StartDate) {
+		from = schedule.StartDate
+	}
+
+	location := schedule.StartDate.Location()
+	year, month, day := schedule.StartDate.In(location).Date()
+
+	// Skip the dosing days that end before from, counting calendar days so
+	// daylight saving changes cannot shift them.
+	start := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	fromYear, fromMonth, fromDay := from.In(location).Date()
+	skip := int(time.Date(fromYear, fromMonth, fromDay, 0, 0, 0, 0, time.UTC).Sub(start).Hours() / 24)
+	skip -= skip % schedule.EveryDays
+
+	var doses []time.Time
+	for offset := max(skip, 0); ; offset += schedule.EveryDays {
+		date := time.Date(year, month, day+offset, 0, 0, 0, 0, location)
+		if !date.Before(to) {
+			return doses
+		}
+		for _, at := range times {
+			due := time.Date(
+				date.Year(), date.Month(), date.Day(),
+				int(at.Hours()), int(at.Minutes())%60, 0, 0,
+				location,
+			)
+			if !due.Before(from) && due.Before(to) {
+				doses = append(doses, due)
+			}
+		}
+	}
+}
+
+func ValidateMedicationSchedule(v *validator.Validator, schedule *MedicationSchedule) {
+	v.Check(schedule.UserID != 0, "user_id", "must be provided")
+	v.Check(schedule.MedicationID != 0, "medication_id", "must be provided")
+	times, err := ParseTimesOfDay(schedule.TimesOfDay)
+	v.Check(err == nil, "times_of_day", "must be a comma-separated list of times such as 08:00")
+	v.Check(err != nil || len(times) > 0, "times_of_day", "must be provided")
+	v.Check(schedule.EveryDays >= 1, "every_days", "must be at least 1")
+	v.Check(!schedule.StartDate.IsZero(), "start_date", "must be provided")
+	v.Check(
+		schedule.EndDate.IsZero() || schedule.EndDate.After(schedule.StartDate),
+		"end_date",
+		"must be after the start date",
+	)
+}
+
+func ValidateDoseLog(v *validator.Validator, log *DoseLog) {
+	v.Check(log.UserID != 0, "user_id", "must be provided")
+	v.Check(log.MedicationID != 0, "medication_id", "must be provided")
+	v.Check(validator.PermittedValue(log.Status, DoseStatuses...), "status", "must be taken, late or skipped")
+	if log.Status == DoseSkipped {
+		v.Check(log.TakenAt.IsZero(), "taken_at", "must not be provided for a skipped dose")
+		v.Check(!log.ScheduledAt.IsZero(), "scheduled_at", "must be provided for a skipped dose")
+	} else {
+		v.Check(!log.TakenAt.IsZero(), "taken_at", "must be provided")
+	}
+	if log.Status == DoseLate {
+		v.Check(!log.ScheduledAt.IsZero(), "scheduled_at", "must be provided for a late dose")
+	}
+}
@@ -0,0 +1,268 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+// MemoryMedicationScheduleStore implements MedicationScheduleStore interface
+type MemoryMedicationScheduleStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryMedicationScheduleStore(db *MemoryDB) *MemoryMedicationScheduleStore {
+	return &MemoryMedicationScheduleStore{db: db}
+}
+
+func (store *MemoryMedicationScheduleStore) CreateMedicationSchedule(
+	ctx context.Context,
+	schedule *MedicationSchedule,
+) (*MedicationSchedule, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if schedule.ID != 0 {
+		if _, exists := store.db.medicationSchedules.get(schedule.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	}
+	_, scheduled := store.db.medicationSchedules.first(func(row *MedicationSchedule) bool {
+		return row.MedicationID == schedule.MedicationID
+	})
+	if scheduled {
+		return nil, ErrRecordConflict
+	}
+	if schedule.ID == 0 {
+		schedule.ID = store.db.medicationSchedules.nextID()
+	}
+	setCreateTimestamps(&schedule.CreatedAt, &schedule.UpdatedAt)
+	store.db.medicationSchedules.put(schedule.ID, *schedule)
+
+	return schedule, nil
+}
+
+func (store *MemoryMedicationScheduleStore) GetMedicationSchedule(
+	ctx context.Context,
+	id int64,
+) (*MedicationSchedule, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	schedule, ok := store.db.medicationSchedules.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &schedule, nil
+}
+
+func (store *MemoryMedicationScheduleStore) GetScheduleForMedication(
+	ctx context.Context,
+	medicationID int64,
+) (*MedicationSchedule, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	schedule, ok := store.db.medicationSchedules.first(func(row *MedicationSchedule) bool {
+		return row.MedicationID == medicationID
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return schedule, nil
+}
+
+func (store *MemoryMedicationScheduleStore) ListUserMedicationSchedules(
+	ctx context.Context,
+	userID int64,
+) ([]*MedicationSchedule, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.medicationSchedules.filter(func(row *MedicationSchedule) bool {
+		return row.UserID == userID
+	}), nil
+}
+
+func (store *MemoryMedicationScheduleStore) UpdateMedicationSchedule(
+	ctx context.Context,
+	schedule *MedicationSchedule,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	_, taken := store.db.medicationSchedules.first(func(row *MedicationSchedule) bool {
+		return row.MedicationID == schedule.MedicationID && row.ID != schedule.ID
+	})
+	if taken {
+		return ErrRecordConflict
+	}
+
+	existing, ok := store.db.medicationSchedules.get(schedule.ID)
+	if schedule.ID == 0 || !ok {
+		if schedule.ID == 0 {
+			schedule.ID = store.db.medicationSchedules.nextID()
+		}
+		setCreateTimestamps(&schedule.CreatedAt, &schedule.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&schedule.CreatedAt, &schedule.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.medicationSchedules.put(schedule.ID, *schedule)
+
+	return nil
+}
+
+func (store *MemoryMedicationScheduleStore) DeleteMedicationSchedule(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.medicationSchedules.delete(id)
+	return nil
+}
+
+// MemoryDoseLogStore implements DoseLogStore interface
+type MemoryDoseLogStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryDoseLogStore(db *MemoryDB) *MemoryDoseLogStore {
+	return &MemoryDoseLogStore{db: db}
+}
+
+func (store *MemoryDoseLogStore) CreateDoseLog(ctx context.Context, log *DoseLog) (*DoseLog, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if log.ID != 0 {
+		if _, exists := store.db.doseLogs.get(log.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		log.ID = store.db.doseLogs.nextID()
+	}
+	setCreateTimestamps(&log.CreatedAt, &log.UpdatedAt)
+	store.db.doseLogs.put(log.ID, *log)
+
+	return log, nil
+}
+
+func (store *MemoryDoseLogStore) GetDoseLog(ctx context.Context, id int64) (*DoseLog, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	log, ok := store.db.doseLogs.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &log, nil
+}
+
+func (store *MemoryDoseLogStore) ListDoseLogsForMedication(
+	ctx context.Context,
+	medicationID int64,
+) ([]*DoseLog, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	logs := store.db.doseLogs.filter(func(row *DoseLog) bool {
+		return row.MedicationID == medicationID
+	})
+	sortDoseLogs(logs)
+	return logs, nil
+}
+
+func (store *MemoryDoseLogStore) ListUserDoseLogs(ctx context.Context, userID int64) ([]*DoseLog, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	logs := store.db.doseLogs.filter(func(row *DoseLog) bool {
+		return row.UserID == userID
+	})
+	sortDoseLogs(logs)
+	return logs, nil
+}
+
+func (store *MemoryDoseLogStore) ListDoseLogsBetween(
+	ctx context.Context,
+	medicationID int64,
+	from time.Time,
+	to time.Time,
+) ([]*DoseLog, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	within := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+	logs := store.db.doseLogs.filter(func(row *DoseLog) bool {
+		return row.MedicationID == medicationID && (within(row.ScheduledAt) || within(row.TakenAt))
+	})
+	sortDoseLogs(logs)
+	return logs, nil
+}
+
+func (store *MemoryDoseLogStore) UpdateDoseLog(ctx context.Context, log *DoseLog) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.doseLogs.get(log.ID)
+	if log.ID == 0 || !ok {
+		if log.ID == 0 {
+			log.ID = store.db.doseLogs.nextID()
+		}
+		setCreateTimestamps(&log.CreatedAt, &log.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&log.CreatedAt, &log.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.doseLogs.put(log.ID, *log)
+
+	return nil
+}
+
+func (store *MemoryDoseLogStore) DeleteDoseLog(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.doseLogs.delete(id)
+	return nil
+}
+
+func (store *MemoryDoseLogStore) DeleteAllDoseLogsForMedication(
+	ctx context.Context,
+	medicationID int64,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.doseLogs.deleteWhere(func(row *DoseLog) bool {
+		return row.MedicationID == medicationID
+	})
+	return nil
+}
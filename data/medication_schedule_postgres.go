@@ -0,0 +1,242 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresMedicationScheduleStore implements MedicationScheduleStore interface
+type PostgresMedicationScheduleStore struct {
+	DB      *gorm.DB
+	Timeout time.Duration
+}
+
+func NewPostgresMedicationScheduleStore(db *gorm.DB) *PostgresMedicationScheduleStore {
+	return &PostgresMedicationScheduleStore{DB: db}
+}
+
+func (store *PostgresMedicationScheduleStore) CreateMedicationSchedule(
+	ctx context.Context,
+	schedule *MedicationSchedule,
+) (*MedicationSchedule, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(schedule).Error
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, ErrRecordConflict
+		}
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (store *PostgresMedicationScheduleStore) GetMedicationSchedule(
+	ctx context.Context,
+	id int64,
+) (*MedicationSchedule, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var schedule MedicationSchedule
+	err := db.First(&schedule, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (store *PostgresMedicationScheduleStore) GetScheduleForMedication(
+	ctx context.Context,
+	medicationID int64,
+) (*MedicationSchedule, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var schedule MedicationSchedule
+	err := db.Where("medication_id = ?", medicationID).First(&schedule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (store *PostgresMedicationScheduleStore) ListUserMedicationSchedules(
+	ctx context.Context,
+	userID int64,
+) ([]*MedicationSchedule, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var schedules []*MedicationSchedule
+	err := db.Where("user_id = ?", userID).Order("id").Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (store *PostgresMedicationScheduleStore) UpdateMedicationSchedule(
+	ctx context.Context,
+	schedule *MedicationSchedule,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(schedule).Error
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return ErrRecordConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (store *PostgresMedicationScheduleStore) DeleteMedicationSchedule(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&MedicationSchedule{}, id).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// PostgresDoseLogStore implements DoseLogStore interface
+type PostgresDoseLogStore struct {
+	DB      *gorm.DB
+	Timeout time.Duration
+}
+
+func NewPostgresDoseLogStore(db *gorm.DB) *PostgresDoseLogStore {
+	return &PostgresDoseLogStore{DB: db}
+}
+
+func (store *PostgresDoseLogStore) CreateDoseLog(ctx context.Context, log *DoseLog) (*DoseLog, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(log).Error
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+func (store *PostgresDoseLogStore) GetDoseLog(ctx context.Context, id int64) (*DoseLog, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var log DoseLog
+	err := db.First(&log, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+func (store *PostgresDoseLogStore) ListDoseLogsForMedication(
+	ctx context.Context,
+	medicationID int64,
+) ([]*DoseLog, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var logs []*DoseLog
+	err := db.Where("medication_id = ?", medicationID).Order("id").Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	sortDoseLogs(logs)
+	return logs, nil
+}
+
+func (store *PostgresDoseLogStore) ListUserDoseLogs(ctx context.Context, userID int64) ([]*DoseLog, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var logs []*DoseLog
+	err := db.Where("user_id = ?", userID).Order("id").Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	sortDoseLogs(logs)
+	return logs, nil
+}
+
+func (store *PostgresDoseLogStore) ListDoseLogsBetween(
+	ctx context.Context,
+	medicationID int64,
+	from time.Time,
+	to time.Time,
+) ([]*DoseLog, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var logs []*DoseLog
+	err := db.
+		Where("medication_id = ?", medicationID).
+		Where(
+			"((scheduled_at >= ? AND scheduled_at < ?) OR (taken_at >= ? AND taken_at < ?))",
+			from, to, from, to,
+		).
+		Order("id").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	sortDoseLogs(logs)
+	return logs, nil
+}
+
+func (store *PostgresDoseLogStore) UpdateDoseLog(ctx context.Context, log *DoseLog) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(log).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (store *PostgresDoseLogStore) DeleteDoseLog(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&DoseLog{}, id).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (store *PostgresDoseLogStore) DeleteAllDoseLogsForMedication(
+	ctx context.Context,
+	medicationID int64,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Where("medication_id = ?", medicationID).Delete(&DoseLog{}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
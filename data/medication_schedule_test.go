@@ -0,0 +1,61 @@
+package data_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func TestNewMedicationSchedule(t *testing.T) {
+	start := time.Date(2025, 3, 1, 14, 30, 0, 0, time.UTC)
+	every := func(frequency int, period float64, unit data.PeriodUnit) data.Dose {
+		return data.Dose{DoseFrequency: frequency, DosePeriod: period, DosePeriodUnit: unit}
+	}
+
+	tests := []struct {
+		dose      data.Dose
+		times     string
+		everyDays int
+	}{
+		{every(1, 1, data.PeriodDay), "08:00", 1},
+		{every(2, 1, data.PeriodDay), "08:00,20:00", 1},
+		{every(3, 1, data.PeriodDay), "08:00,14:00,20:00", 1},
+		{every(1, 2, data.PeriodDay), "08:00", 2},
+		{every(1, 8, data.PeriodHour), "00:00,08:00,16:00", 1},
+		{every(1, 6, data.PeriodHour), "02:00,08:00,14:00,20:00", 1},
+		{every(1, 24, data.PeriodHour), "08:00", 1},
+		{every(1, 1, data.PeriodWeek), "08:00", 7},
+		{every(3, 1, data.PeriodWeek), "08:00", 2},
+	}
+	for _, test := range tests {
+		schedule, err := data.NewMedicationSchedule(&data.Medication{Dose: test.dose}, start)
+		if err != nil {
+			t.Errorf("NewMedicationSchedule(%+v): %v", test.dose, err)
+			continue
+		}
+		if schedule.TimesOfDay != test.times || schedule.EveryDays != test.everyDays {
+			t.Errorf(
+				"NewMedicationSchedule(%+v) = %q every %d days, want %q every %d days",
+				test.dose, schedule.TimesOfDay, schedule.EveryDays, test.times, test.everyDays,
+			)
+		}
+		if want := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC); !schedule.StartDate.Equal(want) {
+			t.Errorf("NewMedicationSchedule(%+v) starts %v, want %v", test.dose, schedule.StartDate, want)
+		}
+	}
+
+	for _, dose := range []data.Dose{
+		{},
+		{DoseAsNeeded: true, DoseFrequency: 1, DosePeriod: 4, DosePeriodUnit: data.PeriodHour},
+		every(1, 5, data.PeriodHour),
+		every(2, 7, data.PeriodHour),
+		every(1_000_000, 1, data.PeriodDay),
+		every(1<<62, 1, data.PeriodHour),
+	} {
+		if _, err := data.NewMedicationSchedule(&data.Medication{Dose: dose}, start); !errors.Is(err, data.ErrNotSchedulable) {
+			t.Errorf("NewMedicationSchedule(%+v): got error %v, want %v", dose, err, data.ErrNotSchedulable)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryDB holds the state shared by the in-memory stores. A single lock
+// guards every table so that operations spanning several tables (e.g.
+// GetByToken) see a consistent view, mirroring a single Postgres database.
+type MemoryDB struct {
+	mu sync.RWMutex
+
+	memoryTables
+}
+
+type memoryTables struct {
+	users              *memoryTable[User]
+	tokens             *memoryTable[Token]
+	allergies          *memoryTable[Allergy]
+	caregivers         *memoryTable[Caregiver]
+	dietarySupplements *memoryTable[DietarySupplement]
+	emergencyContacts  *memoryTable[EmergencyContact]
+	frequentFoods      *memoryTable[FrequentFood]
+	medicalEvents      *memoryTable[MedicalEvent]
+	medicalInformation *memoryTable[MedicalInformation]
+	medications        *memoryTable[Medication]
+	userIntakes        *memoryTable[UserIntake]
+	trackingPeriods    *memoryTable[TrackingPeriod]
+	mealEntries        *memoryTable[MealEntry]
+	foodItems          *memoryTable[FoodItem]
+	mealFoods          *memoryTable[MealFood]
+	customFoods        *memoryTable[CustomFood]
+	symptoms           *memoryTable[Symptom]
+	symptomTypes       *memoryTable[SymptomType]
+
+	symptomAttributions *memoryTable[SymptomAttribution]
+
+	medicationSchedules *memoryTable[MedicationSchedule]
+	doseLogs            *memoryTable[DoseLog]
+
+	eliminationPlans        *memoryTable[EliminationPlan]
+	eliminationRestrictions *memoryTable[EliminationRestriction]
+	reintroductionSteps     *memoryTable[ReintroductionStep]
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{memoryTables: memoryTables{
+		users:              newMemoryTable[User](),
+		tokens:             newMemoryTable[Token](),
+		allergies:          newMemoryTable[Allergy](),
+		caregivers:         newMemoryTable[Caregiver](),
+		dietarySupplements: newMemoryTable[DietarySupplement](),
+		emergencyContacts:  newMemoryTable[EmergencyContact](),
+		frequentFoods:      newMemoryTable[FrequentFood](),
+		medicalEvents:      newMemoryTable[MedicalEvent](),
+		medicalInformation: newMemoryTable[MedicalInformation](),
+		medications:        newMemoryTable[Medication](),
+		userIntakes:        newMemoryTable[UserIntake](),
+		trackingPeriods:    newMemoryTable[TrackingPeriod](),
+		mealEntries:        newMemoryTable[MealEntry](),
+		foodItems:          newMemoryTable[FoodItem](),
+		mealFoods:          newMemoryTable[MealFood](),
+		customFoods:        newMemoryTable[CustomFood](),
+		symptoms:           newMemoryTable[Symptom](),
+		symptomTypes:       newMemoryTable[SymptomType](),
+
+		symptomAttributions: newMemoryTable[SymptomAttribution](),
+
+		medicationSchedules: newMemoryTable[MedicationSchedule](),
+		doseLogs:            newMemoryTable[DoseLog](),
+
+		eliminationPlans:        newMemoryTable[EliminationPlan](),
+		eliminationRestrictions: newMemoryTable[EliminationRestriction](),
+		reintroductionSteps:     newMemoryTable[ReintroductionStep](),
+	}}
+}
+
+func (tables memoryTables) clone() memoryTables {
+	return memoryTables{
+		users:              tables.users.clone(),
+		tokens:             tables.tokens.clone(),
+		allergies:          tables.allergies.clone(),
+		caregivers:         tables.caregivers.clone(),
+		dietarySupplements: tables.dietarySupplements.clone(),
+		emergencyContacts:  tables.emergencyContacts.clone(),
+		frequentFoods:      tables.frequentFoods.clone(),
+		medicalEvents:      tables.medicalEvents.clone(),
+		medicalInformation: tables.medicalInformation.clone(),
+		medications:        tables.medications.clone(),
+		userIntakes:        tables.userIntakes.clone(),
+		trackingPeriods:    tables.trackingPeriods.clone(),
+		mealEntries:        tables.mealEntries.clone(),
+		foodItems:          tables.foodItems.clone(),
+		mealFoods:          tables.mealFoods.clone(),
+		customFoods:        tables.customFoods.clone(),
+		symptoms:           tables.symptoms.clone(),
+		symptomTypes:       tables.symptomTypes.clone(),
+
+		symptomAttributions: tables.symptomAttributions.clone(),
+
+		medicationSchedules: tables.medicationSchedules.clone(),
+		doseLogs:            tables.doseLogs.clone(),
+
+		eliminationPlans:        tables.eliminationPlans.clone(),
+		eliminationRestrictions: tables.eliminationRestrictions.clone(),
+		reintroductionSteps:     tables.reintroductionSteps.clone(),
+	}
+}
+
+// transaction runs fn against a private copy of the database and publishes
+// the copy only if fn succeeds. The database stays write-locked until fn
+// returns, so fn must only use stores built on the tx it is given.
+func (db *MemoryDB) transaction(ctx context.Context, fn func(tx *MemoryDB) error) error {
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mu.Unlock()
+
+	tx := &MemoryDB{memoryTables: db.memoryTables.clone()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.memoryTables = tx.memoryTables
+	return nil
+}
+
+// memoryTable stores rows by value so callers never share memory with the
+// table; every read hands out a fresh copy, like a row scanned from Postgres.
+type memoryTable[T any] struct {
+	rows   map[int64]T
+	lastID int64
+}
+
+func newMemoryTable[T any]() *memoryTable[T] {
+	return &memoryTable[T]{rows: make(map[int64]T)}
+}
+
+func (table *memoryTable[T]) clone() *memoryTable[T] {
+	rows := make(map[int64]T, len(table.rows))
+	for id, row := range table.rows {
+		rows[id] = row
+	}
+	return &memoryTable[T]{rows: rows, lastID: table.lastID}
+}
+
+func (table *memoryTable[T]) nextID() int64 {
+	table.lastID++
+	return table.lastID
+}
+
+func (table *memoryTable[T]) get(id int64) (T, bool) {
+	row, ok := table.rows[id]
+	return row, ok
+}
+
+func (table *memoryTable[T]) put(id int64, row T) {
+	table.rows[id] = row
+	if id > table.lastID {
+		table.lastID = id
+	}
+}
+
+func (table *memoryTable[T]) delete(id int64) {
+	delete(table.rows, id)
+}
+
+// filter returns copies of the matching rows in primary key order, which is
+// the order Postgres uses for First and is a stable default for lists.
+func (table *memoryTable[T]) filter(match func(row *T) bool) []*T {
+	ids := make([]int64, 0, len(table.rows))
+	for id := range table.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows := make([]*T, 0, len(ids))
+	for _, id := range ids {
+		row := table.rows[id]
+		if match == nil || match(&row) {
+			rows = append(rows, &row)
+		}
+	}
+	return rows
+}
+
+func (table *memoryTable[T]) first(match func(row *T) bool) (*T, bool) {
+	rows := table.filter(match)
+	if len(rows) == 0 {
+		return nil, false
+	}
+	return rows[0], true
+}
+
+func (table *memoryTable[T]) deleteWhere(match func(row *T) bool) {
+	for id, row := range table.rows {
+		if match(&row) {
+			delete(table.rows, id)
+		}
+	}
+}
+
+// setCreateTimestamps mimics GORM's autoCreateTime/autoUpdateTime on insert.
+func setCreateTimestamps(createdAt, updatedAt *time.Time) {
+	now := time.Now()
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	if updatedAt.IsZero() {
+		*updatedAt = now
+	}
+}
+
+// setUpdateTimestamps mimics GORM's Save, which always refreshes UpdatedAt
+// and keeps the stored CreatedAt when the caller did not load it.
+func setUpdateTimestamps(createdAt, updatedAt *time.Time, stored time.Time) {
+	if createdAt.IsZero() {
+		*createdAt = stored
+	}
+	*updatedAt = time.Now()
+}
+
+// lock and rlock acquire the database lock unless ctx is already done, so
+// cancelled callers fail the same way they would against Postgres.
+func (db *MemoryDB) lock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	return nil
+}
+
+func (db *MemoryDB) rlock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.RLock()
+	return nil
+}
@@ -0,0 +1,12 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/Universal-Selfcare/utils/data"
+	"github.com/Universal-Selfcare/utils/data/datatest"
+)
+
+func TestMemoryStores(t *testing.T) {
+	datatest.RunStoreSuite(t, data.NewMemoryStores())
+}
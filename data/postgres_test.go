@@ -0,0 +1,46 @@
+package data_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Universal-Selfcare/utils/data"
+	"github.com/Universal-Selfcare/utils/data/datatest"
+	"github.com/Universal-Selfcare/utils/migrate"
+)
+
+// testDSNEnv names the variable holding the DSN of a scratch database for
+// the Postgres suite. The suite is skipped when it is unset.
+const testDSNEnv = "UNIVERSAL_SELFCARE_TEST_DB_DSN"
+
+func TestPostgresStores(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql.DB: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := migrate.New(sqlDB)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	datatest.RunStoreSuite(t, data.NewStores(db))
+}
@@ -1,7 +1,9 @@
 package data
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -9,6 +11,7 @@ import (
 var (
 	ErrRecordNotFound = errors.New("record not found")
 	ErrRecordConflict = errors.New("new record conflicts with existing record")
+	ErrTxUnsupported  = errors.New("stores were not created with transaction support")
 )
 
 type Stores struct {
@@ -29,28 +32,118 @@ type Stores struct {
 	MealFoodStore           MealFoodStore
 	CustomFoodStore         CustomFoodStore
 	SymptomStore            SymptomStore
+	SymptomTypeStore        SymptomTypeStore
+	SymptomAttributionStore SymptomAttributionStore
+
+	MedicationScheduleStore MedicationScheduleStore
+	DoseLogStore            DoseLogStore
+
+	EliminationPlanStore        EliminationPlanStore
+	EliminationRestrictionStore EliminationRestrictionStore
+	ReintroductionStepStore     ReintroductionStepStore
+
+	withTx func(ctx context.Context, fn func(tx *Stores) error) error
+}
+
+// DefaultQueryTimeout bounds every Postgres query issued through NewStores.
+const DefaultQueryTimeout = 5 * time.Second
+
+// StoreTimeouts is the query timeout policy for the Postgres stores. A
+// store-specific value takes precedence over Default; when both are zero the
+// store only honours the deadline of the caller's context.
+type StoreTimeouts struct {
+	Default time.Duration
+
+	UserStore               time.Duration
+	TokenStore              time.Duration
+	AllergyStore            time.Duration
+	CaregiverStore          time.Duration
+	DietarySupplementStore  time.Duration
+	EmergencyContactStore   time.Duration
+	FrequentFoodStore       time.Duration
+	MedicalEventStore       time.Duration
+	MedicalInformationStore time.Duration
+	MedicationStore         time.Duration
+	UserIntakeStore         time.Duration
+	TrackingPeriodStore     time.Duration
+	MealEntryStore          time.Duration
+	FoodItemStore           time.Duration
+	MealFoodStore           time.Duration
+	CustomFoodStore         time.Duration
+	SymptomStore            time.Duration
+	SymptomTypeStore        time.Duration
+	SymptomAttributionStore time.Duration
+
+	MedicationScheduleStore time.Duration
+	DoseLogStore            time.Duration
+
+	EliminationPlanStore        time.Duration
+	EliminationRestrictionStore time.Duration
+	ReintroductionStepStore     time.Duration
+}
+
+func (timeouts StoreTimeouts) orDefault(timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+	return timeouts.Default
 }
 
 func NewStores(db *gorm.DB) *Stores {
+	return NewStoresWithTimeouts(db, StoreTimeouts{Default: DefaultQueryTimeout})
+}
+
+func NewStoresWithTimeouts(db *gorm.DB, timeouts StoreTimeouts) *Stores {
 	userStore := NewPostgresUserStore(db)
+	userStore.Timeout = timeouts.orDefault(timeouts.UserStore)
 	tokenStore := NewPostgresTokenStore(db)
+	tokenStore.Timeout = timeouts.orDefault(timeouts.TokenStore)
 	allergyStore := NewPostgresAllergyStore(db)
+	allergyStore.Timeout = timeouts.orDefault(timeouts.AllergyStore)
 	caregiverStore := NewPostgresCaregiverStore(db)
+	caregiverStore.Timeout = timeouts.orDefault(timeouts.CaregiverStore)
 	dietarySupplementStore := NewPostgresDietarySupplementStore(db)
+	dietarySupplementStore.Timeout = timeouts.orDefault(timeouts.DietarySupplementStore)
 	emergencyContactStore := NewPostgresEmergencyContactStore(db)
+	emergencyContactStore.Timeout = timeouts.orDefault(timeouts.EmergencyContactStore)
 	frequentFoodStore := NewPostgresFrequentFoodStore(db)
+	frequentFoodStore.Timeout = timeouts.orDefault(timeouts.FrequentFoodStore)
 	medicalEventStore := NewPostgresMedicalEventStore(db)
+	medicalEventStore.Timeout = timeouts.orDefault(timeouts.MedicalEventStore)
 	medicalInformationStore := NewPostgresMedicalInformationStore(db)
+	medicalInformationStore.Timeout = timeouts.orDefault(timeouts.MedicalInformationStore)
 	medicationStore := NewPostgresMedicationStore(db)
+	medicationStore.Timeout = timeouts.orDefault(timeouts.MedicationStore)
 	userIntakeStore := NewPostgresUserIntakeStore(db)
+	userIntakeStore.Timeout = timeouts.orDefault(timeouts.UserIntakeStore)
 	trackingPeriodStore := NewPostgresTrackingPeriodStore(db)
+	trackingPeriodStore.Timeout = timeouts.orDefault(timeouts.TrackingPeriodStore)
 	mealEntryStore := NewPostgresMealEntryStore(db)
+	mealEntryStore.Timeout = timeouts.orDefault(timeouts.MealEntryStore)
 	foodItemStore := NewPostgresFoodItemStore(db)
+	foodItemStore.Timeout = timeouts.orDefault(timeouts.FoodItemStore)
 	mealFoodStore := NewPostgresMealFoodStore(db)
+	mealFoodStore.Timeout = timeouts.orDefault(timeouts.MealFoodStore)
 	customFoodStore := NewPostgresCustomFoodStore(db)
+	customFoodStore.Timeout = timeouts.orDefault(timeouts.CustomFoodStore)
 	symptomStore := NewPostgresSymptomStore(db)
+	symptomStore.Timeout = timeouts.orDefault(timeouts.SymptomStore)
+	symptomTypeStore := NewPostgresSymptomTypeStore(db)
+	symptomTypeStore.Timeout = timeouts.orDefault(timeouts.SymptomTypeStore)
+	symptomAttributionStore := NewPostgresSymptomAttributionStore(db)
+	symptomAttributionStore.Timeout = timeouts.orDefault(timeouts.SymptomAttributionStore)
+	medicationScheduleStore := NewPostgresMedicationScheduleStore(db)
+	medicationScheduleStore.Timeout = timeouts.orDefault(timeouts.MedicationScheduleStore)
+	doseLogStore := NewPostgresDoseLogStore(db)
+	doseLogStore.Timeout = timeouts.orDefault(timeouts.DoseLogStore)
+	eliminationPlanStore := NewPostgresEliminationPlanStore(db)
+	eliminationPlanStore.Timeout = timeouts.orDefault(timeouts.EliminationPlanStore)
+	eliminationRestrictionStore := NewPostgresEliminationRestrictionStore(db)
+	eliminationRestrictionStore.Timeout = timeouts.orDefault(timeouts.EliminationRestrictionStore)
+	reintroductionStepStore := NewPostgresReintroductionStepStore(db)
+	reintroductionStepStore.Timeout = timeouts.orDefault(timeouts.ReintroductionStepStore)
 
-	return &Stores{
+	stores := &Stores{
 		UserStore:               userStore,
 		TokenStore:              tokenStore,
 		AllergyStore:            allergyStore,
@@ -68,5 +161,92 @@ func NewStores(db *gorm.DB) *Stores {
 		MealFoodStore:           mealFoodStore,
 		CustomFoodStore:         customFoodStore,
 		SymptomStore:            symptomStore,
+		SymptomTypeStore:        symptomTypeStore,
+		SymptomAttributionStore: symptomAttributionStore,
+
+		MedicationScheduleStore: medicationScheduleStore,
+		DoseLogStore:            doseLogStore,
+
+		EliminationPlanStore:        eliminationPlanStore,
+		EliminationRestrictionStore: eliminationRestrictionStore,
+		ReintroductionStepStore:     reintroductionStepStore,
+	}
+	stores.withTx = func(ctx context.Context, fn func(tx *Stores) error) error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(NewStoresWithTimeouts(tx, timeouts))
+		})
+	}
+
+	return stores
+}
+
+// withTimeout scopes db to ctx, additionally bounded by timeout when it is
+// positive. The returned cancel function must always be called.
+func withTimeout(
+	ctx context.Context,
+	db *gorm.DB,
+	timeout time.Duration,
+) (*gorm.DB, context.CancelFunc) {
+	if timeout <= 0 {
+		return db.WithContext(ctx), func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return db.WithContext(ctx), cancel
+}
+
+// NewMemoryStores returns Stores backed by a fresh in-memory database. The
+// stores are safe for concurrent use and follow the same contracts as the
+// Postgres implementations, which makes them suitable for service tests.
+func NewMemoryStores() *Stores {
+	return NewMemoryStoresFor(NewMemoryDB())
+}
+
+func NewMemoryStoresFor(db *MemoryDB) *Stores {
+	stores := &Stores{
+		UserStore:               NewMemoryUserStore(db),
+		TokenStore:              NewMemoryTokenStore(db),
+		AllergyStore:            NewMemoryAllergyStore(db),
+		CaregiverStore:          NewMemoryCaregiverStore(db),
+		DietarySupplementStore:  NewMemoryDietarySupplementStore(db),
+		EmergencyContactStore:   NewMemoryEmergencyContactStore(db),
+		FrequentFoodStore:       NewMemoryFrequentFoodStore(db),
+		MedicalEventStore:       NewMemoryMedicalEventStore(db),
+		MedicalInformationStore: NewMemoryMedicalInformationStore(db),
+		MedicationStore:         NewMemoryMedicationStore(db),
+		UserIntakeStore:         NewMemoryUserIntakeStore(db),
+		TrackingPeriodStore:     NewMemoryTrackingPeriodStore(db),
+		MealEntryStore:          NewMemoryMealEntryStore(db),
+		FoodItemStore:           NewMemoryFoodItemStore(db),
+		MealFoodStore:           NewMemoryMealFoodStore(db),
+		CustomFoodStore:         NewMemoryCustomFoodStore(db),
+		SymptomStore:            NewMemorySymptomStore(db),
+		SymptomTypeStore:        NewMemorySymptomTypeStore(db),
+		SymptomAttributionStore: NewMemorySymptomAttributionStore(db),
+
+		MedicationScheduleStore: NewMemoryMedicationScheduleStore(db),
+		DoseLogStore:            NewMemoryDoseLogStore(db),
+
+		EliminationPlanStore:        NewMemoryEliminationPlanStore(db),
+		EliminationRestrictionStore: NewMemoryEliminationRestrictionStore(db),
+		ReintroductionStepStore:     NewMemoryReintroductionStepStore(db),
+	}
+	stores.withTx = func(ctx context.Context, fn func(tx *Stores) error) error {
+		return db.transaction(ctx, func(tx *MemoryDB) error {
+			return fn(NewMemoryStoresFor(tx))
+		})
+	}
+
+	return stores
+}
+
+// WithTx runs fn with a copy of the stores scoped to a single transaction.
+// The transaction commits when fn returns nil and rolls back when it returns
+// an error or panics. fn must only use tx, never the receiver, and tx must
+// not be used after WithTx returns. Calling WithTx on tx nests the
+// transaction.
+func (stores *Stores) WithTx(ctx context.Context, fn func(tx *Stores) error) error {
+	if stores.withTx == nil {
+		return ErrTxUnsupported
 	}
+	return stores.withTx(ctx, fn)
 }
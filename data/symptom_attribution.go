@@ -0,0 +1,49 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/validator"
+)
+
+// SymptomAttribution links a standalone symptom to a meal eaten before it
+// that may have caused it. Lag is the time from the start of the meal to the
+// symptom.
+type SymptomAttribution struct {
+	ID          int64         `gorm:"primaryKey"     json:"id"`
+	SymptomID   int64         `gorm:"not null;index" json:"symptom_id"`
+	MealEntryID int64         `gorm:"not null;index" json:"meal_entry_id"`
+	Lag         time.Duration `gorm:"not null"       json:"lag"`
+	CreatedAt   time.Time     `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time     `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// SymptomAttributionStore provides database operations for symptom
+// attributions. A symptom is attributed to a meal at most once.
+type SymptomAttributionStore interface {
+	CreateSymptomAttribution(ctx context.Context, attribution *SymptomAttribution) (*SymptomAttribution, error)
+	ListAttributionsForSymptom(ctx context.Context, symptomID int64) ([]*SymptomAttribution, error)
+	ListAttributionsForMeal(ctx context.Context, mealEntryID int64) ([]*SymptomAttribution, error)
+	DeleteAllAttributionsForSymptom(ctx context.Context, symptomID int64) error
+	DeleteAllAttributionsForMeal(ctx context.Context, mealEntryID int64) error
+}
+
+// IsStandalone reports whether the symptom was logged without a meal.
+func (symptom *Symptom) IsStandalone() bool {
+	return symptom.MealEntryID == 0
+}
+
+// ValidateStandaloneSymptom checks a standalone symptom against the tracking
+// period it is logged in. The symptom itself is checked by ValidateSymptom.
+func ValidateStandaloneSymptom(v *validator.Validator, symptom *Symptom, period *TrackingPeriod) {
+	v.Check(symptom.IsStandalone(), "meal_entry_id", "must not be provided")
+	v.Check(symptom.TrackingPeriodID == period.ID, "tracking_period_id", "must match the tracking period")
+	v.Check(symptom.UserID == period.UserID, "user_id", "must match the tracking period's user")
+
+	v.Check(!symptom.OccurredAt.IsZero(), "occurred_at", "must be provided")
+	if !symptom.OccurredAt.IsZero() {
+		_, err := period.TrackingDayFor(symptom.OccurredAt)
+		v.Check(err == nil, "occurred_at", "must fall within the tracking period")
+	}
+}
@@ -0,0 +1,101 @@
+package data
+
+import (
+	"context"
+)
+
+// MemorySymptomAttributionStore implements SymptomAttributionStore interface
+type MemorySymptomAttributionStore struct {
+	db *MemoryDB
+}
+
+func NewMemorySymptomAttributionStore(db *MemoryDB) *MemorySymptomAttributionStore {
+	return &MemorySymptomAttributionStore{db: db}
+}
+
+func (store *MemorySymptomAttributionStore) CreateSymptomAttribution(
+	ctx context.Context,
+	attribution *SymptomAttribution,
+) (*SymptomAttribution, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if attribution.ID != 0 {
+		if _, exists := store.db.symptomAttributions.get(attribution.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	}
+	_, linked := store.db.symptomAttributions.first(func(row *SymptomAttribution) bool {
+		return row.SymptomID == attribution.SymptomID && row.MealEntryID == attribution.MealEntryID
+	})
+	if linked {
+		return nil, ErrRecordConflict
+	}
+	if attribution.ID == 0 {
+		attribution.ID = store.db.symptomAttributions.nextID()
+	}
+	setCreateTimestamps(&attribution.CreatedAt, &attribution.UpdatedAt)
+	store.db.symptomAttributions.put(attribution.ID, *attribution)
+
+	return attribution, nil
+}
+
+func (store *MemorySymptomAttributionStore) ListAttributionsForSymptom(
+	ctx context.Context,
+	symptomID int64,
+) ([]*SymptomAttribution, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.symptomAttributions.filter(func(row *SymptomAttribution) bool {
+		return row.SymptomID == symptomID
+	}), nil
+}
+
+func (store *MemorySymptomAttributionStore) ListAttributionsForMeal(
+	ctx context.Context,
+	mealEntryID int64,
+) ([]*SymptomAttribution, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.symptomAttributions.filter(func(row *SymptomAttribution) bool {
+		return row.MealEntryID == mealEntryID
+	}), nil
+}
+
+func (store *MemorySymptomAttributionStore) DeleteAllAttributionsForSymptom(
+	ctx context.Context,
+	symptomID int64,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.symptomAttributions.deleteWhere(func(row *SymptomAttribution) bool {
+		return row.SymptomID == symptomID
+	})
+	return nil
+}
+
+func (store *MemorySymptomAttributionStore) DeleteAllAttributionsForMeal(
+	ctx context.Context,
+	mealEntryID int64,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.symptomAttributions.deleteWhere(func(row *SymptomAttribution) bool {
+		return row.MealEntryID == mealEntryID
+	})
+	return nil
+}
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresSymptomAttributionStore implements SymptomAttributionStore interface
+type PostgresSymptomAttributionStore struct {
+	DB      *gorm.DB
+	Timeout time.Duration
+}
+
+func NewPostgresSymptomAttributionStore(db *gorm.DB) *PostgresSymptomAttributionStore {
+	return &PostgresSymptomAttributionStore{DB: db}
+}
+
+func (store *PostgresSymptomAttributionStore) CreateSymptomAttribution(
+	ctx context.Context,
+	attribution *SymptomAttribution,
+) (*SymptomAttribution, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(attribution).Error
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, ErrRecordConflict
+		}
+		return nil, err
+	}
+	return attribution, nil
+}
+
+func (store *PostgresSymptomAttributionStore) ListAttributionsForSymptom(
+	ctx context.Context,
+	symptomID int64,
+) ([]*SymptomAttribution, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var attributions []*SymptomAttribution
+	err := db.Where("symptom_id = ?", symptomID).Order("id").Find(&attributions).Error
+	if err != nil {
+		return nil, err
+	}
+	return attributions, nil
+}
+
+func (store *PostgresSymptomAttributionStore) ListAttributionsForMeal(
+	ctx context.Context,
+	mealEntryID int64,
+) ([]*SymptomAttribution, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var attributions []*SymptomAttribution
+	err := db.Where("meal_entry_id = ?", mealEntryID).Order("id").Find(&attributions).Error
+	if err != nil {
+		return nil, err
+	}
+	return attributions, nil
+}
+
+func (store *PostgresSymptomAttributionStore) DeleteAllAttributionsForSymptom(
+	ctx context.Context,
+	symptomID int64,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Where("symptom_id = ?", symptomID).Delete(&SymptomAttribution{}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (store *PostgresSymptomAttributionStore) DeleteAllAttributionsForMeal(
+	ctx context.Context,
+	mealEntryID int64,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Where("meal_entry_id = ?", mealEntryID).Delete(&SymptomAttribution{}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
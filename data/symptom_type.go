@@ -0,0 +1,247 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/validator"
+)
+
+// BodySystem groups symptom types by the part of the body they affect.
+type BodySystem string
+
+const (
+	BodySystemDigestive       BodySystem = "digestive"
+	BodySystemSkin            BodySystem = "skin"
+	BodySystemRespiratory     BodySystem = "respiratory"
+	BodySystemNeurological    BodySystem = "neurological"
+	BodySystemMusculoskeletal BodySystem = "musculoskeletal"
+	BodySystemCardiovascular  BodySystem = "cardiovascular"
+	BodySystemUrinary         BodySystem = "urinary"
+	BodySystemMood            BodySystem = "mood"
+	BodySystemGeneral         BodySystem = "general"
+)
+
+var BodySystems = []BodySystem{
+	BodySystemDigestive,
+	BodySystemSkin,
+	BodySystemRespiratory,
+	BodySystemNeurological,
+	BodySystemMusculoskeletal,
+	BodySystemCardiovascular,
+	BodySystemUrinary,
+	BodySystemMood,
+	BodySystemGeneral,
+}
+
+// ErrInvalidSeverityLabels is returned when severity labels are not a list
+// of value=label pairs.
+var ErrInvalidSeverityLabels = errors.New("invalid severity labels")
+
+// SymptomType is an entry of the symptom catalog. Symptom.SymptomType holds
+// its name. The severity of a symptom of the type is recorded on the type's
+// scale, from SeverityMin to SeverityMax; SeverityLabels names points of the
+// scale as comma-separated value=label pairs, e.g. "0=none,1=mild,3=severe".
+type SymptomType struct {
+	ID             int64      `gorm:"primaryKey"           json:"id"`
+	Name           string     `gorm:"not null;uniqueIndex" json:"name"`
+	BodySystem     BodySystem `gorm:"not null;index"       json:"body_system"`
+	Description    string     `gorm:"type:text"            json:"description"`
+	SeverityMin    int        `gorm:"not null;default:0"   json:"severity_min"`
+	SeverityMax    int        `gorm:"not null;default:10"  json:"severity_max"`
+	SeverityLabels string     `gorm:"not null;default:''"  json:"severity_labels"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"       json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"       json:"updated_at"`
+}
+
+// SeverityLabel is a named point of a severity scale.
+type SeverityLabel struct {
+	Value int    `json:"value"`
+	Label string `json:"label"`
+}
+
+// Labels parses SeverityLabels, ordered by value.
+func (symptomType *SymptomType) Labels() ([]SeverityLabel, error) {
+	var labels []SeverityLabel
+	for _, pair := range splitList(symptomType.SeverityLabels) {
+		value, label, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not value=label", ErrInvalidSeverityLabels, pair)
+		}
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a number", ErrInvalidSeverityLabels, value)
+		}
+		labels = append(labels, SeverityLabel{Value: parsed, Label: strings.TrimSpace(label)})
+	}
+	sort.SliceStable(labels, func(i, j int) bool { return labels[i].Value < labels[j].Value })
+	return labels, nil
+}
+
+// Label returns the label of the greatest labelled point of the scale at or
+// below severity, or "" if there is none.
+func (symptomType *SymptomType) Label(severity int) string {
+	labels, _ := symptomType.Labels()
+	label := ""
+	for _, point := range labels {
+		if point.Value > severity {
+			break
+		}
+		label = point.Label
+	}
+	return label
+}
+
+// RelativeSeverity places severity on the type's scale, from 0 at
+// SeverityMin to 1 at SeverityMax, so severities of types with different
+// scales can be compared.
+func (symptomType *SymptomType) RelativeSeverity(severity int) float64 {
+	if symptomType.SeverityMax <= symptomType.SeverityMin {
+		return 0
+	}
+	return float64(severity-symptomType.SeverityMin) /
+		float64(symptomType.SeverityMax-symptomType.SeverityMin)
+}
+
+// BodySystemSummary aggregates the symptoms of one body system.
+// MeanSeverity and MaxSeverity are relative severities, between 0 and 1.
+type BodySystemSummary struct {
+	BodySystem   BodySystem `json:"body_system"`
+	SymptomTypes int        `json:"symptom_types"`
+	Symptoms     int        `json:"symptoms"`
+	MeanSeverity float64    `json:"mean_severity"`
+	MaxSeverity  float64    `json:"max_severity"`
+}
+
+// SymptomTypeStore provides database operations for the symptom catalog.
+// Names are unique and looked up ignoring case.
+type SymptomTypeStore interface {
+	CreateSymptomType(ctx context.Context, symptomType *SymptomType) (*SymptomType, error)
+	GetSymptomType(ctx context.Context, id int64) (*SymptomType, error)
+	GetSymptomTypeByName(ctx context.Context, name string) (*SymptomType, error)
+	ListSymptomTypes(ctx context.Context) ([]*SymptomType, error)
+	ListSymptomTypesByBodySystem(ctx context.Context, bodySystem BodySystem) ([]*SymptomType, error)
+	// CountSymptomTypesByBodySystem counts the catalog entries of each body
+	// system that has any.
+	CountSymptomTypesByBodySystem(ctx context.Context) (map[BodySystem]int, error)
+	UpdateSymptomType(ctx context.Context, symptomType *SymptomType) error
+	DeleteSymptomType(ctx context.Context, id int64) error
+}
+
+func ValidateSymptomType(v *validator.Validator, symptomType *SymptomType) {
+	v.Check(strings.TrimSpace(symptomType.Name) != "", "name", "must be provided")
+	v.Check(validator.MaxLength(symptomType.Name, 100), "name", "must not be more than 100 bytes long")
+	v.Check(
+		validator.PermittedValue(symptomType.BodySystem, BodySystems...),
+		"body_system",
+		"must be a known body system",
+	)
+	v.Check(
+		symptomType.SeverityMax > symptomType.SeverityMin,
+		"severity_max",
+		"must be greater than the minimum severity",
+	)
+
+	labels, err := symptomType.Labels()
+	if err != nil {
+		v.AddError("severity_labels", err.Error())
+		return
+	}
+	for i, point := range labels {
+		v.Check(
+			point.Value >= symptomType.SeverityMin && point.Value <= symptomType.SeverityMax,
+			"severity_labels",
+			fmt.Sprintf("must be within the scale, %d is not", point.Value),
+		)
+		v.Check(point.Label != "", "severity_labels", "must not be empty")
+		v.Check(i == 0 || labels[i-1].Value != point.Value, "severity_labels", "must not repeat a value")
+	}
+}
+
+// ValidateSymptom checks symptom against its catalog entry, which is nil if
+// the symptom type is not in the catalog.
+func ValidateSymptom(v *validator.Validator, symptom *Symptom, symptomType *SymptomType) {
+	v.Check(symptom.SymptomType != "", "symptom_type", "must be provided")
+	if symptom.SymptomType == "" {
+		return
+	}
+	v.Check(symptomType != nil, "symptom_type", "must be in the symptom catalog")
+	if symptomType == nil {
+		return
+	}
+	v.Check(
+		symptom.Severity >= symptomType.SeverityMin && symptom.Severity <= symptomType.SeverityMax,
+		"severity",
+		fmt.Sprintf(
+			"must be between %d and %d for %s",
+			symptomType.SeverityMin,
+			symptomType.SeverityMax,
+			symptomType.Name,
+		),
+	)
+}
+
+// CheckSymptom looks up the catalog entry of symptom and validates it with
+// ValidateSymptom.
+func (stores *Stores) CheckSymptom(ctx context.Context, v *validator.Validator, symptom *Symptom) error {
+	var symptomType *SymptomType
+	if symptom.SymptomType != "" {
+		var err error
+		symptomType, err = stores.SymptomTypeStore.GetSymptomTypeByName(ctx, symptom.SymptomType)
+		if err != nil && !errors.Is(err, ErrRecordNotFound) {
+			return err
+		}
+	}
+	ValidateSymptom(v, symptom, symptomType)
+	return nil
+}
+
+// summarizeByBodySystem aggregates symptoms by the body system of their
+// type. Symptoms whose type is not in types are left out.
+func summarizeByBodySystem(symptoms []*Symptom, types []*SymptomType) []*BodySystemSummary {
+	byName := make(map[string]*SymptomType, len(types))
+	for _, symptomType := range types {
+		byName[strings.ToLower(symptomType.Name)] = symptomType
+	}
+
+	summaries := make(map[BodySystem]*BodySystemSummary)
+	seenTypes := make(map[int64]bool)
+	for _, symptom := range symptoms {
+		symptomType, ok := byName[strings.ToLower(symptom.SymptomType)]
+		if !ok {
+			continue
+		}
+		summary, ok := summaries[symptomType.BodySystem]
+		if !ok {
+			summary = &BodySystemSummary{BodySystem: symptomType.BodySystem}
+			summaries[symptomType.BodySystem] = summary
+		}
+		if !seenTypes[symptomType.ID] {
+			seenTypes[symptomType.ID] = true
+			summary.SymptomTypes++
+		}
+		severity := symptomType.RelativeSeverity(symptom.Severity)
+		summary.Symptoms++
+		summary.MeanSeverity += severity
+		summary.MaxSeverity = max(summary.MaxSeverity, severity)
+	}
+
+	result := make([]*BodySystemSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		summary.MeanSeverity /= float64(summary.Symptoms)
+		result = append(result, summary)
+	}
+	sortBodySystemSummaries(result)
+	return result
+}
+
+func sortBodySystemSummaries(summaries []*BodySystemSummary) {
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].BodySystem < summaries[j].BodySystem
+	})
+}
@@ -0,0 +1,163 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// MemorySymptomTypeStore implements SymptomTypeStore interface
+type MemorySymptomTypeStore struct {
+	db *MemoryDB
+}
+
+func NewMemorySymptomTypeStore(db *MemoryDB) *MemorySymptomTypeStore {
+	return &MemorySymptomTypeStore{db: db}
+}
+
+// conflicts reports whether another symptom type has the same name. The
+// caller must hold the lock.
+func (store *MemorySymptomTypeStore) conflicts(symptomType *SymptomType) bool {
+	_, found := store.db.symptomTypes.first(func(row *SymptomType) bool {
+		return row.ID != symptomType.ID && strings.EqualFold(row.Name, symptomType.Name)
+	})
+	return found
+}
+
+func (store *MemorySymptomTypeStore) CreateSymptomType(
+	ctx context.Context,
+	symptomType *SymptomType,
+) (*SymptomType, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if symptomType.ID != 0 {
+		if _, exists := store.db.symptomTypes.get(symptomType.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	}
+	if store.conflicts(symptomType) {
+		return nil, ErrRecordConflict
+	}
+	if symptomType.ID == 0 {
+		symptomType.ID = store.db.symptomTypes.nextID()
+	}
+	setCreateTimestamps(&symptomType.CreatedAt, &symptomType.UpdatedAt)
+	store.db.symptomTypes.put(symptomType.ID, *symptomType)
+
+	return symptomType, nil
+}
+
+func (store *MemorySymptomTypeStore) GetSymptomType(ctx context.Context, id int64) (*SymptomType, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	symptomType, ok := store.db.symptomTypes.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &symptomType, nil
+}
+
+func (store *MemorySymptomTypeStore) GetSymptomTypeByName(
+	ctx context.Context,
+	name string,
+) (*SymptomType, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	symptomType, ok := store.db.symptomTypes.first(func(row *SymptomType) bool {
+		return strings.EqualFold(row.Name, name)
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return symptomType, nil
+}
+
+func (store *MemorySymptomTypeStore) ListSymptomTypes(ctx context.Context) ([]*SymptomType, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	symptomTypes := store.db.symptomTypes.filter(nil)
+	sortSymptomTypesByName(symptomTypes)
+	return symptomTypes, nil
+}
+
+func (store *MemorySymptomTypeStore) ListSymptomTypesByBodySystem(
+	ctx context.Context,
+	bodySystem BodySystem,
+) ([]*SymptomType, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	symptomTypes := store.db.symptomTypes.filter(func(row *SymptomType) bool {
+		return row.BodySystem == bodySystem
+	})
+	sortSymptomTypesByName(symptomTypes)
+	return symptomTypes, nil
+}
+
+func (store *MemorySymptomTypeStore) CountSymptomTypesByBodySystem(
+	ctx context.Context,
+) (map[BodySystem]int, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	counts := make(map[BodySystem]int)
+	for _, symptomType := range store.db.symptomTypes.filter(nil) {
+		counts[symptomType.BodySystem]++
+	}
+	return counts, nil
+}
+
+func (store *MemorySymptomTypeStore) UpdateSymptomType(ctx context.Context, symptomType *SymptomType) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	if store.conflicts(symptomType) {
+		return ErrRecordConflict
+	}
+	existing, ok := store.db.symptomTypes.get(symptomType.ID)
+	if symptomType.ID == 0 || !ok {
+		if symptomType.ID == 0 {
+			symptomType.ID = store.db.symptomTypes.nextID()
+		}
+		setCreateTimestamps(&symptomType.CreatedAt, &symptomType.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&symptomType.CreatedAt, &symptomType.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.symptomTypes.put(symptomType.ID, *symptomType)
+
+	return nil
+}
+
+func (store *MemorySymptomTypeStore) DeleteSymptomType(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.symptomTypes.delete(id)
+	return nil
+}
+
+func sortSymptomTypesByName(symptomTypes []*SymptomType) {
+	sort.SliceStable(symptomTypes, func(i, j int) bool {
+		return symptomTypes[i].Name < symptomTypes[j].Name
+	})
+}
@@ -0,0 +1,147 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresSymptomTypeStore implements SymptomTypeStore interface
+type PostgresSymptomTypeStore struct {
+	DB      *gorm.DB
+	Timeout time.Duration
+}
+
+func NewPostgresSymptomTypeStore(db *gorm.DB) *PostgresSymptomTypeStore {
+	return &PostgresSymptomTypeStore{DB: db}
+}
+
+func (store *PostgresSymptomTypeStore) CreateSymptomType(
+	ctx context.Context,
+	symptomType *SymptomType,
+) (*SymptomType, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(symptomType).Error
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, ErrRecordConflict
+		}
+		return nil, err
+	}
+	return symptomType, nil
+}
+
+func (store *PostgresSymptomTypeStore) GetSymptomType(ctx context.Context, id int64) (*SymptomType, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var symptomType SymptomType
+	err := db.First(&symptomType, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &symptomType, nil
+}
+
+func (store *PostgresSymptomTypeStore) GetSymptomTypeByName(
+	ctx context.Context,
+	name string,
+) (*SymptomType, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var symptomType SymptomType
+	err := db.Where("lower(name) = lower(?)", name).First(&symptomType).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &symptomType, nil
+}
+
+func (store *PostgresSymptomTypeStore) ListSymptomTypes(ctx context.Context) ([]*SymptomType, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var symptomTypes []*SymptomType
+	err := db.Order("name").Find(&symptomTypes).Error
+	if err != nil {
+		return nil, err
+	}
+	return symptomTypes, nil
+}
+
+func (store *PostgresSymptomTypeStore) ListSymptomTypesByBodySystem(
+	ctx context.Context,
+	bodySystem BodySystem,
+) ([]*SymptomType, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var symptomTypes []*SymptomType
+	err := db.Where("body_system = ?", bodySystem).Order("name").Find(&symptomTypes).Error
+	if err != nil {
+		return nil, err
+	}
+	return symptomTypes, nil
+}
+
+func (store *PostgresSymptomTypeStore) CountSymptomTypesByBodySystem(
+	ctx context.Context,
+) (map[BodySystem]int, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	var rows []struct {
+		BodySystem BodySystem
+		Count      int
+	}
+	err := db.Model(&SymptomType{}).
+		Select("body_system, count(*) AS count").
+		Group("body_system").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[BodySystem]int, len(rows))
+	for _, row := range rows {
+		counts[row.BodySystem] = row.Count
+	}
+	return counts, nil
+}
+
+func (store *PostgresSymptomTypeStore) UpdateSymptomType(ctx context.Context, symptomType *SymptomType) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(symptomType).Error
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return ErrRecordConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (store *PostgresSymptomTypeStore) DeleteSymptomType(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&SymptomType{}, id).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
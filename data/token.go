@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base32"
@@ -21,10 +22,11 @@ type Token struct {
 }
 
 type TokenStore interface {
-	CreateToken(userID int64, ttl time.Duration, scope string) (*Token, error)
-	InsertToken(token *Token) error
-	DeleteAllForUser(scope string, userID int64) error
-	GetToken(scope string, plaintext string) (*Token, error)
+	CreateToken(ctx context.Context, userID int64, ttl time.Duration, scope string) (*Token, error)
+	InsertToken(ctx context.Context, token *Token) error
+	DeleteAllForUser(ctx context.Context, scope string, userID int64) error
+	DeleteAllTokensForUser(ctx context.Context, userID int64) (int64, error)
+	GetToken(ctx context.Context, scope string, plaintext string) (*Token, error)
 }
 
 func generateToken(userID int64, ttl time.Duration, scope string) (*Token, error) {
@@ -0,0 +1,120 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"time"
+)
+
+type MemoryTokenStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryTokenStore(db *MemoryDB) *MemoryTokenStore {
+	return &MemoryTokenStore{db: db}
+}
+
+func (store *MemoryTokenStore) CreateToken(
+	ctx context.Context,
+	userID int64,
+	ttl time.Duration,
+	scope string,
+) (*Token, error) {
+	token, err := generateToken(userID, ttl, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.InsertToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (store *MemoryTokenStore) InsertToken(ctx context.Context, token *Token) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	_, duplicate := store.db.tokens.first(func(row *Token) bool {
+		return bytes.Equal(row.Hash, token.Hash)
+	})
+	if duplicate {
+		return ErrRecordConflict
+	}
+
+	if token.ID == 0 {
+		token.ID = uint(store.db.tokens.nextID())
+	}
+	// Plaintext is never persisted, matching the gorm:"-" tag.
+	row := *token
+	row.Plaintext = ""
+	store.db.tokens.put(int64(token.ID), row)
+
+	return nil
+}
+
+func (store *MemoryTokenStore) DeleteAllForUser(
+	ctx context.Context,
+	scope string,
+	userID int64,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.tokens.deleteWhere(func(row *Token) bool {
+		return row.Scope == scope && row.UserID == userID
+	})
+
+	return nil
+}
+
+func (store *MemoryTokenStore) DeleteAllTokensForUser(
+	ctx context.Context,
+	userID int64,
+) (int64, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer store.db.mu.Unlock()
+
+	var deleted int64
+	store.db.tokens.deleteWhere(func(row *Token) bool {
+		if row.UserID == userID {
+			deleted++
+			return true
+		}
+		return false
+	})
+
+	return deleted, nil
+}
+
+func (store *MemoryTokenStore) GetToken(
+	ctx context.Context,
+	scope string,
+	plaintext string,
+) (*Token, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	hash := sha256.Sum256([]byte(plaintext))
+	now := time.Now()
+
+	token, ok := store.db.tokens.first(func(row *Token) bool {
+		return row.Scope == scope && bytes.Equal(row.Hash, hash[:]) && row.Expiry.After(now)
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	return token, nil
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"crypto/sha256"
 	"errors"
 	"time"
@@ -9,17 +10,16 @@ import (
 )
 
 type PostgresTokenStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresTokenStore(db *gorm.DB) *PostgresTokenStore {
-	if err := db.AutoMigrate(&Token{}); err != nil {
-		panic("failed to migrate token schema: " + err.Error())
-	}
 	return &PostgresTokenStore{DB: db}
 }
 
 func (store *PostgresTokenStore) CreateToken(
+	ctx context.Context,
 	userID int64,
 	ttl time.Duration,
 	scope string,
@@ -29,7 +29,7 @@ func (store *PostgresTokenStore) CreateToken(
 		return nil, err
 	}
 
-	err = store.InsertToken(token)
+	err = store.InsertToken(ctx, token)
 	if err != nil {
 		return nil, err
 	}
@@ -37,13 +37,23 @@ func (store *PostgresTokenStore) CreateToken(
 	return token, nil
 }
 
-func (store *PostgresTokenStore) InsertToken(token *Token) error {
-	err := store.DB.Create(token).Error
+func (store *PostgresTokenStore) InsertToken(ctx context.Context, token *Token) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(token).Error
 	return err
 }
 
-func (store *PostgresTokenStore) DeleteAllForUser(scope string, userID int64) error {
-	err := store.DB.
+func (store *PostgresTokenStore) DeleteAllForUser(
+	ctx context.Context,
+	scope string,
+	userID int64,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.
 		Where("scope = ? AND user_id = ?", scope, userID).
 		Delete(&Token{}).
 		Error
@@ -55,12 +65,36 @@ func (store *PostgresTokenStore) DeleteAllForUser(scope string, userID int64) er
 	return err
 }
 
-func (store *PostgresTokenStore) GetToken(scope string, plaintext string) (*Token, error) {
+// DeleteAllTokensForUser removes the user's tokens in every scope and
+// reports how many were removed.
+func (store *PostgresTokenStore) DeleteAllTokensForUser(
+	ctx context.Context,
+	userID int64,
+) (int64, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	result := db.Where("user_id = ?", userID).Delete(&Token{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (store *PostgresTokenStore) GetToken(
+	ctx context.Context,
+	scope string,
+	plaintext string,
+) (*Token, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	hash := sha256.Sum256([]byte(plaintext))
 
 	var token Token
 
-	err := store.DB.
+	err := db.
 		Where("scope = ? AND hash = ? AND expiry > ?", scope, hash[:], time.Now()).
 		First(&token).
 		Error
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"time"
 
 	"github.com/Universal-Selfcare/utils/validator"
@@ -35,15 +36,15 @@ type User struct {
 }
 
 type UserStore interface {
-	CreateUser(user *User) (*User, error)
-	GetUser(id int64) (*User, error)
-	GetByEmail(email string) (*User, error)
-	GetByPhoneNumber(phoneNumber string) (*User, error)
-	GetByUserName(userName string) (*User, error)
-	GetByToken(token string, scope string) (*User, error)
-	UpdateUser(user *User) error
-	DeleteUser(id int64) error
-	ListUsers() ([]*User, error)
+	CreateUser(ctx context.Context, user *User) (*User, error)
+	GetUser(ctx context.Context, id int64) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	GetByPhoneNumber(ctx context.Context, phoneNumber string) (*User, error)
+	GetByUserName(ctx context.Context, userName string) (*User, error)
+	GetByToken(ctx context.Context, scope string, plaintext string) (*User, error)
+	UpdateUser(ctx context.Context, user *User) error
+	DeleteUser(ctx context.Context, id int64) error
+	ListUsers(ctx context.Context) ([]*User, error)
 }
 
 func (user *User) IsAnonymous() bool {
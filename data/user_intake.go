@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"time"
 )
 
@@ -13,7 +14,8 @@ type UserIntake struct {
 }
 
 type UserIntakeStore interface {
-	CreateUserIntake(userIntake *UserIntake) (*UserIntake, error)
-	GetUserIntakeByUserID(userID int64) (*UserIntake, error)
-	UpdateUserIntake(userIntake *UserIntake) error
+	CreateUserIntake(ctx context.Context, userIntake *UserIntake) (*UserIntake, error)
+	GetUserIntakeByUserID(ctx context.Context, userID int64) (*UserIntake, error)
+	UpdateUserIntake(ctx context.Context, userIntake *UserIntake) error
+	DeleteUserIntake(ctx context.Context, id int64) error
 }
@@ -0,0 +1,84 @@
+package data
+
+import "context"
+
+type MemoryUserIntakeStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryUserIntakeStore(db *MemoryDB) *MemoryUserIntakeStore {
+	return &MemoryUserIntakeStore{db: db}
+}
+
+func (store *MemoryUserIntakeStore) CreateUserIntake(
+	ctx context.Context,
+	userIntake *UserIntake,
+) (*UserIntake, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if userIntake.ID != 0 {
+		if _, exists := store.db.userIntakes.get(userIntake.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	} else {
+		userIntake.ID = store.db.userIntakes.nextID()
+	}
+	setCreateTimestamps(&userIntake.CreatedAt, &userIntake.UpdatedAt)
+	store.db.userIntakes.put(userIntake.ID, *userIntake)
+
+	return userIntake, nil
+}
+
+func (store *MemoryUserIntakeStore) GetUserIntakeByUserID(
+	ctx context.Context,
+	userID int64,
+) (*UserIntake, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	userIntake, ok := store.db.userIntakes.first(func(row *UserIntake) bool {
+		return row.UserID == userID
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return userIntake, nil
+}
+
+func (store *MemoryUserIntakeStore) UpdateUserIntake(
+	ctx context.Context,
+	userIntake *UserIntake,
+) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	existing, ok := store.db.userIntakes.get(userIntake.ID)
+	if userIntake.ID == 0 || !ok {
+		if userIntake.ID == 0 {
+			userIntake.ID = store.db.userIntakes.nextID()
+		}
+		setCreateTimestamps(&userIntake.CreatedAt, &userIntake.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&userIntake.CreatedAt, &userIntake.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.userIntakes.put(userIntake.ID, *userIntake)
+
+	return nil
+}
+
+func (store *MemoryUserIntakeStore) DeleteUserIntake(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.userIntakes.delete(id)
+	return nil
+}
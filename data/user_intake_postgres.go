@@ -1,35 +1,45 @@
 package data
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type PostgresUserIntakeStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresUserIntakeStore(db *gorm.DB) *PostgresUserIntakeStore {
-	if err := db.AutoMigrate(&UserIntake{}); err != nil {
-		panic("failed to migrate user intake schema: " + err.Error())
-	}
 	return &PostgresUserIntakeStore{DB: db}
 }
 
 func (store *PostgresUserIntakeStore) CreateUserIntake(
+	ctx context.Context,
 	userIntake *UserIntake,
 ) (*UserIntake, error) {
-	err := store.DB.Create(userIntake).Error
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(userIntake).Error
 	if err != nil {
 		return nil, err
 	}
 	return userIntake, nil
 }
 
-func (store *PostgresUserIntakeStore) GetUserIntakeByUserID(userID int64) (*UserIntake, error) {
+func (store *PostgresUserIntakeStore) GetUserIntakeByUserID(
+	ctx context.Context,
+	userID int64,
+) (*UserIntake, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var userIntake UserIntake
-	err := store.DB.Where("user_id = ?", userID).First(&userIntake).Error
+	err := db.Where("user_id = ?", userID).First(&userIntake).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrRecordNotFound
 	}
@@ -39,8 +49,25 @@ func (store *PostgresUserIntakeStore) GetUserIntakeByUserID(userID int64) (*User
 	return &userIntake, nil
 }
 
-func (store *PostgresUserIntakeStore) UpdateUserIntake(userIntake *UserIntake) error {
-	err := store.DB.Save(userIntake).Error
+func (store *PostgresUserIntakeStore) UpdateUserIntake(
+	ctx context.Context,
+	userIntake *UserIntake,
+) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Save(userIntake).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (store *PostgresUserIntakeStore) DeleteUserIntake(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Delete(&UserIntake{}, id).Error
 	if err != nil {
 		return err
 	}
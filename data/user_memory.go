@@ -0,0 +1,181 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"time"
+)
+
+type MemoryUserStore struct {
+	db *MemoryDB
+}
+
+func NewMemoryUserStore(db *MemoryDB) *MemoryUserStore {
+	return &MemoryUserStore{db: db}
+}
+
+// storedUser strips the associations, which the Postgres store never preloads.
+func storedUser(user *User) User {
+	row := *user
+	row.UserIntake = UserIntake{}
+	row.MedicalInformation = MedicalInformation{}
+	row.Caregivers = nil
+	row.EmergencyContacts = nil
+	row.MedicalEvents = nil
+	row.FrequentFoods = nil
+	row.Allergies = nil
+	row.Medications = nil
+	row.DietarySupplements = nil
+	return row
+}
+
+// conflicts reports whether another user already holds one of the unique
+// user_name, email or phone_number values.
+func (store *MemoryUserStore) conflicts(user *User) bool {
+	_, found := store.db.users.first(func(row *User) bool {
+		return row.ID != user.ID &&
+			(row.UserName == user.UserName ||
+				row.Email == user.Email ||
+				row.PhoneNumber == user.PhoneNumber)
+	})
+	return found
+}
+
+func (store *MemoryUserStore) CreateUser(ctx context.Context, user *User) (*User, error) {
+	if err := store.db.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.Unlock()
+
+	if user.ID != 0 {
+		if _, exists := store.db.users.get(user.ID); exists {
+			return nil, ErrRecordConflict
+		}
+	}
+	if store.conflicts(user) {
+		return nil, ErrRecordConflict
+	}
+
+	if user.ID == 0 {
+		user.ID = store.db.users.nextID()
+	}
+	setCreateTimestamps(&user.CreatedAt, &user.UpdatedAt)
+	store.db.users.put(user.ID, storedUser(user))
+
+	return user, nil
+}
+
+func (store *MemoryUserStore) GetUser(ctx context.Context, id int64) (*User, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	user, ok := store.db.users.get(id)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+func (store *MemoryUserStore) GetByUserName(ctx context.Context, userName string) (*User, error) {
+	return store.getBy(ctx, func(row *User) bool { return row.UserName == userName })
+}
+
+func (store *MemoryUserStore) GetByEmail(ctx context.Context, email string) (*User, error) {
+	return store.getBy(ctx, func(row *User) bool { return row.Email == email })
+}
+
+func (store *MemoryUserStore) GetByPhoneNumber(
+	ctx context.Context,
+	phoneNumber string,
+) (*User, error) {
+	return store.getBy(ctx, func(row *User) bool { return row.PhoneNumber == phoneNumber })
+}
+
+func (store *MemoryUserStore) getBy(
+	ctx context.Context,
+	match func(row *User) bool,
+) (*User, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	user, ok := store.db.users.first(match)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (store *MemoryUserStore) UpdateUser(ctx context.Context, user *User) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	if store.conflicts(user) {
+		return ErrRecordConflict
+	}
+
+	existing, ok := store.db.users.get(user.ID)
+	if user.ID == 0 || !ok {
+		if user.ID == 0 {
+			user.ID = store.db.users.nextID()
+		}
+		setCreateTimestamps(&user.CreatedAt, &user.UpdatedAt)
+	} else {
+		setUpdateTimestamps(&user.CreatedAt, &user.UpdatedAt, existing.CreatedAt)
+	}
+	store.db.users.put(user.ID, storedUser(user))
+
+	return nil
+}
+
+func (store *MemoryUserStore) DeleteUser(ctx context.Context, id int64) error {
+	if err := store.db.lock(ctx); err != nil {
+		return err
+	}
+	defer store.db.mu.Unlock()
+
+	store.db.users.delete(id)
+	return nil
+}
+
+func (store *MemoryUserStore) ListUsers(ctx context.Context) ([]*User, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	return store.db.users.filter(nil), nil
+}
+
+func (store *MemoryUserStore) GetByToken(
+	ctx context.Context,
+	scope string,
+	plaintext string,
+) (*User, error) {
+	if err := store.db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer store.db.mu.RUnlock()
+
+	hash := sha256.Sum256([]byte(plaintext))
+	now := time.Now()
+	token, ok := store.db.tokens.first(func(row *Token) bool {
+		return row.Scope == scope && bytes.Equal(row.Hash, hash[:]) && row.Expiry.After(now)
+	})
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	user, ok := store.db.users.get(token.UserID)
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	return &user, nil
+}
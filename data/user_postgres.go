@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"crypto/sha256"
 	"errors"
 	"strings"
@@ -10,18 +11,19 @@ import (
 )
 
 type PostgresUserStore struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Timeout time.Duration
 }
 
 func NewPostgresUserStore(db *gorm.DB) *PostgresUserStore {
-	if err := db.AutoMigrate(&User{}); err != nil {
-		panic("failed to migrate user schema: " + err.Error())
-	}
 	return &PostgresUserStore{DB: db}
 }
 
-func (store *PostgresUserStore) CreateUser(user *User) (*User, error) {
-	err := store.DB.Create(user).Error
+func (store *PostgresUserStore) CreateUser(ctx context.Context, user *User) (*User, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	err := db.Create(user).Error
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key") {
 			return nil, ErrRecordConflict
@@ -32,9 +34,12 @@ func (store *PostgresUserStore) CreateUser(user *User) (*User, error) {
 	return user, nil
 }
 
-func (store *PostgresUserStore) GetUser(id int64) (*User, error) {
+func (store *PostgresUserStore) GetUser(ctx context.Context, id int64) (*User, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var user User
-	if err := store.DB.First(&user, id).Error; err != nil {
+	if err := db.First(&user, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRecordNotFound
 		}
@@ -43,9 +48,12 @@ func (store *PostgresUserStore) GetUser(id int64) (*User, error) {
 	return &user, nil
 }
 
-func (store *PostgresUserStore) GetByUserName(userName string) (*User, error) {
+func (store *PostgresUserStore) GetByUserName(ctx context.Context, userName string) (*User, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var user User
-	if err := store.DB.Where("user_name = ?", userName).First(&user).Error; err != nil {
+	if err := db.Where("user_name = ?", userName).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRecordNotFound
 		}
@@ -54,9 +62,12 @@ func (store *PostgresUserStore) GetByUserName(userName string) (*User, error) {
 	return &user, nil
 }
 
-func (store *PostgresUserStore) GetByEmail(email string) (*User, error) {
+func (store *PostgresUserStore) GetByEmail(ctx context.Context, email string) (*User, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var user User
-	if err := store.DB.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRecordNotFound
 		}
@@ -65,9 +76,15 @@ func (store *PostgresUserStore) GetByEmail(email string) (*User, error) {
 	return &user, nil
 }
 
-func (store *PostgresUserStore) GetByPhoneNumber(phoneNumber string) (*User, error) {
+func (store *PostgresUserStore) GetByPhoneNumber(
+	ctx context.Context,
+	phoneNumber string,
+) (*User, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var user User
-	if err := store.DB.Where("phone_number = ?", phoneNumber).First(&user).Error; err != nil {
+	if err := db.Where("phone_number = ?", phoneNumber).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRecordNotFound
 		}
@@ -76,32 +93,51 @@ func (store *PostgresUserStore) GetByPhoneNumber(phoneNumber string) (*User, err
 	return &user, nil
 }
 
-func (store *PostgresUserStore) UpdateUser(user *User) error {
-	if err := store.DB.Save(user).Error; err != nil {
+func (store *PostgresUserStore) UpdateUser(ctx context.Context, user *User) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	if err := db.Save(user).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return ErrRecordConflict
+		}
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresUserStore) DeleteUser(id int64) error {
-	if err := store.DB.Delete(&User{}, id).Error; err != nil {
+func (store *PostgresUserStore) DeleteUser(ctx context.Context, id int64) error {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
+	if err := db.Delete(&User{}, id).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *PostgresUserStore) ListUsers() ([]*User, error) {
+func (store *PostgresUserStore) ListUsers(ctx context.Context) ([]*User, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var users []*User
-	if err := store.DB.Find(&users).Error; err != nil {
+	if err := db.Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
-func (store *PostgresUserStore) GetByToken(scope string, plaintext string) (*User, error) {
+func (store *PostgresUserStore) GetByToken(
+	ctx context.Context,
+	scope string,
+	plaintext string,
+) (*User, error) {
+	db, cancel := withTimeout(ctx, store.DB, store.Timeout)
+	defer cancel()
+
 	var token Token
 	hash := sha256.Sum256([]byte(plaintext))
-	err := store.DB.Where("scope = ? AND hash = ? AND expiry > ?", scope, hash[:], time.Now()).
+	err := db.Where("scope = ? AND hash = ? AND expiry > ?", scope, hash[:], time.Now()).
 		First(&token).
 		Error
 	if err != nil {
@@ -112,7 +148,7 @@ func (store *PostgresUserStore) GetByToken(scope string, plaintext string) (*Use
 	}
 
 	var user User
-	if err := store.DB.First(&user, token.UserID).Error; err != nil {
+	if err := db.First(&user, token.UserID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRecordNotFound
 		}
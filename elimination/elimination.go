@@ -0,0 +1,281 @@
+// Package elimination checks logged meals against a user's elimination diet
+// plan and reports the foods eaten in violation of it.
+package elimination
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+const (
+	PhaseElimination    = "elimination"
+	PhaseReintroduction = "reintroduction"
+)
+
+// Plan is an elimination plan together with its restrictions and
+// reintroduction schedule.
+type Plan struct {
+	*data.EliminationPlan
+	Restrictions []*data.EliminationRestriction
+	Steps        []*data.ReintroductionStep
+}
+
+// LoadPlan loads the plan with the given ID along with its restrictions and
+// reintroduction steps.
+func LoadPlan(ctx context.Context, stores *data.Stores, planID int64) (*Plan, error) {
+	plan, err := stores.EliminationPlanStore.GetEliminationPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	return loadPlanDetails(ctx, stores, plan)
+}
+
+// LoadActivePlan loads the plan the user is following at the given time. It
+// returns data.ErrRecordNotFound if there is none.
+func LoadActivePlan(
+	ctx context.Context,
+	stores *data.Stores,
+	userID int64,
+	at time.Time,
+) (*Plan, error) {
+	plan, err := stores.EliminationPlanStore.GetActiveEliminationPlan(ctx, userID, at)
+	if err != nil {
+		return nil, err
+	}
+	return loadPlanDetails(ctx, stores, plan)
+}
+
+func loadPlanDetails(
+	ctx context.Context,
+	stores *data.Stores,
+	plan *data.EliminationPlan,
+) (*Plan, error) {
+	restrictions, err := stores.EliminationRestrictionStore.ListEliminationRestrictions(ctx, plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := stores.ReintroductionStepStore.ListReintroductionSteps(ctx, plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Plan{EliminationPlan: plan, Restrictions: restrictions, Steps: steps}, nil
+}
+
+// Phase reports which phase of the plan is running at the given time, or ""
+// if the plan is not active then.
+func (plan *Plan) Phase(at time.Time) string {
+	switch {
+	case !plan.IsActive(at):
+		return ""
+	case at.Before(plan.ReintroductionDate):
+		return PhaseElimination
+	default:
+		return PhaseReintroduction
+	}
+}
+
+// Restriction returns the restriction that forbids eating item at the given
+// time, or nil if item is allowed. A food is allowed again once a
+// reintroduction step for it, or for its category, lets it back in.
+func (plan *Plan) Restriction(item *data.FoodItem, at time.Time) *data.EliminationRestriction {
+	if !plan.IsActive(at) {
+		return nil
+	}
+
+	for _, restriction := range plan.Restrictions {
+		if !restrictionMatches(restriction, item) {
+			continue
+		}
+		if plan.reintroduced(item, at) {
+			return nil
+		}
+		return restriction
+	}
+	return nil
+}
+
+func restrictionMatches(restriction *data.EliminationRestriction, item *data.FoodItem) bool {
+	if restriction.FoodItemID != 0 {
+		return restriction.FoodItemID == item.ID
+	}
+	return strings.EqualFold(restriction.Category, item.Category)
+}
+
+func (plan *Plan) reintroduced(item *data.FoodItem, at time.Time) bool {
+	for _, step := range plan.Steps {
+		matches := step.FoodItemID == item.ID ||
+			(step.FoodItemID == 0 && strings.EqualFold(step.Category, item.Category))
+		if matches && step.Allows(at) {
+			return true
+		}
+	}
+	return false
+}
+
+// Violation is a food eaten while the plan forbade it. Exactly one of
+// MealFoodID and CustomFoodID is set.
+type Violation struct {
+	MealEntryID  int64                        `json:"meal_entry_id"`
+	MealFoodID   int64                        `json:"meal_food_id,omitempty"`
+	CustomFoodID int64                        `json:"custom_food_id,omitempty"`
+	FoodName     string                       `json:"food_name"`
+	EatenOn      time.Time                    `json:"eaten_on"`
+	Phase        string                       `json:"phase"`
+	PlanID       int64                        `json:"plan_id"`
+	Restriction  *data.EliminationRestriction `json:"restriction"`
+}
+
+// Checker finds MealFood and CustomFood entries that break the elimination
+// plan active on the day they were eaten.
+type Checker struct {
+	Stores *data.Stores
+}
+
+func NewChecker(stores *data.Stores) *Checker {
+	return &Checker{Stores: stores}
+}
+
+// MealDate returns when a meal entry was eaten: its MealTime, or for an
+// entry without one the start of its tracking day.
+func MealDate(period *data.TrackingPeriod, entry *data.MealEntry) time.Time {
+	if entry.MealTime.IsZero() {
+		return period.Date(entry.TrackingDay)
+	}
+	return entry.MealTime
+}
+
+// CheckTrackingPeriod checks every meal entry of a tracking period.
+func (checker *Checker) CheckTrackingPeriod(
+	ctx context.Context,
+	period *data.TrackingPeriod,
+) ([]Violation, error) {
+	entries, err := checker.Stores.MealEntryStore.ListUserMealEntries(ctx, period.UserID, period.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []Violation
+	for _, entry := range entries {
+		found, err := checker.checkMealEntry(ctx, period, entry)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, found...)
+	}
+	return violations, nil
+}
+
+// CheckMealEntry checks the foods of a single meal entry.
+func (checker *Checker) CheckMealEntry(
+	ctx context.Context,
+	entry *data.MealEntry,
+) ([]Violation, error) {
+	period, err := checker.Stores.TrackingPeriodStore.GetTrackingPeriod(ctx, entry.TrackingPeriodID)
+	if err != nil {
+		return nil, err
+	}
+	return checker.checkMealEntry(ctx, period, entry)
+}
+
+func (checker *Checker) checkMealEntry(
+	ctx context.Context,
+	period *data.TrackingPeriod,
+	entry *data.MealEntry,
+) ([]Violation, error) {
+	eatenOn := MealDate(period, entry)
+
+	plan, err := LoadActivePlan(ctx, checker.Stores, entry.UserID, eatenOn)
+	if errors.Is(err, data.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	violation := Violation{
+		MealEntryID: entry.ID,
+		EatenOn:     eatenOn,
+		Phase:       plan.Phase(eatenOn),
+		PlanID:      plan.ID,
+	}
+	var violations []Violation
+
+	mealFoods, err := checker.Stores.MealFoodStore.GetMealFoodsForMeal(ctx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, mealFood := range mealFoods {
+		item, err := checker.Stores.FoodItemStore.GetFoodItem(ctx, mealFood.FoodItemID)
+		if errors.Is(err, data.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if restriction := plan.Restriction(item, eatenOn); restriction != nil {
+			found := violation
+			found.MealFoodID, found.FoodName, found.Restriction = mealFood.ID, item.Name, restriction
+			violations = append(violations, found)
+		}
+	}
+
+	customFoods, err := checker.Stores.CustomFoodStore.GetCustomFoodsForMeal(ctx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, customFood := range customFoods {
+		restriction, err := checker.customFoodRestriction(ctx, plan, customFood, eatenOn)
+		if err != nil {
+			return nil, err
+		}
+		if restriction != nil {
+			found := violation
+			found.CustomFoodID, found.FoodName, found.Restriction = customFood.ID, customFood.Name, restriction
+			violations = append(violations, found)
+		}
+	}
+
+	return violations, nil
+}
+
+// customFoodRestriction matches a custom food against the plan. Custom foods
+// have no category, so a food whose name is exactly that of a catalog item
+// is checked as that item; otherwise it only violates restrictions on food
+// items whose name it contains, e.g. "milk tea" and a restriction on "Milk".
+func (checker *Checker) customFoodRestriction(
+	ctx context.Context,
+	plan *Plan,
+	customFood *data.CustomFood,
+	at time.Time,
+) (*data.EliminationRestriction, error) {
+	item, err := checker.Stores.FoodItemStore.GetFoodItemByName(ctx, customFood.Name)
+	if err == nil {
+		return plan.Restriction(item, at), nil
+	}
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	name := strings.ToLower(customFood.Name)
+	for _, restriction := range plan.Restrictions {
+		if restriction.FoodItemID == 0 {
+			continue
+		}
+		item, err := checker.Stores.FoodItemStore.GetFoodItem(ctx, restriction.FoodItemID)
+		if errors.Is(err, data.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(name, strings.ToLower(item.Name)) && !plan.reintroduced(item, at) {
+			return restriction, nil
+		}
+	}
+
+	return nil, nil
+}
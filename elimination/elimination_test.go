@@ -0,0 +1,281 @@
+package elimination
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// fixture is a user with a three-day tracking period starting on
+// 2025-03-01 and a catalog of foods, on top of memory stores.
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	stores *data.Stores
+	user   *data.User
+	period *data.TrackingPeriod
+	items  map[string]*data.FoodItem
+}
+
+var fixtureStart = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	f := &fixture{
+		t:      t,
+		ctx:    context.Background(),
+		stores: data.NewMemoryStores(),
+		items:  make(map[string]*data.FoodItem),
+	}
+	var err error
+	f.user, err = f.stores.UserStore.CreateUser(f.ctx, &data.User{
+		UserName:    "user",
+		Email:       "user@example.com",
+		PhoneNumber: "15550100",
+	})
+	f.must("CreateUser", err)
+	f.period, err = f.stores.TrackingPeriodStore.CreateTrackingPeriod(
+		f.ctx,
+		data.NewTrackingPeriod(f.user.ID, fixtureStart, 3),
+	)
+	f.must("CreateTrackingPeriod", err)
+
+	for name, category := range map[string]string{
+		"Milk":   "Dairy",
+		"Cheese": "Dairy",
+		"Bread":  "Grains",
+		"Apple":  "Fruit",
+	} {
+		f.items[name], err = f.stores.FoodItemStore.CreateFoodItem(f.ctx, &data.FoodItem{
+			Name:     name,
+			Category: category,
+		})
+		f.must("CreateFoodItem", err)
+	}
+	return f
+}
+
+func (f *fixture) must(what string, err error) {
+	f.t.Helper()
+
+	if err != nil {
+		f.t.Fatalf("%s: %v", what, err)
+	}
+}
+
+// plan creates a plan whose elimination phase runs from start for a week,
+// followed by a week of reintroduction.
+func (f *fixture) plan(start time.Time, restrictions ...*data.EliminationRestriction) *data.EliminationPlan {
+	f.t.Helper()
+
+	plan, err := f.stores.EliminationPlanStore.CreateEliminationPlan(f.ctx, &data.EliminationPlan{
+		UserID:             f.user.ID,
+		Name:               "Test plan",
+		StartDate:          start,
+		ReintroductionDate: start.AddDate(0, 0, 7),
+		EndDate:            start.AddDate(0, 0, 14),
+	})
+	f.must("CreateEliminationPlan", err)
+	for _, restriction := range restrictions {
+		restriction.PlanID = plan.ID
+		_, err := f.stores.EliminationRestrictionStore.CreateEliminationRestriction(f.ctx, restriction)
+		f.must("CreateEliminationRestriction", err)
+	}
+	return plan
+}
+
+// meal logs a meal at the given time with catalog foods by name and custom
+// foods.
+func (f *fixture) meal(mealType data.MealType, at time.Time, foods []string, custom ...string) *data.MealEntry {
+	f.t.Helper()
+
+	day, err := f.period.TrackingDayFor(at)
+	f.must("TrackingDayFor", err)
+	entry, err := f.stores.MealEntryStore.CreateMealEntry(f.ctx, &data.MealEntry{
+		UserID:           f.user.ID,
+		TrackingPeriodID: f.period.ID,
+		TrackingDay:      day,
+		MealType:         mealType,
+		MealTime:         at,
+		MealDuration:     30 * time.Minute,
+		PortionQuantity:  1,
+		PortionUnit:      data.PortionServing,
+	})
+	f.must("CreateMealEntry", err)
+	for _, name := range foods {
+		_, err := f.stores.MealFoodStore.CreateMealFood(f.ctx, &data.MealFood{
+			MealEntryID: entry.ID,
+			FoodItemID:  f.items[name].ID,
+		})
+		f.must("CreateMealFood", err)
+	}
+	for _, name := range custom {
+		_, err := f.stores.CustomFoodStore.CreateCustomFood(f.ctx, &data.CustomFood{
+			MealEntryID: entry.ID,
+			Name:        name,
+			Portion:     "1",
+			Preparation: "Raw",
+		})
+		f.must("CreateCustomFood", err)
+	}
+	return entry
+}
+
+func TestCheckerUsesMealTime(t *testing.T) {
+	f := newFixture(t)
+	f.plan(fixtureStart.Add(12*time.Hour), &data.EliminationRestriction{Category: "Dairy"})
+
+	breakfast := f.meal(data.MealBreakfast, fixtureStart.Add(8*time.Hour), []string{"Milk"})
+	dinner := f.meal(data.MealDinner, fixtureStart.Add(19*time.Hour), []string{"Milk"})
+
+	checker := NewChecker(f.stores)
+	violations, err := checker.CheckMealEntry(f.ctx, breakfast)
+	f.must("CheckMealEntry", err)
+	if len(violations) != 0 {
+		t.Fatalf("breakfast before the plan started has violations %+v", violations)
+	}
+
+	violations, err = checker.CheckMealEntry(f.ctx, dinner)
+	f.must("CheckMealEntry", err)
+	if len(violations) != 1 || !violations[0].EatenOn.Equal(dinner.MealTime) {
+		t.Fatalf("dinner after the plan started has violations %+v, want one eaten at %v",
+			violations, dinner.MealTime)
+	}
+}
+
+func TestPlanRestriction(t *testing.T) {
+	start := fixtureStart
+	milk := &data.FoodItem{ID: 1, Name: "Milk", Category: "Dairy"}
+	cheese := &data.FoodItem{ID: 2, Name: "Cheese", Category: "Dairy"}
+	bread := &data.FoodItem{ID: 3, Name: "Bread", Category: "Grains"}
+	apple := &data.FoodItem{ID: 4, Name: "Apple", Category: "Fruit"}
+
+	plan := &Plan{
+		EliminationPlan: &data.EliminationPlan{
+			StartDate:          start,
+			ReintroductionDate: start.AddDate(0, 0, 7),
+			EndDate:            start.AddDate(0, 0, 14),
+		},
+		Restrictions: []*data.EliminationRestriction{
+			{ID: 1, Category: "dairy"},
+			{ID: 2, FoodItemID: bread.ID},
+		},
+		Steps: []*data.ReintroductionStep{
+			{
+				FoodItemID: cheese.ID,
+				StartDate:  start.AddDate(0, 0, 7),
+				EndDate:    start.AddDate(0, 0, 9),
+				Outcome:    data.ReintroductionReacted,
+			},
+			{
+				Category:  "Grains",
+				StartDate: start.AddDate(0, 0, 10),
+				EndDate:   start.AddDate(0, 0, 12),
+				Outcome:   data.ReintroductionTolerated,
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		item      *data.FoodItem
+		day       int
+		want      int64
+		wantPhase string
+	}{
+		{name: "before the plan", item: milk, day: -1},
+		{name: "category matched ignoring case", item: milk, day: 0, want: 1, wantPhase: PhaseElimination},
+		{name: "food item restriction", item: bread, day: 6, want: 2, wantPhase: PhaseElimination},
+		{name: "unrestricted food", item: apple, day: 3, wantPhase: PhaseElimination},
+		{name: "other food during a challenge", item: milk, day: 8, want: 1, wantPhase: PhaseReintroduction},
+		{name: "food during its challenge", item: cheese, day: 8, wantPhase: PhaseReintroduction},
+		{name: "food reacted to after its challenge", item: cheese, day: 9, want: 1, wantPhase: PhaseReintroduction},
+		{name: "before a category challenge", item: bread, day: 9, want: 2, wantPhase: PhaseReintroduction},
+		{name: "after a tolerated category challenge", item: bread, day: 13, wantPhase: PhaseReintroduction},
+		{name: "after the plan", item: milk, day: 14},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			at := start.AddDate(0, 0, test.day).Add(12 * time.Hour)
+
+			var got int64
+			if restriction := plan.Restriction(test.item, at); restriction != nil {
+				got = restriction.ID
+			}
+			if got != test.want {
+				t.Errorf("Restriction(%s) = %d, want %d", test.item.Name, got, test.want)
+			}
+			if phase := plan.Phase(at); phase != test.wantPhase {
+				t.Errorf("Phase = %q, want %q", phase, test.wantPhase)
+			}
+		})
+	}
+}
+
+func TestCheckerCustomFoods(t *testing.T) {
+	f := newFixture(t)
+	f.plan(fixtureStart,
+		&data.EliminationRestriction{FoodItemID: f.items["Milk"].ID},
+		&data.EliminationRestriction{Category: "Grains"},
+	)
+
+	// Each case logs a different meal of the first day, as a meal type can
+	// only be logged once a day.
+	tests := []struct {
+		name     string
+		mealType data.MealType
+		custom   string
+		want     bool
+	}{
+		{name: "catalog name checked as the item", mealType: data.MealBreakfast, custom: "Bread", want: true},
+		{name: "name containing a restricted item", mealType: data.MealLunch, custom: "Oat Milk latte", want: true},
+		{name: "category restriction not guessed", mealType: data.MealDinner, custom: "Rye crackers"},
+		{name: "unrestricted food", mealType: data.MealSnack, custom: "Apple pie"},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			at := fixtureStart.Add(time.Duration(8+i) * time.Hour)
+			entry := f.meal(test.mealType, at, nil, test.custom)
+
+			violations, err := NewChecker(f.stores).CheckMealEntry(f.ctx, entry)
+			f.must("CheckMealEntry", err)
+			if got := len(violations) == 1; got != test.want || len(violations) > 1 {
+				t.Fatalf("%q has violations %+v, want violation = %v", test.custom, violations, test.want)
+			}
+			if test.want && (violations[0].CustomFoodID == 0 || violations[0].FoodName != test.custom) {
+				t.Fatalf("violation %+v does not name custom food %q", violations[0], test.custom)
+			}
+		})
+	}
+}
+
+func TestCheckTrackingPeriod(t *testing.T) {
+	f := newFixture(t)
+
+	f.meal(data.MealBreakfast, fixtureStart.Add(8*time.Hour), []string{"Milk", "Bread"})
+	checker := NewChecker(f.stores)
+	violations, err := checker.CheckTrackingPeriod(f.ctx, f.period)
+	f.must("CheckTrackingPeriod", err)
+	if len(violations) != 0 {
+		t.Fatalf("meals without a plan have violations %+v", violations)
+	}
+
+	plan := f.plan(fixtureStart.AddDate(0, 0, 1), &data.EliminationRestriction{Category: "Dairy"})
+	f.meal(data.MealLunch, fixtureStart.AddDate(0, 0, 1).Add(12*time.Hour), []string{"Cheese", "Apple"})
+	f.meal(data.MealDinner, fixtureStart.AddDate(0, 0, 2).Add(19*time.Hour), []string{"Milk", "Bread"})
+
+	violations, err = checker.CheckTrackingPeriod(f.ctx, f.period)
+	f.must("CheckTrackingPeriod", err)
+	if len(violations) != 2 || violations[0].FoodName != "Cheese" || violations[1].FoodName != "Milk" {
+		t.Fatalf("got violations %+v, want cheese at lunch and milk at dinner", violations)
+	}
+	for _, violation := range violations {
+		if violation.PlanID != plan.ID || violation.Phase != PhaseElimination || violation.MealFoodID == 0 {
+			t.Fatalf("violation %+v is not a meal food eaten during the elimination phase of plan %d",
+				violation, plan.ID)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+// Package interaction detects interactions between a user's medications and
+// the supplements they take or the foods they log, following rules read
+// from a local rules file.
+package interaction
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Universal-Selfcare/utils/data"
+	"github.com/Universal-Selfcare/utils/search"
+	"github.com/Universal-Selfcare/utils/tracking"
+)
+
+// Kind tells what a medication interacts with in a finding.
+type Kind string
+
+const (
+	KindDrugSupplement Kind = "drug-supplement"
+	KindDrugFood       Kind = "drug-food"
+)
+
+// Finding is an interaction found between one of the user's medications and
+// a supplement or a food logged in a meal. For food findings exactly one of
+// MealFoodID and CustomFoodID is set.
+type Finding struct {
+	RuleID       string   `json:"rule_id"`
+	Kind         Kind     `json:"kind"`
+	Severity     Severity `json:"severity"`
+	MedicationID int64    `json:"medication_id"`
+	Medication   string   `json:"medication"`
+	SupplementID int64    `json:"supplement_id,omitempty"`
+	Supplement   string   `json:"supplement,omitempty"`
+	MealEntryID  int64    `json:"meal_entry_id,omitempty"`
+	MealFoodID   int64    `json:"meal_food_id,omitempty"`
+	CustomFoodID int64    `json:"custom_food_id,omitempty"`
+	Food         string   `json:"food,omitempty"`
+	Separation   Duration `json:"separation,omitempty"`
+	Advice       string   `json:"advice"`
+}
+
+// Engine checks a user's records against a set of rules.
+type Engine struct {
+	Stores *data.Stores
+	Rules  *RuleSet
+
+	// Lifecycle finds the user's current tracking period, closing it if it
+	// has expired.
+	Lifecycle *tracking.Lifecycle
+}
+
+func NewEngine(stores *data.Stores, rules *RuleSet) *Engine {
+	return &Engine{Stores: stores, Rules: rules, Lifecycle: tracking.NewLifecycle(stores)}
+}
+
+// food is something eaten in a meal, described by every name and category a
+// rule may match.
+type food struct {
+	mealEntryID  int64
+	mealFoodID   int64
+	customFoodID int64
+	name         string
+	names        []string
+	category     string
+}
+
+// Check matches the user's medications against their supplements and the
+// foods of their meals. Without periodIDs it checks what the user takes now
+// against the meals of the current tracking period. For each period given
+// it checks what the user took at some point during that period, judging by
+// the start and end dates of their records, against that period's meals.
+// Findings are ordered from most to least severe.
+func (engine *Engine) Check(ctx context.Context, userID int64, periodIDs ...int64) ([]Finding, error) {
+	if len(periodIDs) == 0 {
+		return engine.checkCurrent(ctx, userID)
+	}
+
+	var findings []Finding
+	seen := make(map[Finding]bool)
+	for _, periodID := range periodIDs {
+		history, err := engine.Lifecycle.MedicationHistory(ctx, periodID)
+		if err != nil {
+			return nil, err
+		}
+		if history.UserID != userID {
+			return nil, data.ErrRecordNotFound
+		}
+		if len(history.Medications) == 0 {
+			continue
+		}
+
+		foods, err := engine.loadFoods(ctx, userID, periodID)
+		if err != nil {
+			return nil, err
+		}
+		// A supplement taken over several periods interacts only once.
+		for _, finding := range engine.match(history.Medications, history.Supplements, foods) {
+			if !seen[finding] {
+				seen[finding] = true
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	sortFindings(findings)
+	return findings, nil
+}
+
+// checkCurrent checks what the user takes now against the meals of their
+// current tracking period, if they have one.
+func (engine *Engine) checkCurrent(ctx context.Context, userID int64) ([]Finding, error) {
+	now := engine.Lifecycle.Now()
+	medications, err := engine.Stores.MedicationStore.ListUserMedicationsAsOf(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(medications) == 0 {
+		return nil, nil
+	}
+
+	supplements, err := engine.Stores.DietarySupplementStore.ListUserDietarySupplementsAsOf(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var foods []food
+	period, err := engine.Lifecycle.CurrentTrackingPeriod(ctx, userID)
+	if err == nil {
+		foods, err = engine.loadFoods(ctx, userID, period.ID)
+	}
+	if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	medicationUses := make([]*tracking.MedicationUse, len(medications))
+	for i, medication := range medications {
+		medicationUses[i] = &tracking.MedicationUse{MedicationID: medication.ID, Name: medication.Name}
+	}
+	supplementUses := make([]*tracking.MedicationUse, len(supplements))
+	for i, supplement := range supplements {
+		supplementUses[i] = &tracking.MedicationUse{SupplementID: supplement.ID, Name: supplement.Name}
+	}
+
+	findings := engine.match(medicationUses, supplementUses, foods)
+	sortFindings(findings)
+	return findings, nil
+}
+
+// match applies the rules to medications taken alongside supplements and
+// foods.
+func (engine *Engine) match(medications, supplements []*tracking.MedicationUse, foods []food) []Finding {
+	var findings []Finding
+	for _, rule := range engine.Rules.Rules {
+		for _, medication := range medications {
+			if !matchesAny(medication.Name, rule.Drugs) {
+				continue
+			}
+			finding := Finding{
+				RuleID:       rule.ID,
+				Severity:     rule.Severity,
+				MedicationID: medication.MedicationID,
+				Medication:   medication.Name,
+				Separation:   rule.Separation,
+				Advice:       rule.Advice,
+			}
+
+			for _, supplement := range supplements {
+				if matchesAny(supplement.Name, rule.Supplements) {
+					found := finding
+					found.Kind, found.SupplementID, found.Supplement = KindDrugSupplement, supplement.SupplementID, supplement.Name
+					findings = append(findings, found)
+				}
+			}
+			for _, food := range foods {
+				if rule.matchesFood(food) {
+					found := finding
+					found.Kind, found.MealEntryID, found.Food = KindDrugFood, food.mealEntryID, food.name
+					found.MealFoodID, found.CustomFoodID = food.mealFoodID, food.customFoodID
+					findings = append(findings, found)
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity.Rank() > findings[j].Severity.Rank()
+	})
+}
+
+func (rule *Rule) matchesFood(food food) bool {
+	for _, name := range food.names {
+		if matchesAny(name, rule.Foods) {
+			return true
+		}
+	}
+	return food.category != "" && slices.ContainsFunc(rule.FoodCategories, func(category string) bool {
+		return strings.EqualFold(category, food.category)
+	})
+}
+
+// matchesAny reports whether name contains any of the phrases.
+func matchesAny(name string, phrases []string) bool {
+	return slices.ContainsFunc(phrases, func(phrase string) bool {
+		return search.ContainsPhrase(name, phrase)
+	})
+}
+
+// loadFoods collects the foods logged during a tracking period. Custom
+// foods named exactly like a catalog item take on its synonyms and
+// category.
+func (engine *Engine) loadFoods(ctx context.Context, userID, periodID int64) ([]food, error) {
+	entries, err := engine.Stores.MealEntryStore.ListUserMealEntries(ctx, userID, periodID)
+	if err != nil {
+		return nil, err
+	}
+
+	var foods []food
+	for _, entry := range entries {
+		mealFoods, err := engine.Stores.MealFoodStore.GetMealFoodsForMeal(ctx, entry.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, mealFood := range mealFoods {
+			item, err := engine.Stores.FoodItemStore.GetFoodItem(ctx, mealFood.FoodItemID)
+			if errors.Is(err, data.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			found := itemFood(item)
+			found.mealEntryID, found.mealFoodID = entry.ID, mealFood.ID
+			foods = append(foods, found)
+		}
+
+		customFoods, err := engine.Stores.CustomFoodStore.GetCustomFoodsForMeal(ctx, entry.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, customFood := range customFoods {
+			found := food{name: customFood.Name, names: []string{customFood.Name}}
+			item, err := engine.Stores.FoodItemStore.GetFoodItemByName(ctx, customFood.Name)
+			if err == nil {
+				found = itemFood(item)
+				found.name = customFood.Name
+			} else if !errors.Is(err, data.ErrRecordNotFound) {
+				return nil, err
+			}
+			found.mealEntryID, found.customFoodID = entry.ID, customFood.ID
+			foods = append(foods, found)
+		}
+	}
+	return foods, nil
+}
+
+func itemFood(item *data.FoodItem) food {
+	return food{
+		name:     item.Name,
+		names:    append([]string{item.Name}, item.SynonymList()...),
+		category: item.Category,
+	}
+}
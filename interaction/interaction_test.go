@@ -0,0 +1,275 @@
+package interaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func TestDefaultRules(t *testing.T) {
+	rules, err := DefaultRules()
+	if err != nil {
+		t.Fatalf("DefaultRules: %v", err)
+	}
+	if len(rules.Rules) == 0 {
+		t.Fatal("DefaultRules returned no rules")
+	}
+
+	byID := make(map[string]*Rule)
+	for _, rule := range rules.Rules {
+		byID[rule.ID] = rule
+	}
+	if rule := byID["levothyroxine-minerals"]; rule == nil ||
+		rule.Severity != SeverityModerate || rule.Separation != Duration(4*time.Hour) {
+		t.Fatalf("levothyroxine-minerals is %+v, want a moderate rule with 4h separation", rule)
+	}
+	if rule := byID["antibiotics-dairy"]; rule == nil || len(rule.FoodCategories) != 1 || rule.FoodCategories[0] != "Dairy" {
+		t.Fatalf("antibiotics-dairy is %+v, want a rule on the Dairy category", rule)
+	}
+}
+
+func TestReadRules(t *testing.T) {
+	const valid = `{"id": "a", "severity": "minor", "drugs": ["x"], "foods": ["y"], "advice": "z"}`
+
+	tests := []struct {
+		name string
+		json string
+		// problems are substrings of the ErrInvalidRules error; empty if the
+		// rules are valid or fail to decode.
+		problems  []string
+		decodeErr bool
+	}{
+		{name: "valid", json: `{"rules": [` + valid + `]}`},
+		{name: "no rules", json: `{"rules": []}`},
+		{
+			name: "separation",
+			json: `{"rules": [{"id": "a", "severity": "major", "drugs": ["x"], "supplements": ["y"], "separation": "90m", "advice": "z"}]}`,
+		},
+		{name: "unknown field", json: `{"rules": [{"id": "a", "level": "minor"}]}`, decodeErr: true},
+		{name: "bad duration", json: `{"rules": [{"id": "a", "separation": "soon"}]}`, decodeErr: true},
+		{name: "not JSON", json: `rules:`, decodeErr: true},
+		{
+			name:     "missing fields",
+			json:     `{"rules": [{"id": "a"}]}`,
+			problems: []string{`rule 1 ("a"): advice`, "drugs", "severity", "supplements"},
+		},
+		{
+			name:     "unknown severity",
+			json:     `{"rules": [{"id": "a", "severity": "severe", "drugs": ["x"], "foods": ["y"], "advice": "z"}]}`,
+			problems: []string{"severity must be minor, moderate, major or contraindicated"},
+		},
+		{
+			name:     "duplicate id",
+			json:     `{"rules": [` + valid + `, ` + valid + `]}`,
+			problems: []string{`rule 2 ("a"): id must be unique`},
+		},
+		{
+			name:     "blank name and negative separation",
+			json:     `{"rules": [{"id": "a", "severity": "minor", "drugs": [" "], "foods": ["y"], "separation": "-1h", "advice": "z"}]}`,
+			problems: []string{"names must not be blank", "separation must not be negative"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules, err := ReadRules(strings.NewReader(test.json))
+			switch {
+			case test.decodeErr:
+				if err == nil || errors.Is(err, ErrInvalidRules) {
+					t.Fatalf("got error %v, want a decoding error", err)
+				}
+			case len(test.problems) > 0:
+				if !errors.Is(err, ErrInvalidRules) {
+					t.Fatalf("got error %v, want %v", err, ErrInvalidRules)
+				}
+				for _, problem := range test.problems {
+					if !strings.Contains(err.Error(), problem) {
+						t.Errorf("error %q does not mention %q", err, problem)
+					}
+				}
+			case err != nil:
+				t.Fatalf("ReadRules: %v", err)
+			case rules == nil:
+				t.Fatal("ReadRules returned no rule set")
+			}
+		})
+	}
+}
+
+func TestSeverityRank(t *testing.T) {
+	previous := 0
+	for _, severity := range Severities {
+		if rank := severity.Rank(); rank <= previous {
+			t.Fatalf("%s ranks %d, want more than %d", severity, rank, previous)
+		}
+		previous = severity.Rank()
+	}
+	if rank := Severity("Major").Rank(); rank != 0 {
+		t.Fatalf("an unknown severity ranks %d, want 0", rank)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	stores := data.NewMemoryStores()
+	must := func(what string, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", what, err)
+		}
+	}
+	rules, err := DefaultRules()
+	must("DefaultRules", err)
+	engine := NewEngine(stores, rules)
+
+	user, err := stores.UserStore.CreateUser(ctx, &data.User{
+		UserName:    "user",
+		Email:       "user@example.com",
+		PhoneNumber: "15550100",
+	})
+	must("CreateUser", err)
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	period, err := stores.TrackingPeriodStore.CreateTrackingPeriod(ctx, data.NewTrackingPeriod(user.ID, start, 3))
+	must("CreateTrackingPeriod", err)
+	engine.Lifecycle.Now = func() time.Time { return start.Add(36 * time.Hour) }
+
+	findings, err := engine.Check(ctx, user.ID)
+	if err != nil || findings != nil {
+		t.Fatalf("Check without medications returned %v, %v, want nothing", findings, err)
+	}
+
+	for _, medication := range []*data.Medication{
+		{UserID: user.ID, Name: "Synthroid 100 mcg", Current: true},
+		{UserID: user.ID, Name: "Doxycycline", Current: true},
+		{UserID: user.ID, Name: "Simvastatin", StartDate: start, EndDate: start.AddDate(0, 0, 1)},
+	} {
+		_, err := stores.MedicationStore.CreateMedication(ctx, medication)
+		must("CreateMedication", err)
+	}
+	for _, supplement := range []*data.DietarySupplement{
+		{UserID: user.ID, Name: "Calcium Carbonate", Current: true},
+		{UserID: user.ID, Name: "Vitamin D", Current: true},
+		{UserID: user.ID, Name: "Iron", Current: false},
+	} {
+		_, err := stores.DietarySupplementStore.CreateDietarySupplement(ctx, supplement)
+		must("CreateDietarySupplement", err)
+	}
+	cheddar, err := stores.FoodItemStore.CreateFoodItem(ctx, &data.FoodItem{Name: "Cheddar", Category: "Dairy"})
+	must("CreateFoodItem", err)
+	_, err = stores.FoodItemStore.CreateFoodItem(ctx, &data.FoodItem{
+		Name:     "Bean curd",
+		Category: "Legumes",
+		Synonyms: "tofu",
+	})
+	must("CreateFoodItem", err)
+
+	entry, err := stores.MealEntryStore.CreateMealEntry(ctx, &data.MealEntry{
+		UserID:           user.ID,
+		TrackingPeriodID: period.ID,
+		TrackingDay:      1,
+		MealType:         data.MealBreakfast,
+		PortionQuantity:  1,
+		PortionUnit:      data.PortionServing,
+	})
+	must("CreateMealEntry", err)
+	mealFood, err := stores.MealFoodStore.CreateMealFood(ctx, &data.MealFood{MealEntryID: entry.ID, FoodItemID: cheddar.ID})
+	must("CreateMealFood", err)
+	for _, name := range []string{"Espresso", "Bean curd", "Grapefruit"} {
+		_, err := stores.CustomFoodStore.CreateCustomFood(ctx, &data.CustomFood{MealEntryID: entry.ID, Name: name})
+		must("CreateCustomFood", err)
+	}
+
+	findings, err = engine.Check(ctx, user.ID)
+	must("Check", err)
+
+	// Simvastatin has been stopped and iron is not current, so neither the
+	// grapefruit nor the iron rules apply.
+	want := []string{
+		"moderate levothyroxine-minerals Synthroid 100 mcg with Calcium Carbonate",
+		"moderate antibiotics-minerals Doxycycline with Calcium Carbonate",
+		"moderate antibiotics-dairy Doxycycline with Cheddar",
+		"minor levothyroxine-foods Synthroid 100 mcg with Espresso",
+		"minor levothyroxine-foods Synthroid 100 mcg with Bean curd",
+	}
+	var got []string
+	for _, finding := range findings {
+		with := finding.Supplement + finding.Food
+		got = append(got, fmt.Sprintf("%s %s %s with %s", finding.Severity, finding.RuleID, finding.Medication, with))
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got findings\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	dairy := findings[2]
+	if dairy.Kind != KindDrugFood || dairy.MealEntryID != entry.ID || dairy.MealFoodID != mealFood.ID ||
+		dairy.CustomFoodID != 0 || dairy.Separation != Duration(2*time.Hour) || dairy.Advice == "" {
+		t.Fatalf("dairy finding is %+v, want the meal food with 2h separation and advice", dairy)
+	}
+	// Bean curd is a custom food matched through the synonyms of the
+	// catalog item of the same name.
+	if tofu := findings[4]; tofu.CustomFoodID == 0 || tofu.MealFoodID != 0 {
+		t.Fatalf("tofu finding is %+v, want the custom food", tofu)
+	}
+	if findings[0].Kind != KindDrugSupplement || findings[0].SupplementID == 0 {
+		t.Fatalf("calcium finding is %+v, want a supplement finding", findings[0])
+	}
+
+	// Checking the period explicitly matches what was taken during it, so
+	// the Simvastatin stopped on its second day meets the grapefruit.
+	findings, err = engine.Check(ctx, user.ID, period.ID)
+	must("Check", err)
+	if len(findings) != len(want)+1 || findings[0].RuleID != "statins-grapefruit" ||
+		findings[0].Medication != "Simvastatin" || findings[0].Food != "Grapefruit" {
+		t.Fatalf("Check of the period returned %+v, want Simvastatin with grapefruit first", findings)
+	}
+
+	// Once expired, the period is closed and its meals are not checked by
+	// default any more.
+	engine.Lifecycle.Now = func() time.Time { return period.EndDate }
+	findings, err = engine.Check(ctx, user.ID)
+	must("Check", err)
+	for _, finding := range findings {
+		if finding.Kind == KindDrugFood {
+			t.Fatalf("Check after the period expired found %+v", finding)
+		}
+	}
+	closed, err := stores.TrackingPeriodStore.GetTrackingPeriod(ctx, period.ID)
+	must("GetTrackingPeriod", err)
+	if !closed.IsCompleted {
+		t.Fatal("Check did not close the expired period")
+	}
+
+	// A later period, started after Simvastatin was stopped, does not match
+	// it, and checking both periods finds the calcium interactions only once.
+	later, err := stores.TrackingPeriodStore.CreateTrackingPeriod(
+		ctx,
+		data.NewTrackingPeriod(user.ID, start.AddDate(0, 0, 10), 3),
+	)
+	must("CreateTrackingPeriod", err)
+	findings, err = engine.Check(ctx, user.ID, later.ID)
+	must("Check", err)
+	for _, finding := range findings {
+		if finding.Kind == KindDrugFood || finding.Medication == "Simvastatin" {
+			t.Fatalf("Check of a later period without meals found %+v", finding)
+		}
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Check of a later period returned %+v, want the two calcium findings", findings)
+	}
+	findings, err = engine.Check(ctx, user.ID, period.ID, later.ID)
+	must("Check", err)
+	if len(findings) != len(want)+1 {
+		t.Fatalf("Check of both periods returned %d findings, want %d", len(findings), len(want)+1)
+	}
+
+	if _, err := engine.Check(ctx, user.ID+1, period.ID); !errors.Is(err, data.ErrRecordNotFound) {
+		t.Fatalf("Check of another user's period: got error %v, want %v", err, data.ErrRecordNotFound)
+	}
+	if _, err := engine.Check(ctx, user.ID, later.ID+100); !errors.Is(err, data.ErrRecordNotFound) {
+		t.Fatalf("Check of an unknown period: got error %v, want %v", err, data.ErrRecordNotFound)
+	}
+}
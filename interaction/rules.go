@@ -0,0 +1,163 @@
+package interaction
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/validator"
+)
+
+// Severity grades how much harm an interaction can do.
+type Severity string
+
+const (
+	SeverityMinor           Severity = "minor"
+	SeverityModerate        Severity = "moderate"
+	SeverityMajor           Severity = "major"
+	SeverityContraindicated Severity = "contraindicated"
+)
+
+// Severities are the severities from least to most severe.
+var Severities = []Severity{SeverityMinor, SeverityModerate, SeverityMajor, SeverityContraindicated}
+
+// Rank orders severities from 1 for minor upwards; it is 0 for an unknown
+// severity.
+func (severity Severity) Rank() int {
+	for i, known := range Severities {
+		if severity == known {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+var ErrInvalidRules = errors.New("invalid interaction rules")
+
+// Duration is a time.Duration written in rules files as a string such as
+// "4h" or "90m".
+type Duration time.Duration
+
+func (duration Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(duration).String())
+}
+
+func (duration *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*duration = Duration(parsed)
+	return nil
+}
+
+// Rule describes an interaction between the medications named by Drugs and
+// the supplements named by Supplements or the foods named by Foods or
+// belonging to FoodCategories. Names match a record when they appear in it
+// as whole words, ignoring case and plurals. Separation, when set, is how
+// far apart the two should be taken to avoid the interaction.
+type Rule struct {
+	ID             string   `json:"id"`
+	Severity       Severity `json:"severity"`
+	Drugs          []string `json:"drugs"`
+	Supplements    []string `json:"supplements,omitempty"`
+	Foods          []string `json:"foods,omitempty"`
+	FoodCategories []string `json:"food_categories,omitempty"`
+	Separation     Duration `json:"separation,omitempty"`
+	Advice         string   `json:"advice"`
+}
+
+// RuleSet is the content of a rules file.
+type RuleSet struct {
+	Rules []*Rule `json:"rules"`
+}
+
+//go:embed rules.json
+var defaultRules string
+
+// DefaultRules returns the rules shipped with the package, covering common
+// drug-supplement and drug-food interactions.
+func DefaultRules() (*RuleSet, error) {
+	return ReadRules(strings.NewReader(defaultRules))
+}
+
+// LoadRules reads and validates the rules file at path.
+func LoadRules(path string) (*RuleSet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rules, err := ReadRules(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ReadRules decodes a JSON rules file and validates every rule. Invalid
+// rules are reported together in an error wrapping ErrInvalidRules.
+func ReadRules(r io.Reader) (*RuleSet, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var rules RuleSet
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	seen := make(map[string]bool, len(rules.Rules))
+	for i, rule := range rules.Rules {
+		v := validator.New()
+		ValidateRule(v, rule)
+		v.Check(!seen[rule.ID], "id", "must be unique")
+		seen[rule.ID] = true
+		if v.Valid() {
+			continue
+		}
+
+		fields := make([]string, 0, len(v.Errors))
+		for field := range v.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			problems = append(problems, fmt.Sprintf("rule %d (%q): %s %s", i+1, rule.ID, field, v.Errors[field]))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRules, strings.Join(problems, "; "))
+	}
+
+	return &rules, nil
+}
+
+func ValidateRule(v *validator.Validator, rule *Rule) {
+	v.Check(rule.ID != "", "id", "must be provided")
+	v.Check(rule.Severity.Rank() != 0, "severity", "must be minor, moderate, major or contraindicated")
+	v.Check(len(rule.Drugs) > 0, "drugs", "must name at least one medication")
+	v.Check(
+		len(rule.Supplements)+len(rule.Foods)+len(rule.FoodCategories) > 0,
+		"supplements",
+		"must name at least one supplement, food or food category",
+	)
+	v.Check(rule.Separation >= 0, "separation", "must not be negative")
+	v.Check(rule.Advice != "", "advice", "must be provided")
+
+	for _, names := range [][]string{rule.Drugs, rule.Supplements, rule.Foods, rule.FoodCategories} {
+		for _, name := range names {
+			v.Check(strings.TrimSpace(name) != "", "names", "must not be blank")
+		}
+	}
+}
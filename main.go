@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -12,6 +13,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/Universal-Selfcare/utils/data"
+	"github.com/Universal-Selfcare/utils/migrate"
 	"github.com/Universal-Selfcare/utils/password"
 )
 
@@ -174,28 +176,56 @@ func main() {
 
 	db, err := openDB(cfg)
 	if err != nil {
-		return
+		log.Fatalf("Failed to open database: %v", err)
 	}
 
-	db.AutoMigrate(
-		data.User{},
-		data.Token{},
-		data.MedicalInformation{},
-		data.Caregiver{},
-		data.Allergy{},
-		data.MedicalEvent{},
-		data.FrequentFood{},
-		data.Medication{},
-		data.EmergencyContact{},
-		data.DietarySupplement{},
-	)
-
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Fatalf("Failed to get sql.DB: %v", err)
 	}
 	defer sqlDB.Close()
 
+	migrator, err := migrate.New(sqlDB)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	switch flag.Arg(0) {
+	case "migrate":
+		if err := runMigrate(migrator, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+	case "import-foods":
+		if err := runImportFoods(db, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+	case "promote-foods":
+		if err := runPromoteFoods(db, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+	case "interactions":
+		if err := runInteractions(db, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+	case "backfill-dosages":
+		if err := runBackfillDosages(db, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+	case "check-allergies":
+		if err := runCheckAllergies(db, cfg, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+	case "", "seed":
+		if _, err := migrator.Up(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		seed(db)
+	default:
+		log.Fatalf("Unknown command %q (expected seed, migrate, import-foods, promote-foods, interactions, backfill-dosages or check-allergies)", flag.Arg(0))
+	}
+}
+
+func seed(db *gorm.DB) {
 	// Initialize random seed
 	rand.Seed(time.Now().UnixNano())
 
@@ -267,19 +297,14 @@ func openDB(cfg config) (*gorm.DB, error) {
 func createMedicalInformation(db *gorm.DB, userID int64) {
 	medInfo := &data.MedicalInformation{
 		UserID:            userID,
-		Height:            fmt.Sprintf("%d", 150+rand.Intn(50)),
-		Weight:            fmt.Sprintf("%d", 50+rand.Intn(70)),
+		Height:            uint(150 + rand.Intn(50)),
+		Weight:            uint(50 + rand.Intn(70)),
 		Diagnosis:         randomElement(sampleConditions),
 		DiagnosisSeverity: randomElement([]string{"Mild", "Moderate", "Severe"}),
 		CurrentPriority: randomElement(
 			[]string{"Improve health", "Manage symptoms", "Preventive care"},
 		),
-		Gender:            randomElement([]string{"Male", "Female", "Other"}),
-		State:             randomElement([]string{"CA", "NY", "TX", "FL", "IL"}),
-		ContactPreference: randomElement([]string{"Phone", "Email"}),
-		CaregivingPriority: randomElement(
-			[]string{"Medication management", "Diet improvement", "Stress reduction"},
-		),
+		Gender:          randomElement([]string{"Male", "Female", "Other"}),
 		HealthTriggers:  randomElement(sampleTriggers),
 		DesiredChanges:  randomElement(sampleChanges),
 		OralAntibiotics: randomBool(),
@@ -321,15 +346,18 @@ func createRandomMedications(db *gorm.DB, userID int64) {
 
 	for i := 0; i < numMeds; i++ {
 		current := randomBool()
-		endDate := ""
+		var endDate time.Time
 		if !current {
 			endDate = randomDate(2024, 2025)
 		}
 
+		dosage := randomElement([]string{"10mg", "25mg", "50mg", "100mg"})
+		dose, _ := data.ParseDosage(dosage)
 		medication := &data.Medication{
 			UserID:    userID,
 			Name:      randomElement(sampleMedications),
-			Dosage:    randomElement([]string{"10mg", "25mg", "50mg", "100mg"}),
+			Dosage:    dosage,
+			Dose:      dose,
 			StartDate: randomDate(2020, 2024),
 			EndDate:   endDate,
 			Current:   current,
@@ -392,9 +420,9 @@ func randomBool() bool {
 	return rand.Intn(2) == 1
 }
 
-func randomDate(minYear, maxYear int) string {
+func randomDate(minYear, maxYear int) time.Time {
 	year := minYear + rand.Intn(maxYear-minYear+1)
-	month := 1 + rand.Intn(12)
+	month := time.Month(1 + rand.Intn(12))
 	day := 1 + rand.Intn(28)
-	return fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 }
@@ -0,0 +1,201 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestParseFileName(t *testing.T) {
+	tests := []struct {
+		fileName  string
+		version   int64
+		name      string
+		direction string
+	}{
+		{"0001_initial_schema.up.sql", 1, "initial_schema", "up"},
+		{"0012_food_item_name_unique.down.sql", 12, "food_item_name_unique", "down"},
+		{"20250301_v2.1_fix.up.sql", 20250301, "v2.1_fix", "up"},
+	}
+	for _, test := range tests {
+		version, name, direction, err := parseFileName(test.fileName)
+		if err != nil {
+			t.Errorf("parseFileName(%q): %v", test.fileName, err)
+			continue
+		}
+		if version != test.version || name != test.name || direction != test.direction {
+			t.Errorf(
+				"parseFileName(%q) = %d, %q, %q, want %d, %q, %q",
+				test.fileName, version, name, direction, test.version, test.name, test.direction,
+			)
+		}
+	}
+
+	for _, fileName := range []string{
+		"0001_initial_schema.sql",
+		"0001_initial_schema.sideways.sql",
+		"0001.up.sql",
+		"0001_.up.sql",
+		"initial_schema.up.sql",
+		"x001_initial_schema.up.sql",
+		"0000_initial_schema.up.sql",
+		"-001_initial_schema.up.sql",
+	} {
+		if _, _, _, err := parseFileName(fileName); err == nil {
+			t.Errorf("parseFileName(%q) succeeded, want an error", fileName)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file := func(contents string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(contents)}
+	}
+
+	migrations, err := Load(fstest.MapFS{
+		"sql/0010_second.up.sql":   file("up 10"),
+		"sql/0010_second.down.sql": file("down 10"),
+		"sql/0002_first.up.sql":    file("up 2"),
+		"sql/0002_first.down.sql":  file("down 2"),
+		"sql/README.md":            file("not a migration"),
+		"sql/nested/0003_x.up.sql": file("ignored"),
+	}, "sql")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var got []string
+	for _, migration := range migrations {
+		got = append(got, fmt.Sprintf("%d %s %s/%s", migration.Version, migration.Name, migration.Up, migration.Down))
+	}
+	if want := "2 first up 2/down 2, 10 second up 10/down 10"; strings.Join(got, ", ") != want {
+		t.Fatalf("Load returned %s, want %s", strings.Join(got, ", "), want)
+	}
+
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		problem string
+	}{
+		{
+			name:    "missing down file",
+			fsys:    fstest.MapFS{"sql/0001_first.up.sql": file("up")},
+			problem: "must have both up and down files",
+		},
+		{
+			name:    "missing up file",
+			fsys:    fstest.MapFS{"sql/0001_first.down.sql": file("down")},
+			problem: "must have both up and down files",
+		},
+		{
+			name: "name conflict",
+			fsys: fstest.MapFS{
+				"sql/0001_first.up.sql":   file("up"),
+				"sql/0001_other.down.sql": file("down"),
+			},
+			problem: "conflicting names",
+		},
+		{
+			name:    "bad version",
+			fsys:    fstest.MapFS{"sql/first_one.up.sql": file("up")},
+			problem: "invalid version",
+		},
+		{
+			name:    "bad direction",
+			fsys:    fstest.MapFS{"sql/0001_first.upgrade.sql": file("up")},
+			problem: "invalid direction",
+		},
+	}
+	for _, test := range tests {
+		_, err := Load(test.fsys, "sql")
+		if err == nil || !strings.Contains(err.Error(), test.problem) {
+			t.Errorf("%s: got error %v, want one mentioning %q", test.name, err, test.problem)
+		}
+	}
+
+	if _, err := Load(fstest.MapFS{"sql/README.md": file("")}, "sql"); !errors.Is(err, ErrNoMigrations) {
+		t.Errorf("Load without migrations: got error %v, want %v", err, ErrNoMigrations)
+	}
+	if _, err := Load(fstest.MapFS{}, "sql"); err == nil {
+		t.Error("Load of a missing directory succeeded, want an error")
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := Load(embedded, "sql")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for i, migration := range migrations {
+		if migration.Version != int64(i+1) {
+			t.Fatalf("migration %d_%s is number %d, want versions numbered from 1 without gaps",
+				migration.Version, migration.Name, i+1)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	m := &Migrator{Migrations: []Migration{
+		{Version: 1, Name: "one"},
+		{Version: 2, Name: "two"},
+		{Version: 5, Name: "five"},
+		{Version: 7, Name: "seven"},
+	}}
+	applied := func(versions ...int64) map[int64]time.Time {
+		applied := make(map[int64]time.Time)
+		for _, version := range versions {
+			applied[version] = time.Now()
+		}
+		return applied
+	}
+	versions := func(migrations []Migration) string {
+		var got []string
+		for _, migration := range migrations {
+			got = append(got, fmt.Sprint(migration.Version))
+		}
+		return strings.Join(got, ",")
+	}
+
+	tests := []struct {
+		name      string
+		applied   map[int64]time.Time
+		version   int64
+		rollbacks string
+		applies   string
+	}{
+		{name: "up from nothing", applied: applied(), version: 7, applies: "1,2,5,7"},
+		{name: "up part way", applied: applied(1, 2), version: 5, applies: "5"},
+		{name: "up to date", applied: applied(1, 2, 5, 7), version: 7},
+		{name: "down to a version", applied: applied(1, 2, 5, 7), version: 2, rollbacks: "7,5"},
+		{name: "down to nothing", applied: applied(1, 2, 5), version: 0, rollbacks: "5,2,1"},
+		// A gap left by a migration added out of order is filled in.
+		{name: "gap", applied: applied(1, 5), version: 7, applies: "2,7"},
+		{name: "gap below target", applied: applied(1, 5, 7), version: 5, rollbacks: "7", applies: "2"},
+	}
+	for _, test := range tests {
+		rollbacks, applies, err := m.plan(test.applied, test.version)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if versions(rollbacks) != test.rollbacks || versions(applies) != test.applies {
+			t.Errorf(
+				"%s: got rollbacks %q and applies %q, want %q and %q",
+				test.name, versions(rollbacks), versions(applies), test.rollbacks, test.applies,
+			)
+		}
+	}
+
+	if _, _, err := m.plan(applied(1, 2, 3), 7); !errors.Is(err, ErrDirty) || !strings.Contains(err.Error(), "3") {
+		t.Fatalf("plan with an unknown version applied: got error %v, want %v for version 3", err, ErrDirty)
+	}
+
+	if latest, ok := m.latestApplied(applied(1, 5)); !ok || latest.Version != 5 {
+		t.Fatalf("latestApplied = %+v, %t, want version 5", latest, ok)
+	}
+	if latest, ok := m.latestApplied(applied()); ok {
+		t.Fatalf("latestApplied with nothing applied = %+v, want none", latest)
+	}
+}
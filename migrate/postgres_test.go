@@ -0,0 +1,164 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDSNEnv names the variable holding the DSN of a scratch database for
+// the Postgres tests. The tests are skipped when it is unset.
+const testDSNEnv = "UNIVERSAL_SELFCARE_TEST_DB_DSN"
+
+// openTestDB opens the scratch database with a schema of its own first on
+// the search path, so rolling every migration back does not disturb the
+// data package's suite running against the same database.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	schema := fmt.Sprintf("migrate_test_%d", os.Getpid())
+
+	open := func(dsn string) *sql.DB {
+		t.Helper()
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatalf("open database: %v", err)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			t.Fatalf("get sql.DB: %v", err)
+		}
+		return sqlDB
+	}
+
+	admin := open(dsn)
+	t.Cleanup(func() { admin.Close() })
+	// Installed in public ahead of the search migration, pg_trgm survives
+	// the schema being dropped.
+	if _, err := admin.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm SCHEMA public"); err != nil {
+		t.Fatalf("create pg_trgm: %v", err)
+	}
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("drop schema: %v", err)
+		}
+	})
+
+	searchPath := schema + ",public"
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + "search_path=" + url.QueryEscape(searchPath)
+	} else {
+		dsn += " search_path=" + searchPath
+	}
+	db := open(dsn)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestPostgresRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	latest := migrator.Migrations[len(migrator.Migrations)-1].Version
+
+	applied := func() []int64 {
+		t.Helper()
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatalf("Status: %v", err)
+		}
+		var versions []int64
+		for _, status := range statuses {
+			if status.Applied {
+				versions = append(versions, status.Version)
+			}
+		}
+		return versions
+	}
+	tableExists := func(name string) bool {
+		t.Helper()
+		var exists bool
+		if err := db.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", name).Scan(&exists); err != nil {
+			t.Fatalf("look up table %s: %v", name, err)
+		}
+		return exists
+	}
+	run := func(what string, migrate func(context.Context) ([]Migration, error), want int) {
+		t.Helper()
+		changed, err := migrate(ctx)
+		if err != nil {
+			t.Fatalf("%s: %v", what, err)
+		}
+		if len(changed) != want {
+			t.Fatalf("%s changed %d migrations, want %d", what, len(changed), want)
+		}
+	}
+	goTo := func(version int64) func(context.Context) ([]Migration, error) {
+		return func(ctx context.Context) ([]Migration, error) { return migrator.Goto(ctx, version) }
+	}
+
+	run("Up", migrator.Up, len(migrator.Migrations))
+	if got := applied(); len(got) != len(migrator.Migrations) {
+		t.Fatalf("after Up versions %v are applied, want all %d", got, len(migrator.Migrations))
+	}
+	run("Up again", migrator.Up, 0)
+
+	run("Goto(3)", goTo(3), len(migrator.Migrations)-3)
+	if got := fmt.Sprint(applied()); got != "[1 2 3]" {
+		t.Fatalf("after Goto(3) versions %s are applied, want [1 2 3]", got)
+	}
+	run("Down", migrator.Down, 1)
+	if got := fmt.Sprint(applied()); got != "[1 2]" {
+		t.Fatalf("after Down versions %s are applied, want [1 2]", got)
+	}
+
+	run("Up from 2", migrator.Up, len(migrator.Migrations)-2)
+	if !tableExists("food_items") {
+		t.Fatal("food_items does not exist after Up")
+	}
+
+	if _, err := migrator.Goto(ctx, latest+1); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("Goto(%d): got error %v, want %v", latest+1, err, ErrUnknownVersion)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, 'future')", latest+1); err != nil {
+		t.Fatalf("insert unknown version: %v", err)
+	}
+	if _, err := migrator.Goto(ctx, 0); !errors.Is(err, ErrDirty) {
+		t.Fatalf("Goto(0) with an unknown version applied: got error %v, want %v", err, ErrDirty)
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", latest+1); err != nil {
+		t.Fatalf("delete unknown version: %v", err)
+	}
+
+	run("Goto(0)", goTo(0), len(migrator.Migrations))
+	if got := applied(); len(got) != 0 {
+		t.Fatalf("after Goto(0) versions %v are applied, want none", got)
+	}
+	if tableExists("food_items") {
+		t.Fatal("food_items still exists after Goto(0)")
+	}
+	run("Down with nothing applied", migrator.Down, 0)
+}
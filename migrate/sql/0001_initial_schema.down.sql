@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS symptoms;
+DROP TABLE IF EXISTS custom_foods;
+DROP TABLE IF EXISTS meal_foods;
+DROP TABLE IF EXISTS food_items;
+DROP TABLE IF EXISTS meal_entries;
+DROP TABLE IF EXISTS tracking_periods;
+DROP TABLE IF EXISTS dietary_supplements;
+DROP TABLE IF EXISTS medications;
+DROP TABLE IF EXISTS allergies;
+DROP TABLE IF EXISTS frequent_foods;
+DROP TABLE IF EXISTS medical_events;
+DROP TABLE IF EXISTS emergency_contacts;
+DROP TABLE IF EXISTS caregivers;
+DROP TABLE IF EXISTS medical_informations;
+DROP TABLE IF EXISTS user_intakes;
+DROP TABLE IF EXISTS tokens;
+DROP TABLE IF EXISTS users;
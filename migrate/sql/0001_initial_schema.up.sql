@@ -0,0 +1,360 @@
+-- Baseline schema. Every statement is idempotent so databases previously
+-- created by GORM's AutoMigrate can adopt versioned migrations in place.
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial,
+    user_name text NOT NULL,
+    first_name text NOT NULL,
+    last_name text NOT NULL,
+    email text NOT NULL,
+    phone_number text NOT NULL,
+    hash text NOT NULL,
+    user_intake_complete boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_user_name ON users (user_name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone_number ON users (phone_number);
+
+CREATE TABLE IF NOT EXISTS tokens (
+    id bigserial,
+    hash bytea NOT NULL,
+    user_id bigint NOT NULL,
+    expiry timestamptz NOT NULL,
+    scope text NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_tokens_expiry ON tokens (expiry);
+CREATE INDEX IF NOT EXISTS idx_tokens_scope ON tokens (scope);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tokens_hash ON tokens (hash);
+CREATE INDEX IF NOT EXISTS idx_tokens_user_id ON tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS user_intakes (
+    id bigserial,
+    user_id bigint NOT NULL,
+    form_data jsonb,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_user_intake FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_intakes_user_id ON user_intakes (user_id);
+
+CREATE TABLE IF NOT EXISTS medical_informations (
+    id bigserial,
+    user_id bigint NOT NULL,
+    height bigint NOT NULL,
+    weight bigint NOT NULL,
+    diagnosis text NOT NULL,
+    diagnosis_severity text NOT NULL,
+    current_priority text NOT NULL,
+    gender text NOT NULL,
+    oral_antibiotics boolean DEFAULT false,
+    frequent_hydro_lotions boolean DEFAULT false,
+    metals_or_magnesium_powder boolean DEFAULT false,
+    unfiltered_tap_water boolean DEFAULT false,
+    pesticides_from_farm boolean DEFAULT false,
+    two_or_more_hours_screen_time boolean DEFAULT false,
+    dental_or_body_x_rays boolean DEFAULT false,
+    frequent_wireless_device boolean DEFAULT false,
+    water_leakage_in_basement boolean DEFAULT false,
+    musty_mildew_smell boolean DEFAULT false,
+    frequent_deodorant_with_nail_polish boolean DEFAULT false,
+    canned_foods_thermal_receipts boolean DEFAULT false,
+    contact_with_building_materials boolean DEFAULT false,
+    daily_use_plastic_utensils boolean DEFAULT false,
+    frequent_meals_shellfish_large_fish boolean DEFAULT false,
+    trauma_or_nightmares boolean DEFAULT false,
+    screams_or_shrieks boolean DEFAULT false,
+    mood_swings boolean DEFAULT false,
+    irritability boolean DEFAULT false,
+    brain_fog boolean DEFAULT false,
+    difficulty_concentrating boolean DEFAULT false,
+    anxiety_dark_thoughts boolean DEFAULT false,
+    attention_deficit_hyperactivity boolean DEFAULT false,
+    bipolar_disorder boolean DEFAULT false,
+    schizophrenia boolean DEFAULT false,
+    sensory_integration_disorder boolean DEFAULT false,
+    autism boolean DEFAULT false,
+    hair_is_thinning boolean DEFAULT false,
+    bleeding_gums boolean DEFAULT false,
+    gingivitis boolean DEFAULT false,
+    coated_tongue boolean DEFAULT false,
+    stammering boolean DEFAULT false,
+    dizziness_spinning boolean DEFAULT false,
+    limited_speech boolean DEFAULT false,
+    answersby_repeating_schedulal boolean DEFAULT false,
+    poor_eye_contact boolean DEFAULT false,
+    difficulty_falling_asleep boolean DEFAULT false,
+    wake_up_middle_of_night boolean DEFAULT false,
+    chronic_cough boolean DEFAULT false,
+    chronic_runny_nose boolean DEFAULT false,
+    abnormal_early_development boolean DEFAULT false,
+    painful_periods boolean DEFAULT false,
+    headaches_or_migraines boolean DEFAULT false,
+    heart_palpitations boolean DEFAULT false,
+    frequently_catches_infections boolean DEFAULT false,
+    sinus_congestion boolean DEFAULT false,
+    chronic_ear_ache boolean DEFAULT false,
+    tingling_in_hands_or_feet boolean DEFAULT false,
+    sexual_dysfunction boolean DEFAULT false,
+    muscle_cramps_or_twitch boolean DEFAULT false,
+    athletes_foot boolean DEFAULT false,
+    jock_itch boolean DEFAULT false,
+    fungal_nail_infections boolean DEFAULT false,
+    chronic_ache_or_pain boolean DEFAULT false,
+    eczema boolean DEFAULT false,
+    acne boolean DEFAULT false,
+    psoriasis boolean DEFAULT false,
+    dry_skin boolean DEFAULT false,
+    rash boolean DEFAULT false,
+    burning boolean DEFAULT false,
+    hives boolean DEFAULT false,
+    itchy_ear boolean DEFAULT false,
+    itchy_scalp_nation boolean DEFAULT false,
+    itchy_genital_area boolean DEFAULT false,
+    tiny_bumps_on_cheek boolean DEFAULT false,
+    bad_breath boolean DEFAULT false,
+    cavities_dental_health boolean DEFAULT false,
+    bleeding_gums_gi boolean DEFAULT false,
+    coated_tongue_gi boolean DEFAULT false,
+    bloating_in_stomach boolean DEFAULT false,
+    more_than2_bowls_daily boolean DEFAULT false,
+    diarrhea boolean DEFAULT false,
+    constipation boolean DEFAULT false,
+    frequent_urination_bed_wetting boolean DEFAULT false,
+    stool_with_undigested_food boolean DEFAULT false,
+    bladder_infection boolean DEFAULT false,
+    irritable_bowel_syndrome boolean DEFAULT false,
+    ulcerative_colitis boolean DEFAULT false,
+    gastritis_or_peptic_ulcer boolean DEFAULT false,
+    gerd boolean DEFAULT false,
+    celiac_disease boolean DEFAULT false,
+    heart_disease boolean DEFAULT false,
+    elevated_or_low_cholesterol boolean DEFAULT false,
+    high_blood_pressure boolean DEFAULT false,
+    pots_dysautonomia boolean DEFAULT false,
+    rheumatic_fever boolean DEFAULT false,
+    mitral_valve_prolapse boolean DEFAULT false,
+    type1_diabetes boolean DEFAULT false,
+    type2_diabetes boolean DEFAULT false,
+    hypoglycemia boolean DEFAULT false,
+    insulin_resistance_or_prediabetes boolean DEFAULT false,
+    hypothyroidism boolean DEFAULT false,
+    hyperthyroidism boolean DEFAULT false,
+    endocrine_problems boolean DEFAULT false,
+    weight_gain boolean DEFAULT false,
+    weight_loss boolean DEFAULT false,
+    weight_fluctuations boolean DEFAULT false,
+    other_eating_disorder boolean DEFAULT false,
+    mitochondrial_dysfunction boolean DEFAULT false,
+    folate_deficiency boolean DEFAULT false,
+    fatty_acid_oxidation_defect boolean DEFAULT false,
+    kidney_stones boolean DEFAULT false,
+    urinary_tract_infections boolean DEFAULT false,
+    yeast_infections boolean DEFAULT false,
+    arthritis boolean DEFAULT false,
+    fibromyalgia boolean DEFAULT false,
+    chronic_pain boolean DEFAULT false,
+    chronic_fatigue_syndrome boolean DEFAULT false,
+    autoimmune_disease text,
+    rheumatoid_arthritis boolean DEFAULT false,
+    lupus boolean DEFAULT false,
+    immune_deficiency_disease boolean DEFAULT false,
+    poor_immune_function boolean DEFAULT false,
+    food_allergies boolean DEFAULT false,
+    environmental_allergies boolean DEFAULT false,
+    multiple_chemical_sensitivities boolean DEFAULT false,
+    latex_allergy boolean DEFAULT false,
+    frequent_ear_infections boolean DEFAULT false,
+    frequent_sinus_infections boolean DEFAULT false,
+    frequent_upper_respiratory_infections boolean DEFAULT false,
+    bronchitis boolean DEFAULT false,
+    sleep_apnea boolean DEFAULT false,
+    tired_a_lot_of_the_time boolean DEFAULT false,
+    cant_fall_asleep boolean DEFAULT false,
+    neurological_symptoms boolean DEFAULT false,
+    sensitivity_to_stimuli boolean DEFAULT false,
+    bulls_eye_rash boolean DEFAULT false,
+    sweating_headache_cognitive boolean DEFAULT false,
+    other_conditions text,
+    food_related_conditions text,
+    appendix_removed boolean DEFAULT false,
+    health_triggers text,
+    desired_changes text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_medical_information FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_medical_informations_user_id ON medical_informations (user_id);
+
+CREATE TABLE IF NOT EXISTS caregivers (
+    id bigserial,
+    user_id bigint NOT NULL,
+    email text NOT NULL,
+    phone_number text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_caregivers FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_caregivers_user_id ON caregivers (user_id);
+CREATE INDEX IF NOT EXISTS idx_caregivers_email ON caregivers (email);
+CREATE INDEX IF NOT EXISTS idx_caregivers_phone_number ON caregivers (phone_number);
+
+CREATE TABLE IF NOT EXISTS emergency_contacts (
+    id bigserial,
+    user_id bigint NOT NULL,
+    first_name text,
+    last_name text,
+    phone_number text,
+    email text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_emergency_contacts FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_emergency_contacts_user_id ON emergency_contacts (user_id);
+
+CREATE TABLE IF NOT EXISTS medical_events (
+    id bigserial,
+    user_id bigint NOT NULL,
+    age bigint,
+    description text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_medical_events FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_medical_events_user_id ON medical_events (user_id);
+
+CREATE TABLE IF NOT EXISTS frequent_foods (
+    id bigserial,
+    user_id bigint NOT NULL,
+    food_name text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_frequent_foods FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_frequent_foods_user_id ON frequent_foods (user_id);
+
+CREATE TABLE IF NOT EXISTS allergies (
+    id bigserial,
+    user_id bigint NOT NULL,
+    allergy_name text,
+    reaction text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_allergies FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_allergies_user_id ON allergies (user_id);
+
+CREATE TABLE IF NOT EXISTS medications (
+    id bigserial,
+    user_id bigint NOT NULL,
+    name text,
+    dosage text,
+    start_date timestamptz,
+    end_date timestamptz,
+    current boolean,
+    side_effects text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_medications FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_medications_user_id ON medications (user_id);
+
+CREATE TABLE IF NOT EXISTS dietary_supplements (
+    id bigserial,
+    user_id bigint NOT NULL,
+    name text,
+    dosage text,
+    start_date timestamptz,
+    end_date timestamptz,
+    current boolean,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_dietary_supplements FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_dietary_supplements_user_id ON dietary_supplements (user_id);
+
+CREATE TABLE IF NOT EXISTS tracking_periods (
+    id bigserial,
+    user_id bigint NOT NULL,
+    start_date timestamptz NOT NULL,
+    end_date timestamptz NOT NULL,
+    is_completed boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_tracking_periods_user_id ON tracking_periods (user_id);
+
+CREATE TABLE IF NOT EXISTS meal_entries (
+    id bigserial,
+    user_id bigint NOT NULL,
+    tracking_period_id bigint NOT NULL,
+    tracking_day bigint NOT NULL,
+    meal_type text NOT NULL,
+    meal_time text NOT NULL,
+    meal_duration text NOT NULL,
+    notes text,
+    portion_size text NOT NULL,
+    is_completed boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_meal_entries_user_id ON meal_entries (user_id);
+CREATE INDEX IF NOT EXISTS idx_meal_entries_tracking_period_id ON meal_entries (tracking_period_id);
+
+CREATE TABLE IF NOT EXISTS food_items (
+    id bigserial,
+    name text NOT NULL,
+    category text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_food_items_name ON food_items (name);
+
+CREATE TABLE IF NOT EXISTS meal_foods (
+    id bigserial,
+    meal_entry_id bigint NOT NULL,
+    food_item_id bigint NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_meal_foods_meal_entry_id ON meal_foods (meal_entry_id);
+
+CREATE TABLE IF NOT EXISTS custom_foods (
+    id bigserial,
+    meal_entry_id bigint NOT NULL,
+    name text NOT NULL,
+    portion text NOT NULL,
+    preparation text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_custom_foods_meal_entry_id ON custom_foods (meal_entry_id);
+
+CREATE TABLE IF NOT EXISTS symptoms (
+    id bigserial,
+    meal_entry_id bigint NOT NULL,
+    symptom_type text NOT NULL,
+    severity bigint NOT NULL,
+    is_overnight boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_symptoms_meal_entry_id ON symptoms (meal_entry_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reintroduction_steps;
+DROP TABLE IF EXISTS elimination_restrictions;
+DROP TABLE IF EXISTS elimination_plans;
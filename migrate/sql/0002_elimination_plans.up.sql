@@ -0,0 +1,47 @@
+CREATE TABLE elimination_plans (
+    id bigserial,
+    user_id bigint NOT NULL,
+    tracking_period_id bigint,
+    name text,
+    start_date timestamptz NOT NULL,
+    reintroduction_date timestamptz NOT NULL,
+    end_date timestamptz NOT NULL,
+    notes text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_elimination_plans_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_elimination_plans_user_id ON elimination_plans (user_id);
+CREATE INDEX idx_elimination_plans_tracking_period_id ON elimination_plans (tracking_period_id);
+
+CREATE TABLE elimination_restrictions (
+    id bigserial,
+    plan_id bigint NOT NULL,
+    food_item_id bigint,
+    category text,
+    reason text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_elimination_restrictions_plan FOREIGN KEY (plan_id) REFERENCES elimination_plans (id)
+);
+CREATE INDEX idx_elimination_restrictions_plan_id ON elimination_restrictions (plan_id);
+CREATE INDEX idx_elimination_restrictions_food_item_id ON elimination_restrictions (food_item_id);
+
+CREATE TABLE reintroduction_steps (
+    id bigserial,
+    plan_id bigint NOT NULL,
+    food_item_id bigint,
+    category text,
+    start_date timestamptz NOT NULL,
+    end_date timestamptz NOT NULL,
+    outcome text DEFAULT 'pending',
+    notes text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_reintroduction_steps_plan FOREIGN KEY (plan_id) REFERENCES elimination_plans (id)
+);
+CREATE INDEX idx_reintroduction_steps_plan_id ON reintroduction_steps (plan_id);
+CREATE INDEX idx_reintroduction_steps_food_item_id ON reintroduction_steps (food_item_id);
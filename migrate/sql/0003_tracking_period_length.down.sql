@@ -0,0 +1 @@
+ALTER TABLE tracking_periods DROP COLUMN IF EXISTS length_days;
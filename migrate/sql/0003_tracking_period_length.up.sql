@@ -0,0 +1,7 @@
+ALTER TABLE tracking_periods ADD COLUMN length_days bigint NOT NULL DEFAULT 5;
+
+-- Existing periods were all created as 5-day periods, but derive the length
+-- from the stored dates in case any were adjusted by hand.
+UPDATE tracking_periods
+SET length_days = GREATEST(1, end_date::date - start_date::date)
+WHERE end_date > start_date;
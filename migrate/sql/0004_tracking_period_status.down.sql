@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tracking_periods_open_end_date;
+ALTER TABLE tracking_periods DROP COLUMN IF EXISTS status_reason;
+ALTER TABLE tracking_periods DROP COLUMN IF EXISTS status;
@@ -0,0 +1,6 @@
+ALTER TABLE tracking_periods ADD COLUMN status text NOT NULL DEFAULT 'active';
+ALTER TABLE tracking_periods ADD COLUMN status_reason text;
+
+UPDATE tracking_periods SET status = 'completed' WHERE is_completed;
+
+CREATE INDEX idx_tracking_periods_open_end_date ON tracking_periods (end_date) WHERE NOT is_completed;
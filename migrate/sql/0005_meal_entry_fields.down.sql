@@ -0,0 +1,23 @@
+-- The original meal type spellings are not restored.
+DROP INDEX IF EXISTS idx_meal_entries_details;
+ALTER TABLE meal_entries DROP CONSTRAINT IF EXISTS meal_entries_meal_type_check;
+
+ALTER TABLE meal_entries ADD COLUMN meal_time_text text;
+ALTER TABLE meal_entries ADD COLUMN meal_duration_text text;
+ALTER TABLE meal_entries ADD COLUMN portion_size text;
+
+UPDATE meal_entries
+SET meal_time_text = to_char(meal_time AT TIME ZONE 'UTC', 'HH24:MI'),
+    meal_duration_text = (meal_duration / 60000000000) || 'm',
+    portion_size = portion_quantity || ' ' || portion_unit;
+
+ALTER TABLE meal_entries DROP COLUMN meal_time;
+ALTER TABLE meal_entries DROP COLUMN meal_duration;
+ALTER TABLE meal_entries DROP COLUMN portion_quantity;
+ALTER TABLE meal_entries DROP COLUMN portion_unit;
+
+ALTER TABLE meal_entries RENAME COLUMN meal_time_text TO meal_time;
+ALTER TABLE meal_entries RENAME COLUMN meal_duration_text TO meal_duration;
+ALTER TABLE meal_entries ALTER COLUMN meal_time SET NOT NULL;
+ALTER TABLE meal_entries ALTER COLUMN meal_duration SET NOT NULL;
+ALTER TABLE meal_entries ALTER COLUMN portion_size SET NOT NULL;
@@ -0,0 +1,171 @@
+-- Meal times, durations and portions that cannot be normalized are kept by
+-- appending the original value to the entry's notes.
+
+-- Meal types: map the spellings in data.ParseMealType to the canonical
+-- names. No entry is guessed at, merged or deleted: if any meal type is not
+-- one of those spellings, or two entries end up with the same user, period,
+-- day and meal type, the migration fails listing their IDs so they can be
+-- fixed by hand before migrating again.
+UPDATE meal_entries
+SET meal_type = CASE lower(btrim(meal_type))
+    WHEN 'breakfast' THEN 'Breakfast'
+    WHEN 'bf' THEN 'Breakfast'
+    WHEN 'brekkie' THEN 'Breakfast'
+    WHEN 'lunch' THEN 'Lunch'
+    WHEN 'snack' THEN 'Snack'
+    WHEN 'snacks' THEN 'Snack'
+    WHEN 'dinner' THEN 'Dinner'
+    WHEN 'supper' THEN 'Dinner'
+    ELSE meal_type
+END;
+
+DO $$
+DECLARE
+    unknown text;
+    duplicates text;
+BEGIN
+    SELECT string_agg(format('%s (%L)', id, meal_type), ', ' ORDER BY id)
+    INTO unknown
+    FROM meal_entries
+    WHERE meal_type NOT IN ('Breakfast', 'Lunch', 'Snack', 'Dinner');
+
+    IF unknown IS NOT NULL THEN
+        RAISE EXCEPTION 'meal entries with an unrecognized meal type: %', unknown
+            USING HINT = 'Set their meal_type to Breakfast, Lunch, Snack or Dinner, then migrate again.';
+    END IF;
+
+    SELECT string_agg(ids, '; ' ORDER BY ids)
+    INTO duplicates
+    FROM (
+        SELECT string_agg(id::text, ', ' ORDER BY id) AS ids
+        FROM meal_entries
+        GROUP BY user_id, tracking_period_id, tracking_day, meal_type
+        HAVING count(*) > 1
+    ) groups;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'meal entries sharing a user, tracking period, day and meal type: %', duplicates
+            USING HINT = 'Merge, delete or retype the entries in each group, then migrate again.';
+    END IF;
+END
+$$;
+
+ALTER TABLE meal_entries ADD CONSTRAINT meal_entries_meal_type_check
+    CHECK (meal_type IN ('Breakfast', 'Lunch', 'Snack', 'Dinner'));
+CREATE UNIQUE INDEX idx_meal_entries_details
+    ON meal_entries (user_id, tracking_period_id, tracking_day, meal_type);
+
+-- Meal times: clock times such as "08:30" or "7 pm" become a timestamp on
+-- the entry's tracking day, in UTC. Unparseable times fall back to the start
+-- of the day.
+ALTER TABLE meal_entries ADD COLUMN meal_at timestamptz;
+
+UPDATE meal_entries SET meal_time = btrim(meal_time);
+
+UPDATE meal_entries
+SET notes = concat_ws(E'\n', NULLIF(notes, ''), 'Meal time: ' || meal_time)
+WHERE meal_time !~ '^([01]?\d|2[0-3]):[0-5]\d(:[0-5]\d)?$'
+  AND meal_time !~* '^(0?[1-9]|1[0-2])(:[0-5]\d)?\s*(am|pm)$'
+  AND meal_time !~ '^\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}';
+
+UPDATE meal_entries e
+SET meal_at = CASE
+    WHEN e.meal_time ~ '^\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}' THEN e.meal_time::timestamptz
+    ELSE (
+        (p.start_date AT TIME ZONE 'UTC')::date + (e.tracking_day::int - 1)
+        + CASE
+            WHEN e.meal_time ~ '^([01]?\d|2[0-3]):[0-5]\d(:[0-5]\d)?$' THEN make_interval(
+                hours => split_part(e.meal_time, ':', 1)::int,
+                mins => split_part(e.meal_time, ':', 2)::int
+            )
+            WHEN e.meal_time ~* '^(0?[1-9]|1[0-2])(:[0-5]\d)?\s*(am|pm)$' THEN make_interval(
+                hours => substring(e.meal_time from '^\d+')::int % 12
+                    + CASE WHEN e.meal_time ILIKE '%pm' THEN 12 ELSE 0 END,
+                mins => COALESCE(substring(e.meal_time from ':(\d{2})')::int, 0)
+            )
+            ELSE interval '0'
+        END
+    ) AT TIME ZONE 'UTC'
+END
+FROM tracking_periods p
+WHERE p.id = e.tracking_period_id;
+
+-- Entries whose tracking period no longer exists.
+UPDATE meal_entries SET meal_at = COALESCE(created_at, now()) WHERE meal_at IS NULL;
+
+ALTER TABLE meal_entries DROP COLUMN meal_time;
+ALTER TABLE meal_entries RENAME COLUMN meal_at TO meal_time;
+ALTER TABLE meal_entries ALTER COLUMN meal_time SET NOT NULL;
+
+-- Meal durations: "15m", "20 min", "1.5 hours", "1h30m" or a bare number of
+-- minutes become nanoseconds, matching time.Duration.
+ALTER TABLE meal_entries ADD COLUMN meal_duration_ns bigint;
+
+UPDATE meal_entries SET meal_duration = lower(btrim(meal_duration));
+
+UPDATE meal_entries
+SET meal_duration_ns = CASE
+    WHEN meal_duration ~ '^\d+$' THEN meal_duration::bigint * 60000000000
+    WHEN meal_duration <> ''
+        AND meal_duration ~ '^(\d+(\.\d+)?\s*h(ours?|rs?)?)?\s*(\d+\s*m(in(ute)?s?)?)?$'
+    THEN round((
+        COALESCE(substring(meal_duration from '^(\d+(?:\.\d+)?)\s*h')::numeric, 0) * 3600
+        + COALESCE(substring(meal_duration from '(\d+)\s*m')::numeric, 0) * 60
+    ) * 1000000000)::bigint
+END;
+
+UPDATE meal_entries
+SET notes = concat_ws(E'\n', NULLIF(notes, ''), 'Meal duration: ' || meal_duration),
+    meal_duration_ns = 0
+WHERE meal_duration_ns IS NULL;
+
+ALTER TABLE meal_entries DROP COLUMN meal_duration;
+ALTER TABLE meal_entries RENAME COLUMN meal_duration_ns TO meal_duration;
+ALTER TABLE meal_entries ALTER COLUMN meal_duration SET NOT NULL;
+
+-- Portion sizes: "1 bowl", "250g" or "1/2 cup" are split into a quantity
+-- and one of the units in data.PortionUnits. A bare number is a number of
+-- servings.
+ALTER TABLE meal_entries ADD COLUMN portion_quantity double precision;
+ALTER TABLE meal_entries ADD COLUMN portion_unit text;
+
+UPDATE meal_entries e
+SET portion_quantity = CASE
+        WHEN parsed.quantity LIKE '%/%' THEN split_part(parsed.quantity, '/', 1)::float8
+            / NULLIF(split_part(parsed.quantity, '/', 2)::float8, 0)
+        ELSE parsed.quantity::float8
+    END,
+    portion_unit = CASE
+        WHEN parsed.unit IN ('', 'serving', 'servings', 'portion', 'portions') THEN 'serving'
+        WHEN parsed.unit IN ('g', 'gram', 'grams', 'gr') THEN 'g'
+        WHEN parsed.unit IN ('kg', 'kilogram', 'kilograms') THEN 'kg'
+        WHEN parsed.unit IN ('ml', 'milliliter', 'milliliters', 'millilitre', 'millilitres') THEN 'ml'
+        WHEN parsed.unit IN ('l', 'liter', 'liters', 'litre', 'litres') THEN 'l'
+        WHEN parsed.unit IN ('oz', 'ounce', 'ounces') THEN 'oz'
+        WHEN parsed.unit IN ('cup', 'cups') THEN 'cup'
+        WHEN parsed.unit IN ('tbsp', 'tablespoon', 'tablespoons') THEN 'tbsp'
+        WHEN parsed.unit IN ('tsp', 'teaspoon', 'teaspoons') THEN 'tsp'
+        WHEN parsed.unit IN ('piece', 'pieces', 'pc', 'pcs') THEN 'piece'
+        WHEN parsed.unit IN ('slice', 'slices') THEN 'slice'
+        WHEN parsed.unit IN ('bowl', 'bowls') THEN 'bowl'
+        WHEN parsed.unit IN ('plate', 'plates') THEN 'plate'
+        WHEN parsed.unit IN ('handful', 'handfuls') THEN 'handful'
+    END
+FROM (
+    SELECT
+        id,
+        substring(portion_size from '^\s*(\d+/\d+|\d+(?:\.\d+)?)') AS quantity,
+        lower(btrim(regexp_replace(portion_size, '^\s*(\d+/\d+|\d+(\.\d+)?)', ''))) AS unit
+    FROM meal_entries
+) parsed
+WHERE parsed.id = e.id;
+
+UPDATE meal_entries
+SET notes = concat_ws(E'\n', NULLIF(notes, ''), 'Portion size: ' || portion_size),
+    portion_quantity = 1,
+    portion_unit = 'serving'
+WHERE portion_quantity IS NULL OR portion_unit IS NULL;
+
+ALTER TABLE meal_entries DROP COLUMN portion_size;
+ALTER TABLE meal_entries ALTER COLUMN portion_quantity SET NOT NULL;
+ALTER TABLE meal_entries ALTER COLUMN portion_unit SET NOT NULL;
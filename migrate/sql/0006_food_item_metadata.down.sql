@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_food_items_allergens;
+
+ALTER TABLE food_items DROP COLUMN IF EXISTS allergens;
+ALTER TABLE food_items DROP COLUMN IF EXISTS fodmaps;
+ALTER TABLE food_items DROP COLUMN IF EXISTS high_histamine;
+ALTER TABLE food_items DROP COLUMN IF EXISTS high_lectin;
+ALTER TABLE food_items DROP COLUMN IF EXISTS high_oxalate;
+ALTER TABLE food_items DROP COLUMN IF EXISTS calories_kcal;
+ALTER TABLE food_items DROP COLUMN IF EXISTS protein_g;
+ALTER TABLE food_items DROP COLUMN IF EXISTS carbohydrate_g;
+ALTER TABLE food_items DROP COLUMN IF EXISTS fat_g;
+ALTER TABLE food_items DROP COLUMN IF EXISTS fiber_g;
@@ -0,0 +1,14 @@
+-- allergens and fodmaps are bit masks of data.Allergens and data.FODMAPs.
+-- Nutrient amounts are per 100 g.
+ALTER TABLE food_items ADD COLUMN allergens integer NOT NULL DEFAULT 0;
+ALTER TABLE food_items ADD COLUMN fodmaps integer NOT NULL DEFAULT 0;
+ALTER TABLE food_items ADD COLUMN high_histamine boolean NOT NULL DEFAULT false;
+ALTER TABLE food_items ADD COLUMN high_lectin boolean NOT NULL DEFAULT false;
+ALTER TABLE food_items ADD COLUMN high_oxalate boolean NOT NULL DEFAULT false;
+ALTER TABLE food_items ADD COLUMN calories_kcal double precision NOT NULL DEFAULT 0;
+ALTER TABLE food_items ADD COLUMN protein_g double precision NOT NULL DEFAULT 0;
+ALTER TABLE food_items ADD COLUMN carbohydrate_g double precision NOT NULL DEFAULT 0;
+ALTER TABLE food_items ADD COLUMN fat_g double precision NOT NULL DEFAULT 0;
+ALTER TABLE food_items ADD COLUMN fiber_g double precision NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_food_items_allergens ON food_items (allergens) WHERE allergens <> 0;
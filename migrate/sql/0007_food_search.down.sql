@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_custom_foods_name_trgm;
+DROP INDEX IF EXISTS idx_food_items_synonyms_trgm;
+DROP INDEX IF EXISTS idx_food_items_name_trgm;
+
+ALTER TABLE food_items DROP COLUMN IF EXISTS synonyms;
+
+-- The pg_trgm extension is left installed, as other objects may use it.
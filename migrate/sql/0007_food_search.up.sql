@@ -0,0 +1,10 @@
+-- pg_trgm backs the fuzzy food search; it is a trusted extension, so the
+-- database owner can create it.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- synonyms is a comma-separated list of other names of the food.
+ALTER TABLE food_items ADD COLUMN synonyms text NOT NULL DEFAULT '';
+
+CREATE INDEX idx_food_items_name_trgm ON food_items USING gin (lower(name) gin_trgm_ops);
+CREATE INDEX idx_food_items_synonyms_trgm ON food_items USING gin (lower(synonyms) gin_trgm_ops);
+CREATE INDEX idx_custom_foods_name_trgm ON custom_foods USING gin (lower(name) gin_trgm_ops);
@@ -0,0 +1,9 @@
+-- Return the symptoms moved to a legacy type to the type they were
+-- recorded under.
+UPDATE symptoms s
+SET symptom_type = left(s.symptom_type, -length(' (legacy)'))
+FROM symptom_types st
+WHERE lower(st.name) = lower(s.symptom_type)
+  AND st.description = 'Severities recorded before the symptom catalog, on the old 0-180 scale.';
+
+DROP TABLE IF EXISTS symptom_types;
@@ -0,0 +1,91 @@
+-- The symptom catalog. symptoms.symptom_type holds a catalog name, matched
+-- ignoring case, and symptoms.severity lies on that type's scale.
+-- severity_labels names points of the scale as value=label pairs.
+CREATE TABLE symptom_types (
+    id bigserial,
+    name text NOT NULL,
+    body_system text NOT NULL,
+    description text,
+    severity_min integer NOT NULL DEFAULT 0,
+    severity_max integer NOT NULL DEFAULT 10,
+    severity_labels text NOT NULL DEFAULT '',
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT chk_symptom_types_body_system CHECK (body_system IN (
+        'digestive', 'skin', 'respiratory', 'neurological', 'musculoskeletal',
+        'cardiovascular', 'urinary', 'mood', 'general'
+    )),
+    CONSTRAINT chk_symptom_types_severity CHECK (severity_max > severity_min)
+);
+CREATE UNIQUE INDEX idx_symptom_types_name ON symptom_types (lower(name));
+CREATE INDEX idx_symptom_types_body_system ON symptom_types (body_system);
+
+INSERT INTO symptom_types (name, body_system, severity_min, severity_max, severity_labels, created_at, updated_at)
+SELECT t.name, t.body_system, t.severity_min, t.severity_max, t.severity_labels, now(), now()
+FROM (VALUES
+    ('Abdominal pain', 'digestive', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Bloating', 'digestive', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Constipation', 'digestive', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Diarrhea', 'digestive', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Gas', 'digestive', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Heartburn', 'digestive', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Nausea', 'digestive', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Stool form', 'digestive', 1, 7,
+        '1=separate hard lumps,2=lumpy,3=cracked,4=smooth,5=soft blobs,6=mushy,7=watery'),
+    ('Eczema flare', 'skin', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Flushing', 'skin', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Hives', 'skin', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Itching', 'skin', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Rash', 'skin', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Congestion', 'respiratory', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Runny nose', 'respiratory', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Shortness of breath', 'respiratory', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Wheezing', 'respiratory', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Brain fog', 'neurological', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Dizziness', 'neurological', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Headache', 'neurological', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Migraine', 'neurological', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Joint pain', 'musculoskeletal', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Muscle pain', 'musculoskeletal', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Palpitations', 'cardiovascular', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Urinary urgency', 'urinary', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Anxiety', 'mood', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Irritability', 'mood', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Low mood', 'mood', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Fatigue', 'general', 0, 10, '0=none,1=mild,4=moderate,7=severe'),
+    ('Insomnia', 'general', 0, 10, '0=none,1=mild,4=moderate,7=severe')
+) AS t (name, body_system, severity_min, severity_max, severity_labels);
+
+-- Keep the symptoms already recorded valid without touching the scales
+-- above: every other type they use joins the catalog under general, and
+-- severities outside their type's scale, recorded on the old 0-180 scale,
+-- move to a "<name> (legacy)" type holding that scale.
+UPDATE symptoms SET symptom_type = trim(symptom_type) WHERE symptom_type <> trim(symptom_type);
+
+INSERT INTO symptom_types (name, body_system, created_at, updated_at)
+SELECT min(symptom_type), 'general', now(), now()
+FROM symptoms
+WHERE symptom_type <> ''
+GROUP BY lower(symptom_type)
+ON CONFLICT (lower(name)) DO NOTHING;
+
+CREATE TEMPORARY TABLE legacy_symptoms ON COMMIT DROP AS
+SELECT s.id, st.name, st.body_system, s.severity
+FROM symptoms s
+JOIN symptom_types st ON lower(st.name) = lower(s.symptom_type)
+WHERE s.severity < st.severity_min OR s.severity > st.severity_max;
+
+INSERT INTO symptom_types (name, body_system, description, severity_min, severity_max, created_at, updated_at)
+SELECT name || ' (legacy)', body_system, 'Severities recorded before the symptom catalog, on the old 0-180 scale.',
+    least(0, min(severity)), greatest(180, max(severity)), now(), now()
+FROM legacy_symptoms
+GROUP BY name, body_system
+ON CONFLICT (lower(name)) DO UPDATE
+SET severity_min = least(symptom_types.severity_min, excluded.severity_min),
+    severity_max = greatest(symptom_types.severity_max, excluded.severity_max);
+
+UPDATE symptoms s
+SET symptom_type = s.symptom_type || ' (legacy)'
+FROM legacy_symptoms l
+WHERE s.id = l.id;
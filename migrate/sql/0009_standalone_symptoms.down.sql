@@ -0,0 +1,9 @@
+-- Standalone symptoms cannot be kept without a meal and are deleted.
+DROP TABLE IF EXISTS symptom_attributions;
+
+DELETE FROM symptoms WHERE meal_entry_id = 0;
+DROP INDEX IF EXISTS idx_symptoms_standalone;
+ALTER TABLE symptoms DROP COLUMN IF EXISTS occurred_at;
+ALTER TABLE symptoms DROP COLUMN IF EXISTS tracking_period_id;
+ALTER TABLE symptoms DROP COLUMN IF EXISTS user_id;
+ALTER TABLE symptoms ALTER COLUMN meal_entry_id DROP DEFAULT;
@@ -0,0 +1,23 @@
+-- A standalone symptom has meal_entry_id 0 and records its own user,
+-- tracking period and occurred_at; meal symptoms leave those at their
+-- defaults. symptom_attributions links standalone symptoms to the meals
+-- eaten before them, lag being nanoseconds from the start of the meal.
+ALTER TABLE symptoms ALTER COLUMN meal_entry_id SET DEFAULT 0;
+ALTER TABLE symptoms ADD COLUMN user_id bigint NOT NULL DEFAULT 0;
+ALTER TABLE symptoms ADD COLUMN tracking_period_id bigint NOT NULL DEFAULT 0;
+ALTER TABLE symptoms ADD COLUMN occurred_at timestamptz;
+
+CREATE INDEX idx_symptoms_standalone ON symptoms (user_id, tracking_period_id, occurred_at)
+    WHERE meal_entry_id = 0;
+
+CREATE TABLE symptom_attributions (
+    id bigserial,
+    symptom_id bigint NOT NULL,
+    meal_entry_id bigint NOT NULL,
+    lag bigint NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX idx_symptom_attributions_link ON symptom_attributions (symptom_id, meal_entry_id);
+CREATE INDEX idx_symptom_attributions_meal_entry_id ON symptom_attributions (meal_entry_id);
@@ -0,0 +1,15 @@
+ALTER TABLE medications DROP COLUMN IF EXISTS dose_amount;
+ALTER TABLE medications DROP COLUMN IF EXISTS dose_unit;
+ALTER TABLE medications DROP COLUMN IF EXISTS dose_frequency;
+ALTER TABLE medications DROP COLUMN IF EXISTS dose_period;
+ALTER TABLE medications DROP COLUMN IF EXISTS dose_period_unit;
+ALTER TABLE medications DROP COLUMN IF EXISTS dose_route;
+ALTER TABLE medications DROP COLUMN IF EXISTS dose_as_needed;
+
+ALTER TABLE dietary_supplements DROP COLUMN IF EXISTS dose_amount;
+ALTER TABLE dietary_supplements DROP COLUMN IF EXISTS dose_unit;
+ALTER TABLE dietary_supplements DROP COLUMN IF EXISTS dose_frequency;
+ALTER TABLE dietary_supplements DROP COLUMN IF EXISTS dose_period;
+ALTER TABLE dietary_supplements DROP COLUMN IF EXISTS dose_period_unit;
+ALTER TABLE dietary_supplements DROP COLUMN IF EXISTS dose_route;
+ALTER TABLE dietary_supplements DROP COLUMN IF EXISTS dose_as_needed;
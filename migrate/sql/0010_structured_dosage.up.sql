@@ -0,0 +1,18 @@
+-- The dose_* columns hold data.Dose, the structured form of the free-text
+-- dosage. Units are UCUM codes; zero values and empty strings are unknown.
+-- Existing rows are filled in by the backfill-dosages command.
+ALTER TABLE medications ADD COLUMN dose_amount double precision NOT NULL DEFAULT 0;
+ALTER TABLE medications ADD COLUMN dose_unit text NOT NULL DEFAULT '';
+ALTER TABLE medications ADD COLUMN dose_frequency integer NOT NULL DEFAULT 0;
+ALTER TABLE medications ADD COLUMN dose_period double precision NOT NULL DEFAULT 0;
+ALTER TABLE medications ADD COLUMN dose_period_unit text NOT NULL DEFAULT '';
+ALTER TABLE medications ADD COLUMN dose_route text NOT NULL DEFAULT '';
+ALTER TABLE medications ADD COLUMN dose_as_needed boolean NOT NULL DEFAULT false;
+
+ALTER TABLE dietary_supplements ADD COLUMN dose_amount double precision NOT NULL DEFAULT 0;
+ALTER TABLE dietary_supplements ADD COLUMN dose_unit text NOT NULL DEFAULT '';
+ALTER TABLE dietary_supplements ADD COLUMN dose_frequency integer NOT NULL DEFAULT 0;
+ALTER TABLE dietary_supplements ADD COLUMN dose_period double precision NOT NULL DEFAULT 0;
+ALTER TABLE dietary_supplements ADD COLUMN dose_period_unit text NOT NULL DEFAULT '';
+ALTER TABLE dietary_supplements ADD COLUMN dose_route text NOT NULL DEFAULT '';
+ALTER TABLE dietary_supplements ADD COLUMN dose_as_needed boolean NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS dose_logs;
+DROP TABLE IF EXISTS medication_schedules;
@@ -0,0 +1,33 @@
+-- medication_schedules says when the doses of a medication are due;
+-- times_of_day is a comma-separated list of HH:MM times. dose_logs records
+-- each dose taken or skipped. scheduled_at is the zero time for doses taken
+-- outside the schedule, and taken_at for skipped doses.
+CREATE TABLE medication_schedules (
+    id bigserial,
+    user_id bigint NOT NULL,
+    medication_id bigint NOT NULL,
+    times_of_day text NOT NULL,
+    every_days integer NOT NULL DEFAULT 1,
+    start_date timestamptz NOT NULL,
+    end_date timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX idx_medication_schedules_medication_id ON medication_schedules (medication_id);
+CREATE INDEX idx_medication_schedules_user_id ON medication_schedules (user_id);
+
+CREATE TABLE dose_logs (
+    id bigserial,
+    user_id bigint NOT NULL,
+    medication_id bigint NOT NULL,
+    scheduled_at timestamptz,
+    taken_at timestamptz,
+    status text NOT NULL,
+    notes text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_dose_logs_user_id ON dose_logs (user_id);
+CREATE INDEX idx_dose_logs_medication_id ON dose_logs (medication_id, scheduled_at);
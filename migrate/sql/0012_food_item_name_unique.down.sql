@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_food_items_name;
+CREATE INDEX idx_food_items_name ON food_items (name);
@@ -0,0 +1,24 @@
+-- Food items are looked up by name ignoring case, so the name must be
+-- unique ignoring case. Items duplicating an older item's name are merged
+-- into it first.
+CREATE TEMPORARY TABLE food_item_duplicates ON COMMIT DROP AS
+SELECT f.id, k.keep_id
+FROM food_items f
+JOIN (
+    SELECT lower(name) AS name, min(id) AS keep_id
+    FROM food_items
+    GROUP BY lower(name)
+    HAVING count(*) > 1
+) k ON lower(f.name) = k.name
+WHERE f.id <> k.keep_id;
+
+UPDATE meal_foods t SET food_item_id = d.keep_id
+FROM food_item_duplicates d WHERE t.food_item_id = d.id;
+UPDATE elimination_restrictions t SET food_item_id = d.keep_id
+FROM food_item_duplicates d WHERE t.food_item_id = d.id;
+UPDATE reintroduction_steps t SET food_item_id = d.keep_id
+FROM food_item_duplicates d WHERE t.food_item_id = d.id;
+DELETE FROM food_items WHERE id IN (SELECT id FROM food_item_duplicates);
+
+DROP INDEX IF EXISTS idx_food_items_name;
+CREATE UNIQUE INDEX idx_food_items_name ON food_items (lower(name));
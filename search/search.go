@@ -0,0 +1,238 @@
+// Package search ranks food names against what a user typed. It mirrors the
+// trigram similarity of the Postgres pg_trgm extension so that in-process
+// code ranks the same way as the database, without needing the extension.
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Match is how a name matched a query.
+type Match string
+
+const (
+	MatchExact   Match = "exact"
+	MatchPrefix  Match = "prefix"
+	MatchSynonym Match = "synonym"
+	MatchFuzzy   Match = "fuzzy"
+)
+
+// DefaultThreshold is the lowest trigram similarity that counts as a fuzzy
+// match, the same as the pg_trgm default.
+const DefaultThreshold = 0.3
+
+// Scores of the match kinds. A fuzzy match scores its similarity scaled to
+// below prefix matches, and a synonym match a little less than the same
+// match on the name itself.
+const (
+	exactScore     = 1.0
+	prefixScore    = 0.9
+	wordScore      = 0.8
+	fuzzyScale     = 0.7
+	synonymPenalty = 0.95
+)
+
+// Ranker scores names against a query.
+type Ranker struct {
+	// Threshold is the lowest similarity that counts as a fuzzy match.
+	Threshold float64
+}
+
+var DefaultRanker = Ranker{Threshold: DefaultThreshold}
+
+// Score scores name against query, trying each of the synonyms as well.
+// It returns zero if nothing matches.
+func (ranker Ranker) Score(query, name string, synonyms ...string) (float64, Match) {
+	q := Normalize(query)
+	if q == "" {
+		return 0, ""
+	}
+
+	score, match := ranker.score(q, Normalize(name))
+	for _, synonym := range synonyms {
+		synonymScore, _ := ranker.score(q, Normalize(synonym))
+		synonymScore *= synonymPenalty
+		if synonymScore > score {
+			score, match = synonymScore, MatchSynonym
+		}
+	}
+	return score, match
+}
+
+func (ranker Ranker) score(query, name string) (float64, Match) {
+	if name == "" {
+		return 0, ""
+	}
+	if query == name {
+		return exactScore, MatchExact
+	}
+	// Shorter names rank first among prefix matches, so "rice" comes
+	// before "rice noodles" for "ric".
+	shortness := float64(len(query)) / float64(len(name))
+	if strings.HasPrefix(name, query) {
+		return prefixScore + 0.05*shortness, MatchPrefix
+	}
+	if wordPrefixes(strings.Fields(query), strings.Fields(name)) {
+		return wordScore + 0.05*shortness, MatchPrefix
+	}
+
+	similarity := max(Similarity(query, name), WordSimilarity(query, name))
+	if similarity < ranker.Threshold {
+		return 0, ""
+	}
+	return fuzzyScale * similarity, MatchFuzzy
+}
+
+// wordPrefixes reports whether every query word is a prefix of a different
+// word of the name, e.g. "pot sw" for "sweet potato".
+func wordPrefixes(query, name []string) bool {
+	used := make([]bool, len(name))
+	for _, word := range query {
+		found := false
+		for i, candidate := range name {
+			if !used[i] && strings.HasPrefix(candidate, word) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Normalize lower-cases s, replaces punctuation with spaces and reduces
+// plural words to their singular, so "Sweet Potatoes" and "sweet-potato"
+// both become "sweet potato".
+func Normalize(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = singular(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// ContainsPhrase reports whether the normalized words of s contain those of
+// phrase as a consecutive run, so "Peanut butter" contains "peanuts" but
+// "Pineapple" does not contain "apple".
+func ContainsPhrase(s, phrase string) bool {
+	words := strings.Fields(Normalize(phrase))
+	if len(words) == 0 {
+		return false
+	}
+	fields := strings.Fields(Normalize(s))
+	for i := 0; i+len(words) <= len(fields); i++ {
+		if slices.Equal(fields[i:i+len(words)], words) {
+			return true
+		}
+	}
+	return false
+}
+
+func singular(word string) string {
+	switch {
+	case len(word) <= 3:
+		return word
+	case strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "oes"),
+		strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "sses"),
+		strings.HasSuffix(word, "xes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// Trigrams returns the set of trigrams of s the way pg_trgm extracts them:
+// each word is padded with two spaces in front and one behind.
+func Trigrams(s string) map[string]bool {
+	trigrams := make(map[string]bool)
+	for _, word := range strings.Fields(Normalize(s)) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			trigrams[string(padded[i:i+3])] = true
+		}
+	}
+	return trigrams
+}
+
+// Similarity is the share of trigrams a and b have in common, between 0 and
+// 1, like pg_trgm's similarity.
+func Similarity(a, b string) float64 {
+	return jaccard(Trigrams(a), Trigrams(b))
+}
+
+// WordSimilarity is the greatest similarity between a and any run of whole
+// words of b, approximating pg_trgm's word_similarity. It lets "potato"
+// match "sweet potato" well.
+func WordSimilarity(a, b string) float64 {
+	query := Trigrams(a)
+	words := strings.Fields(Normalize(b))
+
+	best := 0.0
+	for start := range words {
+		for end := start + 1; end <= len(words); end++ {
+			similarity := jaccard(query, Trigrams(strings.Join(words[start:end], " ")))
+			best = max(best, similarity)
+		}
+	}
+	return best
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range a {
+		if b[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// Result is a scored candidate.
+type Result[T any] struct {
+	Item  T
+	Score float64
+	Match Match
+}
+
+// Rank scores every item against query, using names to get the name and
+// synonyms of an item, and returns the matches from best to worst. Ties
+// keep the order of items. A limit of zero or less returns every match.
+func Rank[T any](
+	ranker Ranker,
+	query string,
+	items []T,
+	names func(item T) (string, []string),
+	limit int,
+) []Result[T] {
+	var results []Result[T]
+	for _, item := range items {
+		name, synonyms := names(item)
+		score, match := ranker.Score(query, name, synonyms...)
+		if score > 0 {
+			results = append(results, Result[T]{Item: item, Score: score, Match: match})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,162 @@
+package search
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"  ", ""},
+		{"Sweet Potatoes", "sweet potato"},
+		{"sweet-potato", "sweet potato"},
+		{"Berries", "berry"},
+		{"Peaches & Cream", "peach cream"},
+		{"Oats", "oat"},
+		{"Peas", "pea"},
+		{"Glass", "glass"},
+		{"Crème Brûlée", "crème brûlée"},
+		{"7-Up", "7 up"},
+	}
+	for _, test := range tests {
+		if got := Normalize(test.in); got != test.want {
+			t.Errorf("Normalize(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestContainsPhrase(t *testing.T) {
+	tests := []struct {
+		s, phrase string
+		want      bool
+	}{
+		{"Peanut butter", "peanuts", true},
+		{"Pineapple", "apple", false},
+		{"Sweet Potato Fries", "sweet potatoes", true},
+		{"Potato sweet", "sweet potato", false},
+		{"Milk", "", false},
+		{"", "milk", false},
+	}
+	for _, test := range tests {
+		if got := ContainsPhrase(test.s, test.phrase); got != test.want {
+			t.Errorf("ContainsPhrase(%q, %q) = %v, want %v", test.s, test.phrase, got, test.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b        string
+		similarity  float64
+		wordSimilar float64
+	}{
+		// Values as computed by pg_trgm for the normalized strings.
+		{"rice", "rice", 1, 1},
+		{"", "rice", 0, 0},
+		{"potato", "sweet potato", 7.0 / 13.0, 1},
+		{"cat", "dog", 0, 0},
+	}
+	for _, test := range tests {
+		if got := Similarity(test.a, test.b); math.Abs(got-test.similarity) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %g, want %g", test.a, test.b, got, test.similarity)
+		}
+		if got := WordSimilarity(test.a, test.b); math.Abs(got-test.wordSimilar) > 1e-9 {
+			t.Errorf("WordSimilarity(%q, %q) = %g, want %g", test.a, test.b, got, test.wordSimilar)
+		}
+	}
+}
+
+func TestRankerScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		food     string
+		synonyms []string
+		want     Match
+	}{
+		{name: "empty query", query: "  ", food: "Rice"},
+		{name: "exact ignoring case and plurals", query: "SWEET POTATOES", food: "Sweet potato", want: MatchExact},
+		{name: "prefix", query: "ric", food: "Rice noodles", want: MatchPrefix},
+		{name: "word prefixes in any order", query: "pot sw", food: "Sweet potato", want: MatchPrefix},
+		{name: "word prefix used once", query: "sw sw", food: "Sweet potato"},
+		{name: "synonym", query: "aubergine", food: "Eggplant", synonyms: []string{"brinjal", "aubergine"}, want: MatchSynonym},
+		{name: "name preferred to an equal synonym", query: "corn", food: "Corn", synonyms: []string{"corn"}, want: MatchExact},
+		{name: "typo", query: "brocoli", food: "Broccoli", want: MatchFuzzy},
+		{name: "unrelated", query: "chicken", food: "Broccoli"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			score, match := DefaultRanker.Score(test.query, test.food, test.synonyms...)
+			if match != test.want {
+				t.Fatalf("got %q with score %g, want %q", match, score, test.want)
+			}
+			if (score > 0) != (test.want != "") || score > 1 {
+				t.Fatalf("got score %g for a %q match", score, match)
+			}
+		})
+	}
+}
+
+func TestRankerScoreOrder(t *testing.T) {
+	// Each name should score strictly higher than the next one for "rice".
+	names := []string{"Rice", "Rice noodles", "Rice cakes with sesame", "Brown rice"}
+
+	previous := math.Inf(1)
+	for _, name := range names {
+		score, _ := DefaultRanker.Score("rice", name)
+		if score >= previous {
+			t.Fatalf("%q scores %g, want less than the %g of the name before it", name, score, previous)
+		}
+		previous = score
+	}
+
+	strict := Ranker{Threshold: 0.9}
+	if score, _ := strict.Score("brocoli", "Broccoli"); score != 0 {
+		t.Fatalf("a typo scores %g above the ranker threshold, want 0", score)
+	}
+}
+
+func TestRank(t *testing.T) {
+	type food struct {
+		id       int
+		name     string
+		synonyms []string
+	}
+	foods := []food{
+		{1, "Brown rice", nil},
+		{2, "Rice", nil},
+		{3, "Broccoli", nil},
+		{4, "White rice", nil},
+		{5, "Risotto", []string{"rice dish"}},
+	}
+	names := func(f food) (string, []string) { return f.name, f.synonyms }
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []int
+	}{
+		// Brown rice and White rice score the same, so they keep their order.
+		{name: "ties keep input order", query: "rice", want: []int{2, 5, 1, 4}},
+		{name: "limit", query: "rice", limit: 2, want: []int{2, 5}},
+		{name: "no matches", query: "chocolate"},
+		{name: "empty query", query: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results := Rank(DefaultRanker, test.query, foods, names, test.limit)
+			if len(results) != len(test.want) {
+				t.Fatalf("got %d results %+v, want ids %v", len(results), results, test.want)
+			}
+			for i, result := range results {
+				if result.Item.id != test.want[i] {
+					t.Fatalf("got %+v, want ids %v", results, test.want)
+				}
+			}
+		})
+	}
+}
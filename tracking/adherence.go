@@ -0,0 +1,216 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// DayAdherence counts the doses of a medication due on one tracking day and
+// what became of them. Missed counts due doses with no log at all.
+type DayAdherence struct {
+	Day       int       `json:"day"`
+	Date      time.Time `json:"date"`
+	Scheduled int       `json:"scheduled"`
+	Taken     int       `json:"taken"`
+	Late      int       `json:"late"`
+	Skipped   int       `json:"skipped"`
+	Missed    int       `json:"missed"`
+}
+
+// MedicationAdherence describes how closely a medication's schedule was
+// followed during a tracking period. Extra counts doses taken that match no
+// scheduled dose, and Adherence is the percentage of scheduled doses taken,
+// on time or late.
+type MedicationAdherence struct {
+	MedicationID int64           `json:"medication_id"`
+	Medication   string          `json:"medication"`
+	Scheduled    int             `json:"scheduled"`
+	Taken        int             `json:"taken"`
+	Late         int             `json:"late"`
+	Skipped      int             `json:"skipped"`
+	Missed       int             `json:"missed"`
+	Extra        int             `json:"extra"`
+	Adherence    float64         `json:"adherence"`
+	Days         []*DayAdherence `json:"days"`
+}
+
+// AdherenceReport describes the adherence of every scheduled medication of
+// a user during a tracking period.
+type AdherenceReport struct {
+	TrackingPeriodID int64                  `json:"tracking_period_id"`
+	UserID           int64                  `json:"user_id"`
+	Medications      []*MedicationAdherence `json:"medications"`
+}
+
+// MissedOn returns the IDs of the medications with doses skipped or missed
+// on the given tracking day, so symptoms on that day can be read with them
+// in mind.
+func (report *AdherenceReport) MissedOn(day int) []int64 {
+	var ids []int64
+	for _, medication := range report.Medications {
+		if day < 1 || day > len(medication.Days) {
+			continue
+		}
+		if days := medication.Days[day-1]; days.Skipped+days.Missed > 0 {
+			ids = append(ids, medication.MedicationID)
+		}
+	}
+	return ids
+}
+
+// Adherence reports, for every medication of the period's user that has a
+// schedule, how many of the doses due during the tracking period were
+// taken, taken late, skipped or never logged. Doses due after Now are not
+// counted yet. A log answers the dose due at its ScheduledAt, and belongs to
+// the period that dose is due in even if it was taken after the period; if a
+// dose was logged more than once the latest log counts.
+func (lifecycle *Lifecycle) Adherence(ctx context.Context, periodID int64) (*AdherenceReport, error) {
+	var report *AdherenceReport
+
+	err := lifecycle.Stores.WithTx(ctx, func(tx *data.Stores) error {
+		period, err := tx.TrackingPeriodStore.GetTrackingPeriod(ctx, periodID)
+		if err != nil {
+			return err
+		}
+		report, err = lifecycle.adherence(ctx, tx, period)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (lifecycle *Lifecycle) adherence(
+	ctx context.Context,
+	tx *data.Stores,
+	period *data.TrackingPeriod,
+) (*AdherenceReport, error) {
+	report := &AdherenceReport{
+		TrackingPeriodID: period.ID,
+		UserID:           period.UserID,
+		Medications:      []*MedicationAdherence{},
+	}
+
+	from, to := periodBounds(period)
+	if now := lifecycle.Now(); now.Before(to) {
+		to = now
+	}
+
+	medications, err := tx.MedicationStore.ListUserMedications(ctx, period.UserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, medication := range medications {
+		schedule, err := tx.MedicationScheduleStore.GetScheduleForMedication(ctx, medication.ID)
+		if errors.Is(err, data.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		logs, err := tx.DoseLogStore.ListDoseLogsBetween(ctx, medication.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		report.Medications = append(
+			report.Medications,
+			assessAdherence(period, medication, from, to, schedule.DosesBetween(from, to), logs),
+		)
+	}
+
+	return report, nil
+}
+
+// assessAdherence matches the logs of a medication against the doses due
+// from from until before to. Scheduled logs are placed by their ScheduledAt
+// and unscheduled ones by their TakenAt, so a log scheduled outside the
+// window is left to the window its dose is due in.
+func assessAdherence(
+	period *data.TrackingPeriod,
+	medication *data.Medication,
+	from, to time.Time,
+	due []time.Time,
+	logs []*data.DoseLog,
+) *MedicationAdherence {
+	adherence := &MedicationAdherence{
+		MedicationID: medication.ID,
+		Medication:   medication.Name,
+		Days:         make([]*DayAdherence, period.Length()),
+	}
+	for i := range adherence.Days {
+		adherence.Days[i] = &DayAdherence{Day: i + 1, Date: period.Date(i + 1)}
+	}
+
+	// Logs are ordered by time, so a later log of the same dose replaces an
+	// earlier one.
+	within := func(at time.Time) bool {
+		return !at.Before(from) && at.Before(to)
+	}
+	answered := make(map[int64]*data.DoseLog)
+	for _, log := range logs {
+		if !log.ScheduledAt.IsZero() && within(log.ScheduledAt) {
+			answered[log.ScheduledAt.Unix()] = log
+		}
+	}
+
+	for _, at := range due {
+		trackingDay, err := period.TrackingDayFor(at)
+		if err != nil {
+			continue
+		}
+		day := adherence.Days[trackingDay-1]
+		day.Scheduled++
+
+		log, ok := answered[at.Unix()]
+		delete(answered, at.Unix())
+		switch {
+		case !ok:
+			day.Missed++
+		case log.Status == data.DoseSkipped:
+			day.Skipped++
+		case log.Status == data.DoseLate:
+			day.Late++
+		default:
+			day.Taken++
+		}
+	}
+
+	for _, log := range logs {
+		if log.Status == data.DoseSkipped {
+			continue
+		}
+		switch {
+		case log.ScheduledAt.IsZero():
+			if within(log.TakenAt) {
+				adherence.Extra++
+			}
+		case answered[log.ScheduledAt.Unix()] == log:
+			adherence.Extra++
+		}
+	}
+
+	for _, day := range adherence.Days {
+		adherence.Scheduled += day.Scheduled
+		adherence.Taken += day.Taken
+		adherence.Late += day.Late
+		adherence.Skipped += day.Skipped
+		adherence.Missed += day.Missed
+	}
+	adherence.Adherence = percentage(adherence.Taken+adherence.Late, adherence.Scheduled)
+
+	return adherence
+}
+
+// periodBounds returns the start of the first tracking day of a period and
+// the end of its last. Tracking days are calendar days in the location of
+// the start date.
+func periodBounds(period *data.TrackingPeriod) (from, to time.Time) {
+	year, month, day := period.StartDate.Date()
+	from = time.Date(year, month, day, 0, 0, 0, 0, period.StartDate.Location())
+	return from, from.AddDate(0, 0, period.Length())
+}
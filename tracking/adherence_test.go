@@ -0,0 +1,87 @@
+package tracking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// TestAssessAdherenceBoundary checks that a dose due on the last evening of
+// one period and taken after midnight counts as late in that period and not
+// as extra in the next.
+func TestAssessAdherenceBoundary(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	medication := &data.Medication{ID: 1, Name: "Metformin"}
+
+	dosesDue := func(from, to time.Time) []time.Time {
+		var due []time.Time
+		for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+			due = append(due, day.Add(8*time.Hour), day.Add(20*time.Hour))
+		}
+		return due
+	}
+	dose := func(scheduledAt, takenAt time.Time) *data.DoseLog {
+		return &data.DoseLog{
+			MedicationID: medication.ID,
+			ScheduledAt:  scheduledAt,
+			TakenAt:      takenAt,
+			Status:       data.ClassifyDose(scheduledAt, takenAt),
+		}
+	}
+
+	lastDose := start.AddDate(0, 0, 2).Add(20 * time.Hour)
+	late := dose(lastDose, lastDose.Add(5*time.Hour))
+	unscheduled := dose(time.Time{}, start.AddDate(0, 0, 3).Add(12*time.Hour))
+
+	tests := []struct {
+		name      string
+		start     time.Time
+		logs      []*data.DoseLog
+		wantLate  int
+		wantMiss  int
+		wantExtra int
+	}{
+		{
+			name:     "period the dose is due in",
+			start:    start,
+			logs:     []*data.DoseLog{late},
+			wantLate: 1,
+			wantMiss: 5,
+		},
+		{
+			name:     "period the dose is taken in",
+			start:    start.AddDate(0, 0, 3),
+			logs:     []*data.DoseLog{late},
+			wantMiss: 6,
+		},
+		{
+			name:      "unscheduled dose taken in the period",
+			start:     start.AddDate(0, 0, 3),
+			logs:      []*data.DoseLog{late, unscheduled},
+			wantMiss:  6,
+			wantExtra: 1,
+		},
+		{
+			name:     "unscheduled dose taken after the period",
+			start:    start,
+			logs:     []*data.DoseLog{late, unscheduled},
+			wantLate: 1,
+			wantMiss: 5,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			period := data.NewTrackingPeriod(1, test.start, 3)
+			from, to := periodBounds(period)
+
+			got := assessAdherence(period, medication, from, to, dosesDue(from, to), test.logs)
+			if got.Scheduled != 6 || got.Late != test.wantLate || got.Missed != test.wantMiss ||
+				got.Extra != test.wantExtra {
+				t.Fatalf("got %d scheduled, %d late, %d missed and %d extra, want 6, %d, %d and %d",
+					got.Scheduled, got.Late, got.Missed, got.Extra,
+					test.wantLate, test.wantMiss, test.wantExtra)
+			}
+		})
+	}
+}
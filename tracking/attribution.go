@@ -0,0 +1,143 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// Window is how long after the start of a meal a symptom may be caused by
+// it, from Min to Max inclusive.
+type Window struct {
+	Min time.Duration `json:"min"`
+	Max time.Duration `json:"max"`
+}
+
+// Contains reports whether a symptom lag after a meal falls in the window.
+func (window Window) Contains(lag time.Duration) bool {
+	return lag >= window.Min && lag <= window.Max
+}
+
+// DefaultAttributionWindows are the windows of the body systems whose
+// reactions are usually slower or faster than DefaultAttributionWindow.
+var DefaultAttributionWindows = map[data.BodySystem]Window{
+	data.BodySystemDigestive:    {Min: 30 * time.Minute, Max: 8 * time.Hour},
+	data.BodySystemSkin:         {Min: time.Hour, Max: 48 * time.Hour},
+	data.BodySystemRespiratory:  {Min: 0, Max: 4 * time.Hour},
+	data.BodySystemNeurological: {Min: time.Hour, Max: 24 * time.Hour},
+}
+
+// DefaultAttributionWindow applies to the other body systems and to
+// symptoms of types not in the catalog.
+var DefaultAttributionWindow = Window{Min: 0, Max: 6 * time.Hour}
+
+// Attributor links standalone symptoms to the meals eaten before them.
+type Attributor struct {
+	Stores *data.Stores
+
+	// Windows holds the window of each body system; symptoms of other
+	// systems use DefaultWindow.
+	Windows       map[data.BodySystem]Window
+	DefaultWindow Window
+}
+
+func NewAttributor(stores *data.Stores) *Attributor {
+	return &Attributor{
+		Stores:        stores,
+		Windows:       DefaultAttributionWindows,
+		DefaultWindow: DefaultAttributionWindow,
+	}
+}
+
+// AttributionReport lists the attributions made for a tracking period and
+// the standalone symptoms no meal could be found for.
+type AttributionReport struct {
+	TrackingPeriodID int64                      `json:"tracking_period_id"`
+	Attributions     []*data.SymptomAttribution `json:"attributions"`
+	Unattributed     []int64                    `json:"unattributed,omitempty"`
+}
+
+// Attribute links every standalone symptom of a tracking period to the
+// meals of the period that started within the window of the symptom's body
+// system before it. Earlier attributions of the symptoms are replaced, so
+// Attribute can be rerun after symptoms or meals are logged or the windows
+// change.
+func (attributor *Attributor) Attribute(ctx context.Context, periodID int64) (*AttributionReport, error) {
+	var report *AttributionReport
+
+	err := attributor.Stores.WithTx(ctx, func(tx *data.Stores) error {
+		period, err := tx.TrackingPeriodStore.GetTrackingPeriod(ctx, periodID)
+		if err != nil {
+			return err
+		}
+		symptoms, err := tx.SymptomStore.ListStandaloneSymptoms(ctx, period.UserID, period.ID)
+		if err != nil {
+			return err
+		}
+		entries, err := tx.MealEntryStore.ListUserMealEntries(ctx, period.UserID, period.ID)
+		if err != nil {
+			return err
+		}
+
+		report = &AttributionReport{TrackingPeriodID: period.ID}
+		windows := make(map[string]Window)
+		for _, symptom := range symptoms {
+			key := strings.ToLower(symptom.SymptomType)
+			window, ok := windows[key]
+			if !ok {
+				if window, err = attributor.window(ctx, tx, symptom.SymptomType); err != nil {
+					return err
+				}
+				windows[key] = window
+			}
+
+			if err := tx.SymptomAttributionStore.DeleteAllAttributionsForSymptom(ctx, symptom.ID); err != nil {
+				return err
+			}
+
+			attributed := false
+			for _, entry := range entries {
+				lag := symptom.OccurredAt.Sub(entry.MealTime)
+				if !window.Contains(lag) {
+					continue
+				}
+				attribution, err := tx.SymptomAttributionStore.CreateSymptomAttribution(
+					ctx,
+					&data.SymptomAttribution{SymptomID: symptom.ID, MealEntryID: entry.ID, Lag: lag},
+				)
+				if err != nil {
+					return err
+				}
+				report.Attributions = append(report.Attributions, attribution)
+				attributed = true
+			}
+			if !attributed {
+				report.Unattributed = append(report.Unattributed, symptom.ID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// window returns the window of the body system of the named symptom type.
+func (attributor *Attributor) window(ctx context.Context, tx *data.Stores, symptomType string) (Window, error) {
+	catalogued, err := tx.SymptomTypeStore.GetSymptomTypeByName(ctx, symptomType)
+	if errors.Is(err, data.ErrRecordNotFound) {
+		return attributor.DefaultWindow, nil
+	}
+	if err != nil {
+		return Window{}, err
+	}
+	if window, ok := attributor.Windows[catalogued.BodySystem]; ok {
+		return window, nil
+	}
+	return attributor.DefaultWindow, nil
+}
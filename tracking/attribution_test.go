@@ -0,0 +1,187 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func TestWindowContains(t *testing.T) {
+	window := Window{Min: 30 * time.Minute, Max: 8 * time.Hour}
+	tests := []struct {
+		lag  time.Duration
+		want bool
+	}{
+		{-time.Minute, false},
+		{0, false},
+		{30*time.Minute - time.Second, false},
+		{30 * time.Minute, true},
+		{4 * time.Hour, true},
+		{8 * time.Hour, true},
+		{8*time.Hour + time.Second, false},
+	}
+	for _, test := range tests {
+		if got := window.Contains(test.lag); got != test.want {
+			t.Errorf("%+v.Contains(%s) = %t, want %t", window, test.lag, got, test.want)
+		}
+	}
+}
+
+func TestAttribute(t *testing.T) {
+	ctx := context.Background()
+	stores := data.NewMemoryStores()
+	must := func(what string, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", what, err)
+		}
+	}
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	period := newTestPeriod(t, stores, start, 7)
+	at := func(hour, minute int) time.Time {
+		return start.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	for _, symptomType := range []*data.SymptomType{
+		{Name: "Bloating", BodySystem: data.BodySystemDigestive, SeverityMax: 10},
+		{Name: "Hives", BodySystem: data.BodySystemSkin, SeverityMax: 10},
+		{Name: "Joint pain", BodySystem: data.BodySystemMusculoskeletal, SeverityMax: 10},
+	} {
+		_, err := stores.SymptomTypeStore.CreateSymptomType(ctx, symptomType)
+		must("CreateSymptomType", err)
+	}
+
+	meals := make(map[int64]string)
+	for _, entry := range []*data.MealEntry{
+		{MealType: data.MealBreakfast, MealTime: at(8, 0)},
+		{MealType: data.MealLunch, MealTime: at(12, 0)},
+	} {
+		entry.UserID, entry.TrackingPeriodID, entry.TrackingDay = period.UserID, period.ID, 1
+		entry.PortionQuantity, entry.PortionUnit = 1, data.PortionServing
+		created, err := stores.MealEntryStore.CreateMealEntry(ctx, entry)
+		must("CreateMealEntry", err)
+		meals[created.ID] = string(created.MealType)
+	}
+	var lunchID int64
+	for id, mealType := range meals {
+		if mealType == string(data.MealLunch) {
+			lunchID = id
+		}
+	}
+
+	// Digestive symptoms follow meals by 30 minutes to 8 hours, skin ones
+	// by 1 to 48 hours, and the rest, catalogued or not, by up to 6 hours.
+	symptoms := make(map[int64]string)
+	for _, symptom := range []struct {
+		label, symptomType string
+		occurredAt         time.Time
+	}{
+		{"bloating 12:30", "Bloating", at(12, 30)},
+		{"bloating 16:01", "bloating", at(16, 1)},
+		{"hives 12:59", "Hives", at(12, 59)},
+		{"sore knee 18:00", "Sore knee", at(18, 0)},
+		{"joint pain 18:01", "Joint pain", at(18, 1)},
+		{"bloating 07:00", "Bloating", at(7, 0)},
+	} {
+		created, err := stores.SymptomStore.CreateSymptom(ctx, &data.Symptom{
+			UserID:           period.UserID,
+			TrackingPeriodID: period.ID,
+			OccurredAt:       symptom.occurredAt,
+			SymptomType:      symptom.symptomType,
+			Severity:         5,
+		})
+		must("CreateSymptom", err)
+		symptoms[created.ID] = symptom.label
+	}
+	// Symptoms logged with a meal are already attributed to it.
+	_, err := stores.SymptomStore.CreateSymptom(ctx, &data.Symptom{
+		MealEntryID: lunchID,
+		SymptomType: "Bloating",
+		Severity:    5,
+	})
+	must("CreateSymptom", err)
+
+	describe := func(report *AttributionReport) string {
+		var lines []string
+		for _, attribution := range report.Attributions {
+			lines = append(lines, fmt.Sprintf("%s after %s", symptoms[attribution.SymptomID], meals[attribution.MealEntryID]))
+		}
+		for _, id := range report.Unattributed {
+			lines = append(lines, symptoms[id]+" unattributed")
+		}
+		sort.Strings(lines)
+		return strings.Join(lines, "\n")
+	}
+	check := func(report *AttributionReport, want ...string) {
+		t.Helper()
+		sort.Strings(want)
+		if got := describe(report); got != strings.Join(want, "\n") {
+			t.Fatalf("got attributions\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+		}
+		if report.TrackingPeriodID != period.ID {
+			t.Fatalf("report is for period %d, want %d", report.TrackingPeriodID, period.ID)
+		}
+		// The store holds exactly the reported attributions.
+		stored := 0
+		for id := range symptoms {
+			attributions, err := stores.SymptomAttributionStore.ListAttributionsForSymptom(ctx, id)
+			must("ListAttributionsForSymptom", err)
+			stored += len(attributions)
+		}
+		if stored != len(report.Attributions) {
+			t.Fatalf("%d attributions stored, want the %d reported", stored, len(report.Attributions))
+		}
+	}
+
+	attributor := NewAttributor(stores)
+	report, err := attributor.Attribute(ctx, period.ID)
+	must("Attribute", err)
+	want := []string{
+		"bloating 12:30 after Breakfast",
+		"bloating 12:30 after Lunch",
+		"bloating 16:01 after Lunch",
+		"hives 12:59 after Breakfast",
+		"sore knee 18:00 after Lunch",
+		"joint pain 18:01 unattributed",
+		"bloating 07:00 unattributed",
+	}
+	check(report, want...)
+	for _, attribution := range report.Attributions {
+		if symptoms[attribution.SymptomID] == "bloating 12:30" && attribution.MealEntryID == lunchID &&
+			attribution.Lag != 30*time.Minute {
+			t.Fatalf("bloating after lunch has lag %s, want 30m", attribution.Lag)
+		}
+	}
+
+	// Rerunning replaces the earlier attributions instead of adding to them.
+	report, err = attributor.Attribute(ctx, period.ID)
+	must("Attribute", err)
+	check(report, want...)
+
+	// So does rerunning with a wider default window.
+	attributor.DefaultWindow = Window{Min: 0, Max: 12 * time.Hour}
+	report, err = attributor.Attribute(ctx, period.ID)
+	must("Attribute", err)
+	check(report,
+		"bloating 12:30 after Breakfast",
+		"bloating 12:30 after Lunch",
+		"bloating 16:01 after Lunch",
+		"hives 12:59 after Breakfast",
+		"sore knee 18:00 after Breakfast",
+		"sore knee 18:00 after Lunch",
+		"joint pain 18:01 after Breakfast",
+		"joint pain 18:01 after Lunch",
+		"bloating 07:00 unattributed",
+	)
+
+	_, err = attributor.Attribute(ctx, period.ID+100)
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		t.Fatalf("Attribute of an unknown period: got error %v, want %v", err, data.ErrRecordNotFound)
+	}
+}
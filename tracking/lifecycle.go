@@ -0,0 +1,218 @@
+// Package tracking manages tracking periods over their lifetime: closing
+// them once they run out and summarizing what was logged and taken during
+// them, including how closely medication schedules were followed.
+package tracking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// DefaultExpectedMeals are the meals a user is asked to log every day of a
+// tracking period. Snacks are optional.
+var DefaultExpectedMeals = []data.MealType{data.MealBreakfast, data.MealLunch, data.MealDinner}
+
+// DefaultAbandonedBelow is the share of expected meals under which an
+// expired period counts as abandoned rather than incomplete.
+const DefaultAbandonedBelow = 0.25
+
+// Lifecycle closes tracking periods whose end date has passed, recording
+// whether the user logged every expected meal.
+type Lifecycle struct {
+	Stores *data.Stores
+
+	// ExpectedMeals are the meal types that must be logged each day for a
+	// period to count as completed.
+	ExpectedMeals []data.MealType
+	// AbandonedBelow is the share of expected meals, between 0 and 1, under
+	// which a period is marked abandoned instead of incomplete.
+	AbandonedBelow float64
+
+	// Now returns the current time. It is time.Now unless overridden.
+	Now func() time.Time
+}
+
+func NewLifecycle(stores *data.Stores) *Lifecycle {
+	return &Lifecycle{
+		Stores:         stores,
+		ExpectedMeals:  DefaultExpectedMeals,
+		AbandonedBelow: DefaultAbandonedBelow,
+		Now:            time.Now,
+	}
+}
+
+// Closure describes how a period was closed.
+type Closure struct {
+	TrackingPeriodID int64  `json:"tracking_period_id"`
+	UserID           int64  `json:"user_id"`
+	Status           string `json:"status"`
+	Reason           string `json:"reason,omitempty"`
+	ExpectedMeals    int    `json:"expected_meals"`
+	LoggedMeals      int    `json:"logged_meals"`
+}
+
+// SweepReport lists the periods closed by a sweep and those that could not
+// be closed.
+type SweepReport struct {
+	Closed []Closure        `json:"closed"`
+	Failed map[int64]string `json:"failed,omitempty"`
+}
+
+// Sweep closes every tracking period that has expired. Each period is closed
+// in its own transaction, so one failure does not hold back the rest; the
+// failures are reported in the returned report and joined into the error.
+// Sweep is safe to run periodically and from several workers at once.
+func (lifecycle *Lifecycle) Sweep(ctx context.Context) (*SweepReport, error) {
+	periods, err := lifecycle.Stores.TrackingPeriodStore.ListExpiredTrackingPeriods(
+		ctx,
+		lifecycle.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SweepReport{}
+	var errs []error
+
+	for _, period := range periods {
+		closure, err := lifecycle.Close(ctx, period.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, errors.Join(append(errs, err)...)
+			}
+			if report.Failed == nil {
+				report.Failed = make(map[int64]string)
+			}
+			report.Failed[period.ID] = err.Error()
+			errs = append(errs, fmt.Errorf("close tracking period %d: %w", period.ID, err))
+			continue
+		}
+		if closure != nil {
+			report.Closed = append(report.Closed, *closure)
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// Close closes the tracking period with the given ID, marking it completed,
+// incomplete or abandoned depending on how many of the expected meals were
+// logged. It returns a nil Closure if the period was already closed, e.g. by
+// a concurrent sweep.
+func (lifecycle *Lifecycle) Close(ctx context.Context, periodID int64) (*Closure, error) {
+	var closure *Closure
+
+	err := lifecycle.Stores.WithTx(ctx, func(tx *data.Stores) error {
+		period, err := tx.TrackingPeriodStore.GetTrackingPeriod(ctx, periodID)
+		if err != nil {
+			return err
+		}
+		if period.IsCompleted {
+			return nil
+		}
+
+		entries, err := tx.MealEntryStore.ListUserMealEntries(ctx, period.UserID, period.ID)
+		if err != nil {
+			return err
+		}
+
+		assessed := lifecycle.assess(period, entries)
+		closed, err := tx.TrackingPeriodStore.CloseTrackingPeriod(
+			ctx,
+			period.ID,
+			assessed.Status,
+			assessed.Reason,
+		)
+		if err != nil || !closed {
+			return err
+		}
+		closure = assessed
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return closure, nil
+}
+
+// CurrentTrackingPeriod returns the user's open tracking period. An open
+// period that has already expired is closed first, and data.ErrRecordNotFound
+// is returned just as if there were no open period.
+func (lifecycle *Lifecycle) CurrentTrackingPeriod(
+	ctx context.Context,
+	userID int64,
+) (*data.TrackingPeriod, error) {
+	now := lifecycle.Now()
+	period, err := lifecycle.Stores.TrackingPeriodStore.GetCurrentTrackingPeriod(ctx, userID, now)
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		return period, err
+	}
+
+	// The user may still have an open period that no sweep has closed yet.
+	periods, err := lifecycle.Stores.TrackingPeriodStore.ListUserTrackingPeriods(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, period := range periods {
+		if period.IsCompleted || period.EndDate.After(now) {
+			continue
+		}
+		if _, err := lifecycle.Close(ctx, period.ID); err != nil {
+			return nil, err
+		}
+	}
+	return nil, data.ErrRecordNotFound
+}
+
+// assess decides how a period ends based on the meal entries logged in it.
+func (lifecycle *Lifecycle) assess(period *data.TrackingPeriod, entries []*data.MealEntry) *Closure {
+	logged := make(map[int]map[data.MealType]bool)
+	for _, entry := range entries {
+		if logged[entry.TrackingDay] == nil {
+			logged[entry.TrackingDay] = make(map[data.MealType]bool)
+		}
+		logged[entry.TrackingDay][entry.MealType] = true
+	}
+
+	closure := &Closure{
+		TrackingPeriodID: period.ID,
+		UserID:           period.UserID,
+		ExpectedMeals:    period.Length() * len(lifecycle.ExpectedMeals),
+	}
+
+	var missing []string
+	for day := 1; day <= period.Length(); day++ {
+		for _, mealType := range lifecycle.ExpectedMeals {
+			if logged[day][mealType] {
+				closure.LoggedMeals++
+			} else {
+				missing = append(missing, fmt.Sprintf("day %d %s", day, mealType))
+			}
+		}
+	}
+
+	switch {
+	case len(missing) == 0:
+		closure.Status = data.TrackingPeriodCompleted
+	case float64(closure.LoggedMeals) < lifecycle.AbandonedBelow*float64(closure.ExpectedMeals):
+		closure.Status = data.TrackingPeriodAbandoned
+		closure.Reason = fmt.Sprintf(
+			"expired with only %d of %d expected meals logged",
+			closure.LoggedMeals, closure.ExpectedMeals,
+		)
+	default:
+		closure.Status = data.TrackingPeriodIncomplete
+		closure.Reason = fmt.Sprintf(
+			"expired with %d of %d expected meals missing: %s",
+			len(missing), closure.ExpectedMeals, strings.Join(missing, ", "),
+		)
+	}
+
+	return closure
+}
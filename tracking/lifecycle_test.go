@@ -0,0 +1,130 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// newTestPeriod creates a user with a tracking period of the given length
+// starting at start.
+func newTestPeriod(t *testing.T, stores *data.Stores, start time.Time, lengthDays int) *data.TrackingPeriod {
+	t.Helper()
+
+	ctx := context.Background()
+	user, err := stores.UserStore.CreateUser(ctx, &data.User{
+		UserName:    "user" + start.Format("20060102150405.000000000"),
+		Email:       start.Format("20060102150405.000000000") + "@example.com",
+		PhoneNumber: start.Format("0102150405.000000000"),
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	period, err := stores.TrackingPeriodStore.CreateTrackingPeriod(
+		ctx,
+		data.NewTrackingPeriod(user.ID, start, lengthDays),
+	)
+	if err != nil {
+		t.Fatalf("CreateTrackingPeriod: %v", err)
+	}
+	return period
+}
+
+func TestSweepConcurrent(t *testing.T) {
+	ctx := context.Background()
+	stores := data.NewMemoryStores()
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	want := make(map[int64]bool)
+	for i := range 10 {
+		period := newTestPeriod(t, stores, start.Add(time.Duration(i)*time.Millisecond), 3)
+		want[period.ID] = true
+	}
+
+	lifecycle := NewLifecycle(stores)
+	lifecycle.Now = func() time.Time { return start.AddDate(0, 1, 0) }
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		closed = make(map[int64]int)
+	)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report, err := lifecycle.Sweep(ctx)
+			if err != nil {
+				t.Errorf("Sweep: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, closure := range report.Closed {
+				closed[closure.TrackingPeriodID]++
+			}
+		}()
+	}
+	wg.Wait()
+
+	for id := range want {
+		if closed[id] != 1 {
+			t.Errorf("period %d closed %d times, want once", id, closed[id])
+		}
+		period, err := stores.TrackingPeriodStore.GetTrackingPeriod(ctx, id)
+		if err != nil {
+			t.Fatalf("GetTrackingPeriod: %v", err)
+		}
+		if period.Status != data.TrackingPeriodAbandoned {
+			t.Errorf("period %d has status %q, want %q", id, period.Status, data.TrackingPeriodAbandoned)
+		}
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	ctx := context.Background()
+	stores := data.NewMemoryStores()
+	period := newTestPeriod(t, stores, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 3)
+
+	lifecycle := NewLifecycle(stores)
+	closure, err := lifecycle.Close(ctx, period.ID)
+	if err != nil || closure == nil {
+		t.Fatalf("Close returned %+v, %v; want a closure", closure, err)
+	}
+	closure, err = lifecycle.Close(ctx, period.ID)
+	if err != nil || closure != nil {
+		t.Fatalf("Close of a closed period returned %+v, %v; want nil", closure, err)
+	}
+}
+
+func TestCurrentTrackingPeriod(t *testing.T) {
+	ctx := context.Background()
+	stores := data.NewMemoryStores()
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	period := newTestPeriod(t, stores, start, 3)
+
+	lifecycle := NewLifecycle(stores)
+	lifecycle.Now = func() time.Time { return start.Add(36 * time.Hour) }
+	current, err := lifecycle.CurrentTrackingPeriod(ctx, period.UserID)
+	if err != nil || current.ID != period.ID {
+		t.Fatalf("CurrentTrackingPeriod returned %+v, %v; want period %d", current, err, period.ID)
+	}
+
+	lifecycle.Now = func() time.Time { return period.EndDate }
+	current, err = lifecycle.CurrentTrackingPeriod(ctx, period.UserID)
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		t.Fatalf("CurrentTrackingPeriod of an expired period returned %+v, %v; want %v",
+			current, err, data.ErrRecordNotFound)
+	}
+	closed, err := stores.TrackingPeriodStore.GetTrackingPeriod(ctx, period.ID)
+	if err != nil {
+		t.Fatalf("GetTrackingPeriod: %v", err)
+	}
+	if !closed.IsCompleted || closed.Status != data.TrackingPeriodAbandoned {
+		t.Fatalf("expired period is %+v, want it closed as abandoned", closed)
+	}
+}
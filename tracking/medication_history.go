@@ -0,0 +1,123 @@
+package tracking
+
+import (
+	"context"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// MedicationUse is a medication or supplement taken during a tracking
+// period, from tracking day FirstDay to LastDay. Exactly one of
+// MedicationID and SupplementID is set.
+type MedicationUse struct {
+	MedicationID int64     `json:"medication_id,omitempty"`
+	SupplementID int64     `json:"supplement_id,omitempty"`
+	Name         string    `json:"name"`
+	Dosage       string    `json:"dosage"`
+	StartDate    time.Time `json:"start_date"`
+	EndDate      time.Time `json:"end_date"`
+	FirstDay     int       `json:"first_day"`
+	LastDay      int       `json:"last_day"`
+}
+
+// MedicationHistory lists the medications and supplements a user took at
+// some point during a tracking period.
+type MedicationHistory struct {
+	TrackingPeriodID int64            `json:"tracking_period_id"`
+	UserID           int64            `json:"user_id"`
+	Medications      []*MedicationUse `json:"medications"`
+	Supplements      []*MedicationUse `json:"supplements"`
+}
+
+// MedicationHistory reports which of the user's medications and supplements
+// overlapped a tracking period, judging by their start and end dates rather
+// than their Current flag. Records with neither date are assumed to span
+// the whole period if they are marked current.
+func (lifecycle *Lifecycle) MedicationHistory(ctx context.Context, periodID int64) (*MedicationHistory, error) {
+	var history *MedicationHistory
+
+	err := lifecycle.Stores.WithTx(ctx, func(tx *data.Stores) error {
+		period, err := tx.TrackingPeriodStore.GetTrackingPeriod(ctx, periodID)
+		if err != nil {
+			return err
+		}
+		history, err = medicationHistory(ctx, tx, period)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func medicationHistory(
+	ctx context.Context,
+	tx *data.Stores,
+	period *data.TrackingPeriod,
+) (*MedicationHistory, error) {
+	history := &MedicationHistory{
+		TrackingPeriodID: period.ID,
+		UserID:           period.UserID,
+		Medications:      []*MedicationUse{},
+		Supplements:      []*MedicationUse{},
+	}
+	from, to := periodBounds(period)
+
+	medications, err := tx.MedicationStore.ListUserMedications(ctx, period.UserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, medication := range medications {
+		if !medication.TakenBetween(from, to) {
+			continue
+		}
+		use := &MedicationUse{
+			MedicationID: medication.ID,
+			Name:         medication.Name,
+			Dosage:       medication.Dosage,
+			StartDate:    medication.StartDate,
+			EndDate:      medication.EndDate,
+		}
+		use.FirstDay, use.LastDay = overlapDays(period, medication.StartDate, medication.EndDate)
+		history.Medications = append(history.Medications, use)
+	}
+
+	supplements, err := tx.DietarySupplementStore.ListUserDietarySupplements(ctx, period.UserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, supplement := range supplements {
+		if !supplement.TakenBetween(from, to) {
+			continue
+		}
+		use := &MedicationUse{
+			SupplementID: supplement.ID,
+			Name:         supplement.Name,
+			Dosage:       supplement.Dosage,
+			StartDate:    supplement.StartDate,
+			EndDate:      supplement.EndDate,
+		}
+		use.FirstDay, use.LastDay = overlapDays(period, supplement.StartDate, supplement.EndDate)
+		history.Supplements = append(history.Supplements, use)
+	}
+
+	return history, nil
+}
+
+// overlapDays returns the first and last tracking days on which something
+// taken from start until before end was taken. Unset dates leave that side
+// open.
+func overlapDays(period *data.TrackingPeriod, start, end time.Time) (first, last int) {
+	first, last = 1, period.Length()
+	if day, err := period.TrackingDayFor(start); err == nil {
+		first = day
+	}
+	if !end.IsZero() {
+		if day, err := period.TrackingDayFor(end.Add(-time.Nanosecond)); err == nil {
+			last = day
+		}
+	}
+	return first, last
+}
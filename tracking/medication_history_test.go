@@ -0,0 +1,109 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func TestOverlapDays(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	period := data.NewTrackingPeriod(1, start, 7)
+	day := func(n int, hour int) time.Time {
+		return start.AddDate(0, 0, n-1).Add(time.Duration(hour) * time.Hour)
+	}
+
+	tests := []struct {
+		name        string
+		start, end  time.Time
+		first, last int
+	}{
+		{name: "undated", first: 1, last: 7},
+		{name: "open end", start: day(3, 10), first: 3, last: 7},
+		{name: "open start", end: day(5, 9), first: 1, last: 5},
+		{name: "started before", start: day(-3, 0), end: day(2, 12), first: 1, last: 2},
+		{name: "ended after", start: day(6, 0), end: day(12, 0), first: 6, last: 7},
+		// Taken until before midnight, so not on the day that starts then.
+		{name: "ends at midnight", start: day(2, 8), end: day(5, 0), first: 2, last: 4},
+		{name: "ends at period end", end: period.EndDate, first: 1, last: 7},
+	}
+	for _, test := range tests {
+		first, last := overlapDays(period, test.start, test.end)
+		if first != test.first || last != test.last {
+			t.Errorf("%s: got days %d to %d, want %d to %d", test.name, first, last, test.first, test.last)
+		}
+	}
+}
+
+func TestMedicationHistory(t *testing.T) {
+	ctx := context.Background()
+	stores := data.NewMemoryStores()
+	must := func(what string, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", what, err)
+		}
+	}
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	period := newTestPeriod(t, stores, start, 7)
+	other := newTestPeriod(t, stores, start.Add(time.Nanosecond), 7)
+
+	for _, medication := range []*data.Medication{
+		{UserID: period.UserID, Name: "Omeprazole", EndDate: start.AddDate(0, 0, 3)},
+		{UserID: period.UserID, Name: "Amoxicillin", StartDate: start.AddDate(0, 0, 2).Add(12 * time.Hour)},
+		{UserID: period.UserID, Name: "Levothyroxine", Current: true},
+		{UserID: period.UserID, Name: "Ibuprofen"},
+		{UserID: period.UserID, Name: "Prednisone", StartDate: start.AddDate(0, 0, -10), EndDate: start},
+		{UserID: period.UserID, Name: "Cetirizine", StartDate: period.EndDate},
+		{UserID: other.UserID, Name: "Metformin", Current: true},
+	} {
+		_, err := stores.MedicationStore.CreateMedication(ctx, medication)
+		must("CreateMedication", err)
+	}
+	for _, supplement := range []*data.DietarySupplement{
+		{UserID: period.UserID, Name: "Magnesium", StartDate: start.AddDate(0, 0, -5), EndDate: start.AddDate(0, 0, 1)},
+		{UserID: period.UserID, Name: "Zinc", Current: false},
+	} {
+		_, err := stores.DietarySupplementStore.CreateDietarySupplement(ctx, supplement)
+		must("CreateDietarySupplement", err)
+	}
+
+	history, err := NewLifecycle(stores).MedicationHistory(ctx, period.ID)
+	must("MedicationHistory", err)
+	if history.TrackingPeriodID != period.ID || history.UserID != period.UserID {
+		t.Fatalf("MedicationHistory returned the history of period %d of user %d, want period %d of user %d",
+			history.TrackingPeriodID, history.UserID, period.ID, period.UserID)
+	}
+
+	// Ibuprofen is undated and not current, Prednisone was stopped and
+	// Cetirizine started right at the bounds of the period, and Metformin
+	// is another user's.
+	uses := func(uses []*MedicationUse) string {
+		var got []string
+		for _, use := range uses {
+			got = append(got, fmt.Sprintf("%s %d-%d", use.Name, use.FirstDay, use.LastDay))
+		}
+		return strings.Join(got, ", ")
+	}
+	if got, want := uses(history.Medications), "Omeprazole 1-3, Amoxicillin 3-7, Levothyroxine 1-7"; got != want {
+		t.Errorf("got medications %s, want %s", got, want)
+	}
+	if got, want := uses(history.Supplements), "Magnesium 1-1"; got != want {
+		t.Errorf("got supplements %s, want %s", got, want)
+	}
+	for _, use := range history.Medications {
+		if use.MedicationID == 0 || use.SupplementID != 0 {
+			t.Errorf("medication use %+v, want only a medication ID", use)
+		}
+	}
+
+	_, err = NewLifecycle(stores).MedicationHistory(ctx, other.ID+100)
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		t.Fatalf("MedicationHistory of an unknown period: got error %v, want %v", err, data.ErrRecordNotFound)
+	}
+}
@@ -0,0 +1,207 @@
+package tracking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+// MealSummary describes how fully a logged meal was filled in. Symptoms
+// counts the symptoms logged against the meal and the standalone symptoms
+// attributed to it.
+type MealSummary struct {
+	MealEntryID     int64         `json:"meal_entry_id"`
+	MealType        data.MealType `json:"meal_type"`
+	IsCompleted     bool          `json:"is_completed"`
+	Foods           int           `json:"foods"`
+	Symptoms        int           `json:"symptoms"`
+	MissingFoods    bool          `json:"missing_foods"`
+	MissingSymptoms bool          `json:"missing_symptoms"`
+}
+
+// Complete reports whether the meal counts towards completion: it is marked
+// completed and has at least one food.
+func (meal *MealSummary) Complete() bool {
+	return meal.IsCompleted && !meal.MissingFoods
+}
+
+// DaySummary describes what was logged on one tracking day. Missing lists
+// the expected meal types with no entry, and Completion is the percentage
+// of expected meals that are complete.
+type DaySummary struct {
+	Day                int             `json:"day"`
+	Date               time.Time       `json:"date"`
+	Meals              []*MealSummary  `json:"meals"`
+	Missing            []data.MealType `json:"missing"`
+	StandaloneSymptoms int             `json:"standalone_symptoms"`
+	ExpectedMeals      int             `json:"expected_meals"`
+	CompleteMeals      int             `json:"complete_meals"`
+	Completion         float64         `json:"completion"`
+}
+
+// PeriodSummary describes what was logged during a tracking period, day by
+// day, with the completion percentage over the whole period.
+type PeriodSummary struct {
+	TrackingPeriodID int64         `json:"tracking_period_id"`
+	UserID           int64         `json:"user_id"`
+	Days             []*DaySummary `json:"days"`
+	ExpectedMeals    int           `json:"expected_meals"`
+	LoggedMeals      int           `json:"logged_meals"`
+	CompleteMeals    int           `json:"complete_meals"`
+	Completion       float64       `json:"completion"`
+}
+
+// Summarize reports, for every day of a tracking period, which meals were
+// logged and completed, which lack foods or symptoms, and which expected
+// meals are missing. Meals of types not in ExpectedMeals are listed but do
+// not count towards completion.
+func (lifecycle *Lifecycle) Summarize(ctx context.Context, periodID int64) (*PeriodSummary, error) {
+	var summary *PeriodSummary
+
+	err := lifecycle.Stores.WithTx(ctx, func(tx *data.Stores) error {
+		period, err := tx.TrackingPeriodStore.GetTrackingPeriod(ctx, periodID)
+		if err != nil {
+			return err
+		}
+		summary, err = lifecycle.summarize(ctx, tx, period)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// SummarizeDay reports on a single day of a tracking period like Summarize.
+// It returns data.ErrOutsideTrackingPeriod if the period has no such day.
+func (lifecycle *Lifecycle) SummarizeDay(ctx context.Context, periodID int64, day int) (*DaySummary, error) {
+	summary, err := lifecycle.Summarize(ctx, periodID)
+	if err != nil {
+		return nil, err
+	}
+	if day < 1 || day > len(summary.Days) {
+		return nil, fmt.Errorf("day %d: %w", day, data.ErrOutsideTrackingPeriod)
+	}
+	return summary.Days[day-1], nil
+}
+
+func (lifecycle *Lifecycle) summarize(
+	ctx context.Context,
+	tx *data.Stores,
+	period *data.TrackingPeriod,
+) (*PeriodSummary, error) {
+	summary := &PeriodSummary{
+		TrackingPeriodID: period.ID,
+		UserID:           period.UserID,
+		Days:             make([]*DaySummary, period.Length()),
+	}
+	for i := range summary.Days {
+		summary.Days[i] = &DaySummary{
+			Day:   i + 1,
+			Date:  period.Date(i + 1),
+			Meals: []*MealSummary{},
+		}
+	}
+
+	entries, err := tx.MealEntryStore.ListUserMealEntries(ctx, period.UserID, period.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.TrackingDay < 1 || entry.TrackingDay > len(summary.Days) {
+			continue
+		}
+		meal, err := summarizeMeal(ctx, tx, entry)
+		if err != nil {
+			return nil, err
+		}
+		day := summary.Days[entry.TrackingDay-1]
+		day.Meals = append(day.Meals, meal)
+		summary.LoggedMeals++
+	}
+
+	symptoms, err := tx.SymptomStore.ListStandaloneSymptoms(ctx, period.UserID, period.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, symptom := range symptoms {
+		if day, err := period.TrackingDayFor(symptom.OccurredAt); err == nil {
+			summary.Days[day-1].StandaloneSymptoms++
+		}
+	}
+
+	for _, day := range summary.Days {
+		lifecycle.assessDay(day)
+		summary.ExpectedMeals += day.ExpectedMeals
+		summary.CompleteMeals += day.CompleteMeals
+	}
+	summary.Completion = percentage(summary.CompleteMeals, summary.ExpectedMeals)
+
+	return summary, nil
+}
+
+// assessDay fills in the missing meal types and completion of a day whose
+// meals have been collected.
+func (lifecycle *Lifecycle) assessDay(day *DaySummary) {
+	day.Missing = []data.MealType{}
+	day.ExpectedMeals = len(lifecycle.ExpectedMeals)
+	for _, mealType := range lifecycle.ExpectedMeals {
+		logged, complete := false, false
+		for _, meal := range day.Meals {
+			if meal.MealType == mealType {
+				logged = true
+				complete = complete || meal.Complete()
+			}
+		}
+		if !logged {
+			day.Missing = append(day.Missing, mealType)
+		}
+		if complete {
+			day.CompleteMeals++
+		}
+	}
+	day.Completion = percentage(day.CompleteMeals, day.ExpectedMeals)
+}
+
+func summarizeMeal(ctx context.Context, tx *data.Stores, entry *data.MealEntry) (*MealSummary, error) {
+	meal := &MealSummary{
+		MealEntryID: entry.ID,
+		MealType:    entry.MealType,
+		IsCompleted: entry.IsCompleted,
+	}
+
+	mealFoods, err := tx.MealFoodStore.GetMealFoodsForMeal(ctx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	customFoods, err := tx.CustomFoodStore.GetCustomFoodsForMeal(ctx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	meal.Foods = len(mealFoods) + len(customFoods)
+
+	symptoms, err := tx.SymptomStore.ListSymptomsForMeal(ctx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	attributions, err := tx.SymptomAttributionStore.ListAttributionsForMeal(ctx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	meal.Symptoms = len(symptoms) + len(attributions)
+
+	meal.MissingFoods = meal.Foods == 0
+	meal.MissingSymptoms = meal.Symptoms == 0
+	return meal, nil
+}
+
+// percentage returns part as a percentage of whole, or 100 if whole is 0.
+func percentage(part, whole int) float64 {
+	if whole == 0 {
+		return 100
+	}
+	return 100 * float64(part) / float64(whole)
+}
@@ -0,0 +1,183 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Universal-Selfcare/utils/data"
+)
+
+func TestSummarize(t *testing.T) {
+	ctx := context.Background()
+	stores := data.NewMemoryStores()
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	period := newTestPeriod(t, stores, start, 3)
+
+	must := func(what string, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", what, err)
+		}
+	}
+	item, err := stores.FoodItemStore.CreateFoodItem(ctx, &data.FoodItem{Name: "Rice", Category: "Grains"})
+	must("CreateFoodItem", err)
+
+	// meal logs a meal with the given number of catalog and custom foods.
+	meal := func(day int, mealType data.MealType, completed bool, foods, custom int) *data.MealEntry {
+		t.Helper()
+
+		entry, err := stores.MealEntryStore.CreateMealEntry(ctx, &data.MealEntry{
+			UserID:           period.UserID,
+			TrackingPeriodID: period.ID,
+			TrackingDay:      day,
+			MealType:         mealType,
+			IsCompleted:      completed,
+			PortionQuantity:  1,
+			PortionUnit:      data.PortionServing,
+		})
+		must("CreateMealEntry", err)
+		for range foods {
+			_, err := stores.MealFoodStore.CreateMealFood(ctx, &data.MealFood{MealEntryID: entry.ID, FoodItemID: item.ID})
+			must("CreateMealFood", err)
+		}
+		for i := range custom {
+			_, err := stores.CustomFoodStore.CreateCustomFood(ctx, &data.CustomFood{
+				MealEntryID: entry.ID,
+				Name:        fmt.Sprintf("Custom %d", i),
+			})
+			must("CreateCustomFood", err)
+		}
+		return entry
+	}
+	standalone := func(at time.Time) *data.Symptom {
+		t.Helper()
+
+		symptom, err := stores.SymptomStore.CreateSymptom(ctx, &data.Symptom{
+			UserID:           period.UserID,
+			TrackingPeriodID: period.ID,
+			OccurredAt:       at,
+			SymptomType:      "headache",
+			Severity:         2,
+		})
+		must("CreateSymptom", err)
+		return symptom
+	}
+
+	// Day 1: breakfast complete with a symptom, lunch marked completed but
+	// without foods, dinner with a custom food but not marked completed, and
+	// a complete snack, which is not expected.
+	breakfast := meal(1, data.MealBreakfast, true, 1, 0)
+	_, err = stores.SymptomStore.CreateSymptom(ctx, &data.Symptom{
+		MealEntryID: breakfast.ID,
+		SymptomType: "bloating",
+		Severity:    3,
+	})
+	must("CreateSymptom", err)
+	meal(1, data.MealLunch, true, 0, 0)
+	meal(1, data.MealDinner, false, 0, 1)
+	meal(1, data.MealSnack, true, 1, 0)
+
+	// Day 2: every expected meal complete, and a standalone symptom
+	// attributed to lunch.
+	meal(2, data.MealBreakfast, true, 1, 1)
+	lunch := meal(2, data.MealLunch, true, 0, 2)
+	meal(2, data.MealDinner, true, 2, 0)
+	symptom := standalone(start.AddDate(0, 0, 1).Add(15 * time.Hour))
+	_, err = stores.SymptomAttributionStore.CreateSymptomAttribution(ctx, &data.SymptomAttribution{
+		SymptomID:   symptom.ID,
+		MealEntryID: lunch.ID,
+		Lag:         2 * time.Hour,
+	})
+	must("CreateSymptomAttribution", err)
+
+	// Day 3 has nothing logged; a standalone symptom after the period is
+	// not counted.
+	standalone(start.AddDate(0, 0, 3).Add(time.Hour))
+
+	lifecycle := NewLifecycle(stores)
+	summary, err := lifecycle.Summarize(ctx, period.ID)
+	must("Summarize", err)
+
+	if summary.ExpectedMeals != 9 || summary.LoggedMeals != 7 || summary.CompleteMeals != 4 ||
+		summary.Completion != 100*4.0/9 {
+		t.Fatalf("period has %d expected, %d logged and %d complete meals and %g%% completion, want 9, 7, 4 and %g%%",
+			summary.ExpectedMeals, summary.LoggedMeals, summary.CompleteMeals, summary.Completion, 100*4.0/9)
+	}
+
+	tests := []struct {
+		day        int
+		meals      int
+		missing    []data.MealType
+		complete   int
+		standalone int
+	}{
+		{day: 1, meals: 4, complete: 1},
+		{day: 2, meals: 3, complete: 3, standalone: 1},
+		{day: 3, missing: DefaultExpectedMeals},
+	}
+	for _, test := range tests {
+		day := summary.Days[test.day-1]
+		if day.Day != test.day || !day.Date.Equal(start.AddDate(0, 0, test.day-1)) ||
+			len(day.Meals) != test.meals || fmt.Sprint(day.Missing) != fmt.Sprint(test.missing) ||
+			day.ExpectedMeals != 3 || day.CompleteMeals != test.complete ||
+			day.StandaloneSymptoms != test.standalone {
+			t.Errorf("day %d is %+v, want %d meals, %v missing, %d complete and %d standalone symptoms",
+				test.day, day, test.meals, test.missing, test.complete, test.standalone)
+		}
+	}
+
+	meals := make(map[data.MealType]*MealSummary)
+	for _, meal := range summary.Days[0].Meals {
+		meals[meal.MealType] = meal
+	}
+	if meal := meals[data.MealBreakfast]; !meal.Complete() || meal.Symptoms != 1 || meal.MissingSymptoms {
+		t.Errorf("breakfast is %+v, want complete with a symptom", meal)
+	}
+	if meal := meals[data.MealLunch]; meal.Complete() || !meal.MissingFoods || !meal.MissingSymptoms {
+		t.Errorf("lunch is %+v, want incomplete without foods or symptoms", meal)
+	}
+	if meal := meals[data.MealDinner]; meal.Complete() || meal.Foods != 1 || meal.MissingFoods {
+		t.Errorf("dinner is %+v, want one food but not completed", meal)
+	}
+	for _, meal := range summary.Days[1].Meals {
+		if meal.MealEntryID == lunch.ID && (meal.Foods != 2 || meal.Symptoms != 1) {
+			t.Errorf("day 2 lunch is %+v, want two custom foods and the attributed symptom", meal)
+		}
+	}
+
+	day, err := lifecycle.SummarizeDay(ctx, period.ID, 2)
+	must("SummarizeDay", err)
+	if day.Day != 2 || day.Completion != 100 {
+		t.Fatalf("SummarizeDay returned %+v, want day 2 fully complete", day)
+	}
+	for _, n := range []int{0, 4} {
+		_, err = lifecycle.SummarizeDay(ctx, period.ID, n)
+		if !errors.Is(err, data.ErrOutsideTrackingPeriod) {
+			t.Errorf("SummarizeDay(%d): got error %v, want %v", n, err, data.ErrOutsideTrackingPeriod)
+		}
+	}
+
+	_, err = lifecycle.Summarize(ctx, period.ID+100)
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		t.Errorf("Summarize of a missing period: got error %v, want %v", err, data.ErrRecordNotFound)
+	}
+}
+
+func TestSummarizeNoExpectedMeals(t *testing.T) {
+	stores := data.NewMemoryStores()
+	period := newTestPeriod(t, stores, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 2)
+
+	lifecycle := NewLifecycle(stores)
+	lifecycle.ExpectedMeals = nil
+	summary, err := lifecycle.Summarize(context.Background(), period.ID)
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if len(summary.Days) != 2 || summary.ExpectedMeals != 0 || summary.Completion != 100 ||
+		len(summary.Days[0].Missing) != 0 || summary.Days[0].Completion != 100 {
+		t.Fatalf("got %+v, want two days with nothing expected and full completion", summary)
+	}
+}